@@ -0,0 +1,619 @@
+// Package client wraps a draw-tools world state store with typed methods, so callers do not build DoCommand payloads
+// or read their responses by hand. Every payload carries the shape type, so the same calls work against the
+// draw-shapes service and against the arrows, mesh, point cloud and primitives services. Each response is decoded as
+// documented for the kind of service that answers it, which the client reads from the model the service describes.
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/viam-labs/draw-tools/lib"
+	"github.com/viam-labs/draw-tools/lib/command"
+	"github.com/viam-labs/draw-tools/lib/store"
+
+	"go.viam.com/rdk/resource"
+	worldstatestore "go.viam.com/rdk/services/worldstatestore"
+)
+
+// MeshOptions describes a mesh to draw. A directory or glob model path draws every matching file.
+type MeshOptions = lib.MeshJSON
+
+// Kind is the model name of a draw-tools service, such as "draw-mesh-world-state". Each kind answers the draw, remove
+// and clear commands with its own response.
+type Kind string
+
+const (
+	KindShapes       Kind = "draw-shapes-world-state"
+	KindArrows       Kind = "draw-arrows-world-state"
+	KindMesh         Kind = "draw-mesh-world-state"
+	KindPointCloud   Kind = "draw-pointcloud-world-state"
+	KindPrimitives   Kind = "draw-primitives-world-state"
+	KindSegmentation Kind = "segmentation-world-state"
+	KindPoseTracker  Kind = "pose-tracker"
+	KindMotionPlan   Kind = "motion-plan-world-state"
+	KindCamera       Kind = "camera-pointcloud-world-state"
+	KindTrail        Kind = "movement-trail-world-state"
+	KindFrameSystem  Kind = "frame-system-world-state"
+)
+
+// modelFamily is the family of every draw-tools model.
+const modelFamily = "viam-viz:draw-tools"
+
+// removedKeys names the count of removed items in the remove and clear responses of each kind.
+var removedKeys = map[Kind]string{
+	KindShapes:       "removed",
+	KindArrows:       "arrows_removed",
+	KindMesh:         "mesh_removed",
+	KindPointCloud:   "pointclouds_removed",
+	KindPrimitives:   "primitives_removed",
+	KindSegmentation: "objects_removed",
+	KindPoseTracker:  "breadcrumbs_removed",
+	KindMotionPlan:   "transforms_removed",
+	KindCamera:       "pointclouds_removed",
+	KindTrail:        "points_removed",
+	KindFrameSystem:  "frames_removed",
+}
+
+// Client sends draw-tools commands to a world state store service.
+type Client struct {
+	service worldstatestore.Service
+
+	kindMutex sync.Mutex
+	kind      Kind
+}
+
+// Item identifies a drawn shape.
+type Item struct {
+	Type string `json:"type"`
+	UUID string `json:"uuid"`
+	Name string `json:"name"`
+}
+
+// DrawArrowsResult reports the arrows drawn by DrawArrows.
+type DrawArrowsResult struct {
+	Added int `json:"added"` // Number of arrow frames added, three for each axes triad
+}
+
+// MeshResult reports one of the files drawn by a directory or glob model path.
+type MeshResult struct {
+	ModelPath string `json:"model_path"`
+	Success   bool   `json:"success"`
+	UUID      string `json:"uuid,omitempty"`
+	Name      string `json:"name,omitempty"`
+	Error     string `json:"error,omitempty"` // Why the file failed to draw, empty on success
+}
+
+// DrawMeshResult reports the meshes drawn by DrawMesh. A single file fills in UUID and Name, while a directory or glob
+// model path fills in Results with one entry per matching file.
+type DrawMeshResult struct {
+	UUID    string       `json:"uuid,omitempty"`
+	Name    string       `json:"name,omitempty"`
+	Added   int          `json:"added"`
+	Failed  int          `json:"failed,omitempty"`
+	Results []MeshResult `json:"results,omitempty"`
+}
+
+// DrawPointCloudResult reports the point cloud drawn by DrawPointCloud.
+type DrawPointCloudResult struct {
+	UUID string `json:"uuid"`
+	Name string `json:"name"`
+}
+
+// DrawPrimitivesResult reports the primitives drawn by DrawPrimitives, in the order they were given.
+type DrawPrimitivesResult struct {
+	Added int      `json:"added"`
+	UUIDs []string `json:"uuids"`
+	Names []string `json:"names"`
+}
+
+// DrawShapesResult reports the shapes drawn by DrawShapes, in the order they were given. Meshes with a directory or
+// glob model path are drawn best effort, with one entry per matching file in Results.
+type DrawShapesResult struct {
+	Added   int          `json:"added"`
+	Items   []Item       `json:"items"`
+	Failed  int          `json:"failed,omitempty"`
+	Results []MeshResult `json:"results,omitempty"`
+}
+
+// RemoveResult reports the items removed by Remove or Clear.
+type RemoveResult struct {
+	Removed int `json:"removed"`
+}
+
+// VisibilityResult reports the transforms shown or hidden by Show or Hide.
+type VisibilityResult struct {
+	Changed int `json:"changed"` // Number of transforms whose visibility changed
+}
+
+// SceneResult reports the shapes SetScene changed. Shapes both scenes share unchanged are not counted.
+type SceneResult struct {
+	Scene   string `json:"scene"`   // Name of the scene now drawn
+	Added   int    `json:"added"`   // Number of shapes added
+	Updated int    `json:"updated"` // Number of shapes updated
+	Removed int    `json:"removed"` // Number of shapes removed
+}
+
+// New wraps a world state store service, whose kind is read from the model it describes the first time it is needed.
+func New(service worldstatestore.Service) *Client {
+	return &Client{service: service}
+}
+
+// NewOfKind wraps a world state store service of a known kind, which is never asked to describe itself.
+func NewOfKind(service worldstatestore.Service, kind Kind) *Client {
+	return &Client{service: service, kind: kind}
+}
+
+// FromDependencies wraps the named world state store service from a resource's dependencies.
+func FromDependencies(deps resource.Dependencies, name string) (*Client, error) {
+	service, err := worldstatestore.FromDependencies(deps, name)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to get world state store %v: %w", name, err)
+	}
+
+	return New(service), nil
+}
+
+// Service returns the wrapped service.
+func (c *Client) Service() worldstatestore.Service {
+	return c.service
+}
+
+// Kind returns the kind of the wrapped service. Unless the client was made by NewOfKind, it is read from the model the
+// service describes the first time it is needed, and kept once known.
+func (c *Client) Kind(ctx context.Context) (Kind, error) {
+	c.kindMutex.Lock()
+	defer c.kindMutex.Unlock()
+
+	if c.kind != "" {
+		return c.kind, nil
+	}
+
+	result, err := c.do(ctx, "describe service", map[string]any{command.Describe: true})
+	if err != nil {
+		return "", err
+	}
+
+	model, err := resource.NewModelFromString(toString(result["model"]))
+	if err != nil {
+		return "", fmt.Errorf("Failed to describe service: %w", err)
+	}
+
+	kind := Kind(model.Name)
+	if _, ok := removedKeys[kind]; !ok || model.Family.String() != modelFamily {
+		return "", fmt.Errorf("Unsupported service model %s", model)
+	}
+
+	c.kind = kind
+	return kind, nil
+}
+
+// kindFor returns the kind of the wrapped service, or an error if it is not one of the kinds that answer an action.
+func (c *Client) kindFor(ctx context.Context, action string, kinds ...Kind) (Kind, error) {
+	kind, err := c.Kind(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	for _, supported := range kinds {
+		if kind == supported {
+			return kind, nil
+		}
+	}
+
+	return "", fmt.Errorf("Unable to %s: not supported by the %s service", action, kind)
+}
+
+// DrawArrows draws arrows and axes triads on an arrows or shapes service.
+func (c *Client) DrawArrows(ctx context.Context, arrows []lib.ArrowJSON) (*DrawArrowsResult, error) {
+	payload := make([]any, 0, len(arrows))
+	for _, arrow := range arrows {
+		shapeType := lib.ShapeArrow
+		if arrow.Axes {
+			shapeType = lib.ShapeAxes
+		}
+
+		fields, err := encode(arrow, shapeType)
+		if err != nil {
+			return nil, err
+		}
+		payload = append(payload, fields)
+	}
+
+	kind, err := c.kindFor(ctx, "draw arrows", KindArrows, KindShapes)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := c.do(ctx, "draw arrows", map[string]any{"draw": payload})
+	if err != nil {
+		return nil, err
+	}
+
+	if kind == KindArrows {
+		return &DrawArrowsResult{Added: toInt(result["arrows_added"])}, nil
+	}
+
+	// the shapes service counts each axes triad as one item
+	drawn := &DrawArrowsResult{}
+	for _, item := range toItems(result["items"]) {
+		if item.Type == lib.ShapeAxes {
+			drawn.Added += 3
+		} else {
+			drawn.Added++
+		}
+	}
+
+	return drawn, nil
+}
+
+// DrawMesh draws a mesh file, or every mesh matched by a directory or glob model path, on a mesh or shapes service.
+func (c *Client) DrawMesh(ctx context.Context, options MeshOptions) (*DrawMeshResult, error) {
+	fields, err := encode(options, lib.ShapeMesh)
+	if err != nil {
+		return nil, err
+	}
+
+	kind, err := c.kindFor(ctx, "draw mesh", KindMesh, KindShapes)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := c.do(ctx, "draw mesh", map[string]any{"draw": fields})
+	if err != nil {
+		return nil, err
+	}
+
+	if kind == KindMesh {
+		// the mesh service answers a directory or glob model path with its files, and a single file with its mesh
+		if _, ok := result["meshes_added"]; ok {
+			return &DrawMeshResult{
+				Added:   toInt(result["meshes_added"]),
+				Failed:  toInt(result["failed"]),
+				Results: toMeshResults(result["results"]),
+			}, nil
+		}
+
+		return &DrawMeshResult{UUID: toString(result["uuid"]), Name: toString(result["name"]), Added: 1}, nil
+	}
+
+	// the shapes service lists the files of a directory or glob model path in results, even if only one matched
+	items := toItems(result["items"])
+	if results := toMeshResults(result["results"]); len(results) > 0 {
+		return &DrawMeshResult{Added: len(items), Failed: toInt(result["failed"]), Results: results}, nil
+	}
+
+	if len(items) != 1 {
+		return nil, fmt.Errorf("Failed to draw mesh: expected one item, got %d", len(items))
+	}
+
+	return &DrawMeshResult{UUID: items[0].UUID, Name: items[0].Name, Added: 1}, nil
+}
+
+// DrawPointCloud draws a point cloud file on a point cloud or shapes service.
+func (c *Client) DrawPointCloud(ctx context.Context, cloud lib.PointCloudJSON) (*DrawPointCloudResult, error) {
+	fields, err := encode(cloud, lib.ShapePointCloud)
+	if err != nil {
+		return nil, err
+	}
+
+	kind, err := c.kindFor(ctx, "draw point cloud", KindPointCloud, KindShapes)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := c.do(ctx, "draw point cloud", map[string]any{"draw": fields})
+	if err != nil {
+		return nil, err
+	}
+
+	if kind == KindPointCloud {
+		return &DrawPointCloudResult{UUID: toString(result["uuid"]), Name: toString(result["name"])}, nil
+	}
+
+	items := toItems(result["items"])
+	if len(items) != 1 {
+		return nil, fmt.Errorf("Failed to draw point cloud: expected one item, got %d", len(items))
+	}
+
+	return &DrawPointCloudResult{UUID: items[0].UUID, Name: items[0].Name}, nil
+}
+
+// DrawPrimitives draws boxes, spheres, capsules and cylinders on a primitives or shapes service.
+func (c *Client) DrawPrimitives(ctx context.Context, primitives []lib.PrimitiveJSON) (*DrawPrimitivesResult, error) {
+	payload := make([]any, 0, len(primitives))
+	for _, primitive := range primitives {
+		fields, err := encode(primitive, primitive.Type)
+		if err != nil {
+			return nil, err
+		}
+		payload = append(payload, fields)
+	}
+
+	kind, err := c.kindFor(ctx, "draw primitives", KindPrimitives, KindShapes)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := c.do(ctx, "draw primitives", map[string]any{"draw": payload})
+	if err != nil {
+		return nil, err
+	}
+
+	if kind == KindPrimitives {
+		return &DrawPrimitivesResult{
+			Added: toInt(result["primitives_added"]),
+			UUIDs: toStrings(result["uuids"]),
+			Names: toStrings(result["names"]),
+		}, nil
+	}
+
+	drawn := &DrawPrimitivesResult{}
+	for _, item := range toItems(result["items"]) {
+		drawn.UUIDs = append(drawn.UUIDs, item.UUID)
+		drawn.Names = append(drawn.Names, item.Name)
+	}
+	drawn.Added = len(drawn.UUIDs)
+
+	return drawn, nil
+}
+
+// DrawShapes draws shapes of any type on a shapes service. Each shape is a JSON object with a type field and the
+// fields of that type, as parsed from a scene file. Nothing is drawn if any shape is invalid.
+func (c *Client) DrawShapes(ctx context.Context, shapes []any) (*DrawShapesResult, error) {
+	if _, err := c.kindFor(ctx, "draw shapes", KindShapes); err != nil {
+		return nil, err
+	}
+
+	result, err := c.do(ctx, "draw shapes", map[string]any{"draw": shapes})
+	if err != nil {
+		return nil, err
+	}
+
+	items := toItems(result["items"])
+	return &DrawShapesResult{
+		Added:   len(items),
+		Items:   items,
+		Failed:  toInt(result["failed"]),
+		Results: toMeshResults(result["results"]),
+	}, nil
+}
+
+// Remove removes the items matching any of the given UUIDs or names.
+func (c *Client) Remove(ctx context.Context, ids lib.Identifiers) (*RemoveResult, error) {
+	fields := map[string]any{}
+	if len(ids.UUIDs) > 0 {
+		fields["uuids"] = toAnys(ids.UUIDs)
+	}
+	if len(ids.Names) > 0 {
+		fields["names"] = toAnys(ids.Names)
+	}
+
+	kind, err := c.Kind(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := c.do(ctx, "remove", map[string]any{"remove": fields})
+	if err != nil {
+		return nil, err
+	}
+
+	return &RemoveResult{Removed: toInt(result[removedKeys[kind]])}, nil
+}
+
+// Clear removes everything drawn by the service.
+func (c *Client) Clear(ctx context.Context) (*RemoveResult, error) {
+	kind, err := c.Kind(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := c.do(ctx, "clear", map[string]any{"clear": true})
+	if err != nil {
+		return nil, err
+	}
+
+	return &RemoveResult{Removed: toInt(result[removedKeys[kind]])}, nil
+}
+
+// Hide hides the objects matching any of the given UUIDs, names or layers, or every object.
+func (c *Client) Hide(ctx context.Context, selection lib.Selection) (*VisibilityResult, error) {
+	return c.setVisible(ctx, "hide", selection)
+}
+
+// Show shows the objects matching any of the given UUIDs, names or layers, or every object.
+func (c *Client) Show(ctx context.Context, selection lib.Selection) (*VisibilityResult, error) {
+	return c.setVisible(ctx, "show", selection)
+}
+
+func (c *Client) setVisible(ctx context.Context, name string, selection lib.Selection) (*VisibilityResult, error) {
+	fields := map[string]any{}
+	if len(selection.UUIDs) > 0 {
+		fields["uuids"] = toAnys(selection.UUIDs)
+	}
+	if len(selection.Names) > 0 {
+		fields["names"] = toAnys(selection.Names)
+	}
+	if len(selection.Layers) > 0 {
+		fields["layers"] = toAnys(selection.Layers)
+	}
+	if selection.All {
+		fields["all"] = true
+	}
+
+	result, err := c.do(ctx, name, map[string]any{name: fields})
+	if err != nil {
+		return nil, err
+	}
+
+	return &VisibilityResult{Changed: toInt(result["changed"])}, nil
+}
+
+// SetScene draws the named scene of a shapes service in place of the previous one.
+func (c *Client) SetScene(ctx context.Context, name string) (*SceneResult, error) {
+	if _, err := c.kindFor(ctx, "set scene", KindShapes); err != nil {
+		return nil, err
+	}
+
+	result, err := c.do(ctx, "set scene", map[string]any{"set_scene": name})
+	if err != nil {
+		return nil, err
+	}
+
+	scene, _ := result["scene"].(string)
+	return &SceneResult{
+		Scene:   scene,
+		Added:   toInt(result["added"]),
+		Updated: toInt(result["updated"]),
+		Removed: toInt(result["removed"]),
+	}, nil
+}
+
+// Stats reports what the service holds: its transforms by type and layer, their approximate bytes, its subscribers,
+// the changes it emitted and dropped, and its last error.
+func (c *Client) Stats(ctx context.Context) (*store.Stats, error) {
+	result, err := c.do(ctx, "get stats", map[string]any{"stats": true})
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &store.Stats{}
+	if err := lib.Decode(result["stats"], stats); err != nil {
+		return nil, fmt.Errorf("Failed to parse stats: %w", err)
+	}
+
+	return stats, nil
+}
+
+// do sends a command and checks that the service reported success. A failure keeps the code, path and index of the
+// response when the service returns one, so callers can match it with errors.Is(err, lib.ErrNotFound).
+func (c *Client) do(ctx context.Context, action string, cmd map[string]any) (map[string]any, error) {
+	result, err := c.service.DoCommand(ctx, cmd)
+	if err != nil {
+		return nil, responseError(fmt.Errorf("Failed to %s: %w", action, err), result)
+	}
+
+	if result["success"] != true {
+		return nil, responseError(fmt.Errorf("Failed to %s: %v", action, result["error"]), result)
+	}
+
+	return result, nil
+}
+
+// responseError attaches the code, path and index of a failed response to err, unless err already carries them.
+func responseError(err error, result map[string]any) error {
+	var coded *lib.Error
+	if errors.As(err, &coded) {
+		return err
+	}
+
+	var fields struct {
+		Code  string `json:"code"`
+		Path  string `json:"path"`
+		Index *int   `json:"index"`
+	}
+	if lib.Decode(result, &fields) != nil || fields.Code == "" {
+		return err
+	}
+
+	return &lib.Error{Code: lib.ErrorCode(fields.Code), Path: fields.Path, Index: fields.Index, Err: err}
+}
+
+// encode converts a spec to the plain JSON object the services parse, as they would receive it from any other client,
+// tagged with its shape type. A nil color is left out, so the service applies its default.
+func encode(spec any, shapeType string) (map[string]any, error) {
+	data, err := json.Marshal(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	var fields map[string]any
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+
+	fields["type"] = shapeType
+
+	return fields, nil
+}
+
+// toInt reads a count, which is an int in process and a float64 once it has crossed the network.
+func toInt(value any) int {
+	switch number := value.(type) {
+	case int:
+		return number
+	case int64:
+		return int(number)
+	case float64:
+		return int(number)
+	default:
+		return 0
+	}
+}
+
+func toString(value any) string {
+	str, _ := value.(string)
+	return str
+}
+
+func toStrings(value any) []string {
+	switch values := value.(type) {
+	case []string:
+		return values
+	case []any:
+		strs := make([]string, 0, len(values))
+		for _, item := range values {
+			strs = append(strs, toString(item))
+		}
+		return strs
+	default:
+		return nil
+	}
+}
+
+func toItems(value any) []Item {
+	values, _ := value.([]any)
+	items := make([]Item, 0, len(values))
+	for _, data := range values {
+		fields, _ := data.(map[string]any)
+		items = append(items, Item{
+			Type: toString(fields["type"]),
+			UUID: toString(fields["uuid"]),
+			Name: toString(fields["name"]),
+		})
+	}
+	return items
+}
+
+// toMeshResults decodes the results of the files matched by a directory or glob model path.
+func toMeshResults(value any) []MeshResult {
+	values, _ := value.([]any)
+	if len(values) == 0 {
+		return nil
+	}
+
+	results := make([]MeshResult, 0, len(values))
+	for _, data := range values {
+		fields, _ := data.(map[string]any)
+		results = append(results, MeshResult{
+			ModelPath: toString(fields["model_path"]),
+			Success:   fields["success"] == true,
+			UUID:      toString(fields["uuid"]),
+			Name:      toString(fields["name"]),
+			Error:     toString(fields["error"]),
+		})
+	}
+	return results
+}
+
+func toAnys(values []string) []any {
+	anys := make([]any, 0, len(values))
+	for _, value := range values {
+		anys = append(anys, value)
+	}
+	return anys
+}
@@ -0,0 +1,331 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/viam-labs/draw-tools/drawarrows"
+	"github.com/viam-labs/draw-tools/drawmesh"
+	"github.com/viam-labs/draw-tools/drawprimitives"
+	"github.com/viam-labs/draw-tools/drawshapes"
+	"github.com/viam-labs/draw-tools/lib"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	worldstatestore "go.viam.com/rdk/services/worldstatestore"
+	"go.viam.com/rdk/testutils/inject"
+	"go.viam.com/test"
+)
+
+const testPLY = `ply
+format ascii 1.0
+element vertex 3
+property float x
+property float y
+property float z
+element face 1
+property list uchar int vertex_indices
+end_header
+0 0 0
+1 0 0
+0 1 0
+3 0 1 2
+`
+
+func TestArrows(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	service, err := drawarrows.NewWorldStateService(ctx, nil, worldstatestore.Named("arrows"), &drawarrows.Config{}, logging.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	defer service.Close(ctx)
+
+	client := New(service)
+	drawn, err := client.DrawArrows(ctx, []lib.ArrowJSON{
+		{Name: "up", Pose: lib.PoseJSON{Z: 100, OZ: 1}},
+		{Name: "origin", Pose: lib.PoseJSON{OZ: 1}, Axes: true},
+	})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, drawn.Added, test.ShouldEqual, 4)
+
+	removed, err := client.Clear(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, removed.Removed, test.ShouldEqual, 4)
+
+	uuids, err := service.ListUUIDs(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, uuids, test.ShouldBeEmpty)
+}
+
+func TestMesh(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	dir := t.TempDir()
+	for _, name := range []string{"a.ply", "b.ply"} {
+		test.That(t, os.WriteFile(filepath.Join(dir, name), []byte(testPLY), 0o644), test.ShouldBeNil)
+	}
+	test.That(t, os.WriteFile(filepath.Join(dir, "broken.ply"), []byte("not a mesh"), 0o644), test.ShouldBeNil)
+
+	service, err := drawmesh.NewWorldStateService(ctx, nil, worldstatestore.Named("mesh"), &drawmesh.Config{}, logging.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	defer service.Close(ctx)
+
+	client := New(service)
+	drawn, err := client.DrawMesh(ctx, MeshOptions{ModelPath: filepath.Join(dir, "a.ply"), Name: "part"})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, drawn.Name, test.ShouldEqual, "part")
+	test.That(t, drawn.Added, test.ShouldEqual, 1)
+	_, err = uuid.Parse(drawn.UUID)
+	test.That(t, err, test.ShouldBeNil)
+
+	// a glob reports each matching file, including the ones that failed
+	drawn, err = client.DrawMesh(ctx, MeshOptions{ModelPath: filepath.Join(dir, "*.ply")})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, drawn.Added, test.ShouldEqual, 2)
+	test.That(t, drawn.Failed, test.ShouldEqual, 1)
+	test.That(t, drawn.Results, test.ShouldHaveLength, 3)
+	for _, result := range drawn.Results {
+		test.That(t, result.Success, test.ShouldEqual, result.Error == "")
+	}
+
+	removed, err := client.Remove(ctx, lib.Identifiers{Names: []string{"part"}})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, removed.Removed, test.ShouldEqual, 1)
+
+	_, err = client.DrawMesh(ctx, MeshOptions{ModelPath: filepath.Join(dir, "missing.ply")})
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldStartWith, "Failed to draw mesh: ")
+}
+
+func TestPrimitives(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	service, err := drawprimitives.NewWorldStateService(ctx, nil, worldstatestore.Named("primitives"), &drawprimitives.Config{}, logging.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	defer service.Close(ctx)
+
+	client := New(service)
+	drawn, err := client.DrawPrimitives(ctx, []lib.PrimitiveJSON{
+		{Type: "sphere", Name: "ball", RadiusMm: 50},
+		{Type: "box", Name: "crate", DimsMm: lib.Vector3JSON{X: 10, Y: 10, Z: 10}, Color: &lib.Color{}},
+	})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, drawn.Added, test.ShouldEqual, 2)
+	test.That(t, drawn.Names, test.ShouldResemble, []string{"ball", "crate"})
+	test.That(t, drawn.UUIDs, test.ShouldHaveLength, 2)
+
+	// an unset color falls back to the service default instead of black
+	id, err := uuid.Parse(drawn.UUIDs[0])
+	test.That(t, err, test.ShouldBeNil)
+	transform, err := service.GetTransform(ctx, id[:], nil)
+	test.That(t, err, test.ShouldBeNil)
+	color := transform.Metadata.Fields["color"].GetStructValue().Fields
+	test.That(t, color["r"].GetNumberValue(), test.ShouldEqual, lib.DefaultPrimitiveColor.R)
+
+	// while an explicit black is drawn black
+	id, err = uuid.Parse(drawn.UUIDs[1])
+	test.That(t, err, test.ShouldBeNil)
+	transform, err = service.GetTransform(ctx, id[:], nil)
+	test.That(t, err, test.ShouldBeNil)
+	color = transform.Metadata.Fields["color"].GetStructValue().Fields
+	test.That(t, color["r"].GetNumberValue(), test.ShouldEqual, 0)
+	test.That(t, color["g"].GetNumberValue(), test.ShouldEqual, 0)
+	test.That(t, color["b"].GetNumberValue(), test.ShouldEqual, 0)
+
+	removed, err := client.Remove(ctx, lib.Identifiers{UUIDs: drawn.UUIDs[1:]})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, removed.Removed, test.ShouldEqual, 1)
+}
+
+func TestShapes(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	path := filepath.Join(t.TempDir(), "part.ply")
+	test.That(t, os.WriteFile(path, []byte(testPLY), 0o644), test.ShouldBeNil)
+
+	service, err := drawshapes.NewWorldStateService(ctx, nil, worldstatestore.Named("shapes"), &drawshapes.Config{}, logging.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	defer service.Close(ctx)
+
+	// the typed calls work against the shapes service as well
+	client := New(service)
+	arrows, err := client.DrawArrows(ctx, []lib.ArrowJSON{
+		{Name: "up", Pose: lib.PoseJSON{Z: 100, OZ: 1}},
+		{Name: "origin", Pose: lib.PoseJSON{OZ: 1}, Axes: true},
+	})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, arrows.Added, test.ShouldEqual, 4)
+
+	mesh, err := client.DrawMesh(ctx, MeshOptions{ModelPath: path, Name: "part"})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, mesh.Name, test.ShouldEqual, "part")
+	test.That(t, mesh.Added, test.ShouldEqual, 1)
+
+	primitives, err := client.DrawPrimitives(ctx, []lib.PrimitiveJSON{{Type: "sphere", Name: "ball", RadiusMm: 50}})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, primitives.Names, test.ShouldResemble, []string{"ball"})
+
+	shapes, err := client.DrawShapes(ctx, []any{
+		map[string]any{"type": "label", "name": "note", "text": "pick here", "pose": map[string]any{"z": 300}},
+		map[string]any{"type": "box", "name": "table", "dims_mm": map[string]any{"x": 1000, "y": 600, "z": 20}},
+	})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, shapes.Added, test.ShouldEqual, 2)
+	test.That(t, shapes.Items[1].Type, test.ShouldEqual, "box")
+	test.That(t, shapes.Items[1].Name, test.ShouldEqual, "table")
+
+	// nothing is drawn when one shape is invalid
+	_, err = client.DrawShapes(ctx, []any{map[string]any{"type": "sphere", "name": "other"}, map[string]any{"type": "cone"}})
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, errors.Is(err, lib.ErrInvalidArgument), test.ShouldBeTrue)
+
+	_, err = client.Remove(ctx, lib.Identifiers{Names: []string{"part", "missing"}})
+	test.That(t, errors.Is(err, lib.ErrNotFound), test.ShouldBeTrue)
+
+	removed, err := client.Remove(ctx, lib.Identifiers{Names: []string{"part", "ball"}})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, removed.Removed, test.ShouldEqual, 2)
+
+	stats, err := client.Stats(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, stats.Transforms, test.ShouldEqual, 6)
+	test.That(t, stats.ByType, test.ShouldResemble, map[string]int{"arrow": 1, "axes": 3, "label": 1, "box": 1})
+	test.That(t, stats.ByLayer, test.ShouldResemble, map[string]int{"default": 6})
+	test.That(t, stats.LastError, test.ShouldContainSubstring, "shape not found")
+
+	hidden, err := client.Hide(ctx, lib.Selection{Names: []string{"table"}})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, hidden.Changed, test.ShouldEqual, 1)
+
+	shown, err := client.Show(ctx, lib.Selection{All: true})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, shown.Changed, test.ShouldEqual, 1)
+
+	_, err = client.Hide(ctx, lib.Selection{})
+	test.That(t, errors.Is(err, lib.ErrInvalidArgument), test.ShouldBeTrue)
+
+	removed, err = client.Clear(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, removed.Removed, test.ShouldEqual, 4)
+	// a glob matching a single file still reports it in results
+	glob, err := client.DrawMesh(ctx, MeshOptions{ModelPath: filepath.Join(filepath.Dir(path), "*.ply")})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, glob.Added, test.ShouldEqual, 1)
+	test.That(t, glob.UUID, test.ShouldBeEmpty)
+	test.That(t, glob.Results, test.ShouldHaveLength, 1)
+	test.That(t, glob.Results[0].ModelPath, test.ShouldEqual, path)
+	test.That(t, glob.Results[0].Success, test.ShouldBeTrue)
+}
+
+func TestResponses(t *testing.T) {
+	ctx := context.Background()
+	service := inject.NewWorldStateStoreService("remote")
+	client := NewOfKind(service, KindPointCloud)
+
+	// counts cross the network as float64
+	service.DoFunc = func(ctx context.Context, cmd map[string]any) (map[string]any, error) {
+		return map[string]any{"success": true, "pointclouds_removed": 3.0}, nil
+	}
+	removed, err := client.Clear(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, removed.Removed, test.ShouldEqual, 3)
+
+	service.DoFunc = func(ctx context.Context, cmd map[string]any) (map[string]any, error) {
+		test.That(t, cmd, test.ShouldResemble, map[string]any{"set_scene": "night"})
+		return map[string]any{"success": true, "scene": "night", "added": 1.0, "updated": 2.0, "removed": 0.0}, nil
+	}
+	scene, err := NewOfKind(service, KindShapes).SetScene(ctx, "night")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, *scene, test.ShouldResemble, SceneResult{Scene: "night", Added: 1, Updated: 2})
+
+	service.DoFunc = func(ctx context.Context, cmd map[string]any) (map[string]any, error) {
+		return map[string]any{"success": false, "error": "nothing to draw"}, nil
+	}
+	_, err = client.DrawPointCloud(ctx, lib.PointCloudJSON{ModelPath: "/clouds/scan.pcd"})
+	test.That(t, err, test.ShouldBeError, errors.New("Failed to draw point cloud: nothing to draw"))
+
+	// codes survive the response, with indexes as float64
+	service.DoFunc = func(ctx context.Context, cmd map[string]any) (map[string]any, error) {
+		return map[string]any{"success": false, "error": "not a PLY file", "code": "unsupported_format", "index": 1.0}, nil
+	}
+	_, err = NewOfKind(service, KindShapes).DrawShapes(ctx, []any{})
+	test.That(t, errors.Is(err, lib.ErrUnsupportedFormat), test.ShouldBeTrue)
+	var coded *lib.Error
+	test.That(t, errors.As(err, &coded), test.ShouldBeTrue)
+	test.That(t, *coded.Index, test.ShouldEqual, 1)
+
+	failure := errors.New("connection lost")
+	service.DoFunc = func(ctx context.Context, cmd map[string]any) (map[string]any, error) {
+		return nil, failure
+	}
+	_, err = client.Remove(ctx, lib.Identifiers{Names: []string{"scan"}})
+	test.That(t, errors.Is(err, failure), test.ShouldBeTrue)
+}
+
+func TestKind(t *testing.T) {
+	ctx := context.Background()
+
+	// the kinds are the names of the models
+	test.That(t, string(KindShapes), test.ShouldEqual, drawshapes.WorldState.Name)
+	test.That(t, string(KindArrows), test.ShouldEqual, drawarrows.WorldState.Name)
+	test.That(t, string(KindMesh), test.ShouldEqual, drawmesh.WorldState.Name)
+	test.That(t, string(KindPrimitives), test.ShouldEqual, drawprimitives.WorldState.Name)
+
+	service := inject.NewWorldStateStoreService("remote")
+	described := 0
+	service.DoFunc = func(ctx context.Context, cmd map[string]any) (map[string]any, error) {
+		if _, ok := cmd["describe"]; ok {
+			described++
+			return map[string]any{"success": true, "model": drawmesh.WorldState.String(), "commands": []any{}}, nil
+		}
+		return map[string]any{"success": true, "mesh_removed": 2.0}, nil
+	}
+
+	// the kind is described once, then kept
+	client := New(service)
+	removed, err := client.Clear(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, removed.Removed, test.ShouldEqual, 2)
+	kind, err := client.Kind(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, kind, test.ShouldEqual, KindMesh)
+	test.That(t, described, test.ShouldEqual, 1)
+
+	// commands the kind does not answer are not sent
+	_, err = client.DrawShapes(ctx, []any{})
+	test.That(t, err, test.ShouldBeError, errors.New("Unable to draw shapes: not supported by the draw-mesh-world-state service"))
+	_, err = client.DrawArrows(ctx, nil)
+	test.That(t, err, test.ShouldNotBeNil)
+
+	service.DoFunc = func(ctx context.Context, cmd map[string]any) (map[string]any, error) {
+		return map[string]any{"success": true, "model": "acme:tools:painter", "commands": []any{}}, nil
+	}
+	_, err = New(service).Kind(ctx)
+	test.That(t, err, test.ShouldBeError, errors.New("Unsupported service model acme:tools:painter"))
+
+	failure := errors.New("connection lost")
+	service.DoFunc = func(ctx context.Context, cmd map[string]any) (map[string]any, error) {
+		return nil, failure
+	}
+	_, err = New(service).Clear(ctx)
+	test.That(t, errors.Is(err, failure), test.ShouldBeTrue)
+}
+
+func TestFromDependencies(t *testing.T) {
+	service := inject.NewWorldStateStoreService("arrows")
+	deps := resource.Dependencies{worldstatestore.Named("arrows"): service}
+
+	client, err := FromDependencies(deps, "arrows")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, client.Service(), test.ShouldEqual, service)
+
+	_, err = FromDependencies(deps, "missing")
+	test.That(t, err, test.ShouldNotBeNil)
+}
@@ -0,0 +1,376 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/viam-labs/draw-tools/client"
+	"github.com/viam-labs/draw-tools/lib"
+	"gopkg.in/yaml.v3"
+
+	commonPB "go.viam.com/api/common/v1"
+)
+
+// drawctl runs commands against one world state store.
+type drawctl struct {
+	client *client.Client
+	in     io.Reader
+	out    io.Writer
+	json   bool
+}
+
+// transformJSON describes a drawn transform in list and watch output.
+type transformJSON struct {
+	UUID   string       `json:"uuid"`
+	Name   string       `json:"name"`
+	Type   string       `json:"type,omitempty"`
+	Parent string       `json:"parent,omitempty"`
+	Pose   lib.PoseJSON `json:"pose"`
+}
+
+// changeJSON describes a transform change in watch output.
+type changeJSON struct {
+	Change        string        `json:"change"`
+	Transform     transformJSON `json:"transform"`
+	UpdatedFields []string      `json:"updated_fields,omitempty"`
+}
+
+func (d *drawctl) execute(ctx context.Context, args []string) error {
+	switch args[0] {
+	case "draw":
+		if len(args) < 2 {
+			return errors.New("Expected draw arrow or draw mesh")
+		}
+
+		switch args[1] {
+		case "arrow", "arrows":
+			return d.drawArrows(ctx)
+		case "mesh":
+			return d.drawMesh(ctx, args[2:])
+		default:
+			return fmt.Errorf("Unknown shape %q, expected arrow or mesh", args[1])
+		}
+	case "list":
+		return d.list(ctx)
+	case "remove":
+		return d.remove(ctx, args[1:])
+	case "clear":
+		return d.clear(ctx)
+	case "watch":
+		return d.watch(ctx, args[1:])
+	case "load":
+		return d.load(ctx, args[1:])
+	default:
+		return fmt.Errorf("Unknown command %q", args[0])
+	}
+}
+
+func (d *drawctl) drawArrows(ctx context.Context) error {
+	arrows, err := readList[lib.ArrowJSON](d.in)
+	if err != nil {
+		return err
+	}
+
+	drawn, err := d.client.DrawArrows(ctx, arrows)
+	if err != nil {
+		return err
+	}
+
+	if d.json {
+		return d.print(drawn)
+	}
+
+	fmt.Fprintf(d.out, "added %d arrows\n", drawn.Added)
+	return nil
+}
+
+func (d *drawctl) drawMesh(ctx context.Context, args []string) error {
+	var options client.MeshOptions
+	if len(args) > 0 {
+		options.ModelPath = args[0]
+	} else if err := readInput(d.in, &options); err != nil {
+		return err
+	}
+
+	drawn, err := d.client.DrawMesh(ctx, options)
+	if err != nil {
+		return err
+	}
+
+	if d.json {
+		return d.print(drawn)
+	}
+
+	if drawn.Results == nil {
+		fmt.Fprintf(d.out, "%s\t%s\n", drawn.UUID, drawn.Name)
+		return nil
+	}
+
+	for _, result := range drawn.Results {
+		if result.Success {
+			fmt.Fprintf(d.out, "%s\t%s\t%s\n", result.UUID, result.Name, result.ModelPath)
+		} else {
+			fmt.Fprintf(d.out, "failed\t%s\t%s\n", result.ModelPath, result.Error)
+		}
+	}
+	return nil
+}
+
+func (d *drawctl) list(ctx context.Context) error {
+	service := d.client.Service()
+	uuids, err := service.ListUUIDs(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	transforms := make([]transformJSON, 0, len(uuids))
+	for _, id := range uuids {
+		transform, err := service.GetTransform(ctx, id, nil)
+		if err != nil {
+			return err
+		}
+		transforms = append(transforms, describe(transform))
+	}
+
+	if d.json {
+		return d.print(transforms)
+	}
+
+	for _, transform := range transforms {
+		fmt.Fprintf(d.out, "%s\t%s\t%s\t%s\n", transform.UUID, transform.Name, transform.Type, transform.Parent)
+	}
+	return nil
+}
+
+// remove removes the transforms named on the command line, where arguments that parse as UUIDs are UUIDs and the
+// rest are names, or the identifiers read from stdin.
+func (d *drawctl) remove(ctx context.Context, args []string) error {
+	var ids lib.Identifiers
+	for _, arg := range args {
+		if _, err := uuid.Parse(arg); err == nil {
+			ids.UUIDs = append(ids.UUIDs, arg)
+		} else {
+			ids.Names = append(ids.Names, arg)
+		}
+	}
+
+	if len(args) == 0 {
+		if err := readInput(d.in, &ids); err != nil {
+			return err
+		}
+	}
+
+	removed, err := d.client.Remove(ctx, ids)
+	if err != nil {
+		return err
+	}
+
+	return d.printRemoved(removed)
+}
+
+func (d *drawctl) clear(ctx context.Context) error {
+	removed, err := d.client.Clear(ctx)
+	if err != nil {
+		return err
+	}
+
+	return d.printRemoved(removed)
+}
+
+// watch prints changes until the context is canceled, the stream ends or count changes were printed.
+func (d *drawctl) watch(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("watch", flag.ContinueOnError)
+	count := flags.Int("count", 0, "Number of changes to print before exiting (defaults to 0, no limit)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	stream, err := d.client.Service().StreamTransformChanges(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	for printed := 0; *count == 0 || printed < *count; printed++ {
+		change, err := stream.Next()
+		if err != nil {
+			if errors.Is(err, io.EOF) || ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+
+		described := changeJSON{
+			Change:        strings.TrimPrefix(change.ChangeType.String(), "TRANSFORM_CHANGE_TYPE_"),
+			Transform:     describe(change.Transform),
+			UpdatedFields: change.UpdatedFields,
+		}
+
+		if d.json {
+			if err := d.print(described); err != nil {
+				return err
+			}
+			continue
+		}
+
+		fmt.Fprintf(d.out, "%s\t%s\t%s", described.Change, described.Transform.UUID, described.Transform.Name)
+		if len(described.UpdatedFields) > 0 {
+			fmt.Fprintf(d.out, "\t%s", strings.Join(described.UpdatedFields, ","))
+		}
+		fmt.Fprintln(d.out)
+	}
+
+	return nil
+}
+
+// load draws a scene file on a shapes service. A scene is an array of shapes, or an object with a shapes field like
+// the draw-shapes configuration. Without a path, or with "-", the scene is read from stdin.
+func (d *drawctl) load(ctx context.Context, args []string) error {
+	in := d.in
+	if len(args) > 0 && args[0] != "-" {
+		file, err := os.Open(args[0])
+		if err != nil {
+			return fmt.Errorf("Unable to open scene: %w", err)
+		}
+		defer file.Close()
+		in = file
+	}
+
+	var scene any
+	if err := readInput(in, &scene); err != nil {
+		return err
+	}
+
+	if fields, ok := scene.(map[string]any); ok {
+		scene = fields["shapes"]
+	}
+
+	shapes, ok := scene.([]any)
+	if !ok {
+		return errors.New("Expected an array of shapes or an object with a shapes field")
+	}
+
+	drawn, err := d.client.DrawShapes(ctx, shapes)
+	if err != nil {
+		return err
+	}
+
+	if d.json {
+		return d.print(drawn)
+	}
+
+	for _, item := range drawn.Items {
+		fmt.Fprintf(d.out, "%s\t%s\t%s\n", item.UUID, item.Name, item.Type)
+	}
+
+	// files matched by a mesh directory or glob are drawn best effort
+	for _, result := range drawn.Results {
+		if !result.Success {
+			fmt.Fprintf(d.out, "failed\t%s\t%s\n", result.ModelPath, result.Error)
+		}
+	}
+	return nil
+}
+
+func (d *drawctl) printRemoved(removed *client.RemoveResult) error {
+	if d.json {
+		return d.print(removed)
+	}
+
+	fmt.Fprintf(d.out, "removed %d\n", removed.Removed)
+	return nil
+}
+
+// print writes a value as one line of JSON.
+func (d *drawctl) print(value any) error {
+	return json.NewEncoder(d.out).Encode(value)
+}
+
+// readInput decodes JSON or YAML into value. YAML is decoded first, as it is a superset of JSON, then converted through
+// JSON so value's JSON field names apply.
+func readInput(in io.Reader, value any) error {
+	data, err := io.ReadAll(in)
+	if err != nil {
+		return err
+	}
+
+	var decoded any
+	if err := yaml.Unmarshal(data, &decoded); err != nil {
+		return fmt.Errorf("Failed to parse input: %w", err)
+	}
+
+	if decoded == nil {
+		return errors.New("Expected JSON or YAML input on stdin")
+	}
+
+	converted, err := json.Marshal(decoded)
+	if err != nil {
+		return fmt.Errorf("Failed to parse input: %w", err)
+	}
+
+	if err := json.Unmarshal(converted, value); err != nil {
+		return fmt.Errorf("Failed to parse input: %w", err)
+	}
+
+	return nil
+}
+
+// readList decodes a single JSON or YAML object, or an array of them.
+func readList[T any](in io.Reader) ([]T, error) {
+	var decoded any
+	if err := readInput(in, &decoded); err != nil {
+		return nil, err
+	}
+
+	if _, ok := decoded.([]any); !ok {
+		decoded = []any{decoded}
+	}
+
+	data, err := json.Marshal(decoded)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []T
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, fmt.Errorf("Failed to parse input: %w", err)
+	}
+
+	return items, nil
+}
+
+// describe summarizes a transform, reading its shape type from the metadata.
+func describe(transform *commonPB.Transform) transformJSON {
+	described := transformJSON{Name: transform.GetReferenceFrame()}
+	if id, err := uuid.FromBytes(transform.GetUuid()); err == nil {
+		described.UUID = id.String()
+	}
+
+	fields := transform.GetMetadata().GetFields()
+	described.Type = fields["type"].GetStringValue()
+	if described.Type == "" {
+		described.Type = fields["shape"].GetStringValue()
+	}
+
+	if observed := transform.GetPoseInObserverFrame(); observed != nil {
+		described.Parent = observed.GetReferenceFrame()
+		pose := observed.GetPose()
+		described.Pose = lib.PoseJSON{
+			X:     pose.GetX(),
+			Y:     pose.GetY(),
+			Z:     pose.GetZ(),
+			OX:    pose.GetOX(),
+			OY:    pose.GetOY(),
+			OZ:    pose.GetOZ(),
+			Theta: pose.GetTheta(),
+		}
+	}
+
+	return described
+}
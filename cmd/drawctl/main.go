@@ -0,0 +1,144 @@
+// Command drawctl draws on draw-tools world state stores from shell scripts and notebooks.
+//
+// Usage:
+//
+//	drawctl [flags] <command> [arguments]
+//
+// It connects to the world state store named by -service on the machine at -address, or with -local draws into a
+// store that lives only as long as the command. Inputs are read from stdin as JSON or YAML.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+
+	"github.com/viam-labs/draw-tools/client"
+	"github.com/viam-labs/draw-tools/drawshapes"
+
+	"go.viam.com/rdk/logging"
+	robotclient "go.viam.com/rdk/robot/client"
+	worldstatestore "go.viam.com/rdk/services/worldstatestore"
+	"go.viam.com/utils/rpc"
+)
+
+const usage = `Usage: drawctl [flags] <command> [arguments]
+
+Commands:
+  draw arrow           Draw the arrow or array of arrows read from stdin
+  draw mesh [path]     Draw the mesh at path, or the mesh options read from stdin
+  list                 List the drawn transforms
+  remove [id...]       Remove transforms by UUID or name, or the {"uuids", "names"} read from stdin
+  clear                Remove every drawn transform
+  watch [-count n]     Print transform changes until interrupted, or until n changes were printed
+  load [scene.json]    Draw the shapes of a scene file, or of the scene read from stdin
+
+Flags:
+`
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if err := run(ctx, os.Args[1:], os.Stdin, os.Stdout, os.Stderr); err != nil {
+		if !errors.Is(err, flag.ErrHelp) {
+			fmt.Fprintln(os.Stderr, "drawctl:", err)
+		}
+		os.Exit(1)
+	}
+}
+
+// run parses the global flags, connects to the world state store and executes the command.
+func run(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	flags := flag.NewFlagSet("drawctl", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprint(stderr, usage)
+		flags.PrintDefaults()
+	}
+
+	address := flags.String("address", os.Getenv("DRAWCTL_ADDRESS"), "Address of the machine (defaults to $DRAWCTL_ADDRESS)")
+	keyID := flags.String("api-key-id", os.Getenv("VIAM_API_KEY_ID"), "API key ID (defaults to $VIAM_API_KEY_ID)")
+	key := flags.String("api-key", os.Getenv("VIAM_API_KEY"), "API key (defaults to $VIAM_API_KEY)")
+	serviceName := flags.String("service", os.Getenv("DRAWCTL_SERVICE"), "Name of the world state store (defaults to $DRAWCTL_SERVICE)")
+	local := flags.Bool("local", false, "Draw into an in-process draw-shapes store instead of connecting to a machine")
+	jsonOutput := flags.Bool("json", false, "Print results as JSON")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return flag.ErrHelp
+	}
+
+	logger := logging.NewBlankLogger("drawctl")
+	logger.AddAppender(logging.NewWriterAppender(stderr))
+	logger.SetLevel(logging.WARN)
+
+	service, closeService, err := connect(ctx, *local, *address, *keyID, *key, *serviceName, logger)
+	if err != nil {
+		return err
+	}
+	defer closeService()
+
+	cli := &drawctl{
+		client: client.New(service),
+		in:     stdin,
+		out:    stdout,
+		json:   *jsonOutput,
+	}
+
+	return cli.execute(ctx, flags.Args())
+}
+
+// connect returns the world state store to draw on and a function that releases it.
+func connect(
+	ctx context.Context,
+	local bool,
+	address, keyID, key, serviceName string,
+	logger logging.Logger,
+) (worldstatestore.Service, func(), error) {
+	if local {
+		service, err := drawshapes.NewWorldStateService(ctx, nil, worldstatestore.Named("local"), &drawshapes.Config{}, logger)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return service, func() { service.Close(context.Background()) }, nil
+	}
+
+	if address == "" {
+		return nil, nil, errors.New("Missing -address, or -local to draw in process")
+	}
+
+	if serviceName == "" {
+		return nil, nil, errors.New("Missing -service name of the world state store")
+	}
+
+	var opts []robotclient.RobotClientOption
+	if keyID != "" || key != "" {
+		opts = append(opts, robotclient.WithDialOptions(rpc.WithEntityCredentials(keyID, rpc.Credentials{
+			Type:    rpc.CredentialsTypeAPIKey,
+			Payload: key,
+		})))
+	}
+
+	machine, err := robotclient.New(ctx, address, logger, opts...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Unable to connect to %v: %w", address, err)
+	}
+
+	service, err := worldstatestore.FromRobot(machine, serviceName)
+	if err != nil {
+		machine.Close(context.Background())
+		return nil, nil, fmt.Errorf("Unable to get world state store %v: %w", serviceName, err)
+	}
+
+	return service, func() { machine.Close(context.Background()) }, nil
+}
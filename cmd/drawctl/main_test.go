@@ -0,0 +1,215 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/viam-labs/draw-tools/client"
+	"github.com/viam-labs/draw-tools/drawshapes"
+	"go.viam.com/rdk/logging"
+	worldstatestore "go.viam.com/rdk/services/worldstatestore"
+	"go.viam.com/test"
+)
+
+const testPLY = `ply
+format ascii 1.0
+element vertex 3
+property float x
+property float y
+property float z
+element face 1
+property list uchar int vertex_indices
+end_header
+0 0 0
+1 0 0
+0 1 0
+3 0 1 2
+`
+
+func newService(t *testing.T) worldstatestore.Service {
+	t.Helper()
+	service, err := drawshapes.NewWorldStateService(
+		context.Background(), nil, worldstatestore.Named("shapes"), &drawshapes.Config{}, logging.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	t.Cleanup(func() { service.Close(context.Background()) })
+	return service
+}
+
+// execute runs a command against an in-process store and returns its output.
+func execute(t *testing.T, service worldstatestore.Service, stdin string, args ...string) (string, error) {
+	t.Helper()
+	var out bytes.Buffer
+	cli := &drawctl{
+		client: client.New(service),
+		in:     strings.NewReader(stdin),
+		out:    &out,
+		json:   args[0] == "-json",
+	}
+	if cli.json {
+		args = args[1:]
+	}
+
+	err := cli.execute(context.Background(), args)
+	return out.String(), err
+}
+
+func TestCommands(t *testing.T) {
+	service := newService(t)
+	path := filepath.Join(t.TempDir(), "part.ply")
+	test.That(t, os.WriteFile(path, []byte(testPLY), 0o644), test.ShouldBeNil)
+
+	// arrows may be given as YAML
+	out, err := execute(t, service, "- name: up\n  pose: {z: 100, o_z: 1}\n- name: origin\n  pose: {o_z: 1}\n  axes: true\n", "draw", "arrow")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, out, test.ShouldEqual, "added 4 arrows\n")
+
+	out, err = execute(t, service, "", "-json", "draw", "mesh", path)
+	test.That(t, err, test.ShouldBeNil)
+	var mesh client.DrawMeshResult
+	test.That(t, json.Unmarshal([]byte(out), &mesh), test.ShouldBeNil)
+	test.That(t, mesh.Added, test.ShouldEqual, 1)
+
+	out, err = execute(t, service, `{"model_path": "`+path+`", "name": "part", "pose": {"x": 10}}`, "draw", "mesh")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, out, test.ShouldEndWith, "\tpart\n")
+
+	out, err = execute(t, service, "", "-json", "list")
+	test.That(t, err, test.ShouldBeNil)
+	var transforms []transformJSON
+	test.That(t, json.Unmarshal([]byte(out), &transforms), test.ShouldBeNil)
+	test.That(t, transforms, test.ShouldHaveLength, 6)
+
+	byName := map[string]transformJSON{}
+	for _, transform := range transforms {
+		byName[transform.Name] = transform
+	}
+	test.That(t, byName["up"].Type, test.ShouldEqual, "arrow")
+	test.That(t, byName["part"].Type, test.ShouldEqual, "mesh")
+	test.That(t, byName["part"].Parent, test.ShouldEqual, "world")
+	test.That(t, byName["part"].Pose.X, test.ShouldEqual, 10)
+
+	// arguments that parse as UUIDs remove by UUID, the rest by name
+	out, err = execute(t, service, "", "remove", "part", mesh.UUID)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, out, test.ShouldEqual, "removed 2\n")
+
+	out, err = execute(t, service, `{"names": ["up"]}`, "-json", "remove")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, out, test.ShouldEqual, "{\"removed\":1}\n")
+
+	out, err = execute(t, service, "", "clear")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, out, test.ShouldEqual, "removed 1\n")
+
+	_, err = execute(t, service, "", "draw", "arrow")
+	test.That(t, err, test.ShouldNotBeNil)
+
+	_, err = execute(t, service, "", "draw", "cone")
+	test.That(t, err, test.ShouldNotBeNil)
+
+	_, err = execute(t, service, "", "paint")
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+func TestLoad(t *testing.T) {
+	service := newService(t)
+	scene := filepath.Join(t.TempDir(), "scene.json")
+	err := os.WriteFile(scene, []byte(`{
+		"shapes": [
+			{"type": "box", "name": "table", "dims_mm": {"x": 1000, "y": 600, "z": 20}},
+			{"type": "label", "name": "note", "text": "pick here", "pose": {"z": 300}}
+		]
+	}`), 0o644)
+	test.That(t, err, test.ShouldBeNil)
+
+	out, err := execute(t, service, "", "-json", "load", scene)
+	test.That(t, err, test.ShouldBeNil)
+	var drawn client.DrawShapesResult
+	test.That(t, json.Unmarshal([]byte(out), &drawn), test.ShouldBeNil)
+	test.That(t, drawn.Added, test.ShouldEqual, 2)
+	test.That(t, drawn.Items[0].Name, test.ShouldEqual, "table")
+
+	// a scene read from stdin may be a YAML array of shapes
+	out, err = execute(t, service, "- type: sphere\n  name: ball\n  radius_mm: 30\n", "load", "-")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, out, test.ShouldEndWith, "\tball\tsphere\n")
+
+	// files of a mesh glob that fail are listed after the drawn items
+	dir := t.TempDir()
+	test.That(t, os.WriteFile(filepath.Join(dir, "broken.ply"), []byte("not a mesh"), 0o644), test.ShouldBeNil)
+	test.That(t, os.WriteFile(filepath.Join(dir, "part.ply"), []byte(testPLY), 0o644), test.ShouldBeNil)
+	out, err = execute(t, service, `[{"type": "mesh", "model_path": "`+filepath.Join(dir, "*.ply")+`"}]`, "load")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, out, test.ShouldContainSubstring, "\tpart\tmesh\n")
+	test.That(t, out, test.ShouldContainSubstring, "failed\t"+filepath.Join(dir, "broken.ply")+"\t")
+
+	_, err = execute(t, service, `{"type": "sphere"}`, "load")
+	test.That(t, err, test.ShouldNotBeNil)
+
+	_, err = execute(t, service, "", "load", filepath.Join(t.TempDir(), "missing.json"))
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+func TestWatch(t *testing.T) {
+	service := newService(t)
+	subscribers := service.(interface{ Subscribers() int })
+
+	type watched struct {
+		out string
+		err error
+	}
+	done := make(chan watched)
+	go func() {
+		out, err := execute(t, service, "", "-json", "watch", "-count", "2")
+		done <- watched{out, err}
+	}()
+
+	for subscribers.Subscribers() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	_, err := execute(t, service, `{"name": "up", "pose": {"z": 100, "o_z": 1}}`, "draw", "arrow")
+	test.That(t, err, test.ShouldBeNil)
+	_, err = execute(t, service, "", "clear")
+	test.That(t, err, test.ShouldBeNil)
+
+	result := <-done
+	test.That(t, result.err, test.ShouldBeNil)
+
+	lines := strings.Split(strings.TrimSpace(result.out), "\n")
+	test.That(t, lines, test.ShouldHaveLength, 2)
+
+	var change changeJSON
+	test.That(t, json.Unmarshal([]byte(lines[0]), &change), test.ShouldBeNil)
+	test.That(t, change.Change, test.ShouldEqual, "ADDED")
+	test.That(t, change.Transform.Name, test.ShouldEqual, "up")
+	test.That(t, json.Unmarshal([]byte(lines[1]), &change), test.ShouldBeNil)
+	test.That(t, change.Change, test.ShouldEqual, "REMOVED")
+}
+
+func TestRun(t *testing.T) {
+	ctx := context.Background()
+	var out, errOut bytes.Buffer
+
+	err := run(ctx, nil, strings.NewReader(""), &out, &errOut)
+	test.That(t, err, test.ShouldEqual, flag.ErrHelp)
+	test.That(t, errOut.String(), test.ShouldStartWith, "Usage: drawctl")
+
+	// a local store only lives for one command
+	err = run(ctx, []string{"-local", "-json", "draw", "arrow"}, strings.NewReader(`{"name": "up", "pose": {"o_z": 1}}`), &out, &errOut)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, out.String(), test.ShouldEqual, "{\"added\":1}\n")
+
+	_, _, err = connect(ctx, false, "", "", "", "shapes", logging.NewTestLogger(t))
+	test.That(t, err, test.ShouldNotBeNil)
+
+	_, _, err = connect(ctx, false, "machine.local:8080", "", "", "", logging.NewTestLogger(t))
+	test.That(t, err, test.ShouldNotBeNil)
+}
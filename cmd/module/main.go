@@ -4,11 +4,28 @@ import (
 	drawarrows "github.com/viam-labs/draw-tools/drawarrows"
 	cleararrowsbutton "github.com/viam-labs/draw-tools/drawarrows/clearbutton"
 	drawarrowsbutton "github.com/viam-labs/draw-tools/drawarrows/drawbutton"
+	"github.com/viam-labs/draw-tools/drawcamera"
+	"github.com/viam-labs/draw-tools/drawframesystem"
 	"github.com/viam-labs/draw-tools/drawmesh"
 	clearmeshbutton "github.com/viam-labs/draw-tools/drawmesh/clearbutton"
 	drawmeshbutton "github.com/viam-labs/draw-tools/drawmesh/drawbutton"
+	"github.com/viam-labs/draw-tools/drawmotionplan"
+	"github.com/viam-labs/draw-tools/drawpointcloud"
+	clearpointcloudbutton "github.com/viam-labs/draw-tools/drawpointcloud/clearbutton"
+	drawpointcloudbutton "github.com/viam-labs/draw-tools/drawpointcloud/drawbutton"
+	"github.com/viam-labs/draw-tools/drawprimitives"
+	drawprimitivesbutton "github.com/viam-labs/draw-tools/drawprimitives/drawbutton"
+	"github.com/viam-labs/draw-tools/drawsegmentation"
+	"github.com/viam-labs/draw-tools/drawshapes"
+	"github.com/viam-labs/draw-tools/drawstats"
+	"github.com/viam-labs/draw-tools/drawtrail"
+	"github.com/viam-labs/draw-tools/layerswitch"
+	"github.com/viam-labs/draw-tools/posetracker"
+	"github.com/viam-labs/draw-tools/sceneswitch"
 
 	"go.viam.com/rdk/components/button"
+	"go.viam.com/rdk/components/sensor"
+	toggleswitch "go.viam.com/rdk/components/switch"
 	"go.viam.com/rdk/module"
 	"go.viam.com/rdk/resource"
 	worldstatestore "go.viam.com/rdk/services/worldstatestore"
@@ -23,5 +40,20 @@ func main() {
 		resource.APIModel{API: worldstatestore.API, Model: drawmesh.WorldState},
 		resource.APIModel{API: button.API, Model: clearmeshbutton.ClearMesh},
 		resource.APIModel{API: button.API, Model: drawmeshbutton.DrawMesh},
+		resource.APIModel{API: worldstatestore.API, Model: drawpointcloud.WorldState},
+		resource.APIModel{API: button.API, Model: clearpointcloudbutton.ClearPointCloud},
+		resource.APIModel{API: button.API, Model: drawpointcloudbutton.DrawPointCloud},
+		resource.APIModel{API: worldstatestore.API, Model: drawprimitives.WorldState},
+		resource.APIModel{API: button.API, Model: drawprimitivesbutton.DrawPrimitives},
+		resource.APIModel{API: worldstatestore.API, Model: drawcamera.WorldState},
+		resource.APIModel{API: worldstatestore.API, Model: drawsegmentation.WorldState},
+		resource.APIModel{API: worldstatestore.API, Model: drawtrail.WorldState},
+		resource.APIModel{API: worldstatestore.API, Model: posetracker.WorldState},
+		resource.APIModel{API: worldstatestore.API, Model: drawframesystem.WorldState},
+		resource.APIModel{API: worldstatestore.API, Model: drawmotionplan.WorldState},
+		resource.APIModel{API: worldstatestore.API, Model: drawshapes.WorldState},
+		resource.APIModel{API: sensor.API, Model: drawstats.Stats},
+		resource.APIModel{API: toggleswitch.API, Model: layerswitch.LayerSwitch},
+		resource.APIModel{API: toggleswitch.API, Model: sceneswitch.SceneSwitch},
 	)
 }
@@ -4,10 +4,12 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/viam-labs/draw-tools/client"
+	"github.com/viam-labs/draw-tools/lib/command"
+
 	button "go.viam.com/rdk/components/button"
 	"go.viam.com/rdk/logging"
 	"go.viam.com/rdk/resource"
-	worldstatestore "go.viam.com/rdk/services/worldstatestore"
 )
 
 var (
@@ -45,7 +47,7 @@ type clearArrowsButton struct {
 	cancelCtx  context.Context
 	cancelFunc func()
 
-	service worldstatestore.Service
+	client *client.Client
 }
 
 func newClearArrowsButton(
@@ -78,12 +80,12 @@ func NewClearArrowsButton(
 		cancelFunc: cancelFunc,
 	}
 
-	service, err := worldstatestore.FromDependencies(deps, conf.ServiceName)
+	drawClient, err := client.FromDependencies(deps, conf.ServiceName)
 	if err != nil {
-		return nil, fmt.Errorf("Unable to get world state store %v: %w", conf.ServiceName, err)
+		return nil, err
 	}
 
-	component.service = service
+	component.client = drawClient
 	return component, nil
 }
 
@@ -92,21 +94,21 @@ func (s *clearArrowsButton) Name() resource.Name {
 }
 
 func (s *clearArrowsButton) Push(ctx context.Context, extra map[string]interface{}) error {
-	result, err := s.service.DoCommand(ctx, map[string]interface{}{
-		"clear": map[string]interface{}{},
-	})
-	if err != nil {
+	if _, err := s.client.Clear(ctx); err != nil {
 		return err
 	}
 
-	if result["success"] != true {
-		return fmt.Errorf("Failed to clear arrows: %s", result["error"])
-	}
-
 	return nil
 }
 
+// commands describes the button, which has no commands of its own besides the built-in ones.
+var commands = &command.Set{Model: ClearArrows}
+
 func (s *clearArrowsButton) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	if result, ok, err := commands.Handle(cmd); ok {
+		return result, err
+	}
+
 	return nil, fmt.Errorf("Not implemented, use service DoCommand instead")
 }
 
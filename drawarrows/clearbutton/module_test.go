@@ -0,0 +1,11 @@
+package cleararrowsbutton
+
+import (
+	"testing"
+
+	"github.com/viam-labs/draw-tools/lib/command/commandtest"
+)
+
+func FuzzDoCommand(f *testing.F) {
+	commandtest.Fuzz(f, commands, (&clearArrowsButton{}).DoCommand)
+}
@@ -5,12 +5,13 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/viam-labs/draw-tools/client"
 	"github.com/viam-labs/draw-tools/lib"
+	"github.com/viam-labs/draw-tools/lib/command"
 
 	button "go.viam.com/rdk/components/button"
 	"go.viam.com/rdk/logging"
 	"go.viam.com/rdk/resource"
-	worldstatestore "go.viam.com/rdk/services/worldstatestore"
 )
 
 var (
@@ -56,7 +57,7 @@ type drawArrowsButton struct {
 	cancelCtx  context.Context
 	cancelFunc func()
 
-	service worldstatestore.Service
+	client *client.Client
 }
 
 func newDrawArrowsButton(
@@ -81,8 +82,7 @@ func NewDrawArrowsButton(
 	logger logging.Logger,
 ) (button.Button, error) {
 	cancelCtx, cancelFunc := context.WithCancel(context.Background())
-	serviceName := worldstatestore.Named(conf.ServiceName)
-	service, err := worldstatestore.FromDependencies(deps, serviceName.Name)
+	drawClient, err := client.FromDependencies(deps, conf.ServiceName)
 	if err != nil {
 		return nil, err
 	}
@@ -93,7 +93,7 @@ func NewDrawArrowsButton(
 		config:     conf,
 		cancelCtx:  cancelCtx,
 		cancelFunc: cancelFunc,
-		service:    service,
+		client:     drawClient,
 	}
 
 	return component, nil
@@ -104,21 +104,21 @@ func (s *drawArrowsButton) Name() resource.Name {
 }
 
 func (s *drawArrowsButton) Push(ctx context.Context, extra map[string]interface{}) error {
-	result, err := s.service.DoCommand(ctx, map[string]interface{}{
-		"draw": s.config.Arrows,
-	})
-	if err != nil {
+	if _, err := s.client.DrawArrows(ctx, s.config.Arrows); err != nil {
 		return err
 	}
 
-	if result["success"] != true {
-		return fmt.Errorf("Failed to draw arrows: %s", result["error"])
-	}
-
 	return nil
 }
 
+// commands describes the button, which has no commands of its own besides the built-in ones.
+var commands = &command.Set{Model: DrawArrows}
+
 func (s *drawArrowsButton) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	if result, ok, err := commands.Handle(cmd); ok {
+		return result, err
+	}
+
 	return nil, fmt.Errorf("Not implemented, use service DoCommand instead")
 }
 
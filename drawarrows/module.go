@@ -3,13 +3,11 @@ package drawarrows
 import (
 	"context"
 	"fmt"
-	"sync"
 
+	"github.com/viam-labs/draw-tools/drawshapes"
 	"github.com/viam-labs/draw-tools/lib"
+	"github.com/viam-labs/draw-tools/lib/command"
 
-	"github.com/google/uuid"
-	commonPB "go.viam.com/api/common/v1"
-	v1 "go.viam.com/api/service/worldstatestore/v1"
 	"go.viam.com/rdk/logging"
 	"go.viam.com/rdk/resource"
 	worldstatestore "go.viam.com/rdk/services/worldstatestore"
@@ -35,22 +33,10 @@ func (cfg *Config) Validate(path string) ([]string, []string, error) {
 	return []string{}, nil, nil
 }
 
+// worldStateService keeps the arrow commands on top of the shapes store. Arrows are redrawn in place when drawn
+// again with the same UUID, and may share names.
 type worldStateService struct {
-	resource.AlwaysRebuild
-
-	name   resource.Name
-	logger logging.Logger
-	config *Config
-
-	cancelCtx  context.Context
-	cancelFunc func()
-
-	transforms      map[string]*lib.Arrow
-	transformsMutex sync.RWMutex
-
-	changeStream chan worldstatestore.TransformChange
-
-	workers sync.WaitGroup
+	*drawshapes.Shapes
 }
 
 func newWorldStateService(
@@ -74,200 +60,161 @@ func NewWorldStateService(
 	conf *Config,
 	logger logging.Logger,
 ) (worldstatestore.Service, error) {
-	cancelCtx, cancelFunc := context.WithCancel(context.Background())
 	service := &worldStateService{
-		name:         name,
-		logger:       logger,
-		config:       conf,
-		cancelCtx:    cancelCtx,
-		cancelFunc:   cancelFunc,
-		transforms:   make(map[string]*lib.Arrow),
-		changeStream: make(chan worldstatestore.TransformChange, 100),
+		Shapes: drawshapes.New(name, drawshapes.Options{Upsert: true, DuplicateNames: true}, logger),
 	}
 
-	if conf.Arrows != nil {
-		arrows := make([]*lib.Arrow, 0, len(conf.Arrows))
-		for _, toDraw := range conf.Arrows {
-			pose := lib.PoseFromJSON(toDraw.Pose)
-			id, err := lib.UUIDFromString(toDraw.UUID)
-			if err != nil {
-				return nil, err
-			}
-
-			arrow, err := lib.CreateArrow(pose, toDraw.Name, id.Bytes(), &toDraw.Color, toDraw.ParentFrame)
-			if err != nil {
-				return nil, err
-			}
-
-			arrows = append(arrows, arrow)
-		}
+	shapes := make([]*lib.ShapeJSON, 0, len(conf.Arrows))
+	for i := range conf.Arrows {
+		shapes = append(shapes, arrowShape(&conf.Arrows[i]))
+	}
 
-		service.draw(ctx, arrows)
+	if _, err := service.Draw(shapes); err != nil {
+		service.Close(ctx)
+		return nil, err
 	}
 
 	return service, nil
 }
 
-func (service *worldStateService) Name() resource.Name {
-	return service.name
-}
-
-func (service *worldStateService) ListUUIDs(ctx context.Context, extra map[string]any) ([][]byte, error) {
-	service.transformsMutex.RLock()
-	defer service.transformsMutex.RUnlock()
-
-	uuids := make([][]byte, 0, len(service.transforms))
-	for _, arrow := range service.transforms {
-		parsedId, err := uuid.FromBytes(arrow.Uuid)
-		if err != nil {
-			service.logger.Errorw("Failed to parse UUID", "error", err.Error())
-			return nil, err
-		}
-
-		uuids = append(uuids, parsedId[:])
-	}
-
-	return uuids, nil
+// commands describes the DoCommand keys of the arrows service.
+var commands = &command.Set{
+	Model: WorldState,
+	Commands: []command.Command{
+		{
+			Name:        "draw",
+			Description: "Draws an array of arrows, redrawing in place an arrow whose UUID is already drawn",
+			Args:        []lib.ArrowJSON{},
+			Response:    map[string]any{"arrows_added": 0},
+			Parse: func(args any) error {
+				_, err := parseArrows(args, false)
+				return err
+			},
+		},
+		{
+			Name:        "draw_axes",
+			Description: "Draws an array of axes triads, each as three arrows",
+			Args:        []lib.ArrowJSON{},
+			Response:    map[string]any{"arrows_added": 0},
+			Parse: func(args any) error {
+				_, err := parseArrows(args, true)
+				return err
+			},
+		},
+		{
+			Name:        "clear",
+			Description: "Removes every arrow",
+			Response:    map[string]any{"arrows_removed": 0},
+		},
+		command.HideCommand,
+		command.ShowCommand,
+		command.StatsCommand,
+	},
 }
 
-func (service *worldStateService) GetTransform(ctx context.Context, id []byte, extra map[string]any) (*lib.Arrow, error) {
-	service.transformsMutex.RLock()
-	defer service.transformsMutex.RUnlock()
-
-	uuidString, err := uuid.FromBytes(id)
-	if err != nil {
-		service.logger.Errorw("Failed to parse UUID", "error", err.Error())
-		return nil, err
+func (service *worldStateService) DoCommand(ctx context.Context, cmd map[string]any) (map[string]any, error) {
+	if result, ok, err := commands.Handle(cmd); ok {
+		return result, err
 	}
 
-	arrow, ok := service.transforms[uuidString.String()]
-	if !ok {
-		return nil, fmt.Errorf("transform not found for UUID: %x", uuidString)
+	if result, ok, err := service.Visibility(cmd, service.SetVisible); ok {
+		return result, err
 	}
 
-	return arrow, nil
-}
+	if drawData, ok := cmd["draw"]; ok {
+		shapes, err := parseArrows(drawData, false)
+		if err != nil {
+			return service.Fail(err)
+		}
 
-func (service *worldStateService) StreamTransformChanges(ctx context.Context, extra map[string]any) (*worldstatestore.TransformChangeStream, error) {
-	subscriberChan := make(chan worldstatestore.TransformChange, 10)
-	go func() {
-		defer close(subscriberChan)
-		for {
-			select {
-			case change := <-service.changeStream:
-				select {
-				case subscriberChan <- change:
-				case <-ctx.Done():
-					return
-				}
-			case <-ctx.Done():
-				return
-			}
+		drawn, err := service.Draw(shapes)
+		if err != nil {
+			return service.Fail(err)
 		}
-	}()
 
-	return worldstatestore.NewTransformChangeStreamFromChannel(ctx, subscriberChan), nil
-}
+		return map[string]any{
+			"success":      true,
+			"arrows_added": countTransforms(drawn.Items),
+		}, nil
+	}
 
-func (service *worldStateService) DoCommand(ctx context.Context, cmd map[string]any) (map[string]any, error) {
-	if drawData, ok := cmd["draw"]; ok {
-		arrows, err := lib.ParseArrows(drawData)
+	if drawData, ok := cmd["draw_axes"]; ok {
+		shapes, err := parseArrows(drawData, true)
 		if err != nil {
-			return map[string]any{
-				"success": false,
-				"error":   err.Error(),
-			}, err
+			return service.Fail(err)
 		}
 
-		count, err := service.draw(ctx, arrows)
+		drawn, err := service.Draw(shapes)
 		if err != nil {
-			return map[string]any{
-				"success": false,
-				"error":   err.Error(),
-			}, err
+			return service.Fail(err)
 		}
 
 		return map[string]any{
 			"success":      true,
-			"arrows_added": count,
+			"arrows_added": countTransforms(drawn.Items),
 		}, nil
 	}
 
 	if _, ok := cmd["clear"]; ok {
-		count, err := service.clear(ctx)
-		if err != nil {
-			return map[string]any{
-				"success": false,
-				"error":   err.Error(),
-			}, err
-		}
-
 		return map[string]any{
 			"success":        true,
-			"arrows_removed": count,
+			"arrows_removed": countTransforms(service.Clear()),
 		}, nil
 	}
 
-	return nil, fmt.Errorf("Unknown command")
-}
+	if _, ok := cmd["stats"]; ok {
+		return map[string]any{
+			"success": true,
+			"stats":   service.Stats().ToMap(),
+		}, nil
+	}
 
-func (service *worldStateService) Close(context.Context) error {
-	service.cancelFunc()
-	service.workers.Wait()
-	close(service.changeStream)
-	return nil
+	return nil, lib.Errorf(lib.ErrInvalidArgument, "Unknown command")
 }
 
-func (service *worldStateService) emitChange(change worldstatestore.TransformChange) {
-	select {
-	case service.changeStream <- change:
-		// Successfully sent
-	default:
-		service.logger.Warnw("Change stream buffer full, dropping change")
+// arrowShape wraps a configured arrow or axes triad in a shape.
+func arrowShape(arrow *lib.ArrowJSON) *lib.ShapeJSON {
+	if arrow.Axes {
+		return &lib.ShapeJSON{Type: lib.ShapeAxes, Arrow: arrow}
 	}
+
+	return &lib.ShapeJSON{Type: lib.ShapeArrow, Arrow: arrow}
 }
 
-func (service *worldStateService) draw(ctx context.Context, arrows []*lib.Arrow) (int, error) {
-	service.transformsMutex.Lock()
-	defer service.transformsMutex.Unlock()
+// parseArrows parses an array of arrows, or of axes triads when axes is set. Arrow objects with "axes": true are
+// drawn as axes triads either way.
+func parseArrows(drawData any, axes bool) ([]*lib.ShapeJSON, error) {
+	items, ok := drawData.([]any)
+	if !ok {
+		if axes {
+			return nil, lib.Errorf(lib.ErrInvalidArgument, "Expected array of axes, got %T", drawData)
+		}
+		return nil, lib.Errorf(lib.ErrInvalidArgument, "Expected array of arrows, got %T", drawData)
+	}
 
-	for _, arrow := range arrows {
-		id, err := uuid.FromBytes(arrow.Uuid)
+	shapes := make([]*lib.ShapeJSON, 0, len(items))
+	for i, item := range items {
+		arrow, err := lib.ParseArrowSpec(item)
 		if err != nil {
-			service.logger.Errorw("Failed to parse UUID", "error", err.Error())
-			return 0, err
+			if axes {
+				return nil, lib.AtIndex(i, fmt.Errorf("Failed to parse axes at index %d: %w", i, err))
+			}
+			return nil, lib.AtIndex(i, fmt.Errorf("Failed to parse arrow at index %d: %w", i, err))
 		}
 
-		service.transforms[id.String()] = arrow
-		service.emitChange(worldstatestore.TransformChange{
-			ChangeType: v1.TransformChangeType_TRANSFORM_CHANGE_TYPE_ADDED,
-			Transform:  arrow,
-		})
+		arrow.Axes = arrow.Axes || axes
+		shapes = append(shapes, arrowShape(arrow))
 	}
 
-	return len(arrows), nil
+	return shapes, nil
 }
 
-func (service *worldStateService) clear(ctx context.Context) (int, error) {
-	service.transformsMutex.Lock()
-	defer service.transformsMutex.Unlock()
-
-	count := len(service.transforms)
-	for id := range service.transforms {
-		parsedId, err := uuid.Parse(id)
-		if err != nil {
-			service.logger.Errorw("Failed to parse UUID", "error", err.Error())
-			return 0, err
-		}
-
-		service.emitChange(worldstatestore.TransformChange{
-			ChangeType: v1.TransformChangeType_TRANSFORM_CHANGE_TYPE_REMOVED,
-			Transform: &commonPB.Transform{
-				Uuid: parsedId[:],
-			},
-		})
+// countTransforms counts the arrows drawing items, three for each axes triad.
+func countTransforms(items []*drawshapes.Item) int {
+	count := 0
+	for _, item := range items {
+		count += len(item.Transforms)
 	}
 
-	service.transforms = make(map[string]*commonPB.Transform)
-	return count, nil
+	return count
 }
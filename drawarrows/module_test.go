@@ -0,0 +1,19 @@
+package drawarrows
+
+import (
+	"context"
+	"testing"
+
+	"github.com/viam-labs/draw-tools/lib/command/commandtest"
+	"go.viam.com/rdk/logging"
+	worldstatestore "go.viam.com/rdk/services/worldstatestore"
+	"go.viam.com/test"
+)
+
+func FuzzDoCommand(f *testing.F) {
+	service, err := NewWorldStateService(context.Background(), nil, worldstatestore.Named("arrows"), &Config{}, logging.NewTestLogger(f))
+	test.That(f, err, test.ShouldBeNil)
+	f.Cleanup(func() { service.Close(context.Background()) })
+
+	commandtest.Fuzz(f, commands, service.DoCommand)
+}
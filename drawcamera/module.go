@@ -0,0 +1,297 @@
+package drawcamera
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/viam-labs/draw-tools/lib"
+	"github.com/viam-labs/draw-tools/lib/command"
+	"github.com/viam-labs/draw-tools/lib/store"
+
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	worldstatestore "go.viam.com/rdk/services/worldstatestore"
+)
+
+var (
+	WorldState = resource.NewModel("viam-viz", "draw-tools", "camera-pointcloud-world-state")
+)
+
+// DefaultRateHz is the default number of point clouds requested from the camera per second.
+const DefaultRateHz = 1.0
+
+func init() {
+	resource.RegisterService(worldstatestore.API, WorldState,
+		resource.Registration[worldstatestore.Service, *Config]{
+			Constructor: newWorldStateService,
+		},
+	)
+}
+
+type Config struct {
+	Camera      string    `json:"camera"`
+	RateHz      float64   `json:"rate_hz,omitempty"`       // Point clouds requested per second (defaults to 1)
+	VoxelSizeMm float64   `json:"voxel_size_mm,omitempty"` // Edge length of the downsampling voxels (defaults to 0, no downsampling)
+	MaxPoints   int       `json:"max_points,omitempty"`    // Maximum number of points drawn (defaults to 0, no limit)
+	ColorBy     string    `json:"color_by,omitempty"`      // Recolor the points by "height" or "intensity" (defaults to the camera colors)
+	PointSize   float64   `json:"point_size,omitempty"`    // Rendered size of each point (defaults to the viewer's size)
+	Color       lib.Color `json:"color,omitempty"`         // Color of points without their own color (defaults to gray)
+	Name        string    `json:"name,omitempty"`          // Name of the point cloud frame (defaults to "{camera}-pointcloud")
+	UUID        string    `json:"uuid,omitempty"`          // UUID of the point cloud (defaults to a new UUID)
+	Paused      bool      `json:"paused,omitempty"`        // Wait for a start command before requesting point clouds (defaults to false)
+}
+
+func (cfg *Config) Validate(path string) ([]string, []string, error) {
+	if cfg.Camera == "" {
+		return nil, nil, resource.NewConfigValidationFieldRequiredError(path, "camera")
+	}
+
+	if cfg.RateHz < 0 {
+		return nil, nil, resource.NewConfigValidationError(path, errors.New("rate_hz must not be negative"))
+	}
+
+	if cfg.VoxelSizeMm < 0 {
+		return nil, nil, resource.NewConfigValidationError(path, errors.New("voxel_size_mm must not be negative"))
+	}
+
+	if cfg.MaxPoints < 0 {
+		return nil, nil, resource.NewConfigValidationError(path, errors.New("max_points must not be negative"))
+	}
+
+	if cfg.PointSize < 0 {
+		return nil, nil, resource.NewConfigValidationError(path, errors.New("point_size must not be negative"))
+	}
+
+	if err := lib.ValidateColorBy(cfg.ColorBy); err != nil {
+		return nil, nil, resource.NewConfigValidationError(path, err)
+	}
+
+	if _, err := lib.UUIDFromString(cfg.UUID); err != nil {
+		return nil, nil, resource.NewConfigValidationError(path, fmt.Errorf("invalid uuid: %w", err))
+	}
+
+	return []string{cfg.Camera}, nil, nil
+}
+
+type worldStateService struct {
+	*store.Service
+
+	logger logging.Logger
+	config *Config
+
+	camera camera.Camera
+	id     lib.UUID
+	frame  string
+	period time.Duration
+
+	running      bool
+	runningMutex sync.RWMutex
+}
+
+func newWorldStateService(
+	ctx context.Context,
+	deps resource.Dependencies,
+	rawConf resource.Config,
+	logger logging.Logger,
+) (worldstatestore.Service, error) {
+	conf, err := resource.NativeConfig[*Config](rawConf)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewWorldStateService(ctx, deps, rawConf.ResourceName(), conf, logger)
+}
+
+func NewWorldStateService(
+	ctx context.Context,
+	deps resource.Dependencies,
+	name resource.Name,
+	conf *Config,
+	logger logging.Logger,
+) (worldstatestore.Service, error) {
+	cam, err := camera.FromDependencies(deps, conf.Camera)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to get camera %v: %w", conf.Camera, err)
+	}
+
+	// an empty uuid generates a new one
+	id, err := lib.UUIDFromString(conf.UUID)
+	if err != nil {
+		return nil, err
+	}
+
+	frame := conf.Name
+	if frame == "" {
+		frame = conf.Camera + "-pointcloud"
+	}
+
+	rate := conf.RateHz
+	if rate <= 0 {
+		rate = DefaultRateHz
+	}
+
+	service := &worldStateService{
+		Service: store.NewService(name, logger),
+		logger:  logger,
+		config:  conf,
+		camera:  cam,
+		id:      *id,
+		frame:   frame,
+		period:  time.Duration(float64(time.Second) / rate),
+		running: !conf.Paused,
+	}
+
+	service.Go(service.poll)
+
+	return service, nil
+}
+
+// poll requests a point cloud from the camera every period while the service is running.
+func (s *worldStateService) poll(ctx context.Context) {
+	ticker := time.NewTicker(s.period)
+	defer ticker.Stop()
+
+	for {
+		s.runningMutex.RLock()
+		running := s.running
+		s.runningMutex.RUnlock()
+
+		if running {
+			if err := s.update(ctx); err != nil && ctx.Err() == nil {
+				s.RecordError(err)
+				s.logger.Warnw("Failed to update point cloud from camera", "camera", s.config.Camera, "error", err.Error())
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// update fetches the next point cloud from the camera and publishes it, adding it the first time and updating it in place
+// afterwards. A point cloud identical to the drawn one emits no change.
+func (s *worldStateService) update(ctx context.Context) error {
+	cloud, err := s.camera.NextPointCloud(ctx)
+	if err != nil {
+		return err
+	}
+
+	cloud, err = lib.VoxelDownsample(cloud, s.config.VoxelSizeMm)
+	if err != nil {
+		return err
+	}
+
+	cloud, err = lib.LimitPoints(cloud, s.config.MaxPoints)
+	if err != nil {
+		return err
+	}
+
+	cloud, err = lib.ColorPointCloud(cloud, s.config.ColorBy)
+	if err != nil {
+		return err
+	}
+
+	color := s.config.Color
+	if color == (lib.Color{}) {
+		color = lib.DefaultPointCloudColor
+	}
+
+	// the camera reports points in its own frame, so the cloud sits at the origin of the camera frame
+	transform, err := lib.CreatePointCloud(cloud, nil, s.frame, s.id.Bytes(), &color, s.config.Camera, s.config.PointSize, s.config.Camera)
+	if err != nil {
+		return err
+	}
+
+	s.runningMutex.RLock()
+	defer s.runningMutex.RUnlock()
+
+	// the service may have been stopped while the camera was being read
+	if !s.running {
+		return nil
+	}
+
+	return s.Put(transform)
+}
+
+// setRunning starts or stops requesting point clouds. The last point cloud stays drawn while stopped.
+func (s *worldStateService) setRunning(running bool) {
+	s.runningMutex.Lock()
+	defer s.runningMutex.Unlock()
+
+	s.running = running
+}
+
+// commands describes the DoCommand keys of the camera point cloud service.
+var commands = &command.Set{
+	Model: WorldState,
+	Commands: []command.Command{
+		{
+			Name:        "start",
+			Description: "Starts requesting point clouds from the camera",
+			Response:    map[string]any{"running": true},
+		},
+		{
+			Name:        "stop",
+			Description: "Stops requesting point clouds, keeping the last one drawn",
+			Response:    map[string]any{"running": true},
+		},
+		{
+			Name:        "clear",
+			Description: "Removes the drawn point cloud",
+			Response:    map[string]any{"pointclouds_removed": 0},
+		},
+		command.HideCommand,
+		command.ShowCommand,
+		command.StatsCommand,
+	},
+}
+
+func (service *worldStateService) DoCommand(ctx context.Context, cmd map[string]any) (map[string]any, error) {
+	if result, ok, err := commands.Handle(cmd); ok {
+		return result, err
+	}
+
+	if result, ok, err := service.Visibility(cmd, service.SetVisible); ok {
+		return result, err
+	}
+
+	if _, ok := cmd["start"]; ok {
+		service.setRunning(true)
+		return map[string]any{
+			"success": true,
+			"running": true,
+		}, nil
+	}
+
+	if _, ok := cmd["stop"]; ok {
+		service.setRunning(false)
+		return map[string]any{
+			"success": true,
+			"running": false,
+		}, nil
+	}
+
+	if _, ok := cmd["clear"]; ok {
+		count := service.Remove(service.id.String())
+		return map[string]any{
+			"success":             true,
+			"pointclouds_removed": count,
+		}, nil
+	}
+
+	if _, ok := cmd["stats"]; ok {
+		return map[string]any{
+			"success": true,
+			"stats":   service.Stats().ToMap(),
+		}, nil
+	}
+
+	return nil, lib.Errorf(lib.ErrInvalidArgument, "Unknown command")
+}
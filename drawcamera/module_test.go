@@ -0,0 +1,143 @@
+package drawcamera
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang/geo/r3"
+	"github.com/viam-labs/draw-tools/lib/command/commandtest"
+	v1 "go.viam.com/api/service/worldstatestore/v1"
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/pointcloud"
+	"go.viam.com/rdk/resource"
+	worldstatestore "go.viam.com/rdk/services/worldstatestore"
+	"go.viam.com/rdk/testutils/inject"
+	"go.viam.com/test"
+)
+
+// newFakeCamera returns a camera whose point clouds move one millimeter further away on every request.
+func newFakeCamera(points int) *inject.Camera {
+	cam := inject.NewCamera("depth")
+	var requests atomic.Int64
+	cam.NextPointCloudFunc = func(ctx context.Context) (pointcloud.PointCloud, error) {
+		z := 1000 + float64(requests.Add(1))
+		cloud := pointcloud.NewBasicPointCloud(points)
+		for i := 0; i < points; i++ {
+			if err := cloud.Set(r3.Vector{X: float64(i), Z: z}, pointcloud.NewBasicData()); err != nil {
+				return nil, err
+			}
+		}
+
+		return cloud, nil
+	}
+
+	return cam
+}
+
+func TestCameraPointCloud(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cam := newFakeCamera(100)
+	deps := resource.Dependencies{camera.Named("depth"): cam}
+	conf := &Config{Camera: "depth", RateHz: 50, MaxPoints: 10}
+
+	service, err := NewWorldStateService(ctx, deps, worldstatestore.Named("live"), conf, logging.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	defer service.Close(ctx)
+
+	stream, err := service.StreamTransformChanges(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+
+	// the first point cloud may be drawn before the stream subscribed, so wait for it to be listed
+	var uuids [][]byte
+	for len(uuids) == 0 {
+		uuids, err = service.ListUUIDs(ctx, nil)
+		test.That(t, err, test.ShouldBeNil)
+		time.Sleep(time.Millisecond)
+	}
+
+	added, err := service.GetTransform(ctx, uuids[0], nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, added.ReferenceFrame, test.ShouldEqual, "depth-pointcloud")
+	test.That(t, added.PoseInObserverFrame.ReferenceFrame, test.ShouldEqual, "depth")
+
+	cloud, err := pointcloud.NewPointCloudFromProto(added.PhysicalObject.GetPointcloud(), "")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, cloud.Size(), test.ShouldEqual, 10)
+
+	var updated worldstatestore.TransformChange
+	for updated.ChangeType != v1.TransformChangeType_TRANSFORM_CHANGE_TYPE_UPDATED {
+		updated, err = stream.Next()
+		test.That(t, err, test.ShouldBeNil)
+	}
+	test.That(t, updated.Transform.Uuid, test.ShouldResemble, added.Uuid)
+	test.That(t, updated.UpdatedFields, test.ShouldResemble, []string{"physicalObject"})
+
+	result, err := service.DoCommand(ctx, map[string]any{"stop": true})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, result["running"], test.ShouldEqual, false)
+
+	result, err = service.DoCommand(ctx, map[string]any{"clear": true})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, result["pointclouds_removed"], test.ShouldEqual, 1)
+
+	uuids, err = service.ListUUIDs(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, uuids, test.ShouldBeEmpty)
+}
+
+func TestCameraPointCloudPaused(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cam := newFakeCamera(5)
+	deps := resource.Dependencies{camera.Named("depth"): cam}
+	conf := &Config{Camera: "depth", RateHz: 50, Paused: true}
+
+	service, err := NewWorldStateService(ctx, deps, worldstatestore.Named("live"), conf, logging.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	defer service.Close(ctx)
+
+	time.Sleep(100 * time.Millisecond)
+	uuids, err := service.ListUUIDs(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, uuids, test.ShouldBeEmpty)
+
+	stream, err := service.StreamTransformChanges(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+
+	result, err := service.DoCommand(ctx, map[string]any{"start": true})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, result["running"], test.ShouldEqual, true)
+
+	added, err := stream.Next()
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, added.ChangeType, test.ShouldEqual, v1.TransformChangeType_TRANSFORM_CHANGE_TYPE_ADDED)
+}
+
+func TestValidate(t *testing.T) {
+	deps, _, err := (&Config{Camera: "depth"}).Validate("services.0")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, deps, test.ShouldResemble, []string{"depth"})
+
+	_, _, err = (&Config{}).Validate("services.0")
+	test.That(t, err, test.ShouldNotBeNil)
+
+	_, _, err = (&Config{Camera: "depth", ColorBy: "depth"}).Validate("services.0")
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+func FuzzDoCommand(f *testing.F) {
+	deps := resource.Dependencies{camera.Named("depth"): newFakeCamera(10)}
+	conf := &Config{Camera: "depth", RateHz: 50, Paused: true}
+
+	service, err := NewWorldStateService(context.Background(), deps, worldstatestore.Named("live"), conf, logging.NewTestLogger(f))
+	test.That(f, err, test.ShouldBeNil)
+	f.Cleanup(func() { service.Close(context.Background()) })
+
+	commandtest.Fuzz(f, commands, service.DoCommand)
+}
@@ -0,0 +1,405 @@
+package drawframesystem
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/viam-labs/draw-tools/lib"
+	"github.com/viam-labs/draw-tools/lib/command"
+	"github.com/viam-labs/draw-tools/lib/store"
+
+	"github.com/golang/geo/r3"
+	commonPB "go.viam.com/api/common/v1"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/robot/framesystem"
+	worldstatestore "go.viam.com/rdk/services/worldstatestore"
+	"go.viam.com/rdk/spatialmath"
+)
+
+var (
+	WorldState = resource.NewModel("viam-viz", "draw-tools", "frame-system-world-state")
+)
+
+const (
+	// DefaultRateHz is the default number of times per second the frame system is redrawn.
+	DefaultRateHz = 1.0
+	// DefaultLabelOffsetMm is the default height of each frame's label above its origin.
+	DefaultLabelOffsetMm = 30.0
+	// DefaultLinkWidthMm is the default width of the lines connecting parent and child frames.
+	DefaultLinkWidthMm = 4.0
+)
+
+// DefaultLinkColor is the color of the lines connecting parent and child frames (gray).
+var DefaultLinkColor = lib.Color{R: 160, G: 160, B: 160}
+
+func init() {
+	resource.RegisterService(worldstatestore.API, WorldState,
+		resource.Registration[worldstatestore.Service, *Config]{
+			Constructor: newWorldStateService,
+		},
+	)
+}
+
+type Config struct {
+	ReferenceFrame string  `json:"reference_frame,omitempty"` // Frame every frame is drawn in (defaults to "world")
+	RateHz         float64 `json:"rate_hz,omitempty"`         // Redraws per second (defaults to 1)
+	Labels         *bool   `json:"labels,omitempty"`          // Draw a name label above every frame (defaults to true)
+	LabelOffsetMm  float64 `json:"label_offset_mm,omitempty"` // Height of each label above its frame (defaults to 30)
+	Links          bool    `json:"links,omitempty"`           // Draw lines connecting parent and child frames (defaults to false)
+	LinkWidthMm    float64 `json:"link_width_mm,omitempty"`   // Width of the lines connecting frames (defaults to 4)
+	Paused         bool    `json:"paused,omitempty"`          // Only redraw on a refresh command (defaults to false)
+}
+
+func (cfg *Config) Validate(path string) ([]string, []string, error) {
+	if cfg.RateHz < 0 {
+		return nil, nil, resource.NewConfigValidationError(path, errors.New("rate_hz must not be negative"))
+	}
+
+	if cfg.LabelOffsetMm < 0 {
+		return nil, nil, resource.NewConfigValidationError(path, errors.New("label_offset_mm must not be negative"))
+	}
+
+	if cfg.LinkWidthMm < 0 {
+		return nil, nil, resource.NewConfigValidationError(path, errors.New("link_width_mm must not be negative"))
+	}
+
+	// the frame system is always available to modules
+	return nil, nil, nil
+}
+
+type worldStateService struct {
+	*store.Service
+
+	logger logging.Logger
+	config *Config
+
+	frameSystem    framesystem.Service
+	referenceFrame string
+	id             lib.UUID
+	period         time.Duration
+
+	frames      int
+	running     bool
+	framesMutex sync.RWMutex
+
+	// serializes redraws from the timer and the refresh command
+	refreshMutex sync.Mutex
+}
+
+func newWorldStateService(
+	ctx context.Context,
+	deps resource.Dependencies,
+	rawConf resource.Config,
+	logger logging.Logger,
+) (worldstatestore.Service, error) {
+	conf, err := resource.NativeConfig[*Config](rawConf)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewWorldStateService(ctx, deps, rawConf.ResourceName(), conf, logger)
+}
+
+func NewWorldStateService(
+	ctx context.Context,
+	deps resource.Dependencies,
+	name resource.Name,
+	conf *Config,
+	logger logging.Logger,
+) (worldstatestore.Service, error) {
+	frameSystem, err := framesystem.FromDependencies(deps)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to get frame system: %w", err)
+	}
+
+	referenceFrame := conf.ReferenceFrame
+	if referenceFrame == "" {
+		referenceFrame = referenceframe.World
+	}
+
+	rate := conf.RateHz
+	if rate <= 0 {
+		rate = DefaultRateHz
+	}
+
+	service := &worldStateService{
+		Service:        store.NewService(name, logger),
+		logger:         logger,
+		config:         conf,
+		frameSystem:    frameSystem,
+		referenceFrame: referenceFrame,
+		id:             lib.GenerateUUID(),
+		period:         time.Duration(float64(time.Second) / rate),
+		running:        !conf.Paused,
+	}
+
+	service.Go(service.poll)
+
+	return service, nil
+}
+
+// poll redraws the frame system every period while the service is running.
+func (s *worldStateService) poll(ctx context.Context) {
+	ticker := time.NewTicker(s.period)
+	defer ticker.Stop()
+
+	for {
+		s.framesMutex.RLock()
+		running := s.running
+		s.framesMutex.RUnlock()
+
+		if running {
+			if _, err := s.refresh(ctx); err != nil && ctx.Err() == nil {
+				s.RecordError(err)
+				s.logger.Warnw("Failed to refresh frame system", "error", err.Error())
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// refresh reads the frame system configuration and the current pose of every frame, then reconciles the drawing:
+// new transforms are ADDED, changed ones are UPDATED with the fields that changed, and ones for frames that no longer
+// exist are REMOVED. A frame whose pose cannot be read is left out, which usually points at a misconfigured parent.
+// Returns the number of frames drawn.
+func (s *worldStateService) refresh(ctx context.Context) (int, error) {
+	s.refreshMutex.Lock()
+	defer s.refreshMutex.Unlock()
+
+	conf, err := s.frameSystem.FrameSystemConfig(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	parents := make(map[string]string, len(conf.Parts))
+	for _, part := range conf.Parts {
+		if part.FrameConfig == nil {
+			continue
+		}
+		parents[part.FrameConfig.Name()] = part.FrameConfig.Parent()
+	}
+
+	poses := map[string]spatialmath.Pose{s.referenceFrame: spatialmath.NewZeroPose()}
+	if s.referenceFrame != referenceframe.World {
+		pose, err := s.frameSystem.GetPose(ctx, referenceframe.World, s.referenceFrame, nil, nil)
+		if err != nil {
+			return 0, fmt.Errorf("Unable to get pose of %s in %s: %w", referenceframe.World, s.referenceFrame, err)
+		}
+		poses[referenceframe.World] = pose.Pose()
+	}
+
+	for name := range parents {
+		if _, ok := poses[name]; ok {
+			continue
+		}
+
+		pose, err := s.frameSystem.GetPose(ctx, name, s.referenceFrame, nil, nil)
+		if err != nil {
+			if ctx.Err() != nil {
+				return 0, ctx.Err()
+			}
+			s.RecordError(err)
+			s.logger.Warnw("Failed to get frame pose", "frame", name, "error", err.Error())
+			continue
+		}
+		poses[name] = pose.Pose()
+	}
+
+	next, err := s.build(parents, poses)
+	if err != nil {
+		return 0, err
+	}
+
+	s.framesMutex.Lock()
+	defer s.framesMutex.Unlock()
+
+	if err := s.Reconcile(next...); err != nil {
+		return 0, err
+	}
+
+	s.frames = len(poses)
+	return len(poses), nil
+}
+
+// build creates the axes, labels and links drawing every frame with a known pose. UUIDs are derived from the frame
+// names so each frame keeps its transforms between refreshes.
+func (s *worldStateService) build(parents map[string]string, poses map[string]spatialmath.Pose) ([]*commonPB.Transform, error) {
+	names := make([]string, 0, len(poses))
+	for name := range poses {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	labelOffset := s.config.LabelOffsetMm
+	if labelOffset <= 0 {
+		labelOffset = DefaultLabelOffsetMm
+	}
+
+	next := []*commonPB.Transform{}
+
+	for _, name := range names {
+		pose := poses[name]
+
+		axesID := lib.DeriveUUID(s.id, "axes:"+name)
+		axes, err := lib.CreateAxes(spatialmath.PoseToProtobuf(pose), name, axesID.Bytes(), s.referenceFrame)
+		if err != nil {
+			return nil, err
+		}
+		next = append(next, axes...)
+
+		if s.config.Labels == nil || *s.config.Labels {
+			labelID := lib.DeriveUUID(s.id, "label:"+name)
+			labelPose := spatialmath.NewPoseFromPoint(pose.Point().Add(r3.Vector{Z: labelOffset}))
+			label, err := lib.CreateLabel(spatialmath.PoseToProtobuf(labelPose), name, name+"-label", labelID.Bytes(), nil, s.referenceFrame)
+			if err != nil {
+				return nil, err
+			}
+			next = append(next, label)
+		}
+
+		parentPose, ok := poses[parents[name]]
+		if !s.config.Links || !ok || parentPose.Point().Distance(pose.Point()) == 0 {
+			continue
+		}
+
+		color := DefaultLinkColor
+		linkID := lib.DeriveUUID(s.id, "link:"+name)
+		link, err := lib.CreatePolyline(
+			[]r3.Vector{parentPose.Point(), pose.Point()},
+			s.linkWidth(),
+			parents[name]+"-"+name+"-link",
+			linkID.Bytes(),
+			&color,
+			s.referenceFrame,
+		)
+		if err != nil {
+			return nil, err
+		}
+		next = append(next, link)
+	}
+
+	return next, nil
+}
+
+func (s *worldStateService) linkWidth() float64 {
+	if s.config.LinkWidthMm > 0 {
+		return s.config.LinkWidthMm
+	}
+
+	return DefaultLinkWidthMm
+}
+
+// setRunning starts or stops the periodic redraw. The frames stay drawn while stopped.
+func (s *worldStateService) setRunning(running bool) {
+	s.framesMutex.Lock()
+	defer s.framesMutex.Unlock()
+
+	s.running = running
+}
+
+// commands describes the DoCommand keys of the frame system service.
+var commands = &command.Set{
+	Model: WorldState,
+	Commands: []command.Command{
+		{
+			Name:        "refresh",
+			Description: "Redraws the frame system immediately",
+			Response:    map[string]any{"frames": 0},
+		},
+		{
+			Name:        "start",
+			Description: "Starts redrawing the frame system on the timer",
+			Response:    map[string]any{"running": true},
+		},
+		{
+			Name:        "stop",
+			Description: "Stops redrawing the frame system on the timer",
+			Response:    map[string]any{"running": true},
+		},
+		{
+			Name:        "clear",
+			Description: "Removes every drawn frame",
+			Response:    map[string]any{"frames_removed": 0},
+		},
+		command.HideCommand,
+		command.ShowCommand,
+		command.StatsCommand,
+	},
+}
+
+func (service *worldStateService) DoCommand(ctx context.Context, cmd map[string]any) (map[string]any, error) {
+	if result, ok, err := commands.Handle(cmd); ok {
+		return result, err
+	}
+
+	if result, ok, err := service.Visibility(cmd, service.SetVisible); ok {
+		return result, err
+	}
+
+	if _, ok := cmd["refresh"]; ok {
+		count, err := service.refresh(ctx)
+		if err != nil {
+			return service.Fail(err)
+		}
+
+		return map[string]any{
+			"success": true,
+			"frames":  count,
+		}, nil
+	}
+
+	if _, ok := cmd["start"]; ok {
+		service.setRunning(true)
+		return map[string]any{
+			"success": true,
+			"running": true,
+		}, nil
+	}
+
+	if _, ok := cmd["stop"]; ok {
+		service.setRunning(false)
+		return map[string]any{
+			"success": true,
+			"running": false,
+		}, nil
+	}
+
+	if _, ok := cmd["clear"]; ok {
+		count := service.clear()
+		return map[string]any{
+			"success":        true,
+			"frames_removed": count,
+		}, nil
+	}
+
+	if _, ok := cmd["stats"]; ok {
+		return map[string]any{
+			"success": true,
+			"stats":   service.Stats().ToMap(),
+		}, nil
+	}
+
+	return nil, lib.Errorf(lib.ErrInvalidArgument, "Unknown command")
+}
+
+// clear removes every drawn frame. A running service draws the frames again on the next redraw.
+func (service *worldStateService) clear() int {
+	service.framesMutex.Lock()
+	defer service.framesMutex.Unlock()
+
+	count := service.frames
+	service.Clear()
+	service.frames = 0
+	return count
+}
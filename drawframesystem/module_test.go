@@ -0,0 +1,207 @@
+package drawframesystem
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/geo/r3"
+	"github.com/viam-labs/draw-tools/lib/command/commandtest"
+	v1 "go.viam.com/api/service/worldstatestore/v1"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/robot/framesystem"
+	worldstatestore "go.viam.com/rdk/services/worldstatestore"
+	"go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/testutils/inject"
+	"go.viam.com/test"
+)
+
+// fakeFrameSystem is a frame system of frames translated from their parents, posed by composing those translations.
+type fakeFrameSystem struct {
+	*inject.FrameSystemService
+
+	mu      sync.Mutex
+	parents map[string]string
+	offsets map[string]r3.Vector
+}
+
+func newFakeFrameSystem() *fakeFrameSystem {
+	fs := &fakeFrameSystem{
+		FrameSystemService: inject.NewFrameSystemService("$framesystem"),
+		parents:            map[string]string{"arm": "world", "gripper": "arm"},
+		offsets:            map[string]r3.Vector{"arm": {X: 100}, "gripper": {Z: 50}},
+	}
+
+	fs.GetPoseFunc = func(
+		ctx context.Context,
+		componentName, destinationFrame string,
+		supplementalTransforms []*referenceframe.LinkInFrame,
+		extra map[string]interface{},
+	) (*referenceframe.PoseInFrame, error) {
+		fs.mu.Lock()
+		defer fs.mu.Unlock()
+
+		point := r3.Vector{}
+		for name := componentName; name != referenceframe.World; name = fs.parents[name] {
+			offset, ok := fs.offsets[name]
+			if !ok {
+				return nil, errors.New("frame not found")
+			}
+			point = point.Add(offset)
+		}
+
+		return referenceframe.NewPoseInFrame(destinationFrame, spatialmath.NewPoseFromPoint(point)), nil
+	}
+
+	return fs
+}
+
+func (fs *fakeFrameSystem) FrameSystemConfig(ctx context.Context) (*framesystem.Config, error) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	parts := []*referenceframe.FrameSystemPart{}
+	for name, parent := range fs.parents {
+		link := referenceframe.NewLinkInFrame(parent, spatialmath.NewPoseFromPoint(fs.offsets[name]), name, nil)
+		parts = append(parts, &referenceframe.FrameSystemPart{FrameConfig: link})
+	}
+
+	return &framesystem.Config{Parts: parts}, nil
+}
+
+func (fs *fakeFrameSystem) move(name string, offset r3.Vector) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	fs.offsets[name] = offset
+}
+
+func (fs *fakeFrameSystem) remove(name string) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	delete(fs.parents, name)
+	delete(fs.offsets, name)
+}
+
+func TestFrameSystem(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	fs := newFakeFrameSystem()
+	deps := resource.Dependencies{framesystem.PublicServiceName: fs}
+	conf := &Config{Links: true, Paused: true}
+
+	service, err := NewWorldStateService(ctx, deps, worldstatestore.Named("frames"), conf, logging.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	defer service.Close(ctx)
+
+	stream, err := service.StreamTransformChanges(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+
+	result, err := service.DoCommand(ctx, map[string]any{"refresh": true})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, result["frames"], test.ShouldEqual, 3)
+
+	// world, arm and gripper each get axes and a label, and arm and gripper a link to their parent
+	frames := map[string][]byte{}
+	for len(frames) < 3*3+3+2 {
+		change, err := stream.Next()
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, change.ChangeType, test.ShouldEqual, v1.TransformChangeType_TRANSFORM_CHANGE_TYPE_ADDED)
+		frames[change.Transform.ReferenceFrame] = change.Transform.Uuid
+	}
+
+	for _, name := range []string{"world-x", "arm-y", "gripper-z", "arm-label", "gripper-label", "world-arm-link", "arm-gripper-link"} {
+		test.That(t, frames, test.ShouldContainKey, name)
+	}
+
+	label, err := service.GetTransform(ctx, frames["gripper-label"], nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, label.Metadata.AsMap()["text"], test.ShouldEqual, "gripper")
+	test.That(t, label.PoseInObserverFrame.Pose.X, test.ShouldAlmostEqual, 100.0)
+	test.That(t, label.PoseInObserverFrame.Pose.Z, test.ShouldAlmostEqual, 50+DefaultLabelOffsetMm)
+
+	// moving a frame only updates the transforms that changed
+	fs.move("gripper", r3.Vector{Z: 80})
+	_, err = service.DoCommand(ctx, map[string]any{"refresh": true})
+	test.That(t, err, test.ShouldBeNil)
+
+	updated := map[string]bool{}
+	for len(updated) < 3+1+1 {
+		change, err := stream.Next()
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, change.ChangeType, test.ShouldEqual, v1.TransformChangeType_TRANSFORM_CHANGE_TYPE_UPDATED)
+		updated[change.Transform.ReferenceFrame] = true
+	}
+	test.That(t, updated, test.ShouldContainKey, "gripper-x")
+	test.That(t, updated, test.ShouldContainKey, "gripper-label")
+	test.That(t, updated, test.ShouldContainKey, "arm-gripper-link")
+
+	// removing a frame removes its axes, label and link
+	fs.remove("gripper")
+	result, err = service.DoCommand(ctx, map[string]any{"refresh": true})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, result["frames"], test.ShouldEqual, 2)
+
+	removed := map[string]bool{}
+	for len(removed) < 5 {
+		change, err := stream.Next()
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, change.ChangeType, test.ShouldEqual, v1.TransformChangeType_TRANSFORM_CHANGE_TYPE_REMOVED)
+		removed[string(change.Transform.Uuid)] = true
+	}
+	test.That(t, removed[string(frames["gripper-label"])], test.ShouldBeTrue)
+	test.That(t, removed[string(frames["arm-gripper-link"])], test.ShouldBeTrue)
+
+	result, err = service.DoCommand(ctx, map[string]any{"clear": true})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, result["frames_removed"], test.ShouldEqual, 2)
+
+	uuids, err := service.ListUUIDs(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, uuids, test.ShouldBeEmpty)
+}
+
+func TestFrameSystemWithoutLabels(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	labels := false
+	deps := resource.Dependencies{framesystem.PublicServiceName: newFakeFrameSystem()}
+	conf := &Config{Labels: &labels, Paused: true}
+
+	service, err := NewWorldStateService(ctx, deps, worldstatestore.Named("frames"), conf, logging.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	defer service.Close(ctx)
+
+	_, err = service.DoCommand(ctx, map[string]any{"refresh": true})
+	test.That(t, err, test.ShouldBeNil)
+
+	// only the axes of world, arm and gripper are drawn
+	uuids, err := service.ListUUIDs(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, uuids, test.ShouldHaveLength, 9)
+}
+
+func TestValidate(t *testing.T) {
+	deps, _, err := (&Config{}).Validate("services.0")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, deps, test.ShouldBeEmpty)
+
+	_, _, err = (&Config{RateHz: -1}).Validate("services.0")
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+func FuzzDoCommand(f *testing.F) {
+	deps := resource.Dependencies{framesystem.PublicServiceName: newFakeFrameSystem()}
+	conf := &Config{Paused: true}
+
+	service, err := NewWorldStateService(context.Background(), deps, worldstatestore.Named("frames"), conf, logging.NewTestLogger(f))
+	test.That(f, err, test.ShouldBeNil)
+	f.Cleanup(func() { service.Close(context.Background()) })
+
+	commandtest.Fuzz(f, commands, service.DoCommand)
+}
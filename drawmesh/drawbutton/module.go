@@ -5,11 +5,12 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/viam-labs/draw-tools/client"
 	"github.com/viam-labs/draw-tools/lib"
+	"github.com/viam-labs/draw-tools/lib/command"
 	button "go.viam.com/rdk/components/button"
 	"go.viam.com/rdk/logging"
 	"go.viam.com/rdk/resource"
-	worldstatestore "go.viam.com/rdk/services/worldstatestore"
 )
 
 var (
@@ -25,9 +26,9 @@ func init() {
 }
 
 type Config struct {
-	ServiceName string    `json:"service_name"`
-	ModelPath   string    `json:"model_path"`
-	Color       lib.Color `json:"color"`
+	ServiceName string     `json:"service_name"`
+	ModelPath   string     `json:"model_path"`
+	Color       *lib.Color `json:"color,omitempty"`
 }
 
 func (config *Config) Validate(path string) ([]string, []string, error) {
@@ -52,7 +53,7 @@ type drawMeshButton struct {
 	cancelCtx  context.Context
 	cancelFunc func()
 
-	service worldstatestore.Service
+	client *client.Client
 }
 
 func newDrawMeshButton(
@@ -77,8 +78,7 @@ func NewDrawMeshButton(
 	logger logging.Logger,
 ) (button.Button, error) {
 	cancelCtx, cancelFunc := context.WithCancel(context.Background())
-	serviceName := worldstatestore.Named(conf.ServiceName)
-	service, err := worldstatestore.FromDependencies(deps, serviceName.Name)
+	drawClient, err := client.FromDependencies(deps, conf.ServiceName)
 	if err != nil {
 		return nil, err
 	}
@@ -89,7 +89,7 @@ func NewDrawMeshButton(
 		config:     conf,
 		cancelCtx:  cancelCtx,
 		cancelFunc: cancelFunc,
-		service:    service,
+		client:     drawClient,
 	}
 
 	return component, nil
@@ -100,29 +100,30 @@ func (s *drawMeshButton) Name() resource.Name {
 }
 
 func (s *drawMeshButton) Push(ctx context.Context, extra map[string]interface{}) error {
-
-	color := lib.Color{R: 0, G: 0, B: 255}
-	if s.config.Color != (lib.Color{}) {
-		color = s.config.Color
-	}
-	result, err := s.service.DoCommand(ctx, map[string]interface{}{
-		"draw": map[string]interface{}{
-			"model_path": s.config.ModelPath,
-			"color":      color,
-		},
+	drawn, err := s.client.DrawMesh(ctx, client.MeshOptions{
+		ModelPath: s.config.ModelPath,
+		Color:     s.config.Color,
 	})
 	if err != nil {
 		return err
 	}
 
-	if result["success"] != true {
-		return fmt.Errorf("Failed to draw mesh: %s", result["error"])
+	// directories and glob patterns report files that failed to draw without failing the push
+	if drawn.Failed > 0 {
+		s.logger.Warnw("Some meshes failed to draw", "failed", drawn.Failed, "results", drawn.Results)
 	}
 
 	return nil
 }
 
+// commands describes the button, which has no commands of its own besides the built-in ones.
+var commands = &command.Set{Model: DrawMesh}
+
 func (s *drawMeshButton) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	if result, ok, err := commands.Handle(cmd); ok {
+		return result, err
+	}
+
 	return nil, fmt.Errorf("Not implemented, use service DoCommand instead")
 }
 
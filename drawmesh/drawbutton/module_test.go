@@ -0,0 +1,11 @@
+package drawmeshbutton
+
+import (
+	"testing"
+
+	"github.com/viam-labs/draw-tools/lib/command/commandtest"
+)
+
+func FuzzDoCommand(f *testing.F) {
+	commandtest.Fuzz(f, commands, (&drawMeshButton{}).DoCommand)
+}
@@ -2,20 +2,17 @@ package drawmesh
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"os"
-	"sync"
+	"time"
 
+	"github.com/viam-labs/draw-tools/drawshapes"
 	"github.com/viam-labs/draw-tools/lib"
-	"google.golang.org/protobuf/types/known/structpb"
+	"github.com/viam-labs/draw-tools/lib/command"
 
-	"github.com/google/uuid"
-	commonPB "go.viam.com/api/common/v1"
-	v1 "go.viam.com/api/service/worldstatestore/v1"
 	"go.viam.com/rdk/logging"
 	"go.viam.com/rdk/resource"
 	worldstatestore "go.viam.com/rdk/services/worldstatestore"
-	"go.viam.com/rdk/spatialmath"
 )
 
 var (
@@ -31,28 +28,57 @@ func init() {
 }
 
 type Config struct {
+	Meshes          []lib.MeshJSON `json:"meshes"`
+	CacheMaxBytes   int64          `json:"cache_max_bytes,omitempty"`   // Approximate memory budget of the parsed mesh cache (defaults to 256 MiB)
+	CacheMaxEntries int            `json:"cache_max_entries,omitempty"` // Maximum number of parsed meshes to cache (defaults to 64)
+	WatchIntervalMs int            `json:"watch_interval_ms,omitempty"` // Period between checks of watched mesh files (defaults to 1000)
+	WatchDebounceMs int            `json:"watch_debounce_ms,omitempty"` // Time a watched file must stay unchanged before reloading (defaults to 500)
 }
 
 func (cfg *Config) Validate(path string) ([]string, []string, error) {
-	return []string{}, nil, nil
-}
+	if cfg.CacheMaxBytes < 0 {
+		return nil, nil, resource.NewConfigValidationError(path, errors.New("cache_max_bytes must not be negative"))
+	}
 
-type worldStateService struct {
-	resource.AlwaysRebuild
+	if cfg.CacheMaxEntries < 0 {
+		return nil, nil, resource.NewConfigValidationError(path, errors.New("cache_max_entries must not be negative"))
+	}
 
-	name   resource.Name
-	logger logging.Logger
-	config *Config
+	if cfg.WatchIntervalMs < 0 {
+		return nil, nil, resource.NewConfigValidationError(path, errors.New("watch_interval_ms must not be negative"))
+	}
 
-	cancelCtx  context.Context
-	cancelFunc func()
+	if cfg.WatchDebounceMs < 0 {
+		return nil, nil, resource.NewConfigValidationError(path, errors.New("watch_debounce_ms must not be negative"))
+	}
+
+	for i, mesh := range cfg.Meshes {
+		meshPath := fmt.Sprintf("%s.meshes.%d", path, i)
+		if mesh.ModelPath == "" {
+			return nil, nil, resource.NewConfigValidationFieldRequiredError(meshPath, "model_path")
+		}
 
-	transforms      map[string]*commonPB.Transform
-	transformsMutex sync.RWMutex
+		if err := lib.ValidateMeshFile(mesh.ModelPath); err != nil {
+			return nil, nil, resource.NewConfigValidationError(meshPath, err)
+		}
 
-	changeStream chan worldstatestore.TransformChange
+		if _, err := lib.UUIDFromString(mesh.UUID); err != nil {
+			return nil, nil, resource.NewConfigValidationError(meshPath, fmt.Errorf("invalid uuid: %w", err))
+		}
 
-	workers sync.WaitGroup
+		if mesh.Scale < 0 {
+			return nil, nil, resource.NewConfigValidationError(meshPath, errors.New("scale must not be negative"))
+		}
+	}
+
+	return []string{}, nil, nil
+}
+
+// worldStateService keeps the mesh commands on top of the shapes store.
+type worldStateService struct {
+	*drawshapes.Shapes
+
+	logger logging.Logger
 }
 
 func newWorldStateService(
@@ -76,222 +102,220 @@ func NewWorldStateService(
 	conf *Config,
 	logger logging.Logger,
 ) (worldstatestore.Service, error) {
-	cancelCtx, cancelFunc := context.WithCancel(context.Background())
 	service := &worldStateService{
-		name:         name,
-		logger:       logger,
-		config:       conf,
-		cancelCtx:    cancelCtx,
-		cancelFunc:   cancelFunc,
-		transforms:   make(map[string]*commonPB.Transform),
-		changeStream: make(chan worldstatestore.TransformChange, 100000),
+		Shapes: drawshapes.New(name, drawshapes.Options{
+			CacheMaxBytes:   conf.CacheMaxBytes,
+			CacheMaxEntries: conf.CacheMaxEntries,
+			WatchInterval:   time.Duration(conf.WatchIntervalMs) * time.Millisecond,
+			WatchDebounce:   time.Duration(conf.WatchDebounceMs) * time.Millisecond,
+		}, logger),
+		logger: logger,
+	}
+
+	for _, toDraw := range conf.Meshes {
+		if _, err := service.Draw([]*lib.ShapeJSON{meshShape(&toDraw)}); err != nil {
+			service.Close(ctx)
+			return nil, fmt.Errorf("Failed to draw mesh %v: %w", toDraw.ModelPath, err)
+		}
 	}
 
 	return service, nil
 }
 
-func (service *worldStateService) Name() resource.Name {
-	return service.name
+// commands describes the DoCommand keys of the mesh service.
+var commands = &command.Set{
+	Model: WorldState,
+	Commands: []command.Command{
+		{
+			Name:        "draw",
+			Description: "Draws a mesh, or every mesh matched by a directory or glob model path",
+			Args:        command.OneOf(lib.MeshJSON{}, ""),
+			Response: map[string]any{
+				"uuid":         "",
+				"name":         "",
+				"meshes_added": 0,
+				"failed":       0,
+				"results":      []map[string]any{},
+			},
+			Parse: parseDraw,
+		},
+		{
+			Name:        "replace",
+			Description: "Replaces the mesh with the given uuid or name, keeping its UUID",
+			Args:        lib.MeshJSON{},
+			Response:    map[string]any{"uuid": "", "name": ""},
+			Parse: func(args any) error {
+				_, spec, err := parseReplace(args)
+				if err != nil {
+					return err
+				}
+				return lib.ValidateMeshFile(spec.ModelPath)
+			},
+		},
+		{
+			Name:        "remove",
+			Description: "Removes the meshes with any of the given UUIDs or names",
+			Args:        lib.Identifiers{},
+			Response:    map[string]any{"mesh_removed": 0},
+			Parse:       command.ParseIdentifiers,
+		},
+		{
+			Name:        "cache_stats",
+			Description: "Reports the usage of the parsed mesh cache",
+			Response:    map[string]any{"cache": lib.MeshCacheStats{}},
+		},
+		{
+			Name:        "clear",
+			Description: "Removes every mesh",
+			Response:    map[string]any{"mesh_removed": 0},
+		},
+		command.HideCommand,
+		command.ShowCommand,
+		command.StatsCommand,
+	},
 }
 
-func (service *worldStateService) ListUUIDs(ctx context.Context, extra map[string]any) ([][]byte, error) {
-	service.transformsMutex.RLock()
-	defer service.transformsMutex.RUnlock()
-
-	uuids := make([][]byte, 0, len(service.transforms))
-	for _, transform := range service.transforms {
-		parsedId, err := uuid.FromBytes(transform.Uuid)
-		if err != nil {
-			service.logger.Errorw("Failed to parse UUID", "error", err.Error())
-			return nil, err
-		}
-		uuids = append(uuids, parsedId[:])
+// parseMesh parses the argument of a draw command, which is a mesh object or just its model path.
+func parseMesh(args any) (*lib.MeshJSON, error) {
+	if path, ok := args.(string); ok {
+		args = map[string]any{"model_path": path}
 	}
 
-	return uuids, nil
+	return lib.ParseMesh(args)
 }
 
-func (service *worldStateService) GetTransform(ctx context.Context, id []byte, extra map[string]any) (*commonPB.Transform, error) {
-	service.transformsMutex.RLock()
-	defer service.transformsMutex.RUnlock()
-
-	uuidString, err := uuid.FromBytes(id)
+// parseDraw checks a draw command's mesh and the files it is loaded from.
+func parseDraw(args any) error {
+	spec, err := parseMesh(args)
 	if err != nil {
-		service.logger.Errorw("Failed to parse UUID", "error", err.Error())
-		return nil, err
+		return err
 	}
 
-	transform, ok := service.transforms[uuidString.String()]
-	if !ok {
-		return nil, fmt.Errorf("transform not found for UUID: %x", uuidString)
+	if lib.IsMeshPattern(spec.ModelPath) {
+		_, err := lib.ExpandMeshes(*spec)
+		return err
 	}
 
-	return transform, nil
-}
-
-func (service *worldStateService) StreamTransformChanges(ctx context.Context, extra map[string]any) (*worldstatestore.TransformChangeStream, error) {
-	subscriberChan := make(chan worldstatestore.TransformChange, 10)
-	go func() {
-		defer close(subscriberChan)
-		for {
-			select {
-			case change := <-service.changeStream:
-				select {
-				case subscriberChan <- change:
-				case <-ctx.Done():
-					return
-				}
-			case <-ctx.Done():
-				return
-			}
-		}
-	}()
-
-	return worldstatestore.NewTransformChangeStreamFromChannel(ctx, subscriberChan), nil
+	return lib.ValidateMeshFile(spec.ModelPath)
 }
 
-func (s *worldStateService) draw(meshPath string, color lib.Color) error {
-	// Read PLY from the file specified in the config (ModelPath)
-	file, err := os.Open(meshPath)
-	if err != nil {
-		return err
+func (service *worldStateService) DoCommand(ctx context.Context, cmd map[string]any) (map[string]any, error) {
+	if result, ok, err := commands.Handle(cmd); ok {
+		return result, err
 	}
-	defer file.Close()
 
-	mesh, err := spatialmath.NewMeshFromPLYFile(meshPath)
-	if err != nil {
-		s.logger.Errorw("Error creating mesh from PLY file:", err)
-		return err
+	if result, ok, err := service.Visibility(cmd, service.SetVisible); ok {
+		return result, err
 	}
 
-	s.logger.Infow("Successfully created mesh from PLY file:", meshPath)
+	if drawCmd, ok := cmd["draw"]; ok {
+		spec, err := parseMesh(drawCmd)
+		if err != nil {
+			return service.Fail(err)
+		}
 
-	geometry := mesh.ToProtobuf()
-	uuidBytes := lib.GenerateUUID()
-	if err != nil {
-		s.logger.Errorw("Failed to parse UUID", "error", err.Error())
-		return err
-	}
+		drawn, err := service.Draw([]*lib.ShapeJSON{meshShape(spec)})
+		if err != nil {
+			return service.Fail(err)
+		}
 
-	metadata, err := structpb.NewStruct(map[string]any{
-		"color": map[string]any{
-			"r": int(color.R),
-			"g": int(color.G),
-			"b": int(color.B),
-		},
-	})
-
-	transform := commonPB.Transform{
-		ReferenceFrame: fmt.Sprintf("mesh-%s", uuidBytes.String()),
-		PoseInObserverFrame: &commonPB.PoseInFrame{
-			ReferenceFrame: "world",
-			Pose: &commonPB.Pose{
-				X:     0,
-				Y:     0,
-				Z:     0,
-				OX:    0,
-				OY:    0,
-				OZ:    1,
-				Theta: 0,
-			},
-		},
-		Uuid:           uuidBytes.Bytes(),
-		PhysicalObject: geometry,
-		Metadata:       metadata,
+		// a directory or glob reports every matching file, including the ones that failed
+		if lib.IsMeshPattern(spec.ModelPath) {
+			return map[string]any{
+				"success":      true,
+				"meshes_added": len(drawn.Items),
+				"failed":       drawn.Failed(),
+				"results":      drawn.FileMaps(),
+			}, nil
+		}
+
+		return meshResponse(drawn.Items[0]), nil
 	}
 
-	s.transformsMutex.Lock()
-	defer s.transformsMutex.Unlock()
+	if replaceCmd, ok := cmd["replace"]; ok {
+		target, spec, err := parseReplace(replaceCmd)
+		if err != nil {
+			return service.Fail(err)
+		}
 
-	s.transforms[uuidBytes.String()] = &transform
-	s.emitChange(worldstatestore.TransformChange{
-		ChangeType: v1.TransformChangeType_TRANSFORM_CHANGE_TYPE_ADDED,
-		Transform:  &transform,
-	})
-	s.logger.Infow("Successfully added transform to world state store:", uuidBytes.String())
+		item, err := service.Replace(target, meshShape(spec))
+		if err != nil {
+			return service.Fail(err)
+		}
 
-	return nil
-}
+		return meshResponse(item), nil
+	}
 
-func (service *worldStateService) DoCommand(ctx context.Context, cmd map[string]any) (map[string]any, error) {
-	if drawCmd, ok := cmd["draw"]; ok {
-		meshPath := drawCmd.(map[string]any)["model_path"].(string)
-		colorJson := drawCmd.(map[string]any)["color"]
-		color, err := lib.ParseColor(colorJson, lib.Color{R: 0, G: 0, B: 255})
+	if removeCmd, ok := cmd["remove"]; ok {
+		ids, err := lib.ParseIdentifiers(removeCmd)
 		if err != nil {
-			return map[string]any{
-				"success": false,
-				"error":   err.Error(),
-			}, err
+			return service.Fail(err)
 		}
-		err = service.draw(meshPath, color)
+
+		removed, err := service.Remove(ids)
 		if err != nil {
-			return map[string]any{
-				"success": false,
-				"error":   err.Error(),
-			}, err
+			return service.Fail(err)
 		}
 
+		return map[string]any{
+			"success":      true,
+			"mesh_removed": len(removed),
+		}, nil
+	}
+
+	if _, ok := cmd["cache_stats"]; ok {
 		return map[string]any{
 			"success": true,
+			"cache":   service.CacheStats().ToMap(),
 		}, nil
 	}
 
 	if _, ok := cmd["clear"]; ok {
-		count, err := service.clear()
-		if err != nil {
-			return map[string]any{
-				"success": false,
-				"error":   err.Error(),
-			}, err
-		}
-
 		return map[string]any{
 			"success":      true,
-			"mesh_removed": count,
+			"mesh_removed": len(service.Clear()),
 		}, nil
 	}
 
-	return nil, fmt.Errorf("Unknown command")
-}
+	if _, ok := cmd["stats"]; ok {
+		return map[string]any{
+			"success": true,
+			"stats":   service.Stats().ToMap(),
+		}, nil
+	}
 
-func (service *worldStateService) Close(context.Context) error {
-	service.cancelFunc()
-	service.workers.Wait()
-	close(service.changeStream)
-	return nil
+	return nil, lib.Errorf(lib.ErrInvalidArgument, "Unknown command")
 }
 
-func (service *worldStateService) emitChange(change worldstatestore.TransformChange) {
-	select {
-	case service.changeStream <- change:
-		// Successfully sent
-	case <-service.cancelCtx.Done():
-		// Service is closing, don't block
-		service.logger.Debugw("Service closing, dropping change event")
-	}
+func meshShape(spec *lib.MeshJSON) *lib.ShapeJSON {
+	return &lib.ShapeJSON{Type: lib.ShapeMesh, Mesh: spec}
 }
 
-func (service *worldStateService) clear() (int, error) {
-	service.transformsMutex.Lock()
-	defer service.transformsMutex.Unlock()
+// parseReplace parses a replace command into the UUID or name of the mesh to replace and its new definition.
+func parseReplace(data any) (string, *lib.MeshJSON, error) {
+	spec, err := lib.ParseMesh(data)
+	if err != nil {
+		return "", nil, err
+	}
 
-	count := len(service.transforms)
-	for id := range service.transforms {
-		parsedId, err := uuid.Parse(id)
-		if err != nil {
-			service.logger.Errorw("Failed to parse UUID", "error", err.Error())
-			return 0, err
-		}
+	// a mesh selected by UUID may be renamed, one selected by name keeps it
+	target := spec.UUID
+	if target == "" {
+		target, spec.Name = spec.Name, ""
+	}
 
-		service.emitChange(worldstatestore.TransformChange{
-			ChangeType: v1.TransformChangeType_TRANSFORM_CHANGE_TYPE_REMOVED,
-			Transform: &commonPB.Transform{
-				Uuid: parsedId[:],
-			},
-		})
+	if target == "" {
+		return "", nil, lib.Errorf(lib.ErrInvalidArgument, "Missing required 'uuid' or 'name' field")
 	}
 
-	service.transforms = make(map[string]*commonPB.Transform)
-	return count, nil
+	spec.UUID = ""
+	return target, spec, nil
+}
+
+func meshResponse(item *drawshapes.Item) map[string]any {
+	return map[string]any{
+		"success": true,
+		"uuid":    item.UUID,
+		"name":    item.Name,
+	}
 }
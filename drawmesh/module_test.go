@@ -0,0 +1,198 @@
+package drawmesh
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/viam-labs/draw-tools/lib"
+	"github.com/viam-labs/draw-tools/lib/command/commandtest"
+	v1 "go.viam.com/api/service/worldstatestore/v1"
+	"go.viam.com/rdk/logging"
+	worldstatestore "go.viam.com/rdk/services/worldstatestore"
+	"go.viam.com/test"
+)
+
+const testPLY = `ply
+format ascii 1.0
+element vertex 3
+property float x
+property float y
+property float z
+element face 1
+property list uchar int vertex_indices
+end_header
+0 0 0
+1 0 0
+0 1 0
+3 0 1 2
+`
+
+func TestDrawPath(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "part.ply")
+	test.That(t, os.WriteFile(path, []byte(testPLY), 0o644), test.ShouldBeNil)
+
+	service, err := NewWorldStateService(ctx, nil, worldstatestore.Named("meshes"), &Config{}, logging.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	defer service.Close(ctx)
+
+	// a model path alone draws the mesh with the default fields
+	result, err := service.DoCommand(ctx, map[string]any{"draw": path})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, result["uuid"], test.ShouldNotBeEmpty)
+
+	result, err = service.DoCommand(ctx, map[string]any{"draw": map[string]any{"model_path": path, "pose": map[string]any{"x": "up"}}})
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, result["error"], test.ShouldEqual, "Failed to parse pose.x: Expected number, got string")
+}
+
+func TestDrawBinary(t *testing.T) {
+	ctx := context.Background()
+
+	// the colored triangle of TestDrawPath, as a little endian binary file
+	var data bytes.Buffer
+	data.WriteString(`ply
+format binary_little_endian 1.0
+element vertex 3
+property float x
+property float y
+property float z
+property uchar red
+property uchar green
+property uchar blue
+element face 1
+property list uchar int vertex_indices
+end_header
+`)
+	for _, vertex := range [][3]float32{{0, 0, 0}, {1, 0, 0}, {0, 1, 0}} {
+		binary.Write(&data, binary.LittleEndian, vertex)
+		data.Write([]byte{255, 0, 0})
+	}
+	data.WriteByte(3)
+	binary.Write(&data, binary.LittleEndian, []int32{0, 1, 2})
+
+	path := filepath.Join(t.TempDir(), "part.ply")
+	test.That(t, os.WriteFile(path, data.Bytes(), 0o644), test.ShouldBeNil)
+
+	service, err := NewWorldStateService(ctx, nil, worldstatestore.Named("meshes"), &Config{}, logging.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	defer service.Close(ctx)
+
+	for _, scale := range []float64{1, 2} {
+		result, err := service.DoCommand(ctx, map[string]any{"draw": map[string]any{"model_path": path, "scale": scale}})
+		test.That(t, err, test.ShouldBeNil)
+		id, err := uuid.Parse(result["uuid"].(string))
+		test.That(t, err, test.ShouldBeNil)
+
+		transform, err := service.GetTransform(ctx, id[:], nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, transform.Metadata.Fields["vertex_colors"].GetStringValue(), test.ShouldEqual, "/wAA//8AAP//AAD/")
+
+		mesh := transform.PhysicalObject.GetMesh()
+		test.That(t, string(mesh.GetMesh()), test.ShouldStartWith, "ply\nformat ascii 1.0\n")
+		test.That(t, string(mesh.GetMesh()), test.ShouldContainSubstring, fmt.Sprintf("\n%v 0 0 255 0 0\n", scale))
+	}
+}
+
+func TestReplaceAndRemove(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	path := filepath.Join(t.TempDir(), "part.ply")
+	test.That(t, os.WriteFile(path, []byte(testPLY), 0o644), test.ShouldBeNil)
+
+	service, err := NewWorldStateService(ctx, nil, worldstatestore.Named("meshes"), &Config{}, logging.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	defer service.Close(ctx)
+
+	stream, err := service.StreamTransformChanges(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+
+	// the draw response identifies the mesh, with a generated UUID unless one is given
+	result, err := service.DoCommand(ctx, map[string]any{"draw": map[string]any{"model_path": path, "name": "part"}})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, result["name"], test.ShouldEqual, "part")
+	partID, err := uuid.Parse(result["uuid"].(string))
+	test.That(t, err, test.ShouldBeNil)
+
+	change, err := stream.Next()
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, change.ChangeType, test.ShouldEqual, v1.TransformChangeType_TRANSFORM_CHANGE_TYPE_ADDED)
+	test.That(t, change.Transform.Uuid, test.ShouldResemble, partID[:])
+
+	givenID := uuid.New()
+	result, err = service.DoCommand(ctx, map[string]any{"draw": map[string]any{"model_path": path, "name": "spare", "uuid": givenID.String()}})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, result["uuid"], test.ShouldEqual, givenID.String())
+	test.That(t, result["name"], test.ShouldEqual, "spare")
+	_, err = stream.Next()
+	test.That(t, err, test.ShouldBeNil)
+
+	// replacing by name keeps the UUID and streams only the fields that changed
+	result, err = service.DoCommand(ctx, map[string]any{"replace": map[string]any{"model_path": path, "name": "part", "pose": map[string]any{"z": 100.0}}})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, result["uuid"], test.ShouldEqual, partID.String())
+	test.That(t, result["name"], test.ShouldEqual, "part")
+
+	change, err = stream.Next()
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, change.ChangeType, test.ShouldEqual, v1.TransformChangeType_TRANSFORM_CHANGE_TYPE_UPDATED)
+	test.That(t, change.Transform.Uuid, test.ShouldResemble, partID[:])
+	test.That(t, change.UpdatedFields, test.ShouldResemble, []string{"poseInObserverFrame"})
+
+	// replacing by UUID may rename the mesh
+	result, err = service.DoCommand(ctx, map[string]any{"replace": map[string]any{"model_path": path, "uuid": givenID.String(), "name": "backup"}})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, result["uuid"], test.ShouldEqual, givenID.String())
+	test.That(t, result["name"], test.ShouldEqual, "backup")
+
+	change, err = stream.Next()
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, change.ChangeType, test.ShouldEqual, v1.TransformChangeType_TRANSFORM_CHANGE_TYPE_UPDATED)
+	test.That(t, change.UpdatedFields, test.ShouldContain, "referenceFrame")
+
+	_, err = service.DoCommand(ctx, map[string]any{"replace": map[string]any{"model_path": path, "name": "missing"}})
+	test.That(t, errors.Is(err, lib.ErrNotFound), test.ShouldBeTrue)
+
+	// remove selects meshes by name or by UUID, and removes nothing if one of them is missing
+	_, err = service.DoCommand(ctx, map[string]any{"remove": map[string]any{"names": []any{"part", "missing"}}})
+	test.That(t, errors.Is(err, lib.ErrNotFound), test.ShouldBeTrue)
+
+	result, err = service.DoCommand(ctx, map[string]any{"remove": map[string]any{"names": []any{"part"}}})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, result["mesh_removed"], test.ShouldEqual, 1)
+
+	change, err = stream.Next()
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, change.ChangeType, test.ShouldEqual, v1.TransformChangeType_TRANSFORM_CHANGE_TYPE_REMOVED)
+	test.That(t, change.Transform.Uuid, test.ShouldResemble, partID[:])
+
+	result, err = service.DoCommand(ctx, map[string]any{"remove": map[string]any{"uuids": []any{givenID.String()}}})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, result["mesh_removed"], test.ShouldEqual, 1)
+
+	change, err = stream.Next()
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, change.ChangeType, test.ShouldEqual, v1.TransformChangeType_TRANSFORM_CHANGE_TYPE_REMOVED)
+	test.That(t, change.Transform.Uuid, test.ShouldResemble, givenID[:])
+
+	uuids, err := service.ListUUIDs(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, uuids, test.ShouldBeEmpty)
+}
+
+func FuzzDoCommand(f *testing.F) {
+	service, err := NewWorldStateService(context.Background(), nil, worldstatestore.Named("meshes"), &Config{}, logging.NewTestLogger(f))
+	test.That(f, err, test.ShouldBeNil)
+	f.Cleanup(func() { service.Close(context.Background()) })
+
+	commandtest.Fuzz(f, commands, service.DoCommand)
+}
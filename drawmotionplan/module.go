@@ -0,0 +1,522 @@
+package drawmotionplan
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/viam-labs/draw-tools/lib"
+	"github.com/viam-labs/draw-tools/lib/command"
+	"github.com/viam-labs/draw-tools/lib/store"
+
+	"github.com/golang/geo/r3"
+	"github.com/google/uuid"
+	commonPB "go.viam.com/api/common/v1"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/motionplan"
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/robot/framesystem"
+	"go.viam.com/rdk/services/motion"
+	worldstatestore "go.viam.com/rdk/services/worldstatestore"
+	"go.viam.com/rdk/spatialmath"
+)
+
+var (
+	WorldState = resource.NewModel("viam-viz", "draw-tools", "motion-plan-world-state")
+)
+
+const (
+	// DefaultLineWidthMm is the default width of the line through the plan's waypoints.
+	DefaultLineWidthMm = 6.0
+	// DefaultWaypointRadiusMm is the default radius of the sphere drawn at each waypoint.
+	DefaultWaypointRadiusMm = 10.0
+	// DefaultSweptSamples is the default number of trajectory steps drawn when drawing swept geometry.
+	DefaultSweptSamples = 10
+	// DefaultSweptOpacity is the default opacity of the swept geometry.
+	DefaultSweptOpacity = 0.25
+)
+
+func init() {
+	resource.RegisterService(worldstatestore.API, WorldState,
+		resource.Registration[worldstatestore.Service, *Config]{
+			Constructor: newWorldStateService,
+		},
+	)
+}
+
+type Config struct {
+	MotionService    string     `json:"motion_service"`               // Motion service to read plans from (required)
+	Component        string     `json:"component,omitempty"`          // Component whose plan is drawn when a command names none
+	LineWidthMm      float64    `json:"line_width_mm,omitempty"`      // Width of the line through the waypoints (defaults to 6)
+	LineColor        *lib.Color `json:"line_color,omitempty"`         // Color of the line through the waypoints (defaults to cyan)
+	WaypointRadiusMm float64    `json:"waypoint_radius_mm,omitempty"` // Radius of the sphere at each waypoint (defaults to 10)
+	Axes             *bool      `json:"axes,omitempty"`               // Draw an axes triad at each waypoint (defaults to true)
+	Swept            bool       `json:"swept,omitempty"`              // Draw the arm's link geometries along the trajectory (defaults to false)
+	SweptSamples     int        `json:"swept_samples,omitempty"`      // Trajectory steps drawn when sweeping (defaults to 10)
+	SweptOpacity     float64    `json:"swept_opacity,omitempty"`      // Opacity of the swept geometry (defaults to 0.25)
+}
+
+func (cfg *Config) Validate(path string) ([]string, []string, error) {
+	if cfg.MotionService == "" {
+		return nil, nil, resource.NewConfigValidationFieldRequiredError(path, "motion_service")
+	}
+
+	if cfg.LineWidthMm < 0 {
+		return nil, nil, resource.NewConfigValidationError(path, errors.New("line_width_mm must not be negative"))
+	}
+
+	if cfg.WaypointRadiusMm < 0 {
+		return nil, nil, resource.NewConfigValidationError(path, errors.New("waypoint_radius_mm must not be negative"))
+	}
+
+	if cfg.SweptSamples < 0 {
+		return nil, nil, resource.NewConfigValidationError(path, errors.New("swept_samples must not be negative"))
+	}
+
+	if cfg.SweptOpacity < 0 || cfg.SweptOpacity > 1 {
+		return nil, nil, resource.NewConfigValidationError(path, errors.New("swept_opacity must be between 0 and 1"))
+	}
+
+	// the frame system is always available to modules
+	return []string{cfg.MotionService}, nil, nil
+}
+
+// drawnPlan describes the plan currently drawn.
+type drawnPlan struct {
+	id          motion.PlanID
+	executionID motion.ExecutionID
+	component   string
+	state       string
+	waypoints   int
+	swept       int
+}
+
+type worldStateService struct {
+	*store.Service
+
+	logger logging.Logger
+	config *Config
+
+	motion      motion.Service
+	frameSystem framesystem.Service
+	id          lib.UUID
+
+	plan      *drawnPlan
+	planMutex sync.RWMutex
+
+	// serializes plan redraws
+	drawMutex sync.Mutex
+}
+
+func newWorldStateService(
+	ctx context.Context,
+	deps resource.Dependencies,
+	rawConf resource.Config,
+	logger logging.Logger,
+) (worldstatestore.Service, error) {
+	conf, err := resource.NativeConfig[*Config](rawConf)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewWorldStateService(ctx, deps, rawConf.ResourceName(), conf, logger)
+}
+
+func NewWorldStateService(
+	ctx context.Context,
+	deps resource.Dependencies,
+	name resource.Name,
+	conf *Config,
+	logger logging.Logger,
+) (worldstatestore.Service, error) {
+	motionService, err := motion.FromDependencies(deps, conf.MotionService)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to get motion service %s: %w", conf.MotionService, err)
+	}
+
+	// the frame system is only needed to sweep the link geometries along the trajectory
+	var frameSystem framesystem.Service
+	if conf.Swept {
+		frameSystem, err = framesystem.FromDependencies(deps)
+		if err != nil {
+			return nil, fmt.Errorf("Unable to get frame system: %w", err)
+		}
+	}
+
+	service := &worldStateService{
+		Service:     store.NewService(name, logger),
+		logger:      logger,
+		config:      conf,
+		motion:      motionService,
+		frameSystem: frameSystem,
+		id:          lib.GenerateUUID(),
+	}
+
+	return service, nil
+}
+
+// planRequestJSON is the argument of a draw_plan command.
+type planRequestJSON struct {
+	Component   string `json:"component,omitempty"`    // Component whose plan is drawn (optional, defaults to the configured component)
+	ExecutionID string `json:"execution_id,omitempty"` // Execution whose plan is drawn (optional, defaults to the most recent)
+}
+
+// parsePlanRequest reads the component and optional execution ID of a draw_plan command. The command value is either
+// an object with "component" and "execution_id" fields or any other value to draw the configured component's latest
+// plan.
+func (s *worldStateService) parsePlanRequest(value any) (motion.PlanHistoryReq, error) {
+	req := motion.PlanHistoryReq{ComponentName: s.config.Component, LastPlanOnly: true}
+
+	request := planRequestJSON{Component: s.config.Component}
+	if _, ok := value.(map[string]any); ok {
+		if err := lib.Decode(value, &request); err != nil {
+			return req, err
+		}
+	}
+	req.ComponentName = request.Component
+
+	if request.ExecutionID != "" {
+		id, err := uuid.Parse(request.ExecutionID)
+		if err != nil {
+			return req, lib.FieldErrorf("execution_id", "Invalid execution_id: %w", err)
+		}
+		req.ExecutionID = id
+	}
+
+	if req.ComponentName == "" {
+		return req, lib.FieldErrorf("component", "component is required when none is configured")
+	}
+
+	return req, nil
+}
+
+// drawPlan fetches the most recent plan matching the request from the motion service and replaces the drawing with
+// it: a line through the component's waypoints, a sphere and optionally an axes triad at each waypoint colored by
+// index from blue (first) to red (last), and optionally the link geometries swept along the trajectory.
+func (s *worldStateService) drawPlan(ctx context.Context, req motion.PlanHistoryReq) (*drawnPlan, error) {
+	s.drawMutex.Lock()
+	defer s.drawMutex.Unlock()
+
+	history, err := s.motion.PlanHistory(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to get plan history for %s: %w", req.ComponentName, err)
+	}
+	if len(history) == 0 {
+		return nil, lib.Errorf(lib.ErrNotFound, "No plan found for %s", req.ComponentName)
+	}
+
+	plan := history[0]
+	drawn := &drawnPlan{
+		id:          plan.Plan.ID,
+		executionID: plan.Plan.ExecutionID,
+		component:   req.ComponentName,
+	}
+	if len(plan.StatusHistory) > 0 {
+		drawn.state = plan.StatusHistory[len(plan.StatusHistory)-1].State.String()
+	}
+
+	next := []*commonPB.Transform{}
+	add := func(transform *commonPB.Transform) error {
+		next = append(next, transform)
+		return nil
+	}
+
+	waypoints, err := s.buildWaypoints(plan.Plan.Path(), req.ComponentName, add)
+	if err != nil {
+		return nil, err
+	}
+	drawn.waypoints = waypoints
+
+	if s.config.Swept {
+		swept, err := s.buildSwept(ctx, plan.Plan.Trajectory(), req.ComponentName, add)
+		if err != nil {
+			return nil, err
+		}
+		drawn.swept = swept
+	}
+
+	s.planMutex.Lock()
+	defer s.planMutex.Unlock()
+
+	if err := s.Reconcile(next...); err != nil {
+		return nil, err
+	}
+
+	s.plan = drawn
+	return drawn, nil
+}
+
+// buildWaypoints creates the line, spheres and axes drawing the component's waypoints along a plan's path, in the
+// frame the path poses are expressed in. Returns the number of waypoints.
+func (s *worldStateService) buildWaypoints(path motionplan.Path, component string, add func(*commonPB.Transform) error) (int, error) {
+	if len(path) == 0 {
+		return 0, nil
+	}
+
+	parent := referenceframe.World
+	if first, ok := path[0][component]; ok && first.Parent() != "" {
+		parent = first.Parent()
+	}
+
+	poses, err := path.GetFramePoses(component)
+	if err != nil {
+		return 0, err
+	}
+
+	points := make([]r3.Vector, len(poses))
+	for i, pose := range poses {
+		points[i] = pose.Point()
+	}
+
+	if lib.PolylineLength(points) > 0 {
+		width := s.config.LineWidthMm
+		if width <= 0 {
+			width = DefaultLineWidthMm
+		}
+
+		lineID := lib.DeriveUUID(s.id, "line")
+		line, err := lib.CreatePolyline(points, width, component+"-plan", lineID.Bytes(), s.config.LineColor, parent)
+		if err != nil {
+			return 0, err
+		}
+		if err := add(line); err != nil {
+			return 0, err
+		}
+	}
+
+	radius := s.config.WaypointRadiusMm
+	if radius <= 0 {
+		radius = DefaultWaypointRadiusMm
+	}
+
+	for i, pose := range poses {
+		name := fmt.Sprintf("%s-waypoint-%d", component, i)
+		color := waypointColor(i, len(poses))
+
+		sphere, err := spatialmath.NewSphere(spatialmath.NewZeroPose(), radius, name)
+		if err != nil {
+			return 0, err
+		}
+
+		sphereID := lib.DeriveUUID(s.id, fmt.Sprintf("waypoint:%d", i))
+		marker, err := lib.CreatePrimitive(
+			lib.PrimitiveSphere,
+			sphere,
+			spatialmath.PoseToProtobuf(spatialmath.NewPoseFromPoint(pose.Point())),
+			name,
+			sphereID.Bytes(),
+			&color,
+			parent,
+			1,
+			false,
+		)
+		if err != nil {
+			return 0, err
+		}
+		if err := add(marker); err != nil {
+			return 0, err
+		}
+
+		if s.config.Axes != nil && !*s.config.Axes {
+			continue
+		}
+
+		axesID := lib.DeriveUUID(s.id, fmt.Sprintf("axes:%d", i))
+		axes, err := lib.CreateAxes(spatialmath.PoseToProtobuf(pose), name, axesID.Bytes(), parent)
+		if err != nil {
+			return 0, err
+		}
+		for _, arrow := range axes {
+			if err := add(arrow); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	return len(poses), nil
+}
+
+// buildSwept creates the component's link geometries at evenly spaced steps of a plan's trajectory, in the world
+// frame and colored by step like the waypoints. Frames without inputs in a step keep their zero inputs. Returns the
+// number of steps drawn.
+func (s *worldStateService) buildSwept(ctx context.Context, trajectory motionplan.Trajectory, component string, add func(*commonPB.Transform) error) (int, error) {
+	if len(trajectory) == 0 {
+		return 0, nil
+	}
+
+	fs, err := framesystem.NewFromService(ctx, s.frameSystem, nil)
+	if err != nil {
+		return 0, fmt.Errorf("Unable to build frame system: %w", err)
+	}
+
+	samples := s.config.SweptSamples
+	if samples <= 0 {
+		samples = DefaultSweptSamples
+	}
+
+	opacity := s.config.SweptOpacity
+	if opacity == 0 {
+		opacity = DefaultSweptOpacity
+	}
+
+	steps := sampleSteps(len(trajectory), samples)
+	for _, step := range steps {
+		inputs := referenceframe.NewZeroInputs(fs)
+		for name, value := range trajectory[step] {
+			inputs[name] = value
+		}
+
+		geometries, err := referenceframe.FrameSystemGeometries(fs, inputs)
+		if geometries[component] == nil {
+			if err != nil {
+				return 0, fmt.Errorf("Unable to get geometries of %s: %w", component, err)
+			}
+			return 0, fmt.Errorf("%s has no geometries to sweep", component)
+		}
+
+		color := waypointColor(step, len(trajectory))
+		for j, geometry := range geometries[component].Geometries() {
+			name := fmt.Sprintf("%s-swept-%d-%d", component, step, j)
+			geometryID := lib.DeriveUUID(s.id, fmt.Sprintf("swept:%d:%d", step, j))
+			transform, err := lib.CreatePrimitive("swept", geometry, nil, name, geometryID.Bytes(), &color, geometries[component].Parent(), opacity, false)
+			if err != nil {
+				return 0, err
+			}
+			if err := add(transform); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	return len(steps), nil
+}
+
+// sampleSteps picks up to samples evenly spaced step indexes out of count, always including the first and last.
+func sampleSteps(count, samples int) []int {
+	if count <= samples {
+		steps := make([]int, count)
+		for i := range steps {
+			steps[i] = i
+		}
+		return steps
+	}
+
+	if samples == 1 {
+		return []int{count - 1}
+	}
+
+	steps := make([]int, samples)
+	for i := range steps {
+		steps[i] = i * (count - 1) / (samples - 1)
+	}
+	return steps
+}
+
+// waypointColor colors the waypoint at index out of count from blue (first) to red (last).
+func waypointColor(index, count int) lib.Color {
+	t := 0.0
+	if count > 1 {
+		t = float64(index) / float64(count-1)
+	}
+
+	c := lib.Colormap(t)
+	return lib.Color{R: c.R, G: c.G, B: c.B}
+}
+
+// commands describes the DoCommand keys of the motion plan service. draw_plan is parsed against the configured
+// component, so the set belongs to the service rather than the package.
+func (service *worldStateService) commands() *command.Set {
+	return &command.Set{
+		Model: WorldState,
+		Commands: []command.Command{
+			{
+				Name:        "draw_plan",
+				Description: "Draws the latest plan for a component, or the plan of an earlier execution",
+				Args:        planRequestJSON{},
+				Response: map[string]any{
+					"plan_id":      "",
+					"execution_id": "",
+					"component":    "",
+					"state":        "",
+					"waypoints":    0,
+					"swept_steps":  0,
+				},
+				Parse: func(args any) error {
+					_, err := service.parsePlanRequest(args)
+					return err
+				},
+			},
+			{
+				Name:        "clear",
+				Description: "Removes the drawn plan",
+				Response:    map[string]any{"transforms_removed": 0},
+			},
+			command.HideCommand,
+			command.ShowCommand,
+			command.StatsCommand,
+		},
+	}
+}
+
+func (service *worldStateService) DoCommand(ctx context.Context, cmd map[string]any) (map[string]any, error) {
+	if result, ok, err := service.commands().Handle(cmd); ok {
+		return result, err
+	}
+
+	if result, ok, err := service.Visibility(cmd, service.SetVisible); ok {
+		return result, err
+	}
+
+	if value, ok := cmd["draw_plan"]; ok {
+		req, err := service.parsePlanRequest(value)
+		if err == nil {
+			var plan *drawnPlan
+			plan, err = service.drawPlan(ctx, req)
+			if err == nil {
+				return map[string]any{
+					"success":      true,
+					"plan_id":      plan.id.String(),
+					"execution_id": plan.executionID.String(),
+					"component":    plan.component,
+					"state":        plan.state,
+					"waypoints":    plan.waypoints,
+					"swept_steps":  plan.swept,
+				}, nil
+			}
+		}
+
+		return service.Fail(err)
+	}
+
+	if _, ok := cmd["clear"]; ok {
+		count := service.clear()
+		return map[string]any{
+			"success":            true,
+			"transforms_removed": count,
+		}, nil
+	}
+
+	if _, ok := cmd["stats"]; ok {
+		return map[string]any{
+			"success": true,
+			"stats":   service.Stats().ToMap(),
+		}, nil
+	}
+
+	return nil, lib.Errorf(lib.ErrInvalidArgument, "Unknown command")
+}
+
+// clear removes the drawn plan.
+func (service *worldStateService) clear() int {
+	service.drawMutex.Lock()
+	defer service.drawMutex.Unlock()
+
+	service.planMutex.Lock()
+	defer service.planMutex.Unlock()
+
+	count := service.Clear()
+	service.plan = nil
+	return count
+}
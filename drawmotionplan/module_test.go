@@ -0,0 +1,256 @@
+package drawmotionplan
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/geo/r3"
+	"github.com/google/uuid"
+	"github.com/viam-labs/draw-tools/lib/command/commandtest"
+	v1 "go.viam.com/api/service/worldstatestore/v1"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/motionplan"
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/services/motion"
+	worldstatestore "go.viam.com/rdk/services/worldstatestore"
+	"go.viam.com/rdk/spatialmath"
+	injectmotion "go.viam.com/rdk/testutils/inject/motion"
+	"go.viam.com/test"
+)
+
+// newPlan creates a plan moving the arm through the given points in the world frame.
+func newPlan(executionID uuid.UUID, points ...r3.Vector) motion.PlanWithStatus {
+	path := make(motionplan.Path, len(points))
+	for i, point := range points {
+		path[i] = referenceframe.FrameSystemPoses{
+			"arm": referenceframe.NewPoseInFrame(referenceframe.World, spatialmath.NewPoseFromPoint(point)),
+		}
+	}
+
+	return motion.PlanWithStatus{
+		Plan: motion.PlanWithMetadata{
+			ID:            uuid.New(),
+			ComponentName: "arm",
+			ExecutionID:   executionID,
+			Plan:          motionplan.NewSimplePlan(path, nil),
+		},
+		StatusHistory: []motion.PlanStatus{{State: motion.PlanStateSucceeded, Timestamp: time.Now()}},
+	}
+}
+
+func TestDrawPlan(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	executionID := uuid.New()
+	plans := []motion.PlanWithStatus{newPlan(executionID, r3.Vector{}, r3.Vector{X: 100}, r3.Vector{X: 100, Y: 100})}
+
+	var requested motion.PlanHistoryReq
+	motionService := injectmotion.NewMotionService("motion")
+	motionService.PlanHistoryFunc = func(ctx context.Context, req motion.PlanHistoryReq) ([]motion.PlanWithStatus, error) {
+		requested = req
+		return plans, nil
+	}
+
+	deps := resource.Dependencies{motion.Named("motion"): motionService}
+	conf := &Config{MotionService: "motion", Component: "arm"}
+
+	service, err := NewWorldStateService(ctx, deps, worldstatestore.Named("plan"), conf, logging.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	defer service.Close(ctx)
+
+	stream, err := service.StreamTransformChanges(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+
+	result, err := service.DoCommand(ctx, map[string]any{"draw_plan": map[string]any{"execution_id": executionID.String()}})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, result["waypoints"], test.ShouldEqual, 3)
+	test.That(t, result["execution_id"], test.ShouldEqual, executionID.String())
+	test.That(t, result["state"], test.ShouldEqual, "succeeded")
+	test.That(t, requested.ComponentName, test.ShouldEqual, "arm")
+	test.That(t, requested.ExecutionID, test.ShouldEqual, executionID)
+	test.That(t, requested.LastPlanOnly, test.ShouldBeTrue)
+
+	// a line, and a sphere and axes triad at each of the three waypoints
+	frames := map[string][]byte{}
+	for len(frames) < 1+3+3*3 {
+		change, err := stream.Next()
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, change.ChangeType, test.ShouldEqual, v1.TransformChangeType_TRANSFORM_CHANGE_TYPE_ADDED)
+		frames[change.Transform.ReferenceFrame] = change.Transform.Uuid
+	}
+
+	for _, name := range []string{"arm-plan", "arm-waypoint-0", "arm-waypoint-2", "arm-waypoint-1-x", "arm-waypoint-2-z"} {
+		test.That(t, frames, test.ShouldContainKey, name)
+	}
+
+	// waypoints are colored from blue to red
+	first, err := service.GetTransform(ctx, frames["arm-waypoint-0"], nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, first.Metadata.AsMap()["color"], test.ShouldResemble, map[string]any{"r": 0.0, "g": 0.0, "b": 255.0})
+
+	last, err := service.GetTransform(ctx, frames["arm-waypoint-2"], nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, last.Metadata.AsMap()["color"], test.ShouldResemble, map[string]any{"r": 255.0, "g": 0.0, "b": 0.0})
+	test.That(t, last.PoseInObserverFrame.ReferenceFrame, test.ShouldEqual, referenceframe.World)
+	test.That(t, last.PoseInObserverFrame.Pose.Y, test.ShouldEqual, 100.0)
+
+	// drawing a shorter plan updates the line and removes the extra waypoint
+	plans = []motion.PlanWithStatus{newPlan(uuid.New(), r3.Vector{}, r3.Vector{Z: 100})}
+	result, err = service.DoCommand(ctx, map[string]any{"draw_plan": true})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, result["waypoints"], test.ShouldEqual, 2)
+	test.That(t, requested.ExecutionID, test.ShouldEqual, uuid.Nil)
+
+	uuids, err := service.ListUUIDs(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, uuids, test.ShouldHaveLength, 1+2+2*3)
+
+	line, err := service.GetTransform(ctx, frames["arm-plan"], nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, line.PhysicalObject, test.ShouldNotBeNil)
+
+	_, err = service.GetTransform(ctx, frames["arm-waypoint-2"], nil)
+	test.That(t, err, test.ShouldNotBeNil)
+
+	result, err = service.DoCommand(ctx, map[string]any{"clear": true})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, result["transforms_removed"], test.ShouldEqual, 1+2+2*3)
+
+	uuids, err = service.ListUUIDs(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, uuids, test.ShouldBeEmpty)
+}
+
+func TestDrawPlanWithoutAxes(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	motionService := injectmotion.NewMotionService("motion")
+	motionService.PlanHistoryFunc = func(ctx context.Context, req motion.PlanHistoryReq) ([]motion.PlanWithStatus, error) {
+		return []motion.PlanWithStatus{newPlan(uuid.New(), r3.Vector{}, r3.Vector{X: 100}, r3.Vector{X: 200})}, nil
+	}
+
+	axes := false
+	deps := resource.Dependencies{motion.Named("motion"): motionService}
+	conf := &Config{MotionService: "motion", Axes: &axes}
+
+	service, err := NewWorldStateService(ctx, deps, worldstatestore.Named("plan"), conf, logging.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	defer service.Close(ctx)
+
+	// no component is configured, so the command must name one
+	_, err = service.DoCommand(ctx, map[string]any{"draw_plan": true})
+	test.That(t, err, test.ShouldNotBeNil)
+
+	_, err = service.DoCommand(ctx, map[string]any{"draw_plan": map[string]any{"component": "arm"}})
+	test.That(t, err, test.ShouldBeNil)
+
+	// only the line and the waypoint spheres are drawn
+	uuids, err := service.ListUUIDs(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, uuids, test.ShouldHaveLength, 1+3)
+}
+
+func TestDrawPlanErrors(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var historyErr error
+	motionService := injectmotion.NewMotionService("motion")
+	motionService.PlanHistoryFunc = func(ctx context.Context, req motion.PlanHistoryReq) ([]motion.PlanWithStatus, error) {
+		return nil, historyErr
+	}
+
+	deps := resource.Dependencies{motion.Named("motion"): motionService}
+	conf := &Config{MotionService: "motion", Component: "arm"}
+
+	service, err := NewWorldStateService(ctx, deps, worldstatestore.Named("plan"), conf, logging.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	defer service.Close(ctx)
+
+	result, err := service.DoCommand(ctx, map[string]any{"draw_plan": true})
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, result["success"], test.ShouldEqual, false)
+
+	historyErr = errors.New("no plans for execution")
+	_, err = service.DoCommand(ctx, map[string]any{"draw_plan": true})
+	test.That(t, err, test.ShouldNotBeNil)
+
+	_, err = service.DoCommand(ctx, map[string]any{"draw_plan": map[string]any{"execution_id": "not-a-uuid"}})
+	test.That(t, err, test.ShouldNotBeNil)
+
+	_, err = service.DoCommand(ctx, map[string]any{"unknown": true})
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+func TestValidateCommand(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	requests := 0
+	motionService := injectmotion.NewMotionService("motion")
+	motionService.PlanHistoryFunc = func(ctx context.Context, req motion.PlanHistoryReq) ([]motion.PlanWithStatus, error) {
+		requests++
+		return nil, nil
+	}
+
+	deps := resource.Dependencies{motion.Named("motion"): motionService}
+	conf := &Config{MotionService: "motion", Component: "arm"}
+
+	service, err := NewWorldStateService(ctx, deps, worldstatestore.Named("plan"), conf, logging.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	defer service.Close(ctx)
+
+	result, err := service.DoCommand(ctx, map[string]any{"validate": map[string]any{"draw_plan": map[string]any{"execution_id": "not-a-uuid"}}})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, result["valid"], test.ShouldBeFalse)
+
+	// the configured component fills in for a missing one, without asking the motion service for a plan
+	result, err = service.DoCommand(ctx, map[string]any{"validate": map[string]any{"draw_plan": true, "clear": true}})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, result["valid"], test.ShouldBeTrue)
+	test.That(t, requests, test.ShouldEqual, 0)
+
+	result, err = service.DoCommand(ctx, map[string]any{"describe": "draw_plan"})
+	test.That(t, err, test.ShouldBeNil)
+	described := result["commands"].([]any)[0].(map[string]any)
+	test.That(t, described["args"].(map[string]any)["properties"], test.ShouldContainKey, "execution_id")
+}
+
+func FuzzDoCommand(f *testing.F) {
+	motionService := injectmotion.NewMotionService("motion")
+	motionService.PlanHistoryFunc = func(ctx context.Context, req motion.PlanHistoryReq) ([]motion.PlanWithStatus, error) {
+		return []motion.PlanWithStatus{newPlan(uuid.New(), r3.Vector{}, r3.Vector{Z: 100})}, nil
+	}
+
+	deps := resource.Dependencies{motion.Named("motion"): motionService}
+	conf := &Config{MotionService: "motion", Component: "arm"}
+
+	service, err := NewWorldStateService(context.Background(), deps, worldstatestore.Named("plan"), conf, logging.NewTestLogger(f))
+	test.That(f, err, test.ShouldBeNil)
+	f.Cleanup(func() { service.Close(context.Background()) })
+
+	commandtest.Fuzz(f, service.(*worldStateService).commands(), service.DoCommand)
+}
+
+func TestValidate(t *testing.T) {
+	deps, _, err := (&Config{MotionService: "builtin"}).Validate("services.0")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, deps, test.ShouldResemble, []string{"builtin"})
+
+	_, _, err = (&Config{}).Validate("services.0")
+	test.That(t, err, test.ShouldNotBeNil)
+
+	_, _, err = (&Config{MotionService: "builtin", SweptOpacity: 2}).Validate("services.0")
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+func TestSampleSteps(t *testing.T) {
+	test.That(t, sampleSteps(3, 10), test.ShouldResemble, []int{0, 1, 2})
+	test.That(t, sampleSteps(11, 3), test.ShouldResemble, []int{0, 5, 10})
+	test.That(t, sampleSteps(5, 1), test.ShouldResemble, []int{4})
+}
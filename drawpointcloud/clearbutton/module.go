@@ -0,0 +1,118 @@
+package clearpointcloudbutton
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/viam-labs/draw-tools/client"
+	"github.com/viam-labs/draw-tools/lib/command"
+
+	button "go.viam.com/rdk/components/button"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+)
+
+var (
+	ClearPointCloud = resource.NewModel("viam-viz", "draw-tools", "clear-pointcloud-button")
+)
+
+func init() {
+	resource.RegisterComponent(button.API, ClearPointCloud,
+		resource.Registration[button.Button, *Config]{
+			Constructor: newClearPointCloudButton,
+		},
+	)
+
+}
+
+type Config struct {
+	ServiceName string `json:"service_name"`
+}
+
+func (config *Config) Validate(path string) ([]string, []string, error) {
+	if config.ServiceName == "" {
+		return nil, nil, resource.NewConfigValidationFieldRequiredError(path, "service_name")
+	}
+
+	return nil, nil, nil
+}
+
+type clearPointCloudButton struct {
+	resource.AlwaysRebuild
+
+	name   resource.Name
+	logger logging.Logger
+	config *Config
+
+	cancelCtx  context.Context
+	cancelFunc func()
+
+	client *client.Client
+}
+
+func newClearPointCloudButton(
+	ctx context.Context,
+	deps resource.Dependencies,
+	rawConf resource.Config,
+	logger logging.Logger,
+) (button.Button, error) {
+	conf, err := resource.NativeConfig[*Config](rawConf)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewClearPointCloudButton(ctx, deps, rawConf.ResourceName(), conf, logger)
+}
+
+func NewClearPointCloudButton(
+	ctx context.Context,
+	deps resource.Dependencies,
+	name resource.Name,
+	conf *Config,
+	logger logging.Logger,
+) (button.Button, error) {
+	cancelCtx, cancelFunc := context.WithCancel(context.Background())
+	component := &clearPointCloudButton{
+		name:       name,
+		logger:     logger,
+		config:     conf,
+		cancelCtx:  cancelCtx,
+		cancelFunc: cancelFunc,
+	}
+
+	drawClient, err := client.FromDependencies(deps, conf.ServiceName)
+	if err != nil {
+		return nil, err
+	}
+
+	component.client = drawClient
+	return component, nil
+}
+
+func (s *clearPointCloudButton) Name() resource.Name {
+	return s.name
+}
+
+func (s *clearPointCloudButton) Push(ctx context.Context, extra map[string]interface{}) error {
+	if _, err := s.client.Clear(ctx); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// commands describes the button, which has no commands of its own besides the built-in ones.
+var commands = &command.Set{Model: ClearPointCloud}
+
+func (s *clearPointCloudButton) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	if result, ok, err := commands.Handle(cmd); ok {
+		return result, err
+	}
+
+	return nil, fmt.Errorf("Not implemented, use service DoCommand instead")
+}
+
+func (s *clearPointCloudButton) Close(context.Context) error {
+	s.cancelFunc()
+	return nil
+}
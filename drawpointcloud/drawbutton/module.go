@@ -0,0 +1,138 @@
+package drawpointcloudbutton
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/viam-labs/draw-tools/client"
+	"github.com/viam-labs/draw-tools/lib"
+	"github.com/viam-labs/draw-tools/lib/command"
+	button "go.viam.com/rdk/components/button"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+)
+
+var (
+	DrawPointCloud = resource.NewModel("viam-viz", "draw-tools", "draw-pointcloud-button")
+)
+
+func init() {
+	resource.RegisterComponent(button.API, DrawPointCloud,
+		resource.Registration[button.Button, *Config]{
+			Constructor: newDrawPointCloudButton,
+		},
+	)
+}
+
+type Config struct {
+	ServiceName string     `json:"service_name"`
+	ModelPath   string     `json:"model_path"`
+	Color       *lib.Color `json:"color,omitempty"`
+	ColorBy     string     `json:"color_by"`
+	VoxelSizeMm float64    `json:"voxel_size_mm"`
+	PointSize   float64    `json:"point_size"`
+}
+
+func (config *Config) Validate(path string) ([]string, []string, error) {
+	if config.ServiceName == "" {
+		return nil, nil, resource.NewConfigValidationFieldRequiredError(path, "service_name")
+	}
+
+	if config.ModelPath == "" {
+		return nil, nil, errors.New("model_path is required")
+	}
+
+	if err := lib.ValidateColorBy(config.ColorBy); err != nil {
+		return nil, nil, resource.NewConfigValidationError(path, err)
+	}
+
+	return nil, nil, nil
+}
+
+type drawPointCloudButton struct {
+	resource.AlwaysRebuild
+
+	name   resource.Name
+	logger logging.Logger
+	config *Config
+
+	cancelCtx  context.Context
+	cancelFunc func()
+
+	client *client.Client
+}
+
+func newDrawPointCloudButton(
+	ctx context.Context,
+	deps resource.Dependencies,
+	rawConf resource.Config,
+	logger logging.Logger,
+) (button.Button, error) {
+	conf, err := resource.NativeConfig[*Config](rawConf)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewDrawPointCloudButton(ctx, deps, rawConf.ResourceName(), conf, logger)
+}
+
+func NewDrawPointCloudButton(
+	ctx context.Context,
+	deps resource.Dependencies,
+	name resource.Name,
+	conf *Config,
+	logger logging.Logger,
+) (button.Button, error) {
+	cancelCtx, cancelFunc := context.WithCancel(context.Background())
+	drawClient, err := client.FromDependencies(deps, conf.ServiceName)
+	if err != nil {
+		return nil, err
+	}
+
+	component := &drawPointCloudButton{
+		name:       name,
+		logger:     logger,
+		config:     conf,
+		cancelCtx:  cancelCtx,
+		cancelFunc: cancelFunc,
+		client:     drawClient,
+	}
+
+	return component, nil
+}
+
+func (s *drawPointCloudButton) Name() resource.Name {
+	return s.name
+}
+
+func (s *drawPointCloudButton) Push(ctx context.Context, extra map[string]interface{}) error {
+	_, err := s.client.DrawPointCloud(ctx, lib.PointCloudJSON{
+		ModelPath:   s.config.ModelPath,
+		Color:       s.config.Color,
+		ColorBy:     s.config.ColorBy,
+		VoxelSizeMm: s.config.VoxelSizeMm,
+		PointSize:   s.config.PointSize,
+	})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// commands describes the button, which has no commands of its own besides the built-in ones.
+var commands = &command.Set{Model: DrawPointCloud}
+
+func (s *drawPointCloudButton) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	if result, ok, err := commands.Handle(cmd); ok {
+		return result, err
+	}
+
+	return nil, fmt.Errorf("Not implemented, use service DoCommand instead")
+}
+
+func (s *drawPointCloudButton) Close(context.Context) error {
+	s.cancelFunc()
+	return nil
+}
@@ -0,0 +1,202 @@
+package drawpointcloud
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/viam-labs/draw-tools/drawshapes"
+	"github.com/viam-labs/draw-tools/lib"
+	"github.com/viam-labs/draw-tools/lib/command"
+
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	worldstatestore "go.viam.com/rdk/services/worldstatestore"
+)
+
+var (
+	WorldState = resource.NewModel("viam-viz", "draw-tools", "draw-pointcloud-world-state")
+)
+
+func init() {
+	resource.RegisterService(worldstatestore.API, WorldState,
+		resource.Registration[worldstatestore.Service, *Config]{
+			Constructor: newWorldStateService,
+		},
+	)
+}
+
+type Config struct {
+	PointClouds []lib.PointCloudJSON `json:"pointclouds"`
+}
+
+func (cfg *Config) Validate(path string) ([]string, []string, error) {
+	for i, cloud := range cfg.PointClouds {
+		cloudPath := fmt.Sprintf("%s.pointclouds.%d", path, i)
+		if cloud.ModelPath == "" {
+			return nil, nil, resource.NewConfigValidationFieldRequiredError(cloudPath, "model_path")
+		}
+
+		if err := lib.ValidatePointCloudFile(cloud.ModelPath); err != nil {
+			return nil, nil, resource.NewConfigValidationError(cloudPath, err)
+		}
+
+		if _, err := lib.UUIDFromString(cloud.UUID); err != nil {
+			return nil, nil, resource.NewConfigValidationError(cloudPath, fmt.Errorf("invalid uuid: %w", err))
+		}
+
+		if err := lib.ValidateColorBy(cloud.ColorBy); err != nil {
+			return nil, nil, resource.NewConfigValidationError(cloudPath, err)
+		}
+
+		if cloud.VoxelSizeMm < 0 {
+			return nil, nil, resource.NewConfigValidationError(cloudPath, errors.New("voxel_size_mm must not be negative"))
+		}
+
+		if cloud.PointSize < 0 {
+			return nil, nil, resource.NewConfigValidationError(cloudPath, errors.New("point_size must not be negative"))
+		}
+	}
+
+	return []string{}, nil, nil
+}
+
+// worldStateService keeps the point cloud commands on top of the shapes store.
+type worldStateService struct {
+	*drawshapes.Shapes
+}
+
+func newWorldStateService(
+	ctx context.Context,
+	deps resource.Dependencies,
+	rawConf resource.Config,
+	logger logging.Logger,
+) (worldstatestore.Service, error) {
+	conf, err := resource.NativeConfig[*Config](rawConf)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewWorldStateService(ctx, deps, rawConf.ResourceName(), conf, logger)
+}
+
+func NewWorldStateService(
+	ctx context.Context,
+	deps resource.Dependencies,
+	name resource.Name,
+	conf *Config,
+	logger logging.Logger,
+) (worldstatestore.Service, error) {
+	service := &worldStateService{
+		Shapes: drawshapes.New(name, drawshapes.Options{}, logger),
+	}
+
+	for _, toDraw := range conf.PointClouds {
+		if _, err := service.Draw([]*lib.ShapeJSON{pointCloudShape(&toDraw)}); err != nil {
+			service.Close(ctx)
+			return nil, fmt.Errorf("Failed to draw point cloud %v: %w", toDraw.ModelPath, err)
+		}
+	}
+
+	return service, nil
+}
+
+// commands describes the DoCommand keys of the point cloud service.
+var commands = &command.Set{
+	Model: WorldState,
+	Commands: []command.Command{
+		{
+			Name:        "draw",
+			Description: "Draws a point cloud loaded from a PCD or PLY file",
+			Args:        lib.PointCloudJSON{},
+			Response:    map[string]any{"uuid": "", "name": ""},
+			Parse: func(args any) error {
+				spec, err := lib.ParsePointCloud(args)
+				if err != nil {
+					return err
+				}
+				return lib.ValidatePointCloudFile(spec.ModelPath)
+			},
+		},
+		{
+			Name:        "remove",
+			Description: "Removes the point clouds with any of the given UUIDs or names",
+			Args:        lib.Identifiers{},
+			Response:    map[string]any{"pointclouds_removed": 0},
+			Parse:       command.ParseIdentifiers,
+		},
+		{
+			Name:        "clear",
+			Description: "Removes every point cloud",
+			Response:    map[string]any{"pointclouds_removed": 0},
+		},
+		command.HideCommand,
+		command.ShowCommand,
+		command.StatsCommand,
+	},
+}
+
+func (service *worldStateService) DoCommand(ctx context.Context, cmd map[string]any) (map[string]any, error) {
+	if result, ok, err := commands.Handle(cmd); ok {
+		return result, err
+	}
+
+	if result, ok, err := service.Visibility(cmd, service.SetVisible); ok {
+		return result, err
+	}
+
+	if drawCmd, ok := cmd["draw"]; ok {
+		spec, err := lib.ParsePointCloud(drawCmd)
+		if err != nil {
+			return service.Fail(err)
+		}
+
+		drawn, err := service.Draw([]*lib.ShapeJSON{pointCloudShape(spec)})
+		if err != nil {
+			return service.Fail(err)
+		}
+
+		return map[string]any{
+			"success": true,
+			"uuid":    drawn.Items[0].UUID,
+			"name":    drawn.Items[0].Name,
+		}, nil
+	}
+
+	if removeCmd, ok := cmd["remove"]; ok {
+		ids, err := lib.ParseIdentifiers(removeCmd)
+		if err != nil {
+			return service.Fail(err)
+		}
+
+		removed, err := service.Remove(ids)
+		if err != nil {
+			return service.Fail(err)
+		}
+
+		return map[string]any{
+			"success":             true,
+			"pointclouds_removed": len(removed),
+		}, nil
+	}
+
+	if _, ok := cmd["clear"]; ok {
+		return map[string]any{
+			"success":             true,
+			"pointclouds_removed": len(service.Clear()),
+		}, nil
+	}
+
+	if _, ok := cmd["stats"]; ok {
+		return map[string]any{
+			"success": true,
+			"stats":   service.Stats().ToMap(),
+		}, nil
+	}
+
+	return nil, lib.Errorf(lib.ErrInvalidArgument, "Unknown command")
+}
+
+func pointCloudShape(spec *lib.PointCloudJSON) *lib.ShapeJSON {
+	return &lib.ShapeJSON{Type: lib.ShapePointCloud, PointCloud: spec}
+}
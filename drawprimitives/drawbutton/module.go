@@ -0,0 +1,129 @@
+package drawprimitivesbutton
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/viam-labs/draw-tools/client"
+	"github.com/viam-labs/draw-tools/lib"
+	"github.com/viam-labs/draw-tools/lib/command"
+	button "go.viam.com/rdk/components/button"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+)
+
+var (
+	DrawPrimitives = resource.NewModel("viam-viz", "draw-tools", "draw-primitives-button")
+)
+
+func init() {
+	resource.RegisterComponent(button.API, DrawPrimitives,
+		resource.Registration[button.Button, *Config]{
+			Constructor: newDrawPrimitivesButton,
+		},
+	)
+}
+
+type Config struct {
+	ServiceName string              `json:"service_name"`
+	Primitives  []lib.PrimitiveJSON `json:"primitives"`
+}
+
+func (config *Config) Validate(path string) ([]string, []string, error) {
+	if config.ServiceName == "" {
+		return nil, nil, resource.NewConfigValidationFieldRequiredError(path, "service_name")
+	}
+
+	if len(config.Primitives) == 0 {
+		return nil, nil, errors.New("primitives is required")
+	}
+
+	for i, primitive := range config.Primitives {
+		if err := lib.ValidatePrimitive(&primitive); err != nil {
+			return nil, nil, resource.NewConfigValidationError(fmt.Sprintf("%s.primitives.%d", path, i), err)
+		}
+	}
+
+	return nil, nil, nil
+}
+
+type drawPrimitivesButton struct {
+	resource.AlwaysRebuild
+
+	name   resource.Name
+	logger logging.Logger
+	config *Config
+
+	cancelCtx  context.Context
+	cancelFunc func()
+
+	client *client.Client
+}
+
+func newDrawPrimitivesButton(
+	ctx context.Context,
+	deps resource.Dependencies,
+	rawConf resource.Config,
+	logger logging.Logger,
+) (button.Button, error) {
+	conf, err := resource.NativeConfig[*Config](rawConf)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewDrawPrimitivesButton(ctx, deps, rawConf.ResourceName(), conf, logger)
+}
+
+func NewDrawPrimitivesButton(
+	ctx context.Context,
+	deps resource.Dependencies,
+	name resource.Name,
+	conf *Config,
+	logger logging.Logger,
+) (button.Button, error) {
+	cancelCtx, cancelFunc := context.WithCancel(context.Background())
+	drawClient, err := client.FromDependencies(deps, conf.ServiceName)
+	if err != nil {
+		return nil, err
+	}
+
+	component := &drawPrimitivesButton{
+		name:       name,
+		logger:     logger,
+		config:     conf,
+		cancelCtx:  cancelCtx,
+		cancelFunc: cancelFunc,
+		client:     drawClient,
+	}
+
+	return component, nil
+}
+
+func (s *drawPrimitivesButton) Name() resource.Name {
+	return s.name
+}
+
+func (s *drawPrimitivesButton) Push(ctx context.Context, extra map[string]interface{}) error {
+	if _, err := s.client.DrawPrimitives(ctx, s.config.Primitives); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// commands describes the button, which has no commands of its own besides the built-in ones.
+var commands = &command.Set{Model: DrawPrimitives}
+
+func (s *drawPrimitivesButton) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	if result, ok, err := commands.Handle(cmd); ok {
+		return result, err
+	}
+
+	return nil, fmt.Errorf("Not implemented, use service DoCommand instead")
+}
+
+func (s *drawPrimitivesButton) Close(context.Context) error {
+	s.cancelFunc()
+	return nil
+}
@@ -0,0 +1,217 @@
+package drawprimitives
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/viam-labs/draw-tools/drawshapes"
+	"github.com/viam-labs/draw-tools/lib"
+	"github.com/viam-labs/draw-tools/lib/command"
+
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	worldstatestore "go.viam.com/rdk/services/worldstatestore"
+)
+
+var (
+	WorldState = resource.NewModel("viam-viz", "draw-tools", "draw-primitives-world-state")
+)
+
+func init() {
+	resource.RegisterService(worldstatestore.API, WorldState,
+		resource.Registration[worldstatestore.Service, *Config]{
+			Constructor: newWorldStateService,
+		},
+	)
+}
+
+type Config struct {
+	Primitives []lib.PrimitiveJSON `json:"primitives"`
+}
+
+func (cfg *Config) Validate(path string) ([]string, []string, error) {
+	for i, primitive := range cfg.Primitives {
+		primitivePath := fmt.Sprintf("%s.primitives.%d", path, i)
+		if primitive.Type == "" {
+			return nil, nil, resource.NewConfigValidationFieldRequiredError(primitivePath, "type")
+		}
+
+		if err := lib.ValidatePrimitive(&primitive); err != nil {
+			return nil, nil, resource.NewConfigValidationError(primitivePath, err)
+		}
+
+		if _, err := lib.UUIDFromString(primitive.UUID); err != nil {
+			return nil, nil, resource.NewConfigValidationError(primitivePath, fmt.Errorf("invalid uuid: %w", err))
+		}
+	}
+
+	return []string{}, nil, nil
+}
+
+// worldStateService keeps the primitive commands on top of the shapes store.
+type worldStateService struct {
+	*drawshapes.Shapes
+}
+
+func newWorldStateService(
+	ctx context.Context,
+	deps resource.Dependencies,
+	rawConf resource.Config,
+	logger logging.Logger,
+) (worldstatestore.Service, error) {
+	conf, err := resource.NativeConfig[*Config](rawConf)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewWorldStateService(ctx, deps, rawConf.ResourceName(), conf, logger)
+}
+
+func NewWorldStateService(
+	ctx context.Context,
+	deps resource.Dependencies,
+	name resource.Name,
+	conf *Config,
+	logger logging.Logger,
+) (worldstatestore.Service, error) {
+	service := &worldStateService{
+		Shapes: drawshapes.New(name, drawshapes.Options{}, logger),
+	}
+
+	shapes := make([]*lib.ShapeJSON, 0, len(conf.Primitives))
+	for i := range conf.Primitives {
+		shapes = append(shapes, primitiveShape(&conf.Primitives[i]))
+	}
+
+	if _, err := service.Draw(shapes); err != nil {
+		service.Close(ctx)
+		return nil, fmt.Errorf("Failed to draw primitives: %w", err)
+	}
+
+	return service, nil
+}
+
+// commands describes the DoCommand keys of the primitives service.
+var commands = &command.Set{
+	Model: WorldState,
+	Commands: []command.Command{
+		{
+			Name:        "draw",
+			Description: "Draws a primitive or an array of primitives",
+			Args:        command.OneOf(lib.PrimitiveJSON{}, []lib.PrimitiveJSON{}),
+			Response:    map[string]any{"primitives_added": 0, "uuids": []string{}, "names": []string{}},
+			Parse: func(args any) error {
+				_, err := parsePrimitives(args)
+				return err
+			},
+		},
+		{
+			Name:        "remove",
+			Description: "Removes the primitives with any of the given UUIDs or names",
+			Args:        lib.Identifiers{},
+			Response:    map[string]any{"primitives_removed": 0},
+			Parse:       command.ParseIdentifiers,
+		},
+		{
+			Name:        "clear",
+			Description: "Removes every primitive",
+			Response:    map[string]any{"primitives_removed": 0},
+		},
+		command.HideCommand,
+		command.ShowCommand,
+		command.StatsCommand,
+	},
+}
+
+func (service *worldStateService) DoCommand(ctx context.Context, cmd map[string]any) (map[string]any, error) {
+	if result, ok, err := commands.Handle(cmd); ok {
+		return result, err
+	}
+
+	if result, ok, err := service.Visibility(cmd, service.SetVisible); ok {
+		return result, err
+	}
+
+	if drawCmd, ok := cmd["draw"]; ok {
+		shapes, err := parsePrimitives(drawCmd)
+		if err != nil {
+			return service.Fail(err)
+		}
+
+		drawn, err := service.Draw(shapes)
+		if err != nil {
+			return service.Fail(err)
+		}
+
+		uuids := make([]any, 0, len(drawn.Items))
+		names := make([]any, 0, len(drawn.Items))
+		for _, item := range drawn.Items {
+			uuids = append(uuids, item.UUID)
+			names = append(names, item.Name)
+		}
+
+		return map[string]any{
+			"success":          true,
+			"primitives_added": len(items),
+			"uuids":            uuids,
+			"names":            names,
+		}, nil
+	}
+
+	if removeCmd, ok := cmd["remove"]; ok {
+		ids, err := lib.ParseIdentifiers(removeCmd)
+		if err != nil {
+			return service.Fail(err)
+		}
+
+		removed, err := service.Remove(ids)
+		if err != nil {
+			return service.Fail(err)
+		}
+
+		return map[string]any{
+			"success":            true,
+			"primitives_removed": len(removed),
+		}, nil
+	}
+
+	if _, ok := cmd["clear"]; ok {
+		return map[string]any{
+			"success":            true,
+			"primitives_removed": len(service.Clear()),
+		}, nil
+	}
+
+	if _, ok := cmd["stats"]; ok {
+		return map[string]any{
+			"success": true,
+			"stats":   service.Stats().ToMap(),
+		}, nil
+	}
+
+	return nil, lib.Errorf(lib.ErrInvalidArgument, "Unknown command")
+}
+
+func primitiveShape(spec *lib.PrimitiveJSON) *lib.ShapeJSON {
+	return &lib.ShapeJSON{Type: spec.Type, Primitive: spec}
+}
+
+// parsePrimitives parses a draw command holding a single primitive object or an array of them.
+func parsePrimitives(data any) ([]*lib.ShapeJSON, error) {
+	items, ok := data.([]any)
+	if !ok {
+		items = []any{data}
+	}
+
+	shapes := make([]*lib.ShapeJSON, 0, len(items))
+	for i, item := range items {
+		spec, err := lib.ParsePrimitive(item)
+		if err != nil {
+			return nil, lib.AtIndex(i, fmt.Errorf("Failed to parse primitive at index %d: %w", i, err))
+		}
+
+		shapes = append(shapes, primitiveShape(spec))
+	}
+
+	return shapes, nil
+}
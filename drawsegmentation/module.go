@@ -0,0 +1,464 @@
+package drawsegmentation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/viam-labs/draw-tools/lib"
+	"github.com/viam-labs/draw-tools/lib/command"
+	"github.com/viam-labs/draw-tools/lib/store"
+
+	"github.com/google/uuid"
+	commonPB "go.viam.com/api/common/v1"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/services/vision"
+	worldstatestore "go.viam.com/rdk/services/worldstatestore"
+	viz "go.viam.com/rdk/vision"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+var (
+	WorldState = resource.NewModel("viam-viz", "draw-tools", "segmentation-world-state")
+)
+
+const (
+	// DefaultRateHz is the default number of segmentations requested from the vision service per second.
+	DefaultRateHz = 1.0
+	// DefaultBoundsOpacity is the default opacity of the bounding geometry drawn around each object.
+	DefaultBoundsOpacity = 0.3
+)
+
+func init() {
+	resource.RegisterService(worldstatestore.API, WorldState,
+		resource.Registration[worldstatestore.Service, *Config]{
+			Constructor: newWorldStateService,
+		},
+	)
+}
+
+type Config struct {
+	VisionService string   `json:"vision_service"`
+	Camera        string   `json:"camera"`
+	RateHz        float64  `json:"rate_hz,omitempty"`        // Segmentations requested per second (defaults to 1)
+	VoxelSizeMm   float64  `json:"voxel_size_mm,omitempty"`  // Edge length of the voxels used to downsample each object (defaults to 0, no downsampling)
+	MaxPoints     int      `json:"max_points,omitempty"`     // Maximum number of points drawn per object (defaults to 0, no limit)
+	PointSize     float64  `json:"point_size,omitempty"`     // Rendered size of each point (defaults to the viewer's size)
+	BoundsOpacity *float64 `json:"bounds_opacity,omitempty"` // Opacity of the bounding geometries (defaults to 0.3)
+	Paused        bool     `json:"paused,omitempty"`         // Wait for a start command before requesting segmentations (defaults to false)
+}
+
+func (cfg *Config) Validate(path string) ([]string, []string, error) {
+	if cfg.VisionService == "" {
+		return nil, nil, resource.NewConfigValidationFieldRequiredError(path, "vision_service")
+	}
+
+	if cfg.Camera == "" {
+		return nil, nil, resource.NewConfigValidationFieldRequiredError(path, "camera")
+	}
+
+	if cfg.RateHz < 0 {
+		return nil, nil, resource.NewConfigValidationError(path, errors.New("rate_hz must not be negative"))
+	}
+
+	if cfg.VoxelSizeMm < 0 {
+		return nil, nil, resource.NewConfigValidationError(path, errors.New("voxel_size_mm must not be negative"))
+	}
+
+	if cfg.MaxPoints < 0 {
+		return nil, nil, resource.NewConfigValidationError(path, errors.New("max_points must not be negative"))
+	}
+
+	if cfg.PointSize < 0 {
+		return nil, nil, resource.NewConfigValidationError(path, errors.New("point_size must not be negative"))
+	}
+
+	if cfg.BoundsOpacity != nil && (*cfg.BoundsOpacity < 0 || *cfg.BoundsOpacity > 1) {
+		return nil, nil, resource.NewConfigValidationError(path, errors.New("bounds_opacity must be between 0 and 1"))
+	}
+
+	return []string{cfg.VisionService, cfg.Camera}, nil, nil
+}
+
+// trackedObject is a detected object drawn as a point cloud and a bounding geometry.
+type trackedObject struct {
+	points string    // UUID of the point cloud transform
+	bounds string    // UUID of the bounding geometry transform
+	color  lib.Color // Color the object keeps for as long as it is tracked
+}
+
+type worldStateService struct {
+	*store.Service
+
+	logger logging.Logger
+	config *Config
+
+	vision        vision.Service
+	period        time.Duration
+	boundsOpacity float64
+
+	objects      map[string]*trackedObject
+	colors       int
+	running      bool
+	objectsMutex sync.RWMutex
+}
+
+func newWorldStateService(
+	ctx context.Context,
+	deps resource.Dependencies,
+	rawConf resource.Config,
+	logger logging.Logger,
+) (worldstatestore.Service, error) {
+	conf, err := resource.NativeConfig[*Config](rawConf)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewWorldStateService(ctx, deps, rawConf.ResourceName(), conf, logger)
+}
+
+func NewWorldStateService(
+	ctx context.Context,
+	deps resource.Dependencies,
+	name resource.Name,
+	conf *Config,
+	logger logging.Logger,
+) (worldstatestore.Service, error) {
+	visionService, err := vision.FromDependencies(deps, conf.VisionService)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to get vision service %v: %w", conf.VisionService, err)
+	}
+
+	rate := conf.RateHz
+	if rate <= 0 {
+		rate = DefaultRateHz
+	}
+
+	boundsOpacity := DefaultBoundsOpacity
+	if conf.BoundsOpacity != nil {
+		boundsOpacity = *conf.BoundsOpacity
+	}
+
+	service := &worldStateService{
+		Service:       store.NewService(name, logger),
+		logger:        logger,
+		config:        conf,
+		vision:        visionService,
+		period:        time.Duration(float64(time.Second) / rate),
+		boundsOpacity: boundsOpacity,
+		objects:       make(map[string]*trackedObject),
+		running:       !conf.Paused,
+	}
+
+	service.Go(service.poll)
+
+	return service, nil
+}
+
+// poll requests a segmentation from the vision service every period while the service is running.
+func (s *worldStateService) poll(ctx context.Context) {
+	ticker := time.NewTicker(s.period)
+	defer ticker.Stop()
+
+	for {
+		s.objectsMutex.RLock()
+		running := s.running
+		s.objectsMutex.RUnlock()
+
+		if running {
+			if err := s.update(ctx); err != nil && ctx.Err() == nil {
+				s.RecordError(err)
+				s.logger.Warnw("Failed to update segmentation", "vision_service", s.config.VisionService, "error", err.Error())
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// update fetches the objects currently reported by the vision service and reconciles them with the drawn ones.
+// Objects are matched between segmentations by label and by their order within that label, sorted by distance
+// from the camera. New objects are ADDED, matched objects that changed are UPDATED in place, and missing objects are
+// REMOVED.
+func (s *worldStateService) update(ctx context.Context) error {
+	objects, err := s.vision.GetObjectPointClouds(ctx, s.config.Camera, nil)
+	if err != nil {
+		return err
+	}
+
+	keys := objectKeys(objects)
+
+	s.objectsMutex.Lock()
+	defer s.objectsMutex.Unlock()
+
+	// the service may have been stopped while the vision service was running
+	if !s.running {
+		return nil
+	}
+
+	seen := make(map[string]struct{}, len(objects))
+	for i, object := range objects {
+		key := keys[i]
+		seen[key] = struct{}{}
+
+		tracked, ok := s.objects[key]
+		if !ok {
+			tracked = &trackedObject{points: uuid.NewString(), bounds: uuid.NewString(), color: lib.PaletteColor(s.colors)}
+		}
+
+		points, bounds, err := s.build(key, object, tracked)
+		if err != nil {
+			s.RecordError(err)
+			s.logger.Warnw("Failed to draw segmented object", "object", key, "error", err.Error())
+			continue
+		}
+
+		// only objects that are drawn use up a palette color
+		if !ok {
+			s.colors++
+		}
+
+		s.objects[key] = tracked
+		transforms := []*commonPB.Transform{points}
+		if bounds != nil {
+			transforms = append(transforms, bounds)
+		} else {
+			// the vision service stopped reporting a geometry for the object
+			s.Remove(tracked.bounds)
+		}
+
+		if err := s.Put(transforms...); err != nil {
+			s.RecordError(err)
+			s.logger.Warnw("Failed to draw segmented object", "object", key, "error", err.Error())
+		}
+	}
+
+	for key, tracked := range s.objects {
+		if _, ok := seen[key]; ok {
+			continue
+		}
+
+		s.forget(key, tracked)
+	}
+
+	return nil
+}
+
+// build creates the point cloud and bounding geometry transforms of an object. The bounding geometry is nil
+// if the vision service did not report one.
+func (s *worldStateService) build(key string, object *viz.Object, tracked *trackedObject) (*commonPB.Transform, *commonPB.Transform, error) {
+	if object.PointCloud == nil {
+		return nil, nil, errors.New("object has no point cloud")
+	}
+
+	cloud, err := lib.VoxelDownsample(object.PointCloud, s.config.VoxelSizeMm)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cloud, err = lib.LimitPoints(cloud, s.config.MaxPoints)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	color := tracked.color
+	cloud, err = lib.PaintPointCloud(cloud, color)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	label := objectLabel(object)
+	pointsID, err := lib.UUIDFromString(tracked.points)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// objects are reported in the camera frame, so both transforms sit at the origin of the camera frame
+	points, err := lib.CreatePointCloud(cloud, nil, key+"-points", pointsID.Bytes(), &color, s.config.Camera, s.config.PointSize, label)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if object.Geometry == nil {
+		return points, nil, nil
+	}
+
+	boundsID, err := lib.UUIDFromString(tracked.bounds)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	geometry := object.Geometry.ToProtobuf()
+	geometry.Label = label
+
+	metadata, err := structpb.NewStruct(map[string]any{
+		"shape": "bounds",
+		"label": label,
+		"color": map[string]any{
+			"r": int(color.R),
+			"g": int(color.G),
+			"b": int(color.B),
+		},
+		"opacity":   s.boundsOpacity,
+		"wireframe": false,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	bounds := &commonPB.Transform{
+		ReferenceFrame: key + "-bounds",
+		PoseInObserverFrame: &commonPB.PoseInFrame{
+			ReferenceFrame: s.config.Camera,
+			Pose:           &commonPB.Pose{OZ: 1},
+		},
+		Uuid:           boundsID.Bytes(),
+		PhysicalObject: geometry,
+		Metadata:       metadata,
+	}
+
+	return points, bounds, nil
+}
+
+// forget removes the transforms of an object that is no longer reported. Must be called with objectsMutex held.
+func (s *worldStateService) forget(key string, tracked *trackedObject) {
+	s.Remove(tracked.points, tracked.bounds)
+	delete(s.objects, key)
+}
+
+// setRunning starts or stops requesting segmentations. The last objects stay drawn while stopped.
+func (s *worldStateService) setRunning(running bool) {
+	s.objectsMutex.Lock()
+	defer s.objectsMutex.Unlock()
+
+	s.running = running
+}
+
+// commands describes the DoCommand keys of the segmentation service.
+var commands = &command.Set{
+	Model: WorldState,
+	Commands: []command.Command{
+		{
+			Name:        "start",
+			Description: "Starts requesting segmentations from the vision service",
+			Response:    map[string]any{"running": true},
+		},
+		{
+			Name:        "stop",
+			Description: "Stops requesting segmentations, keeping the last objects drawn",
+			Response:    map[string]any{"running": true},
+		},
+		{
+			Name:        "clear",
+			Description: "Removes every drawn object",
+			Response:    map[string]any{"objects_removed": 0},
+		},
+		command.HideCommand,
+		command.ShowCommand,
+		command.StatsCommand,
+	},
+}
+
+func (service *worldStateService) DoCommand(ctx context.Context, cmd map[string]any) (map[string]any, error) {
+	if result, ok, err := commands.Handle(cmd); ok {
+		return result, err
+	}
+
+	if result, ok, err := service.Visibility(cmd, service.SetVisible); ok {
+		return result, err
+	}
+
+	if _, ok := cmd["start"]; ok {
+		service.setRunning(true)
+		return map[string]any{
+			"success": true,
+			"running": true,
+		}, nil
+	}
+
+	if _, ok := cmd["stop"]; ok {
+		service.setRunning(false)
+		return map[string]any{
+			"success": true,
+			"running": false,
+		}, nil
+	}
+
+	if _, ok := cmd["clear"]; ok {
+		count := service.clear()
+		return map[string]any{
+			"success":         true,
+			"objects_removed": count,
+		}, nil
+	}
+
+	if _, ok := cmd["stats"]; ok {
+		return map[string]any{
+			"success": true,
+			"stats":   service.Stats().ToMap(),
+		}, nil
+	}
+
+	return nil, lib.Errorf(lib.ErrInvalidArgument, "Unknown command")
+}
+
+// clear removes every drawn object. A running service draws the objects of the next segmentation again.
+func (service *worldStateService) clear() int {
+	service.objectsMutex.Lock()
+	defer service.objectsMutex.Unlock()
+
+	count := len(service.objects)
+	for key, tracked := range service.objects {
+		service.forget(key, tracked)
+	}
+
+	return count
+}
+
+// objectKeys names each object by its label and its order among objects with the same label, nearest first.
+func objectKeys(objects []*viz.Object) []string {
+	order := make([]int, len(objects))
+	for i := range order {
+		order[i] = i
+	}
+
+	distance := func(object *viz.Object) float64 {
+		d, err := object.Distance()
+		if err != nil {
+			return 0
+		}
+
+		return d
+	}
+
+	sort.SliceStable(order, func(a, b int) bool {
+		return distance(objects[order[a]]) < distance(objects[order[b]])
+	})
+
+	keys := make([]string, len(objects))
+	counts := make(map[string]int)
+	for _, index := range order {
+		label := objectLabel(objects[index])
+		keys[index] = fmt.Sprintf("%s-%d", label, counts[label])
+		counts[label]++
+	}
+
+	return keys
+}
+
+// objectLabel returns the label of an object's geometry, or "object" if it has none.
+func objectLabel(object *viz.Object) string {
+	if object.Geometry != nil && object.Geometry.Label() != "" {
+		return object.Geometry.Label()
+	}
+
+	return "object"
+}
@@ -0,0 +1,183 @@
+package drawsegmentation
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/geo/r3"
+	"github.com/viam-labs/draw-tools/lib"
+	"github.com/viam-labs/draw-tools/lib/command/commandtest"
+	v1 "go.viam.com/api/service/worldstatestore/v1"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/pointcloud"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/services/vision"
+	worldstatestore "go.viam.com/rdk/services/worldstatestore"
+	"go.viam.com/rdk/testutils/inject"
+	viz "go.viam.com/rdk/vision"
+	"go.viam.com/test"
+)
+
+func newObject(t *testing.T, label string, x float64) *viz.Object {
+	t.Helper()
+
+	cloud := pointcloud.NewBasicPointCloud(2)
+	test.That(t, cloud.Set(r3.Vector{X: x, Z: 1000}, pointcloud.NewBasicData()), test.ShouldBeNil)
+	test.That(t, cloud.Set(r3.Vector{X: x + 10, Z: 1010}, pointcloud.NewBasicData()), test.ShouldBeNil)
+
+	object, err := viz.NewObjectWithLabel(cloud, label, nil)
+	test.That(t, err, test.ShouldBeNil)
+	return object
+}
+
+func TestSegmentation(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var mu sync.Mutex
+	objects := []*viz.Object{newObject(t, "cup", 0), newObject(t, "bowl", 100)}
+
+	segmenter := inject.NewVisionService("segmenter")
+	segmenter.GetObjectPointCloudsFunc = func(ctx context.Context, cameraName string, extra map[string]interface{}) ([]*viz.Object, error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		test.That(t, cameraName, test.ShouldEqual, "depth")
+		return objects, nil
+	}
+
+	deps := resource.Dependencies{vision.Named("segmenter"): segmenter}
+	conf := &Config{VisionService: "segmenter", Camera: "depth", RateHz: 50, Paused: true}
+
+	service, err := NewWorldStateService(ctx, deps, worldstatestore.Named("overlay"), conf, logging.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	defer service.Close(ctx)
+
+	// start after subscribing so the stream sees the first segmentation
+	stream, err := service.StreamTransformChanges(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+
+	_, err = service.DoCommand(ctx, map[string]any{"start": true})
+	test.That(t, err, test.ShouldBeNil)
+
+	// every object is drawn as a point cloud and a bounding geometry
+	added := map[string][]byte{}
+	for len(added) < 4 {
+		change, err := stream.Next()
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, change.ChangeType, test.ShouldEqual, v1.TransformChangeType_TRANSFORM_CHANGE_TYPE_ADDED)
+		test.That(t, change.Transform.PoseInObserverFrame.ReferenceFrame, test.ShouldEqual, "depth")
+		added[change.Transform.ReferenceFrame] = change.Transform.Uuid
+	}
+
+	test.That(t, added, test.ShouldContainKey, "cup-0-points")
+	test.That(t, added, test.ShouldContainKey, "cup-0-bounds")
+	test.That(t, added, test.ShouldContainKey, "bowl-0-points")
+	test.That(t, added, test.ShouldContainKey, "bowl-0-bounds")
+
+	mu.Lock()
+	objects = objects[:1]
+	mu.Unlock()
+
+	removed := map[string]bool{}
+	for len(removed) < 2 {
+		change, err := stream.Next()
+		test.That(t, err, test.ShouldBeNil)
+		if change.ChangeType != v1.TransformChangeType_TRANSFORM_CHANGE_TYPE_REMOVED {
+			test.That(t, change.ChangeType, test.ShouldEqual, v1.TransformChangeType_TRANSFORM_CHANGE_TYPE_UPDATED)
+			continue
+		}
+
+		removed[string(change.Transform.Uuid)] = true
+	}
+
+	test.That(t, removed[string(added["bowl-0-points"])], test.ShouldBeTrue)
+	test.That(t, removed[string(added["bowl-0-bounds"])], test.ShouldBeTrue)
+
+	result, err := service.DoCommand(ctx, map[string]any{"stop": true})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, result["running"], test.ShouldEqual, false)
+
+	result, err = service.DoCommand(ctx, map[string]any{"clear": true})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, result["objects_removed"], test.ShouldEqual, 1)
+
+	uuids, err := service.ListUUIDs(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, uuids, test.ShouldBeEmpty)
+}
+
+func TestSegmentationUpdate(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var mu sync.Mutex
+	broken := newObject(t, "broken", 0)
+	broken.PointCloud = nil
+	unlabeled := newObject(t, "", 100)
+	objects := []*viz.Object{broken, unlabeled}
+
+	segmenter := inject.NewVisionService("segmenter")
+	segmenter.GetObjectPointCloudsFunc = func(ctx context.Context, cameraName string, extra map[string]interface{}) ([]*viz.Object, error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		return objects, nil
+	}
+
+	deps := resource.Dependencies{vision.Named("segmenter"): segmenter}
+	conf := &Config{VisionService: "segmenter", Camera: "depth", RateHz: 50, Paused: true}
+
+	service, err := NewWorldStateService(ctx, deps, worldstatestore.Named("overlay"), conf, logging.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	defer service.Close(ctx)
+
+	segmentation := service.(*worldStateService)
+	segmentation.setRunning(true)
+	test.That(t, segmentation.update(ctx), test.ShouldBeNil)
+
+	// an object that fails to draw does not use up a palette color
+	segmentation.objectsMutex.RLock()
+	test.That(t, segmentation.objects, test.ShouldNotContainKey, "broken-0")
+	test.That(t, segmentation.objects["object-0"].color, test.ShouldResemble, lib.PaletteColor(0))
+	segmentation.objectsMutex.RUnlock()
+
+	uuids, err := service.ListUUIDs(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, uuids, test.ShouldHaveLength, 2)
+
+	// an object reported again without a geometry loses its bounds
+	unbounded := newObject(t, "", 100)
+	unbounded.Geometry = nil
+	mu.Lock()
+	objects = []*viz.Object{unbounded}
+	mu.Unlock()
+	test.That(t, segmentation.update(ctx), test.ShouldBeNil)
+
+	uuids, err = service.ListUUIDs(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, uuids, test.ShouldHaveLength, 1)
+}
+
+func TestObjectKeys(t *testing.T) {
+	objects := []*viz.Object{newObject(t, "cup", 500), newObject(t, "cup", 0), newObject(t, "", 0)}
+	test.That(t, objectKeys(objects), test.ShouldResemble, []string{"cup-1", "cup-0", "object-0"})
+}
+
+func FuzzDoCommand(f *testing.F) {
+	segmenter := inject.NewVisionService("segmenter")
+	segmenter.GetObjectPointCloudsFunc = func(ctx context.Context, cameraName string, extra map[string]interface{}) ([]*viz.Object, error) {
+		return nil, nil
+	}
+
+	deps := resource.Dependencies{vision.Named("segmenter"): segmenter}
+	conf := &Config{VisionService: "segmenter", Camera: "depth", RateHz: 50, Paused: true}
+
+	service, err := NewWorldStateService(context.Background(), deps, worldstatestore.Named("overlay"), conf, logging.NewTestLogger(f))
+	test.That(f, err, test.ShouldBeNil)
+	f.Cleanup(func() { service.Close(context.Background()) })
+
+	commandtest.Fuzz(f, commands, service.DoCommand)
+}
@@ -0,0 +1,1277 @@
+package drawshapes
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/viam-labs/draw-tools/lib"
+	"github.com/viam-labs/draw-tools/lib/command"
+	"github.com/viam-labs/draw-tools/lib/store"
+
+	"github.com/google/uuid"
+	commonPB "go.viam.com/api/common/v1"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	worldstatestore "go.viam.com/rdk/services/worldstatestore"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+var (
+	WorldState = resource.NewModel("viam-viz", "draw-tools", "draw-shapes-world-state")
+)
+
+func init() {
+	resource.RegisterService(worldstatestore.API, WorldState,
+		resource.Registration[worldstatestore.Service, *Config]{
+			Constructor: newWorldStateService,
+		},
+	)
+}
+
+type Config struct {
+	Shapes          []map[string]any `json:"shapes"`                      // Shapes to draw on startup, each with a "type" field
+	CacheMaxBytes   int64            `json:"cache_max_bytes,omitempty"`   // Approximate memory budget of the parsed mesh cache (defaults to 256 MiB)
+	CacheMaxEntries int              `json:"cache_max_entries,omitempty"` // Maximum number of parsed meshes to cache (defaults to 64)
+	WatchIntervalMs int              `json:"watch_interval_ms,omitempty"` // Period between checks of watched mesh files (defaults to 1000)
+	WatchDebounceMs int              `json:"watch_debounce_ms,omitempty"` // Time a watched file must stay unchanged before reloading (defaults to 500)
+	Scenes          map[string]Scene `json:"scenes,omitempty"`            // Named sets of shapes, drawn one at a time by set_scene
+	Scene           string           `json:"scene,omitempty"`             // Scene to draw on startup (optional)
+}
+
+func (cfg *Config) Validate(path string) ([]string, []string, error) {
+	if cfg.CacheMaxBytes < 0 {
+		return nil, nil, resource.NewConfigValidationError(path, errors.New("cache_max_bytes must not be negative"))
+	}
+
+	if cfg.CacheMaxEntries < 0 {
+		return nil, nil, resource.NewConfigValidationError(path, errors.New("cache_max_entries must not be negative"))
+	}
+
+	if cfg.WatchIntervalMs < 0 {
+		return nil, nil, resource.NewConfigValidationError(path, errors.New("watch_interval_ms must not be negative"))
+	}
+
+	if cfg.WatchDebounceMs < 0 {
+		return nil, nil, resource.NewConfigValidationError(path, errors.New("watch_debounce_ms must not be negative"))
+	}
+
+	for i, item := range cfg.Shapes {
+		shapePath := fmt.Sprintf("%s.shapes.%d", path, i)
+		shape, err := lib.ParseShape(item)
+		if err != nil {
+			return nil, nil, resource.NewConfigValidationError(shapePath, err)
+		}
+
+		if err := ValidateShapeFile(shape); err != nil {
+			return nil, nil, resource.NewConfigValidationError(shapePath, err)
+		}
+	}
+
+	for name, scene := range cfg.Scenes {
+		scenePath := fmt.Sprintf("%s.scenes.%s", path, name)
+		if name == "" {
+			return nil, nil, resource.NewConfigValidationError(path, errors.New("scene names must not be empty"))
+		}
+
+		shapes, err := scene.Parse()
+		if err != nil {
+			return nil, nil, resource.NewConfigValidationError(scenePath, err)
+		}
+
+		for i, shape := range shapes {
+			if err := ValidateShapeFile(shape); err != nil {
+				return nil, nil, resource.NewConfigValidationError(scenePath, fmt.Errorf("Invalid shape at index %d: %w", i, err))
+			}
+		}
+	}
+
+	if _, ok := cfg.Scenes[cfg.Scene]; cfg.Scene != "" && !ok {
+		return nil, nil, resource.NewConfigValidationError(path, fmt.Errorf("scene %q is not one of the configured scenes", cfg.Scene))
+	}
+
+	return []string{}, nil, nil
+}
+
+// ValidateShapeFile checks that the file a mesh or point cloud is loaded from can be drawn. Mesh directories and glob
+// patterns are checked when they are expanded instead. Other shapes have no file and always pass.
+func ValidateShapeFile(shape *lib.ShapeJSON) error {
+	switch {
+	case shape.Mesh != nil && !lib.IsMeshPattern(shape.Mesh.ModelPath):
+		return lib.ValidateMeshFile(shape.Mesh.ModelPath)
+	case shape.PointCloud != nil:
+		return lib.ValidatePointCloudFile(shape.PointCloud.ModelPath)
+	default:
+		return nil
+	}
+}
+
+// Options configure how a Shapes store treats the items drawn into it.
+type Options struct {
+	CacheMaxBytes   int64                       // Approximate memory budget of the parsed mesh cache (defaults to 256 MiB)
+	CacheMaxEntries int                         // Maximum number of parsed meshes to cache (defaults to 64)
+	WatchInterval   time.Duration               // Period between checks of watched mesh files (defaults to lib.DefaultWatchInterval)
+	WatchDebounce   time.Duration               // Time a watched file must stay unchanged before reloading (defaults to lib.DefaultWatchDebounce)
+	Upsert          bool                        // Drawing an item whose UUID is already drawn replaces it instead of failing
+	DuplicateNames  bool                        // Several items may share a name; name lookups find the most recently drawn one
+	Scenes          map[string][]*lib.ShapeJSON // Named sets of shapes drawn one at a time by SetScene
+}
+
+// Item is a drawn shape and the transforms drawing it. Most shapes are drawn as a single transform sharing the
+// item's UUID; an axes triad is drawn as three arrows whose UUIDs are derived from the triad's.
+type Item struct {
+	Type       string
+	UUID       string
+	Name       string
+	Transforms []*commonPB.Transform
+
+	shape *lib.ShapeJSON
+}
+
+// ToMap describes the item in DoCommand responses.
+func (item *Item) ToMap() map[string]any {
+	return map[string]any{
+		"type": item.Type,
+		"uuid": item.UUID,
+		"name": item.Name,
+	}
+}
+
+// Shapes is a world state store holding shapes of every type behind one DoCommand vocabulary. It backs the
+// draw-shapes-world-state model, and the single-type models wrap it to keep their own commands.
+type Shapes struct {
+	*store.Service
+
+	logger  logging.Logger
+	options Options
+
+	items      map[string]*Item
+	owners     map[string]string
+	names      map[string]string
+	watchers   map[string]context.CancelFunc
+	itemsMutex sync.RWMutex
+
+	scenes     map[string][]*lib.ShapeJSON
+	scene      string
+	sceneItems map[string]struct{}
+	sceneBase  lib.UUID
+
+	meshes *lib.MeshCache
+}
+
+func newWorldStateService(
+	ctx context.Context,
+	deps resource.Dependencies,
+	rawConf resource.Config,
+	logger logging.Logger,
+) (worldstatestore.Service, error) {
+	conf, err := resource.NativeConfig[*Config](rawConf)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewWorldStateService(ctx, deps, rawConf.ResourceName(), conf, logger)
+}
+
+func NewWorldStateService(
+	ctx context.Context,
+	deps resource.Dependencies,
+	name resource.Name,
+	conf *Config,
+	logger logging.Logger,
+) (worldstatestore.Service, error) {
+	scenes := make(map[string][]*lib.ShapeJSON, len(conf.Scenes))
+	for sceneName, scene := range conf.Scenes {
+		shapes, err := scene.Parse()
+		if err != nil {
+			return nil, fmt.Errorf("Failed to parse scene %q: %w", sceneName, err)
+		}
+
+		scenes[sceneName] = shapes
+	}
+
+	service := New(name, Options{
+		CacheMaxBytes:   conf.CacheMaxBytes,
+		CacheMaxEntries: conf.CacheMaxEntries,
+		WatchInterval:   time.Duration(conf.WatchIntervalMs) * time.Millisecond,
+		WatchDebounce:   time.Duration(conf.WatchDebounceMs) * time.Millisecond,
+		Scenes:          scenes,
+	}, logger)
+
+	shapes := make([]*lib.ShapeJSON, 0, len(conf.Shapes))
+	for i, item := range conf.Shapes {
+		shape, err := lib.ParseShape(item)
+		if err != nil {
+			service.Close(ctx)
+			return nil, lib.AtIndex(i, fmt.Errorf("Failed to parse shape at index %d: %w", i, err))
+		}
+
+		shapes = append(shapes, shape)
+	}
+
+	if _, err := service.Draw(shapes); err != nil {
+		service.Close(ctx)
+		return nil, fmt.Errorf("Failed to draw shapes: %w", err)
+	}
+
+	if conf.Scene != "" {
+		if _, err := service.SetScene(conf.Scene); err != nil {
+			service.Close(ctx)
+			return nil, fmt.Errorf("Failed to draw scene %q: %w", conf.Scene, err)
+		}
+	}
+
+	return service, nil
+}
+
+// New creates an empty shapes store.
+func New(name resource.Name, options Options, logger logging.Logger) *Shapes {
+	if options.WatchInterval <= 0 {
+		options.WatchInterval = lib.DefaultWatchInterval
+	}
+
+	if options.WatchDebounce <= 0 {
+		options.WatchDebounce = lib.DefaultWatchDebounce
+	}
+
+	return &Shapes{
+		Service:    store.NewService(name, logger),
+		logger:     logger,
+		options:    options,
+		items:      make(map[string]*Item),
+		owners:     make(map[string]string),
+		names:      make(map[string]string),
+		watchers:   make(map[string]context.CancelFunc),
+		scenes:     options.Scenes,
+		sceneItems: make(map[string]struct{}),
+		sceneBase:  lib.GenerateUUID(),
+		meshes:     lib.NewMeshCache(options.CacheMaxBytes, options.CacheMaxEntries),
+	}
+}
+
+// build creates the item drawing a shape without storing it. Every transform of the item carries the shape type in
+// its "type" metadata field, and its layer, if it has one, in the "layer" field.
+func (s *Shapes) build(shape *lib.ShapeJSON) (*Item, error) {
+	if id, _ := identity(shape); *id != "" {
+		if _, err := uuid.Parse(*id); err != nil {
+			return nil, lib.FieldErrorf("uuid", "Failed to parse UUID: %w", err)
+		}
+	}
+
+	var (
+		item *Item
+		err  error
+	)
+
+	switch {
+	case shape.Arrow != nil && shape.Arrow.Axes:
+		item, err = s.buildAxes(shape.Arrow)
+	case shape.Arrow != nil:
+		item, err = single(lib.CreateArrow(lib.PoseFromJSON(shape.Arrow.Pose), shape.Arrow.Name, idBytes(shape.Arrow.UUID), shape.Arrow.Color, shape.Arrow.ParentFrame))
+	case shape.Mesh != nil:
+		item, err = single(s.buildMesh(shape.Mesh))
+	case shape.PointCloud != nil:
+		item, err = single(s.buildPointCloud(shape.PointCloud))
+	case shape.Primitive != nil:
+		item, err = single(buildPrimitive(shape.Primitive))
+	case shape.Line != nil:
+		item, err = single(lib.CreatePolyline(lib.LinePoints(shape.Line), shape.Line.WidthMm, shape.Line.Name, idBytes(shape.Line.UUID), shape.Line.Color, shape.Line.ParentFrame))
+	case shape.Label != nil:
+		item, err = single(lib.CreateLabel(lib.PoseFromJSON(shape.Label.Pose), shape.Label.Text, shape.Label.Name, idBytes(shape.Label.UUID), shape.Label.Color, shape.Label.ParentFrame))
+	default:
+		return nil, lib.FieldErrorf("type", "Unknown shape type %q", shape.Type)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	item.Type = shape.Type
+	item.shape = shape
+	for _, transform := range item.Transforms {
+		if transform.Metadata == nil {
+			transform.Metadata = &structpb.Struct{Fields: map[string]*structpb.Value{}}
+		}
+		transform.Metadata.Fields["type"] = structpb.NewStringValue(shape.Type)
+		if value := layer(shape); value != "" {
+			transform.Metadata.Fields["layer"] = structpb.NewStringValue(value)
+		}
+	}
+
+	return item, nil
+}
+
+func (s *Shapes) buildAxes(spec *lib.ArrowJSON) (*Item, error) {
+	id, err := lib.UUIDFromString(spec.UUID)
+	if err != nil {
+		return nil, err
+	}
+
+	axes, err := lib.CreateAxes(lib.PoseFromJSON(spec.Pose), spec.Name, id.Bytes(), spec.ParentFrame)
+	if err != nil {
+		return nil, err
+	}
+
+	name := spec.Name
+	if name == "" {
+		name = fmt.Sprintf("axes-%s", id.String())
+	}
+
+	return &Item{UUID: id.String(), Name: name, Transforms: axes}, nil
+}
+
+// buildMesh loads the mesh described by spec and creates its transform.
+func (s *Shapes) buildMesh(spec *lib.MeshJSON) (*commonPB.Transform, error) {
+	// Parsed meshes are cached, so repeated draws of an unchanged file skip reading and parsing it
+	mesh, err := s.meshes.Load(spec.ModelPath)
+	if err != nil {
+		s.logger.Errorw("Error creating mesh from PLY file", "path", spec.ModelPath, "error", err.Error())
+		return nil, err
+	}
+
+	geometry := mesh.Geometry
+	if spec.Scale != 0 && spec.Scale != 1 {
+		scaled, err := lib.ScaleMesh(mesh.Mesh, spec.Scale)
+		if err != nil {
+			return nil, err
+		}
+
+		geometry = scaled.ToProtobuf()
+	}
+
+	if spec.Label != "" {
+		// cached geometries are shared, so relabel a copy
+		geometry = &commonPB.Geometry{
+			Center:       geometry.Center,
+			GeometryType: geometry.GeometryType,
+			Label:        spec.Label,
+		}
+	}
+
+	colors := mesh.Colors
+	if spec.IgnoreFileColors {
+		colors = nil
+	}
+
+	return lib.CreateMesh(geometry, lib.PoseFromJSON(spec.Pose), spec.Name, idBytes(spec.UUID), spec.Color, spec.ParentFrame, colors)
+}
+
+// buildPointCloud loads the point cloud described by spec, downsamples and recolors it, and creates its transform.
+func (s *Shapes) buildPointCloud(spec *lib.PointCloudJSON) (*commonPB.Transform, error) {
+	cloud, err := lib.LoadPointCloud(spec.ModelPath)
+	if err != nil {
+		s.logger.Errorw("Error loading point cloud file", "path", spec.ModelPath, "error", err.Error())
+		return nil, err
+	}
+
+	loaded := cloud.Size()
+	cloud, err = lib.VoxelDownsample(cloud, spec.VoxelSizeMm)
+	if err != nil {
+		return nil, err
+	}
+
+	cloud, err = lib.ColorPointCloud(cloud, spec.ColorBy)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Debugw("Loaded point cloud", "path", spec.ModelPath, "points", loaded, "drawn", cloud.Size())
+
+	label := spec.Label
+	if label == "" {
+		label = spec.ModelPath
+	}
+
+	return lib.CreatePointCloud(cloud, lib.PoseFromJSON(spec.Pose), spec.Name, idBytes(spec.UUID), spec.Color, spec.ParentFrame, spec.PointSize, label)
+}
+
+// buildPrimitive creates the transform of the primitive described by spec.
+func buildPrimitive(spec *lib.PrimitiveJSON) (*commonPB.Transform, error) {
+	label := spec.Label
+	if label == "" {
+		label = spec.Name
+	}
+
+	geometry, err := lib.NewPrimitiveGeometry(spec, label)
+	if err != nil {
+		return nil, err
+	}
+
+	opacity := 1.0
+	if spec.Opacity != nil {
+		opacity = *spec.Opacity
+	}
+
+	return lib.CreatePrimitive(spec.Type, geometry, lib.PoseFromJSON(spec.Pose), spec.Name, idBytes(spec.UUID), spec.Color, spec.ParentFrame, opacity, spec.Wireframe)
+}
+
+// single wraps the transform of a single-transform shape in an item named and identified after the transform.
+func single(transform *commonPB.Transform, err error) (*Item, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := uuid.FromBytes(transform.Uuid)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Item{UUID: id.String(), Name: transform.ReferenceFrame, Transforms: []*commonPB.Transform{transform}}, nil
+}
+
+// idBytes converts a UUID string to bytes, or nil to generate a new UUID when the string is empty. build has already
+// checked the string is a valid UUID.
+func idBytes(id string) []byte {
+	if id == "" {
+		return nil
+	}
+
+	parsed, err := uuid.Parse(id)
+	if err != nil {
+		return nil
+	}
+
+	return parsed[:]
+}
+
+// identity returns the UUID and name fields of the configuration set in a shape.
+func identity(shape *lib.ShapeJSON) (*string, *string) {
+	switch {
+	case shape.Arrow != nil:
+		return &shape.Arrow.UUID, &shape.Arrow.Name
+	case shape.Mesh != nil:
+		return &shape.Mesh.UUID, &shape.Mesh.Name
+	case shape.PointCloud != nil:
+		return &shape.PointCloud.UUID, &shape.PointCloud.Name
+	case shape.Primitive != nil:
+		return &shape.Primitive.UUID, &shape.Primitive.Name
+	case shape.Line != nil:
+		return &shape.Line.UUID, &shape.Line.Name
+	case shape.Label != nil:
+		return &shape.Label.UUID, &shape.Label.Name
+	default:
+		return new(string), new(string)
+	}
+}
+
+// layer returns the layer of a shape, or an empty string if it has none.
+func layer(shape *lib.ShapeJSON) string {
+	switch {
+	case shape.Arrow != nil:
+		return shape.Arrow.Layer
+	case shape.Mesh != nil:
+		return shape.Mesh.Layer
+	case shape.PointCloud != nil:
+		return shape.PointCloud.Layer
+	case shape.Primitive != nil:
+		return shape.Primitive.Layer
+	case shape.Line != nil:
+		return shape.Line.Layer
+	case shape.Label != nil:
+		return shape.Label.Layer
+	default:
+		return ""
+	}
+}
+
+// withIdentity returns a copy of a shape with its UUID set and its name set unless the shape already has one.
+func withIdentity(shape *lib.ShapeJSON, id, name string) *lib.ShapeJSON {
+	copied := &lib.ShapeJSON{
+		Type:       shape.Type,
+		Arrow:      clone(shape.Arrow),
+		Mesh:       clone(shape.Mesh),
+		PointCloud: clone(shape.PointCloud),
+		Primitive:  clone(shape.Primitive),
+		Line:       clone(shape.Line),
+		Label:      clone(shape.Label),
+	}
+
+	copiedID, copiedName := identity(copied)
+	*copiedID = id
+	*copiedName = cmp.Or(*copiedName, name)
+	return copied
+}
+
+func clone[T any](value *T) *T {
+	if value == nil {
+		return nil
+	}
+
+	copied := *value
+	return &copied
+}
+
+// FileResult is the outcome of drawing one file matched by a mesh whose model path is a directory or glob pattern.
+// Such files are drawn best effort: one that fails is reported here without failing the others.
+type FileResult struct {
+	Index     int    // Index of the shape whose model path matched the file
+	ModelPath string // Path of the file
+	Item      *Item  // Item drawn from the file, or nil if it failed
+	Err       error  // Why the file failed to draw, or nil
+}
+
+// ToMap describes the result in DoCommand responses: the item drawn, or the failure, with the file's model path.
+func (result *FileResult) ToMap() map[string]any {
+	var fields map[string]any
+	if result.Err != nil {
+		fields = lib.ErrorResponse(result.Err)
+	} else {
+		fields = result.Item.ToMap()
+		fields["success"] = true
+	}
+	fields["model_path"] = result.ModelPath
+
+	return fields
+}
+
+// Drawn reports the items added by Draw, and the result of every file matched by a mesh directory or glob pattern.
+type Drawn struct {
+	Items []*Item
+	Files []*FileResult
+}
+
+// Failed counts the matched files that failed to draw.
+func (drawn *Drawn) Failed() int {
+	return failed(drawn.Files)
+}
+
+// FileMaps describes the result of every matched file in DoCommand responses.
+func (drawn *Drawn) FileMaps() []any {
+	return fileMaps(drawn.Files)
+}
+
+// pending is an item built for drawing, with the index of the shape it was built from and, for a file matched by a
+// mesh directory or glob pattern, the result of that file.
+type pending struct {
+	index int
+	item  *Item
+	file  *FileResult
+}
+
+// fail records that the file of a pending item failed to draw, and reports whether it was one. Other items fail the
+// whole draw instead.
+func (p *pending) fail(err error) bool {
+	if p.file == nil {
+		return false
+	}
+
+	p.file.Err = lib.AtIndex(p.index, err)
+	return true
+}
+
+// prepare builds the items drawing shapes, expanding every mesh whose model path is a directory or glob pattern into
+// one mesh per file. identify, if set, may change each shape before it is built and fails it by returning an error;
+// describe wraps the error of a shape that fails. A matched file that fails is recorded in its result, and any other
+// shape that fails fails the draw.
+func (s *Shapes) prepare(
+	shapes []*lib.ShapeJSON,
+	identify func(*lib.ShapeJSON) (*lib.ShapeJSON, error),
+	describe func(index int, shape *lib.ShapeJSON, err error) error,
+) ([]*pending, []*FileResult, error) {
+	built := make([]*pending, 0, len(shapes))
+	files := []*FileResult{}
+	for i, shape := range shapes {
+		expanded := []*lib.ShapeJSON{shape}
+		pattern := shape.Mesh != nil && lib.IsMeshPattern(shape.Mesh.ModelPath)
+		if pattern {
+			meshes, err := lib.ExpandMeshes(*shape.Mesh)
+			if err != nil {
+				return nil, nil, lib.AtIndex(i, fmt.Errorf("Failed to expand mesh: %w", err))
+			}
+
+			expanded = make([]*lib.ShapeJSON, 0, len(meshes))
+			for _, mesh := range meshes {
+				expanded = append(expanded, &lib.ShapeJSON{Type: lib.ShapeMesh, Mesh: &mesh})
+			}
+		}
+
+		for _, each := range expanded {
+			p := &pending{index: i}
+			if pattern {
+				p.file = &FileResult{Index: i, ModelPath: each.Mesh.ModelPath}
+				files = append(files, p.file)
+			}
+
+			item, err := s.buildAs(each, identify)
+			if err != nil {
+				err = describe(i, each, err)
+				if p.fail(err) {
+					continue
+				}
+				return nil, nil, lib.AtIndex(i, err)
+			}
+
+			p.item = item
+			built = append(built, p)
+		}
+	}
+
+	return built, files, nil
+}
+
+// buildAs builds a shape after identify, if set, changed it.
+func (s *Shapes) buildAs(shape *lib.ShapeJSON, identify func(*lib.ShapeJSON) (*lib.ShapeJSON, error)) (*Item, error) {
+	if identify != nil {
+		var err error
+		if shape, err = identify(shape); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.build(shape)
+}
+
+// patternFailure fails a draw in which every file matched by the pattern of one of the shapes failed, with the error
+// of the first of them.
+func patternFailure(shapes []*lib.ShapeJSON, files []*FileResult) error {
+	matched := make(map[int]int)
+	drawn := make(map[int]bool)
+	for _, file := range files {
+		matched[file.Index]++
+		drawn[file.Index] = drawn[file.Index] || file.Err == nil
+	}
+
+	for _, file := range files {
+		if !drawn[file.Index] {
+			return lib.AtIndex(file.Index, fmt.Errorf("Failed to draw any of the %d meshes matching %s: %w",
+				matched[file.Index], shapes[file.Index].Mesh.ModelPath, file.Err))
+		}
+	}
+
+	return nil
+}
+
+// commitBuilt commits the built items that did not fail and logs the files that did. Must be called with itemsMutex
+// held.
+func (s *Shapes) commitBuilt(built []*pending, files []*FileResult) []*Item {
+	items := make([]*Item, 0, len(built))
+	for _, p := range built {
+		if p.file != nil && p.file.Err != nil {
+			continue
+		}
+
+		s.commit(p.item)
+		items = append(items, p.item)
+		if p.file != nil {
+			p.file.Item = p.item
+		}
+	}
+
+	for _, file := range files {
+		if file.Err != nil {
+			s.RecordError(file.Err)
+			s.logger.Warnw("Failed to draw mesh", "path", file.ModelPath, "error", file.Err.Error())
+		}
+	}
+
+	return items
+}
+
+// buildError describes a shape that failed to build in a draw. Its index is left to the index of the response.
+func buildError(_ int, shape *lib.ShapeJSON, err error) error {
+	return fmt.Errorf("Failed to build %s: %w", shape.Type, err)
+}
+
+func failed(files []*FileResult) int {
+	count := 0
+	for _, file := range files {
+		if file.Err != nil {
+			count++
+		}
+	}
+
+	return count
+}
+
+func fileMaps(files []*FileResult) []any {
+	maps := make([]any, 0, len(files))
+	for _, file := range files {
+		maps = append(maps, file.ToMap())
+	}
+
+	return maps
+}
+
+// Draw adds every shape. A mesh whose model path is a directory or glob pattern is expanded into one mesh per file,
+// drawn best effort: a file that fails to build or conflicts with a drawn item is reported in the results, and only
+// fails the draw if every file of the pattern fails. Nothing is added if any other shape fails to build or conflicts
+// with a drawn item, unless Options allow redrawing UUIDs or sharing names.
+func (s *Shapes) Draw(shapes []*lib.ShapeJSON) (*Drawn, error) {
+	built, files, err := s.prepare(shapes, nil, buildError)
+	if err != nil {
+		return nil, err
+	}
+
+	s.itemsMutex.Lock()
+	defer s.itemsMutex.Unlock()
+
+	ids := make(map[string]struct{}, len(built))
+	names := make(map[string]struct{}, len(built))
+	for _, p := range built {
+		if err := s.conflict(p.item, ids, names); err != nil {
+			if p.fail(err) {
+				continue
+			}
+			return nil, lib.AtIndex(p.index, err)
+		}
+
+		ids[p.item.UUID] = struct{}{}
+		names[p.item.Name] = struct{}{}
+	}
+
+	if err := patternFailure(shapes, files); err != nil {
+		return nil, err
+	}
+
+	return &Drawn{Items: s.commitBuilt(built, files), Files: files}, nil
+}
+
+// conflict checks that an item can be drawn next to the drawn items and to the other items of the same draw, whose
+// UUIDs and names are given. Must be called with itemsMutex held.
+func (s *Shapes) conflict(item *Item, ids, names map[string]struct{}) error {
+	if _, ok := s.items[item.UUID]; ok && !s.options.Upsert {
+		return lib.FieldErrorf("uuid", "%s with UUID %s already exists, use replace to change it", item.Type, item.UUID)
+	}
+
+	if _, ok := ids[item.UUID]; ok {
+		return lib.FieldErrorf("uuid", "%s with UUID %s is drawn twice", item.Type, item.UUID)
+	}
+
+	for _, transform := range item.Transforms {
+		id, _ := uuid.FromBytes(transform.Uuid)
+		if owner, ok := s.owners[id.String()]; ok && owner != item.UUID {
+			return lib.FieldErrorf("uuid", "%s with UUID %s is already drawn as part of %s", item.Type, id, owner)
+		}
+	}
+
+	if s.options.DuplicateNames {
+		return nil
+	}
+
+	if owner, ok := s.names[item.Name]; ok && owner != item.UUID {
+		return lib.FieldErrorf("name", "%s named %q already exists", item.Type, item.Name)
+	}
+
+	if _, ok := names[item.Name]; ok {
+		return lib.FieldErrorf("name", "%s named %q is drawn twice", item.Type, item.Name)
+	}
+
+	return nil
+}
+
+// Replace swaps the item identified by target, a UUID or name, for the one described by shape, keeping its UUID.
+// The item keeps its name unless shape provides a new one, and may change type.
+func (s *Shapes) Replace(target string, shape *lib.ShapeJSON) (*Item, error) {
+	s.itemsMutex.RLock()
+	id, ok := s.resolve(target)
+	var previous *Item
+	if ok {
+		previous = s.items[id]
+	}
+	s.itemsMutex.RUnlock()
+
+	if !ok {
+		return nil, lib.Errorf(lib.ErrNotFound, "shape not found: %s", target)
+	}
+
+	item, err := s.build(withIdentity(shape, id, previous.Name))
+	if err != nil {
+		return nil, err
+	}
+
+	s.itemsMutex.Lock()
+	defer s.itemsMutex.Unlock()
+
+	if _, ok := s.items[id]; !ok {
+		return nil, lib.Errorf(lib.ErrNotFound, "shape not found: %s", target)
+	}
+
+	if owner, ok := s.names[item.Name]; ok && owner != id && !s.options.DuplicateNames {
+		return nil, lib.FieldErrorf("name", "%s named %q already exists", item.Type, item.Name)
+	}
+
+	s.commit(item)
+	return item, nil
+}
+
+// Remove deletes the selected items. A UUID may be that of an item or of any of its transforms. Nothing is removed
+// if any of them cannot be found.
+func (s *Shapes) Remove(ids *lib.Identifiers) ([]*Item, error) {
+	s.itemsMutex.Lock()
+	defer s.itemsMutex.Unlock()
+
+	toRemove := make(map[string]struct{})
+	for _, target := range slices.Concat(ids.UUIDs, ids.Names) {
+		id, ok := s.resolve(target)
+		if !ok {
+			return nil, lib.Errorf(lib.ErrNotFound, "shape not found: %s", target)
+		}
+
+		toRemove[id] = struct{}{}
+	}
+
+	removed := make([]*Item, 0, len(toRemove))
+	for id := range toRemove {
+		removed = append(removed, s.delete(id))
+	}
+
+	return removed, nil
+}
+
+// SetVisible shows or hides the selected items, as Store.SetVisible does for transforms. UUIDs and names select items,
+// so an axes triad is shown or hidden as a whole, while layers and all select transforms as the store does.
+func (s *Shapes) SetVisible(selection *lib.Selection, visible bool) (int, error) {
+	s.itemsMutex.RLock()
+	defer s.itemsMutex.RUnlock()
+
+	resolved := &lib.Selection{Layers: selection.Layers, All: selection.All}
+	for _, target := range slices.Concat(selection.UUIDs, selection.Names) {
+		id, ok := s.resolve(target)
+		if !ok {
+			return 0, lib.Errorf(lib.ErrNotFound, "shape not found: %s", target)
+		}
+
+		for _, transform := range s.items[id].Transforms {
+			transformID, _ := uuid.FromBytes(transform.Uuid)
+			resolved.UUIDs = append(resolved.UUIDs, transformID.String())
+		}
+	}
+
+	return s.Service.SetVisible(resolved, visible)
+}
+
+// Clear removes every item.
+func (s *Shapes) Clear() []*Item {
+	s.itemsMutex.Lock()
+	defer s.itemsMutex.Unlock()
+
+	removed := make([]*Item, 0, len(s.items))
+	for id := range s.items {
+		removed = append(removed, s.delete(id))
+	}
+
+	return removed
+}
+
+// List returns every item, sorted by name.
+func (s *Shapes) List() []*Item {
+	s.itemsMutex.RLock()
+	defer s.itemsMutex.RUnlock()
+
+	items := make([]*Item, 0, len(s.items))
+	for _, item := range s.items {
+		items = append(items, item)
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Name != items[j].Name {
+			return items[i].Name < items[j].Name
+		}
+		return items[i].UUID < items[j].UUID
+	})
+
+	return items
+}
+
+// CacheStats reports the usage of the parsed mesh cache.
+func (s *Shapes) CacheStats() lib.MeshCacheStats {
+	return s.meshes.Stats()
+}
+
+// resolve finds the UUID of an item from its UUID, the UUID of one of its transforms, or its name. Must be called
+// with itemsMutex held.
+func (s *Shapes) resolve(target string) (string, bool) {
+	if parsed, err := uuid.Parse(target); err == nil {
+		if _, ok := s.items[parsed.String()]; ok {
+			return parsed.String(), true
+		}
+
+		if owner, ok := s.owners[parsed.String()]; ok {
+			return owner, true
+		}
+	}
+
+	id, ok := s.names[target]
+	return id, ok
+}
+
+// commit stores an item, replacing the item with the same UUID if there is one. Transforms new to the item are
+// ADDED, changed ones are UPDATED with the fields that changed, and ones the item no longer has are REMOVED. Must be
+// called with itemsMutex held.
+func (s *Shapes) commit(item *Item) {
+	previous := s.items[item.UUID]
+
+	next := make(map[string]struct{}, len(item.Transforms))
+	for _, transform := range item.Transforms {
+		id, _ := uuid.FromBytes(transform.Uuid)
+		next[id.String()] = struct{}{}
+		s.owners[id.String()] = item.UUID
+	}
+
+	// build checked every UUID, so storing the transforms cannot fail
+	if err := s.Put(item.Transforms...); err != nil {
+		s.RecordError(err)
+		s.logger.Errorw("Failed to store shape", "uuid", item.UUID, "error", err.Error())
+	}
+
+	if previous != nil {
+		for _, transform := range previous.Transforms {
+			id, _ := uuid.FromBytes(transform.Uuid)
+			if _, ok := next[id.String()]; ok {
+				continue
+			}
+
+			s.removeTransform(id.String())
+		}
+
+		if s.names[previous.Name] == previous.UUID {
+			delete(s.names, previous.Name)
+		}
+	}
+
+	s.items[item.UUID] = item
+	s.names[item.Name] = item.UUID
+
+	// reloading a watched mesh keeps its shape, and with it the watcher that reloaded it
+	if previous == nil || previous.shape != item.shape {
+		s.untrack(item.UUID)
+		s.track(item)
+	}
+}
+
+// delete removes an item and its transforms. Must be called with itemsMutex held.
+func (s *Shapes) delete(id string) *Item {
+	item := s.items[id]
+	for _, transform := range item.Transforms {
+		parsedId, _ := uuid.FromBytes(transform.Uuid)
+		s.removeTransform(parsedId.String())
+	}
+
+	if s.names[item.Name] == id {
+		delete(s.names, item.Name)
+	}
+	delete(s.items, id)
+	s.untrack(id)
+
+	return item
+}
+
+// removeTransform deletes a single transform of an item and emits its removal. Must be called with itemsMutex held.
+func (s *Shapes) removeTransform(id string) {
+	delete(s.owners, id)
+	s.Store.Remove(id)
+}
+
+// track starts watching the file of a mesh drawn with watch set. Must be called with itemsMutex held.
+func (s *Shapes) track(item *Item) {
+	if item.shape.Mesh == nil || !item.shape.Mesh.Watch || s.Context().Err() != nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(s.Context())
+	s.watchers[item.UUID] = cancel
+	s.Go(func(context.Context) {
+		lib.WatchFile(ctx, item.shape.Mesh.ModelPath, s.options.WatchInterval, s.options.WatchDebounce, func() {
+			s.reload(ctx, item.UUID)
+		})
+	})
+}
+
+// untrack stops watching the file of an item. Must be called with itemsMutex held.
+func (s *Shapes) untrack(id string) {
+	if cancel, ok := s.watchers[id]; ok {
+		cancel()
+		delete(s.watchers, id)
+	}
+}
+
+// reload re-parses a watched mesh after its file changed and emits an UPDATED change for it.
+func (s *Shapes) reload(ctx context.Context, id string) {
+	s.itemsMutex.RLock()
+	current, ok := s.items[id]
+	s.itemsMutex.RUnlock()
+
+	if !ok {
+		return
+	}
+
+	item, err := s.build(withIdentity(current.shape, current.UUID, current.Name))
+	if err != nil {
+		s.RecordError(err)
+		s.logger.Warnw("Failed to reload changed mesh, keeping the previous version", "path", current.shape.Mesh.ModelPath, "error", err.Error())
+		return
+	}
+	item.shape = current.shape
+
+	s.itemsMutex.Lock()
+	defer s.itemsMutex.Unlock()
+
+	// the mesh may have been removed or replaced while it was being parsed
+	if ctx.Err() != nil || s.items[id] == nil || s.items[id].shape != current.shape {
+		return
+	}
+
+	s.commit(item)
+	s.logger.Infow("Reloaded changed mesh", "path", current.shape.Mesh.ModelPath, "uuid", id)
+}
+
+// itemSchema describes an item in DoCommand responses, as written by Item.ToMap.
+type itemSchema struct {
+	Type string `json:"type"`
+	UUID string `json:"uuid"`
+	Name string `json:"name"`
+}
+
+// commands describes the DoCommand keys of the draw-shapes service.
+var commands = &command.Set{
+	Model: WorldState,
+	Commands: []command.Command{
+		{
+			Name:        "draw",
+			Description: "Draws a shape or an array of shapes, or nothing if any of them is invalid; files matched by a mesh directory or glob are drawn best effort",
+			Args:        command.OneOf(command.ShapeSchema(), command.ArrayOf(command.ShapeSchema())),
+			Response:    map[string]any{"added": 0, "items": []itemSchema{}, "failed": 0, "results": []map[string]any{}},
+			Parse:       parseDraw,
+		},
+		{
+			Name:        "replace",
+			Description: "Replaces the shape with the given uuid or name, keeping its UUID",
+			Args:        command.ShapeSchema(),
+			Response:    map[string]any{"type": "", "uuid": "", "name": ""},
+			Parse: func(args any) error {
+				_, shape, err := parseReplace(args)
+				if err != nil {
+					return err
+				}
+				return ValidateShapeFile(shape)
+			},
+		},
+		{
+			Name:        "remove",
+			Description: "Removes the shapes with any of the given UUIDs or names",
+			Args:        lib.Identifiers{},
+			Response:    map[string]any{"removed": 0},
+			Parse:       command.ParseIdentifiers,
+		},
+		{
+			Name:        "list",
+			Description: "Lists the drawn shapes",
+			Response:    map[string]any{"items": []itemSchema{}},
+		},
+		{
+			Name:        "cache_stats",
+			Description: "Reports the usage of the parsed mesh cache",
+			Response:    map[string]any{"cache": lib.MeshCacheStats{}},
+		},
+		{
+			Name:        "clear",
+			Description: "Removes every drawn shape",
+			Response:    map[string]any{"removed": 0},
+		},
+		{
+			Name:        "set_scene",
+			Description: "Draws the configured scene with the given name in place of the previous one, changing only the shapes that differ",
+			Args:        "",
+			Response:    map[string]any{"scene": "", "added": 0, "updated": 0, "removed": 0, "failed": 0, "results": []map[string]any{}},
+			Parse: func(args any) error {
+				_, err := parseSceneName(args)
+				return err
+			},
+		},
+		command.HideCommand,
+		command.ShowCommand,
+		command.StatsCommand,
+	},
+}
+
+// parseDraw checks a draw command's shapes and the files they are loaded from.
+func parseDraw(args any) error {
+	shapes, err := lib.ParseShapes(args)
+	if err != nil {
+		return err
+	}
+
+	for i, shape := range shapes {
+		if err := ValidateShapeFile(shape); err != nil {
+			return lib.AtIndex(i, fmt.Errorf("Invalid shape at index %d: %w", i, err))
+		}
+	}
+
+	return nil
+}
+
+func (service *Shapes) DoCommand(ctx context.Context, cmd map[string]any) (map[string]any, error) {
+	if result, ok, err := commands.Handle(cmd); ok {
+		return result, err
+	}
+
+	if result, ok, err := service.Visibility(cmd, service.SetVisible); ok {
+		return result, err
+	}
+
+	if drawCmd, ok := cmd["draw"]; ok {
+		shapes, err := lib.ParseShapes(drawCmd)
+		if err != nil {
+			return service.Fail(err)
+		}
+
+		drawn, err := service.Draw(shapes)
+		if err != nil {
+			return service.Fail(err)
+		}
+
+		return map[string]any{
+			"success": true,
+			"added":   len(drawn.Items),
+			"items":   itemMaps(drawn.Items),
+			"failed":  drawn.Failed(),
+			"results": drawn.FileMaps(),
+		}, nil
+	}
+
+	if replaceCmd, ok := cmd["replace"]; ok {
+		target, shape, err := parseReplace(replaceCmd)
+		if err != nil {
+			return service.Fail(err)
+		}
+
+		item, err := service.Replace(target, shape)
+		if err != nil {
+			return service.Fail(err)
+		}
+
+		result := item.ToMap()
+		result["success"] = true
+		return result, nil
+	}
+
+	if removeCmd, ok := cmd["remove"]; ok {
+		ids, err := lib.ParseIdentifiers(removeCmd)
+		if err != nil {
+			return service.Fail(err)
+		}
+
+		removed, err := service.Remove(ids)
+		if err != nil {
+			return service.Fail(err)
+		}
+
+		return map[string]any{
+			"success": true,
+			"removed": len(removed),
+		}, nil
+	}
+
+	if _, ok := cmd["list"]; ok {
+		return map[string]any{
+			"success": true,
+			"items":   itemMaps(service.List()),
+		}, nil
+	}
+
+	if _, ok := cmd["cache_stats"]; ok {
+		return map[string]any{
+			"success": true,
+			"cache":   service.CacheStats().ToMap(),
+		}, nil
+	}
+
+	if _, ok := cmd["clear"]; ok {
+		removed := service.Clear()
+		return map[string]any{
+			"success": true,
+			"removed": len(removed),
+		}, nil
+	}
+
+	if sceneCmd, ok := cmd["set_scene"]; ok {
+		name, err := parseSceneName(sceneCmd)
+		if err != nil {
+			return service.Fail(err)
+		}
+
+		changes, err := service.SetScene(name)
+		if err != nil {
+			return service.Fail(err)
+		}
+
+		result := changes.ToMap()
+		result["success"] = true
+		return result, nil
+	}
+
+	if _, ok := cmd["stats"]; ok {
+		return map[string]any{
+			"success": true,
+			"stats":   service.Stats().ToMap(),
+		}, nil
+	}
+
+	return nil, lib.Errorf(lib.ErrInvalidArgument, "Unknown command")
+}
+
+// parseSceneName parses the scene name of a set_scene command.
+func parseSceneName(data any) (string, error) {
+	name, ok := data.(string)
+	if !ok || name == "" {
+		return "", lib.Errorf(lib.ErrInvalidArgument, "Expected scene name, got %T", data)
+	}
+
+	return name, nil
+}
+
+// parseReplace parses a replace command into the UUID or name of the item to replace and its new definition.
+// An item selected by UUID may be renamed, one selected by name keeps it.
+func parseReplace(data any) (string, *lib.ShapeJSON, error) {
+	replaceMap, ok := data.(map[string]any)
+	if !ok {
+		return "", nil, lib.Errorf(lib.ErrInvalidArgument, "Expected shape object, got %T", data)
+	}
+
+	var selector struct {
+		UUID string `json:"uuid"`
+		Name string `json:"name"`
+	}
+	if err := lib.Decode(replaceMap, &selector); err != nil {
+		return "", nil, err
+	}
+	target, name := selector.UUID, selector.Name
+
+	// the new definition takes the item's UUID, and its name when selected by name
+	fields := make(map[string]any, len(replaceMap))
+	for key, value := range replaceMap {
+		if key == "uuid" || (target == "" && key == "name") {
+			continue
+		}
+		fields[key] = value
+	}
+
+	if target == "" {
+		target = name
+	}
+
+	if target == "" {
+		return "", nil, lib.Errorf(lib.ErrInvalidArgument, "Missing required 'uuid' or 'name' field")
+	}
+
+	shape, err := lib.ParseShape(fields)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return target, shape, nil
+}
+
+func itemMaps(items []*Item) []any {
+	maps := make([]any, 0, len(items))
+	for _, item := range items {
+		maps = append(maps, item.ToMap())
+	}
+
+	return maps
+}
@@ -0,0 +1,613 @@
+package drawshapes
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/viam-labs/draw-tools/lib"
+	"github.com/viam-labs/draw-tools/lib/command/commandtest"
+	v1 "go.viam.com/api/service/worldstatestore/v1"
+	"go.viam.com/rdk/logging"
+	worldstatestore "go.viam.com/rdk/services/worldstatestore"
+	"go.viam.com/test"
+)
+
+const testPLY = `ply
+format ascii 1.0
+element vertex 3
+property float x
+property float y
+property float z
+element face 1
+property list uchar int vertex_indices
+end_header
+0 0 0
+1 0 0
+0 1 0
+3 0 1 2
+`
+
+func writeMesh(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "part.ply")
+	err := os.WriteFile(path, []byte(testPLY), 0o644)
+	test.That(t, err, test.ShouldBeNil)
+	return path
+}
+
+// collect reads changes from the stream until n of them arrive and returns the UUIDs of the changed transforms by
+// frame name, with the type of the last change.
+func collect(t *testing.T, stream *worldstatestore.TransformChangeStream, n int) (map[string][]byte, v1.TransformChangeType) {
+	t.Helper()
+	frames := map[string][]byte{}
+	var changeType v1.TransformChangeType
+	for range n {
+		change, err := stream.Next()
+		test.That(t, err, test.ShouldBeNil)
+		frames[change.Transform.ReferenceFrame] = change.Transform.Uuid
+		changeType = change.ChangeType
+	}
+
+	return frames, changeType
+}
+
+func TestDrawShapes(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	conf := &Config{Shapes: []map[string]any{
+		{"type": "mesh", "model_path": writeMesh(t), "name": "part"},
+		{"type": "axes", "pose": map[string]any{"z": 100.0, "o_z": 1.0}, "name": "tool"},
+		{"type": "box", "dims_mm": map[string]any{"x": 10.0, "y": 10.0, "z": 10.0}, "name": "crate"},
+	}}
+
+	service, err := NewWorldStateService(ctx, nil, worldstatestore.Named("shapes"), conf, logging.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	defer service.Close(ctx)
+
+	stream, err := service.StreamTransformChanges(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+
+	result, err := service.DoCommand(ctx, map[string]any{"draw": []any{
+		map[string]any{"type": "line", "points": []any{map[string]any{}, map[string]any{"x": 100.0}}, "name": "path"},
+		map[string]any{"type": "label", "pose": map[string]any{}, "text": "dock", "name": "dock"},
+		map[string]any{"type": "arrow", "pose": map[string]any{"o_z": 1.0}, "name": "target"},
+	}})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, result["added"], test.ShouldEqual, 3)
+
+	// the stream only carries changes made after subscribing, so the configured shapes are looked up by name
+	frames, changeType := collect(t, stream, 3)
+	test.That(t, changeType, test.ShouldEqual, v1.TransformChangeType_TRANSFORM_CHANGE_TYPE_ADDED)
+	for _, name := range []string{"part", "tool-x", "tool-z", "crate"} {
+		named := service.(*Shapes).Named(name)
+		test.That(t, named, test.ShouldHaveLength, 1)
+		frames[name] = named[0].Uuid
+	}
+
+	expected := map[string]string{
+		"part":   "mesh",
+		"tool-x": "axes",
+		"tool-z": "axes",
+		"crate":  "box",
+		"path":   "line",
+		"dock":   "label",
+		"target": "arrow",
+	}
+	for name, shapeType := range expected {
+		test.That(t, frames, test.ShouldContainKey, name)
+		transform, err := service.GetTransform(ctx, frames[name], nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, transform.Metadata.AsMap()["type"], test.ShouldEqual, shapeType)
+	}
+
+	result, err = service.DoCommand(ctx, map[string]any{"list": true})
+	test.That(t, err, test.ShouldBeNil)
+	items := result["items"].([]any)
+	test.That(t, items, test.ShouldHaveLength, 6)
+	test.That(t, items[0].(map[string]any)["name"], test.ShouldEqual, "crate")
+	test.That(t, items[5].(map[string]any)["type"], test.ShouldEqual, "axes")
+
+	result, err = service.DoCommand(ctx, map[string]any{"clear": true})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, result["removed"], test.ShouldEqual, 6)
+
+	uuids, err := service.ListUUIDs(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, uuids, test.ShouldBeEmpty)
+}
+
+func TestDrawConflicts(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	service, err := NewWorldStateService(ctx, nil, worldstatestore.Named("shapes"), &Config{}, logging.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	defer service.Close(ctx)
+
+	id := uuid.New().String()
+	_, err = service.DoCommand(ctx, map[string]any{"draw": map[string]any{"type": "sphere", "radius_mm": 10.0, "uuid": id, "name": "ball"}})
+	test.That(t, err, test.ShouldBeNil)
+
+	_, err = service.DoCommand(ctx, map[string]any{"draw": map[string]any{"type": "sphere", "radius_mm": 20.0, "uuid": id}})
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "use replace")
+
+	// the valid label is not drawn because the sphere in the same batch conflicts
+	result, err := service.DoCommand(ctx, map[string]any{"draw": []any{
+		map[string]any{"type": "label", "pose": map[string]any{}, "text": "new"},
+		map[string]any{"type": "sphere", "radius_mm": 20.0, "name": "ball"},
+	}})
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, result["success"], test.ShouldEqual, false)
+	test.That(t, result["code"], test.ShouldEqual, "invalid_argument")
+	test.That(t, result["path"], test.ShouldEqual, "name")
+	test.That(t, result["index"], test.ShouldEqual, 1)
+
+	_, err = service.DoCommand(ctx, map[string]any{"draw": map[string]any{"type": "sphere", "radius_mm": 10.0, "uuid": "not-a-uuid"}})
+	test.That(t, errors.Is(err, lib.ErrInvalidArgument), test.ShouldBeTrue)
+
+	// the index of a shape that fails to build is in the index field rather than the message
+	result, err = service.DoCommand(ctx, map[string]any{"draw": []any{
+		map[string]any{"type": "label", "pose": map[string]any{}, "text": "new"},
+		map[string]any{"type": "mesh", "model_path": "/missing.ply"},
+	}})
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, result["error"], test.ShouldStartWith, "Failed to build mesh: ")
+	test.That(t, result["code"], test.ShouldEqual, "file_not_found")
+	test.That(t, result["index"], test.ShouldEqual, 1)
+
+	_, err = service.DoCommand(ctx, map[string]any{"draw": map[string]any{"type": "teapot"}})
+	test.That(t, errors.Is(err, lib.ErrInvalidArgument), test.ShouldBeTrue)
+
+	result, err = service.DoCommand(ctx, map[string]any{"remove": map[string]any{"names": []any{"ball", "crate"}}})
+	test.That(t, errors.Is(err, lib.ErrNotFound), test.ShouldBeTrue)
+	test.That(t, result["code"], test.ShouldEqual, "not_found")
+
+	_, err = service.DoCommand(ctx, map[string]any{"unknown": true})
+	test.That(t, errors.Is(err, lib.ErrInvalidArgument), test.ShouldBeTrue)
+
+	uuids, err := service.ListUUIDs(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, uuids, test.ShouldHaveLength, 1)
+}
+
+func TestReplaceAndRemove(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	service, err := NewWorldStateService(ctx, nil, worldstatestore.Named("shapes"), &Config{}, logging.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	defer service.Close(ctx)
+
+	stream, err := service.StreamTransformChanges(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+
+	_, err = service.DoCommand(ctx, map[string]any{"draw": []any{
+		map[string]any{"type": "box", "dims_mm": map[string]any{"x": 10.0, "y": 10.0, "z": 10.0}, "name": "crate"},
+		map[string]any{"type": "axes", "pose": map[string]any{"o_z": 1.0}, "name": "tool"},
+	}})
+	test.That(t, err, test.ShouldBeNil)
+	frames, _ := collect(t, stream, 1+3)
+
+	// replacing by name keeps the name and UUID, and may change the type
+	result, err := service.DoCommand(ctx, map[string]any{"replace": map[string]any{"name": "crate", "type": "sphere", "radius_mm": 30.0}})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, result["name"], test.ShouldEqual, "crate")
+	test.That(t, result["type"], test.ShouldEqual, "sphere")
+
+	change, err := stream.Next()
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, change.ChangeType, test.ShouldEqual, v1.TransformChangeType_TRANSFORM_CHANGE_TYPE_UPDATED)
+	test.That(t, change.Transform.Uuid, test.ShouldResemble, frames["crate"])
+	test.That(t, change.UpdatedFields, test.ShouldContain, "physicalObject")
+
+	_, err = service.DoCommand(ctx, map[string]any{"replace": map[string]any{"name": "missing", "type": "sphere", "radius_mm": 30.0}})
+	test.That(t, err, test.ShouldNotBeNil)
+
+	// removing one arrow of an axes triad removes the whole triad
+	id, err := uuid.FromBytes(frames["tool-y"])
+	test.That(t, err, test.ShouldBeNil)
+	result, err = service.DoCommand(ctx, map[string]any{"remove": map[string]any{"uuids": []any{id.String()}}})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, result["removed"], test.ShouldEqual, 1)
+
+	_, changeType := collect(t, stream, 3)
+	test.That(t, changeType, test.ShouldEqual, v1.TransformChangeType_TRANSFORM_CHANGE_TYPE_REMOVED)
+
+	result, err = service.DoCommand(ctx, map[string]any{"remove": map[string]any{"names": []any{"crate"}}})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, result["removed"], test.ShouldEqual, 1)
+
+	_, err = service.DoCommand(ctx, map[string]any{"remove": map[string]any{"names": []any{"crate"}}})
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+func TestUpsert(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	service := New(worldstatestore.Named("shapes"), Options{Upsert: true, DuplicateNames: true}, logging.NewTestLogger(t))
+	defer service.Close(ctx)
+
+	stream, err := service.StreamTransformChanges(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+
+	id := uuid.New().String()
+	draw := func(x float64) {
+		_, err := service.DoCommand(ctx, map[string]any{"draw": []any{
+			map[string]any{"type": "arrow", "pose": map[string]any{"x": x, "o_z": 1.0}, "uuid": id, "name": "target"},
+			map[string]any{"type": "arrow", "pose": map[string]any{"o_z": 1.0}, "name": "target"},
+		}})
+		test.That(t, err, test.ShouldBeNil)
+	}
+
+	draw(0)
+	_, changeType := collect(t, stream, 2)
+	test.That(t, changeType, test.ShouldEqual, v1.TransformChangeType_TRANSFORM_CHANGE_TYPE_ADDED)
+
+	// drawing the same UUID again moves the arrow, and arrows may share names
+	draw(100)
+	change, err := stream.Next()
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, change.ChangeType, test.ShouldEqual, v1.TransformChangeType_TRANSFORM_CHANGE_TYPE_UPDATED)
+	test.That(t, change.Transform.PoseInObserverFrame.Pose.X, test.ShouldEqual, 100.0)
+
+	test.That(t, service.List(), test.ShouldHaveLength, 3)
+}
+
+func TestDrawPattern(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	dir := t.TempDir()
+	for _, name := range []string{"a.ply", "b.ply"} {
+		test.That(t, os.WriteFile(filepath.Join(dir, name), []byte(testPLY), 0o644), test.ShouldBeNil)
+	}
+	test.That(t, os.WriteFile(filepath.Join(dir, "broken.ply"), []byte("not a mesh"), 0o644), test.ShouldBeNil)
+
+	conf := &Config{Scenes: map[string]Scene{"parts": {Meshes: []map[string]any{{"model_path": filepath.Join(dir, "*.ply")}}}}}
+	service, err := NewWorldStateService(ctx, nil, worldstatestore.Named("shapes"), conf, logging.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	defer service.Close(ctx)
+
+	// a file that fails is reported without stopping the other files or shapes
+	result, err := service.DoCommand(ctx, map[string]any{"draw": []any{
+		map[string]any{"type": "box", "dims_mm": map[string]any{"x": 10.0, "y": 10.0, "z": 10.0}, "name": "table"},
+		map[string]any{"type": "mesh", "model_path": dir},
+	}})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, result["added"], test.ShouldEqual, 3)
+	test.That(t, result["failed"], test.ShouldEqual, 1)
+	results := result["results"].([]any)
+	test.That(t, results, test.ShouldHaveLength, 3)
+	for _, data := range results {
+		fields := data.(map[string]any)
+		broken := fields["model_path"] == filepath.Join(dir, "broken.ply")
+		test.That(t, fields["success"], test.ShouldEqual, !broken)
+		if broken {
+			test.That(t, fields["index"], test.ShouldEqual, 1)
+		}
+	}
+
+	// files already drawn conflict, so a second draw of the pattern fails as a whole
+	result, err = service.DoCommand(ctx, map[string]any{"draw": map[string]any{"type": "mesh", "model_path": dir}})
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, result["index"], test.ShouldEqual, 0)
+	test.That(t, result["error"], test.ShouldStartWith, "Failed to draw any of the 3 meshes matching")
+
+	_, err = service.DoCommand(ctx, map[string]any{"clear": true})
+	test.That(t, err, test.ShouldBeNil)
+
+	// scenes draw patterns best effort too
+	result, err = service.DoCommand(ctx, map[string]any{"set_scene": "parts"})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, result["added"], test.ShouldEqual, 2)
+	test.That(t, result["failed"], test.ShouldEqual, 1)
+	test.That(t, result["results"], test.ShouldHaveLength, 3)
+}
+
+func TestWatch(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	path := writeMesh(t)
+	conf := &Config{WatchIntervalMs: 5, WatchDebounceMs: 20}
+	service, err := NewWorldStateService(ctx, nil, worldstatestore.Named("shapes"), conf, logging.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	defer service.Close(ctx)
+
+	result, err := service.DoCommand(ctx, map[string]any{"draw": map[string]any{"type": "mesh", "model_path": path, "name": "part", "watch": true}})
+	test.That(t, err, test.ShouldBeNil)
+	id := uuid.MustParse(result["items"].([]any)[0].(map[string]any)["uuid"].(string))
+
+	stream, err := service.StreamTransformChanges(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+
+	// rewriting the file reloads the mesh in place; the size changes so the rewrite is seen whatever the mtime resolution
+	moved := []byte(strings.Replace(testPLY, "0 1 0", "0 10 0", 1))
+	test.That(t, os.WriteFile(path, moved, 0o644), test.ShouldBeNil)
+
+	change, err := stream.Next()
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, change.ChangeType, test.ShouldEqual, v1.TransformChangeType_TRANSFORM_CHANGE_TYPE_UPDATED)
+	test.That(t, change.Transform.Uuid, test.ShouldResemble, id[:])
+	test.That(t, change.UpdatedFields, test.ShouldResemble, []string{"physicalObject"})
+}
+
+func TestValidate(t *testing.T) {
+	_, _, err := (&Config{Shapes: []map[string]any{{"type": "mesh", "model_path": writeMesh(t)}}}).Validate("services.0")
+	test.That(t, err, test.ShouldBeNil)
+
+	_, _, err = (&Config{Shapes: []map[string]any{{"type": "mesh", "model_path": "/missing/part.ply"}}}).Validate("services.0")
+	test.That(t, errors.Is(err, lib.ErrFileNotFound), test.ShouldBeTrue)
+
+	_, _, err = (&Config{Shapes: []map[string]any{{"type": "teapot"}}}).Validate("services.0")
+	test.That(t, err, test.ShouldNotBeNil)
+
+	_, _, err = (&Config{CacheMaxBytes: -1}).Validate("services.0")
+	test.That(t, err, test.ShouldNotBeNil)
+
+	tests := []struct {
+		name  string
+		conf  *Config
+		valid bool
+	}{
+		{"scene", &Config{Scene: "day", Scenes: map[string]Scene{"day": {Primitives: []map[string]any{
+			{"type": "sphere", "radius_mm": 5.0, "name": "lamp"},
+		}}}}, true},
+		{"unknown startup scene", &Config{Scene: "night", Scenes: map[string]Scene{"day": {}}}, false},
+		{"unnamed shape", &Config{Scenes: map[string]Scene{"day": {Primitives: []map[string]any{
+			{"type": "sphere", "radius_mm": 5.0},
+		}}}}, false},
+		{"arrow among primitives", &Config{Scenes: map[string]Scene{"day": {Primitives: []map[string]any{
+			{"type": "arrow", "pose": map[string]any{}, "name": "north"},
+		}}}}, false},
+		{"missing mesh", &Config{Scenes: map[string]Scene{"day": {Meshes: []map[string]any{
+			{"model_path": "/missing/part.ply", "name": "part"},
+		}}}}, false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, _, err := tc.conf.Validate("services.0")
+			if tc.valid {
+				test.That(t, err, test.ShouldBeNil)
+			} else {
+				test.That(t, err, test.ShouldNotBeNil)
+			}
+		})
+	}
+}
+
+func TestHelp(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	service, err := NewWorldStateService(ctx, nil, worldstatestore.Named("shapes"), &Config{}, logging.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	defer service.Close(ctx)
+
+	result, err := service.DoCommand(ctx, map[string]any{"help": true})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, result["model"], test.ShouldEqual, WorldState.String())
+
+	names := []any{}
+	for _, described := range result["commands"].([]any) {
+		names = append(names, described.(map[string]any)["name"])
+	}
+	test.That(t, names, test.ShouldResemble, []any{"draw", "replace", "remove", "list", "cache_stats", "clear", "set_scene", "hide", "show", "stats", "help", "describe", "validate"})
+
+	// validating parses every command without drawing anything
+	result, err = service.DoCommand(ctx, map[string]any{"validate": map[string]any{
+		"draw": []any{
+			map[string]any{"type": "box", "dims_mm": map[string]any{"x": 10.0, "y": 10.0, "z": 10.0}},
+			map[string]any{"type": "mesh", "model_path": "/missing/part.ply"},
+		},
+		"remove": map[string]any{"names": []any{"crate"}},
+	}})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, result["valid"], test.ShouldBeFalse)
+	test.That(t, result["errors"], test.ShouldHaveLength, 1)
+	test.That(t, result["errors"].([]any)[0].(map[string]any)["command"], test.ShouldEqual, "draw")
+
+	result, err = service.DoCommand(ctx, map[string]any{"validate": map[string]any{
+		"draw": []any{map[string]any{"type": "mesh", "model_path": writeMesh(t)}},
+	}})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, result["valid"], test.ShouldBeTrue)
+
+	uuids, err := service.ListUUIDs(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, uuids, test.ShouldBeEmpty)
+}
+
+func TestStats(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	conf := &Config{Shapes: []map[string]any{
+		{"type": "mesh", "model_path": writeMesh(t), "name": "part", "layer": "fixtures"},
+		{"type": "axes", "pose": map[string]any{"o_z": 1.0}, "name": "tool"},
+	}}
+
+	service, err := NewWorldStateService(ctx, nil, worldstatestore.Named("shapes"), conf, logging.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	defer service.Close(ctx)
+
+	transform, err := service.GetTransform(ctx, service.(*Shapes).Named("part")[0].Uuid, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, transform.Metadata.Fields["layer"].GetStringValue(), test.ShouldEqual, "fixtures")
+
+	_, removeErr := service.DoCommand(ctx, map[string]any{"remove": map[string]any{"uuids": []any{"not-a-uuid"}}})
+	test.That(t, removeErr, test.ShouldNotBeNil)
+
+	result, err := service.DoCommand(ctx, map[string]any{"stats": true})
+	test.That(t, err, test.ShouldBeNil)
+	stats := result["stats"].(map[string]any)
+	test.That(t, stats["transforms"], test.ShouldEqual, 4)
+	test.That(t, stats["by_type"], test.ShouldResemble, map[string]any{"mesh": 1, "axes": 3})
+	test.That(t, stats["by_layer"], test.ShouldResemble, map[string]any{"fixtures": 1, "default": 3})
+	test.That(t, stats["events_emitted"], test.ShouldEqual, int64(4))
+	test.That(t, stats["last_error"], test.ShouldEqual, removeErr.Error())
+}
+
+func TestVisibility(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	conf := &Config{Shapes: []map[string]any{
+		{"type": "axes", "pose": map[string]any{"o_z": 1.0}, "name": "tool"},
+		{"type": "box", "dims_mm": map[string]any{"x": 10.0, "y": 10.0, "z": 10.0}, "name": "crate", "layer": "fixtures"},
+	}}
+
+	service, err := NewWorldStateService(ctx, nil, worldstatestore.Named("shapes"), conf, logging.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	defer service.Close(ctx)
+
+	stream, err := service.StreamTransformChanges(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+
+	// the name of an axes triad hides its three arrows
+	result, err := service.DoCommand(ctx, map[string]any{"hide": map[string]any{"names": []any{"tool"}}})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, result["changed"], test.ShouldEqual, 3)
+	for range 3 {
+		change, err := stream.Next()
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, change.ChangeType, test.ShouldEqual, v1.TransformChangeType_TRANSFORM_CHANGE_TYPE_UPDATED)
+		test.That(t, change.UpdatedFields, test.ShouldResemble, []string{"metadata"})
+		test.That(t, change.Transform.Metadata.Fields["visible"].GetBoolValue(), test.ShouldBeFalse)
+	}
+
+	result, err = service.DoCommand(ctx, map[string]any{"hide": map[string]any{"layers": []any{"fixtures"}}})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, result["changed"], test.ShouldEqual, 1)
+
+	// replacing a hidden shape keeps it hidden
+	_, err = service.DoCommand(ctx, map[string]any{"replace": map[string]any{
+		"type": "box", "dims_mm": map[string]any{"x": 20.0, "y": 10.0, "z": 10.0}, "name": "crate", "layer": "fixtures",
+	}})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, lib.TransformVisible(service.(*Shapes).Named("crate")[0]), test.ShouldBeFalse)
+
+	result, err = service.DoCommand(ctx, map[string]any{"show": "all"})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, result["changed"], test.ShouldEqual, 4)
+
+	result, err = service.DoCommand(ctx, map[string]any{"hide": map[string]any{"names": []any{"missing"}}})
+	test.That(t, errors.Is(err, lib.ErrNotFound), test.ShouldBeTrue)
+	test.That(t, result["success"], test.ShouldBeFalse)
+
+	_, err = service.DoCommand(ctx, map[string]any{"show": map[string]any{}})
+	test.That(t, errors.Is(err, lib.ErrInvalidArgument), test.ShouldBeTrue)
+}
+
+func TestSetScene(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	box := map[string]any{"type": "box", "dims_mm": map[string]any{"x": 10.0, "y": 10.0, "z": 10.0}, "name": "table"}
+	conf := &Config{
+		Scene: "day",
+		Scenes: map[string]Scene{
+			"day": {
+				Arrows: []map[string]any{{"pose": map[string]any{"x": 100.0}, "name": "north"}},
+				Primitives: []map[string]any{
+					box,
+					{"type": "sphere", "radius_mm": 5.0, "name": "lamp"},
+				},
+			},
+			"night": {
+				Primitives: []map[string]any{
+					box,
+					{"type": "sphere", "radius_mm": 8.0, "name": "lamp"},
+					{"type": "capsule", "radius_mm": 5.0, "length_mm": 20.0, "name": "guard"},
+				},
+			},
+			"broken": {
+				Arrows: []map[string]any{{"pose": map[string]any{"x": 100.0}, "name": "north"}},
+				Meshes: []map[string]any{{"model_path": filepath.Join(t.TempDir(), "missing.ply"), "name": "missing"}},
+			},
+		},
+	}
+
+	service, err := NewWorldStateService(ctx, nil, worldstatestore.Named("shapes"), conf, logging.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	defer service.Close(ctx)
+
+	shapes := service.(*Shapes)
+	test.That(t, shapes.Scene(), test.ShouldEqual, "day")
+	test.That(t, shapes.Scenes(), test.ShouldResemble, []string{"day", "night"})
+	table := shapes.Named("table")[0].Uuid
+
+	stream, err := service.StreamTransformChanges(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+
+	// the unchanged table emits nothing
+	result, err := service.DoCommand(ctx, map[string]any{"set_scene": "night"})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, result, test.ShouldResemble, map[string]any{
+		"success": true, "scene": "night", "added": 1, "updated": 1, "removed": 1, "failed": 0, "results": []any{},
+	})
+
+	changes := map[string]v1.TransformChangeType{}
+	for range 3 {
+		change, err := stream.Next()
+		test.That(t, err, test.ShouldBeNil)
+		changes[change.Transform.ReferenceFrame] = change.ChangeType
+	}
+	test.That(t, changes, test.ShouldResemble, map[string]v1.TransformChangeType{
+		"north": v1.TransformChangeType_TRANSFORM_CHANGE_TYPE_REMOVED,
+		"lamp":  v1.TransformChangeType_TRANSFORM_CHANGE_TYPE_UPDATED,
+		"guard": v1.TransformChangeType_TRANSFORM_CHANGE_TYPE_ADDED,
+	})
+	test.That(t, shapes.Named("table")[0].Uuid, test.ShouldResemble, table)
+
+	// setting the same scene again changes nothing, so the next change is that of a drawn shape
+	result, err = service.DoCommand(ctx, map[string]any{"set_scene": "night"})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, result["added"], test.ShouldEqual, 0)
+	test.That(t, result["updated"], test.ShouldEqual, 0)
+	test.That(t, result["removed"], test.ShouldEqual, 0)
+
+	_, err = service.DoCommand(ctx, map[string]any{"draw": map[string]any{"type": "sphere", "radius_mm": 5.0, "name": "moon"}})
+	test.That(t, err, test.ShouldBeNil)
+	frames, changeType := collect(t, stream, 1)
+	test.That(t, frames, test.ShouldContainKey, "moon")
+	test.That(t, changeType, test.ShouldEqual, v1.TransformChangeType_TRANSFORM_CHANGE_TYPE_ADDED)
+
+	// a scene shape may not take the name of a shape drawn outside the scenes
+	_, err = service.DoCommand(ctx, map[string]any{"draw": map[string]any{"type": "box", "dims_mm": map[string]any{"x": 1.0, "y": 1.0, "z": 1.0}, "name": "north"}})
+	test.That(t, err, test.ShouldBeNil)
+	_, err = service.DoCommand(ctx, map[string]any{"set_scene": "day"})
+	test.That(t, errors.Is(err, lib.ErrInvalidArgument), test.ShouldBeTrue)
+	test.That(t, shapes.Scene(), test.ShouldEqual, "night")
+
+	_, err = service.DoCommand(ctx, map[string]any{"set_scene": "dusk"})
+	test.That(t, errors.Is(err, lib.ErrNotFound), test.ShouldBeTrue)
+
+	// a shape that fails to build is named, and its index is only given once
+	result, err = service.DoCommand(ctx, map[string]any{"set_scene": "broken"})
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, result["error"], test.ShouldStartWith, `Failed to build mesh "missing": `)
+	test.That(t, result["code"], test.ShouldEqual, string(lib.ErrFileNotFound))
+	test.That(t, result["index"], test.ShouldEqual, 1)
+
+	_, err = service.DoCommand(ctx, map[string]any{"set_scene": 3})
+	test.That(t, errors.Is(err, lib.ErrInvalidArgument), test.ShouldBeTrue)
+}
+
+func FuzzDoCommand(f *testing.F) {
+	service, err := NewWorldStateService(context.Background(), nil, worldstatestore.Named("shapes"), &Config{}, logging.NewTestLogger(f))
+	test.That(f, err, test.ShouldBeNil)
+	f.Cleanup(func() { service.Close(context.Background()) })
+
+	commandtest.Fuzz(f, commands, service.DoCommand)
+}
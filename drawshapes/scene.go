@@ -0,0 +1,261 @@
+package drawshapes
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/viam-labs/draw-tools/lib"
+
+	"github.com/google/uuid"
+)
+
+// Scene is a named set of shapes drawn together. Setting a scene draws its shapes in place of those of the previous
+// scene; shapes are matched across scenes by name, so a shape both scenes share is updated rather than redrawn.
+type Scene struct {
+	Arrows     []map[string]any `json:"arrows,omitempty"`     // Arrows, or axes triads with axes set, as drawn by the arrows service
+	Meshes     []map[string]any `json:"meshes,omitempty"`     // Meshes, as drawn by the mesh service
+	Primitives []map[string]any `json:"primitives,omitempty"` // Boxes, spheres, capsules and cylinders, each with a "type" field
+}
+
+// Parse parses the shapes of the scene, arrows first, then meshes, then primitives. Every shape needs a name, except
+// meshes loaded from a directory or glob pattern, which are named after their files.
+//
+// Returns the shapes or an error naming the first shape that fails to parse.
+func (scene *Scene) Parse() ([]*lib.ShapeJSON, error) {
+	groups := []struct {
+		name  string
+		items []map[string]any
+		kind  func(map[string]any) string
+	}{
+		{"arrows", scene.Arrows, arrowType},
+		{"meshes", scene.Meshes, func(map[string]any) string { return lib.ShapeMesh }},
+		{"primitives", scene.Primitives, primitiveType},
+	}
+
+	shapes := make([]*lib.ShapeJSON, 0, len(scene.Arrows)+len(scene.Meshes)+len(scene.Primitives))
+	for _, group := range groups {
+		for i, item := range group.items {
+			fields := maps.Clone(item)
+			if fields == nil {
+				fields = map[string]any{}
+			}
+			fields["type"] = group.kind(fields)
+
+			shape, err := lib.ParseShape(fields)
+			if err == nil && group.name == "primitives" && !lib.IsPrimitiveType(shape.Type) {
+				err = lib.FieldErrorf("type", "Expected one of box, sphere, capsule or cylinder, got %q", shape.Type)
+			}
+			if err == nil && group.name == "arrows" && shape.Arrow == nil {
+				err = lib.FieldErrorf("type", "Expected arrow or axes, got %q", shape.Type)
+			}
+			if err == nil {
+				if _, name := identity(shape); *name == "" && (shape.Mesh == nil || !lib.IsMeshPattern(shape.Mesh.ModelPath)) {
+					err = lib.FieldErrorf("name", "Missing required 'name' field")
+				}
+			}
+			if err != nil {
+				return nil, fmt.Errorf("Failed to parse %s[%d]: %w", group.name, i, err)
+			}
+
+			shapes = append(shapes, shape)
+		}
+	}
+
+	return shapes, nil
+}
+
+// arrowType returns the shape type of a scene arrow: "axes" when its axes flag is set, unless it names its type.
+func arrowType(item map[string]any) string {
+	if shapeType, ok := item["type"].(string); ok && shapeType != "" {
+		return shapeType
+	}
+
+	if axes, _ := item["axes"].(bool); axes {
+		return lib.ShapeAxes
+	}
+
+	return lib.ShapeArrow
+}
+
+// primitiveType returns the shape type of a scene primitive, which must name its type.
+func primitiveType(item map[string]any) string {
+	shapeType, _ := item["type"].(string)
+	return shapeType
+}
+
+// SceneChanges counts the items setting a scene added, updated and removed. Items the scenes share unchanged are not
+// counted. Files matched by a mesh directory or glob pattern of the scene are drawn best effort, as by Draw.
+type SceneChanges struct {
+	Scene   string        `json:"scene"`
+	Added   int           `json:"added"`
+	Updated int           `json:"updated"`
+	Removed int           `json:"removed"`
+	Files   []*FileResult `json:"-"`
+}
+
+// ToMap describes the changes in DoCommand responses.
+func (changes *SceneChanges) ToMap() map[string]any {
+	return map[string]any{
+		"scene":   changes.Scene,
+		"added":   changes.Added,
+		"updated": changes.Updated,
+		"removed": changes.Removed,
+		"failed":  failed(changes.Files),
+		"results": fileMaps(changes.Files),
+	}
+}
+
+// Scenes lists the names of the configured scenes, sorted.
+func (s *Shapes) Scenes() []string {
+	return slices.Sorted(maps.Keys(s.scenes))
+}
+
+// Scene returns the name of the scene set last, or an empty string if none has been set.
+func (s *Shapes) Scene() string {
+	s.itemsMutex.RLock()
+	defer s.itemsMutex.RUnlock()
+
+	return s.scene
+}
+
+// SetScene draws the shapes of the named scene in place of those of the previous scene. Shapes of the previous scene
+// missing from the new one are REMOVED, new ones are ADDED, and shared ones that differ are UPDATED with the fields
+// that changed; shared ones that do not differ emit nothing. Each scene shape keeps a UUID derived from its name unless
+// it sets one. Files matched by a mesh directory or glob pattern are drawn best effort, as by Draw; nothing changes if
+// any other shape fails to build or conflicts with an item drawn outside the scenes.
+func (s *Shapes) SetScene(name string) (*SceneChanges, error) {
+	shapes, ok := s.scenes[name]
+	if !ok {
+		return nil, lib.Errorf(lib.ErrNotFound, "scene not found: %s", name)
+	}
+
+	names := make(map[string]struct{}, len(shapes))
+	identify := func(shape *lib.ShapeJSON) (*lib.ShapeJSON, error) {
+		id, shapeName := identity(shape)
+		if _, ok := names[*shapeName]; ok {
+			return nil, lib.FieldErrorf("name", "%s named %q is drawn twice", shape.Type, *shapeName)
+		}
+		names[*shapeName] = struct{}{}
+
+		derived := lib.DeriveUUID(s.sceneBase, *shapeName)
+		return withIdentity(shape, cmp.Or(*id, derived.String()), *shapeName), nil
+	}
+
+	built, files, err := s.prepare(shapes, identify, sceneBuildError)
+	if err != nil {
+		return nil, err
+	}
+
+	s.itemsMutex.Lock()
+	defer s.itemsMutex.Unlock()
+
+	next := make(map[string]struct{}, len(built))
+	for _, p := range built {
+		if err := s.sceneConflict(p.item, next); err != nil {
+			if p.fail(err) {
+				continue
+			}
+			return nil, lib.AtIndex(p.index, err)
+		}
+
+		next[p.item.UUID] = struct{}{}
+	}
+
+	if err := patternFailure(shapes, files); err != nil {
+		return nil, err
+	}
+
+	// count the changes before the items are committed
+	changes := &SceneChanges{Scene: name, Files: files}
+	for _, p := range built {
+		if p.file != nil && p.file.Err != nil {
+			continue
+		}
+
+		previous, ok := s.items[p.item.UUID]
+		switch {
+		case !ok:
+			changes.Added++
+		case itemChanged(previous, p.item):
+			changes.Updated++
+		}
+	}
+
+	removed := []string{}
+	for id := range s.sceneItems {
+		// scene items may have been removed by other commands since
+		if _, ok := next[id]; !ok && s.items[id] != nil {
+			removed = append(removed, id)
+		}
+	}
+
+	// a scene item removed here may hold a name an item of the new scene takes
+	for _, id := range removed {
+		s.delete(id)
+	}
+
+	s.commitBuilt(built, files)
+	changes.Removed = len(removed)
+	s.scene = name
+	s.sceneItems = next
+	return changes, nil
+}
+
+// sceneBuildError describes a scene shape that failed to build by its name. Its index, kept by AtIndex, counts the
+// shapes of every group of the scene, so the message leaves it out.
+func sceneBuildError(_ int, shape *lib.ShapeJSON, err error) error {
+	_, name := identity(shape)
+	return fmt.Errorf("Failed to build %s %q: %w", shape.Type, *name, err)
+}
+
+// sceneConflict checks that an item of a scene can be drawn next to the items drawn outside the scenes and to the other
+// items of the scene, whose UUIDs are given. Must be called with itemsMutex held.
+func (s *Shapes) sceneConflict(item *Item, ids map[string]struct{}) error {
+	if _, ok := ids[item.UUID]; ok {
+		return lib.FieldErrorf("uuid", "%s with UUID %s is drawn twice", item.Type, item.UUID)
+	}
+
+	if _, ok := s.items[item.UUID]; ok && !s.fromScene(item.UUID) {
+		return lib.FieldErrorf("uuid", "%s with UUID %s is already drawn outside the scenes", item.Type, item.UUID)
+	}
+
+	for _, transform := range item.Transforms {
+		id, _ := uuid.FromBytes(transform.Uuid)
+		if owner, ok := s.owners[id.String()]; ok && owner != item.UUID && !s.fromScene(owner) {
+			return lib.FieldErrorf("uuid", "%s with UUID %s is already drawn as part of %s", item.Type, id, owner)
+		}
+	}
+
+	if owner, ok := s.names[item.Name]; ok && owner != item.UUID && !s.fromScene(owner) && !s.options.DuplicateNames {
+		return lib.FieldErrorf("name", "%s named %q is already drawn outside the scenes", item.Type, item.Name)
+	}
+
+	return nil
+}
+
+// fromScene reports whether the item with the given UUID was drawn by SetScene. Must be called with itemsMutex held.
+func (s *Shapes) fromScene(id string) bool {
+	_, ok := s.sceneItems[id]
+	return ok
+}
+
+// itemChanged reports whether committing next in place of previous changes any transform.
+func itemChanged(previous, next *Item) bool {
+	if len(previous.Transforms) != len(next.Transforms) {
+		return true
+	}
+
+	for i, transform := range next.Transforms {
+		if string(previous.Transforms[i].Uuid) != string(transform.Uuid) {
+			return true
+		}
+
+		if len(lib.UpdatedFields(previous.Transforms[i], transform)) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
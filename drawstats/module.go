@@ -0,0 +1,141 @@
+// Package drawstats reports the stats of draw-tools world state store services as sensor readings, so data capture can
+// chart what they hold and how their changes were delivered.
+package drawstats
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/viam-labs/draw-tools/client"
+	"github.com/viam-labs/draw-tools/lib"
+	"github.com/viam-labs/draw-tools/lib/command"
+	"github.com/viam-labs/draw-tools/lib/store"
+
+	"go.viam.com/rdk/components/sensor"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+)
+
+var (
+	Stats = resource.NewModel("viam-viz", "draw-tools", "draw-tools-stats")
+)
+
+func init() {
+	resource.RegisterComponent(sensor.API, Stats,
+		resource.Registration[sensor.Sensor, *Config]{
+			Constructor: newStatsSensor,
+		},
+	)
+}
+
+type Config struct {
+	Services []string `json:"services"` // Names of the draw-tools world state store services to report (required)
+}
+
+func (cfg *Config) Validate(path string) ([]string, []string, error) {
+	if len(cfg.Services) == 0 {
+		return nil, nil, resource.NewConfigValidationFieldRequiredError(path, "services")
+	}
+
+	seen := make(map[string]struct{}, len(cfg.Services))
+	for i, name := range cfg.Services {
+		if name == "" {
+			return nil, nil, resource.NewConfigValidationError(path, fmt.Errorf("services[%d] must not be empty", i))
+		}
+
+		if _, ok := seen[name]; ok {
+			return nil, nil, resource.NewConfigValidationError(path, fmt.Errorf("service %q is listed twice", name))
+		}
+		seen[name] = struct{}{}
+	}
+
+	return cfg.Services, nil, nil
+}
+
+type statsSensor struct {
+	resource.AlwaysRebuild
+
+	name   resource.Name
+	logger logging.Logger
+	config *Config
+
+	clients map[string]*client.Client
+}
+
+func newStatsSensor(
+	ctx context.Context,
+	deps resource.Dependencies,
+	rawConf resource.Config,
+	logger logging.Logger,
+) (sensor.Sensor, error) {
+	conf, err := resource.NativeConfig[*Config](rawConf)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewStatsSensor(ctx, deps, rawConf.ResourceName(), conf, logger)
+}
+
+func NewStatsSensor(
+	ctx context.Context,
+	deps resource.Dependencies,
+	name resource.Name,
+	conf *Config,
+	logger logging.Logger,
+) (sensor.Sensor, error) {
+	clients := make(map[string]*client.Client, len(conf.Services))
+	for _, serviceName := range conf.Services {
+		serviceClient, err := client.FromDependencies(deps, serviceName)
+		if err != nil {
+			return nil, err
+		}
+		clients[serviceName] = serviceClient
+	}
+
+	return &statsSensor{
+		name:    name,
+		logger:  logger,
+		config:  conf,
+		clients: clients,
+	}, nil
+}
+
+func (s *statsSensor) Name() resource.Name {
+	return s.name
+}
+
+// Readings returns the stats of every configured service added together, with the stats of each service under
+// "services". The last error is that of the last service, in configuration order, that has one.
+func (s *statsSensor) Readings(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
+	total := store.Stats{ByType: map[string]int{}, ByLayer: map[string]int{}}
+	services := make(map[string]any, len(s.config.Services))
+	for _, name := range s.config.Services {
+		stats, err := s.clients[name].Stats(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("Unable to get stats of %s: %w", name, err)
+		}
+
+		total.Merge(*stats)
+		services[name] = stats.ToMap()
+	}
+
+	readings := total.ToMap()
+	readings["services"] = services
+
+	return readings, nil
+}
+
+// commands describes the sensor, which has no commands of its own besides the built-in ones.
+var commands = &command.Set{Model: Stats}
+
+func (s *statsSensor) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	if result, ok, err := commands.Handle(cmd); ok {
+		return result, err
+	}
+
+	return nil, lib.Errorf(lib.ErrInvalidArgument, "Unknown command")
+}
+
+func (s *statsSensor) Close(context.Context) error {
+	return nil
+}
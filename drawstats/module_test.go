@@ -0,0 +1,113 @@
+package drawstats
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/viam-labs/draw-tools/drawshapes"
+	"github.com/viam-labs/draw-tools/lib/command/commandtest"
+	"go.viam.com/rdk/components/sensor"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	worldstatestore "go.viam.com/rdk/services/worldstatestore"
+	"go.viam.com/rdk/testutils/inject"
+	"go.viam.com/test"
+)
+
+func TestValidate(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		services []string
+		valid    bool
+	}{
+		{name: "services", services: []string{"shapes", "camera"}, valid: true},
+		{name: "no services"},
+		{name: "empty name", services: []string{"shapes", ""}},
+		{name: "listed twice", services: []string{"shapes", "shapes"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			deps, _, err := (&Config{Services: tc.services}).Validate("path")
+			if !tc.valid {
+				test.That(t, err, test.ShouldNotBeNil)
+				return
+			}
+
+			test.That(t, err, test.ShouldBeNil)
+			test.That(t, deps, test.ShouldResemble, tc.services)
+		})
+	}
+}
+
+func TestReadings(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	logger := logging.NewTestLogger(t)
+	shapes, err := drawshapes.NewWorldStateService(ctx, nil, worldstatestore.Named("shapes"), &drawshapes.Config{Shapes: []map[string]any{
+		{"type": "axes", "pose": map[string]any{"o_z": 1.0}, "name": "tool", "layer": "robot"},
+	}}, logger)
+	test.That(t, err, test.ShouldBeNil)
+	defer shapes.Close(ctx)
+
+	boxes, err := drawshapes.NewWorldStateService(ctx, nil, worldstatestore.Named("boxes"), &drawshapes.Config{Shapes: []map[string]any{
+		{"type": "box", "dims_mm": map[string]any{"x": 10.0, "y": 10.0, "z": 10.0}, "name": "crate"},
+	}}, logger)
+	test.That(t, err, test.ShouldBeNil)
+	defer boxes.Close(ctx)
+
+	deps := resource.Dependencies{
+		worldstatestore.Named("shapes"): shapes,
+		worldstatestore.Named("boxes"):  boxes,
+	}
+
+	_, err = NewStatsSensor(ctx, deps, sensor.Named("stats"), &Config{Services: []string{"missing"}}, logger)
+	test.That(t, err, test.ShouldNotBeNil)
+
+	stats, err := NewStatsSensor(ctx, deps, sensor.Named("stats"), &Config{Services: []string{"shapes", "boxes"}}, logger)
+	test.That(t, err, test.ShouldBeNil)
+	defer stats.Close(ctx)
+
+	readings, err := stats.Readings(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, readings["transforms"], test.ShouldEqual, 4)
+	test.That(t, readings["by_type"], test.ShouldResemble, map[string]any{"axes": 3, "box": 1})
+	test.That(t, readings["by_layer"], test.ShouldResemble, map[string]any{"robot": 3, "default": 1})
+	test.That(t, readings["events_emitted"], test.ShouldEqual, int64(4))
+	test.That(t, readings["bytes"], test.ShouldBeGreaterThan, 0)
+
+	services := readings["services"].(map[string]any)
+	test.That(t, services, test.ShouldHaveLength, 2)
+	test.That(t, services["boxes"].(map[string]any)["transforms"], test.ShouldEqual, 1)
+
+	// a failed command of any service becomes the last error
+	_, failure := boxes.DoCommand(ctx, map[string]any{"remove": map[string]any{"names": []any{"missing"}}})
+	test.That(t, failure, test.ShouldNotBeNil)
+
+	readings, err = stats.Readings(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, readings["last_error"], test.ShouldEqual, failure.Error())
+}
+
+func TestReadingsUnavailable(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	service := inject.NewWorldStateStoreService("shapes")
+	service.DoFunc = func(ctx context.Context, cmd map[string]any) (map[string]any, error) {
+		return nil, errors.New("connection lost")
+	}
+	deps := resource.Dependencies{worldstatestore.Named("shapes"): service}
+
+	stats, err := NewStatsSensor(ctx, deps, sensor.Named("stats"), &Config{Services: []string{"shapes"}}, logging.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+
+	_, err = stats.Readings(ctx, nil)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "Unable to get stats of shapes")
+}
+
+func FuzzDoCommand(f *testing.F) {
+	commandtest.Fuzz(f, commands, (&statsSensor{}).DoCommand)
+}
@@ -0,0 +1,560 @@
+package drawtrail
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/viam-labs/draw-tools/lib"
+	"github.com/viam-labs/draw-tools/lib/command"
+	"github.com/viam-labs/draw-tools/lib/store"
+
+	"github.com/golang/geo/r3"
+	geo "github.com/kellydunn/golang-geo"
+	commonPB "go.viam.com/api/common/v1"
+	"go.viam.com/rdk/components/movementsensor"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	worldstatestore "go.viam.com/rdk/services/worldstatestore"
+	"go.viam.com/rdk/spatialmath"
+)
+
+var (
+	WorldState = resource.NewModel("viam-viz", "draw-tools", "movement-trail-world-state")
+)
+
+const (
+	// DefaultRateHz is the default number of readings requested from the movement sensor per second.
+	DefaultRateHz = 5.0
+	// DefaultMinSpacingMm is the default distance the sensor must move before another point is recorded.
+	DefaultMinSpacingMm = 50.0
+	// DefaultToleranceMm is the default distance a point may be from the drawn line before it is kept when decimating.
+	DefaultToleranceMm = 20.0
+
+	// SourcePosition records the position reported by the sensor, converting GPS fixes to a local frame.
+	SourcePosition = "position"
+	// SourceVelocity records the position integrated from the linear velocity reported by the sensor.
+	SourceVelocity = "velocity"
+)
+
+func init() {
+	resource.RegisterService(worldstatestore.API, WorldState,
+		resource.Registration[worldstatestore.Service, *Config]{
+			Constructor: newWorldStateService,
+		},
+	)
+}
+
+type Config struct {
+	MovementSensor string    `json:"movement_sensor"`
+	Source         string    `json:"source,omitempty"`          // Record "position" or integrate "velocity" (defaults to position when supported)
+	RateHz         float64   `json:"rate_hz,omitempty"`         // Readings requested per second (defaults to 5)
+	MinSpacingMm   float64   `json:"min_spacing_mm,omitempty"`  // Distance moved before another point is recorded (defaults to 50)
+	ToleranceMm    float64   `json:"tolerance_mm,omitempty"`    // Largest distance a dropped point may be from the drawn line (defaults to 20)
+	ArrowSpacingM  float64   `json:"arrow_spacing_m,omitempty"` // Distance between heading arrows along the path (defaults to 0, no arrows)
+	MaxLengthM     float64   `json:"max_length_m,omitempty"`    // Longest path kept, dropping the oldest points (defaults to 0, no limit)
+	TimeWindowSec  float64   `json:"time_window_sec,omitempty"` // Oldest point kept, in seconds (defaults to 0, no limit)
+	LineWidthMm    float64   `json:"line_width_mm,omitempty"`   // Width of the drawn path (defaults to 20)
+	Color          lib.Color `json:"color,omitempty"`           // Color of the path (defaults to cyan)
+	ParentFrame    string    `json:"parent_frame,omitempty"`    // Frame the path is drawn in (defaults to "world")
+	Name           string    `json:"name,omitempty"`            // Name of the path frame (defaults to "{movement_sensor}-trail")
+	Paused         bool      `json:"paused,omitempty"`          // Wait for a resume command before recording (defaults to false)
+}
+
+func (cfg *Config) Validate(path string) ([]string, []string, error) {
+	if cfg.MovementSensor == "" {
+		return nil, nil, resource.NewConfigValidationFieldRequiredError(path, "movement_sensor")
+	}
+
+	if cfg.Source != "" && cfg.Source != SourcePosition && cfg.Source != SourceVelocity {
+		return nil, nil, resource.NewConfigValidationError(path, fmt.Errorf("source must be %q or %q, got %q", SourcePosition, SourceVelocity, cfg.Source))
+	}
+
+	for field, value := range map[string]float64{
+		"rate_hz":         cfg.RateHz,
+		"min_spacing_mm":  cfg.MinSpacingMm,
+		"tolerance_mm":    cfg.ToleranceMm,
+		"arrow_spacing_m": cfg.ArrowSpacingM,
+		"max_length_m":    cfg.MaxLengthM,
+		"time_window_sec": cfg.TimeWindowSec,
+		"line_width_mm":   cfg.LineWidthMm,
+	} {
+		if value < 0 {
+			return nil, nil, resource.NewConfigValidationError(path, fmt.Errorf("%s must not be negative", field))
+		}
+	}
+
+	return []string{cfg.MovementSensor}, nil, nil
+}
+
+// sample is a recorded position of the sensor in the trail frame.
+type sample struct {
+	at       time.Time
+	position r3.Vector // millimeters
+	heading  r3.Vector // unit vector the sensor faces, zero when the sensor reports no heading
+}
+
+type worldStateService struct {
+	*store.Service
+
+	logger logging.Logger
+	config *Config
+
+	sensor     movementsensor.MovementSensor
+	properties *movementsensor.Properties
+	source     string
+	id         lib.UUID
+	frame      string
+	period     time.Duration
+
+	// position source: the fix the local frame is anchored at
+	origin         *geo.Point
+	originAltitude float64
+	// velocity source: the integrated position and the time it was last advanced
+	integrated   r3.Vector
+	integratedAt time.Time
+
+	samples    []sample
+	arrows     int
+	running    bool
+	trailMutex sync.RWMutex
+}
+
+func newWorldStateService(
+	ctx context.Context,
+	deps resource.Dependencies,
+	rawConf resource.Config,
+	logger logging.Logger,
+) (worldstatestore.Service, error) {
+	conf, err := resource.NativeConfig[*Config](rawConf)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewWorldStateService(ctx, deps, rawConf.ResourceName(), conf, logger)
+}
+
+func NewWorldStateService(
+	ctx context.Context,
+	deps resource.Dependencies,
+	name resource.Name,
+	conf *Config,
+	logger logging.Logger,
+) (worldstatestore.Service, error) {
+	sensor, err := movementsensor.FromDependencies(deps, conf.MovementSensor)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to get movement sensor %v: %w", conf.MovementSensor, err)
+	}
+
+	properties, err := sensor.Properties(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to get properties of movement sensor %v: %w", conf.MovementSensor, err)
+	}
+
+	source := conf.Source
+	if source == "" {
+		source = SourcePosition
+		if !properties.PositionSupported && properties.LinearVelocitySupported {
+			source = SourceVelocity
+		}
+	}
+
+	if source == SourcePosition && !properties.PositionSupported {
+		return nil, fmt.Errorf("movement sensor %v does not report its position", conf.MovementSensor)
+	}
+
+	if source == SourceVelocity && !properties.LinearVelocitySupported {
+		return nil, fmt.Errorf("movement sensor %v does not report its linear velocity", conf.MovementSensor)
+	}
+
+	frame := conf.Name
+	if frame == "" {
+		frame = conf.MovementSensor + "-trail"
+	}
+
+	rate := conf.RateHz
+	if rate <= 0 {
+		rate = DefaultRateHz
+	}
+
+	service := &worldStateService{
+		Service:    store.NewService(name, logger),
+		logger:     logger,
+		config:     conf,
+		sensor:     sensor,
+		properties: properties,
+		source:     source,
+		id:         lib.GenerateUUID(),
+		frame:      frame,
+		period:     time.Duration(float64(time.Second) / rate),
+		running:    !conf.Paused,
+	}
+
+	service.Go(service.poll)
+
+	return service, nil
+}
+
+// poll reads the movement sensor every period while the service is running.
+func (s *worldStateService) poll(ctx context.Context) {
+	ticker := time.NewTicker(s.period)
+	defer ticker.Stop()
+
+	for {
+		s.trailMutex.RLock()
+		running := s.running
+		s.trailMutex.RUnlock()
+
+		if running {
+			if err := s.update(ctx, time.Now()); err != nil && ctx.Err() == nil {
+				s.RecordError(err)
+				s.logger.Warnw("Failed to update trail from movement sensor", "movement_sensor", s.config.MovementSensor, "error", err.Error())
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// update reads the sensor, records a new point once it has moved far enough, trims the history to the configured
+// length and time window, and redraws the trail when it changed.
+func (s *worldStateService) update(ctx context.Context, now time.Time) error {
+	orientation, heading, err := s.heading(ctx)
+	if err != nil {
+		return err
+	}
+
+	var position r3.Vector
+	var origin *geo.Point
+	var originAltitude float64
+	switch s.source {
+	case SourceVelocity:
+		velocity, err := s.sensor.LinearVelocity(ctx, nil)
+		if err != nil {
+			return err
+		}
+
+		// the velocity is reported in the sensor frame, so it is turned into the trail frame when the orientation is known
+		if orientation != nil {
+			velocity = spatialmath.Compose(spatialmath.NewPoseFromOrientation(orientation), spatialmath.NewPoseFromPoint(velocity)).Point()
+		}
+
+		s.trailMutex.RLock()
+		position = s.integrated
+		if !s.integratedAt.IsZero() {
+			position = position.Add(velocity.Mul(now.Sub(s.integratedAt).Seconds() * 1000))
+		}
+		s.trailMutex.RUnlock()
+	default:
+		point, altitude, err := s.sensor.Position(ctx, nil)
+		if err != nil {
+			return err
+		}
+
+		if math.IsNaN(altitude) {
+			altitude = 0
+		}
+
+		s.trailMutex.RLock()
+		origin, originAltitude = s.origin, s.originAltitude
+		s.trailMutex.RUnlock()
+
+		// the local frame is anchored at the first fix
+		if origin == nil {
+			origin, originAltitude = point, altitude
+		}
+		position = lib.GeoToENU(origin, originAltitude, point, altitude)
+	}
+
+	s.trailMutex.Lock()
+	defer s.trailMutex.Unlock()
+
+	// the service may have been paused while the sensor was being read
+	if !s.running {
+		return nil
+	}
+
+	if s.source == SourceVelocity {
+		s.integrated, s.integratedAt = position, now
+	} else {
+		s.origin, s.originAltitude = origin, originAltitude
+	}
+
+	changed := s.record(sample{at: now, position: position, heading: heading})
+	if s.trim(now) {
+		changed = true
+	}
+
+	if !changed {
+		return nil
+	}
+
+	return s.draw()
+}
+
+// heading returns the orientation of the sensor when it reports one and the direction it faces in the trail frame.
+// Sensors report a heading through their orientation or their compass; both are zero when neither is supported.
+func (s *worldStateService) heading(ctx context.Context) (spatialmath.Orientation, r3.Vector, error) {
+	if s.properties.OrientationSupported {
+		orientation, err := s.sensor.Orientation(ctx, nil)
+		if err != nil {
+			return nil, r3.Vector{}, err
+		}
+
+		// bases drive along their y axis
+		forward := spatialmath.Compose(spatialmath.NewPoseFromOrientation(orientation), spatialmath.NewPoseFromPoint(r3.Vector{Y: 1})).Point()
+		return orientation, forward, nil
+	}
+
+	if s.properties.CompassHeadingSupported {
+		compass, err := s.sensor.CompassHeading(ctx, nil)
+		if err != nil {
+			return nil, r3.Vector{}, err
+		}
+
+		// compass headings are clockwise from north, which is the y axis of the local frame
+		radians := compass * math.Pi / 180
+		return nil, r3.Vector{X: math.Sin(radians), Y: math.Cos(radians)}, nil
+	}
+
+	return nil, r3.Vector{}, nil
+}
+
+// record appends a sample once the sensor has moved at least the minimum spacing from the last recorded one.
+// Must be called with trailMutex held.
+func (s *worldStateService) record(next sample) bool {
+	spacing := s.config.MinSpacingMm
+	if spacing <= 0 {
+		spacing = DefaultMinSpacingMm
+	}
+
+	if len(s.samples) > 0 {
+		last := s.samples[len(s.samples)-1]
+		if last.position.Distance(next.position) < spacing {
+			return false
+		}
+
+		// without a reported heading the sensor faces the way it moved
+		if next.heading == (r3.Vector{}) {
+			next.heading = next.position.Sub(last.position).Normalize()
+		}
+	}
+
+	s.samples = append(s.samples, next)
+	return true
+}
+
+// trim drops the oldest samples outside the time window and past the maximum length. Must be called with
+// trailMutex held.
+func (s *worldStateService) trim(now time.Time) bool {
+	dropped := 0
+	if s.config.TimeWindowSec > 0 {
+		oldest := now.Add(-time.Duration(s.config.TimeWindowSec * float64(time.Second)))
+		for dropped < len(s.samples) && s.samples[dropped].at.Before(oldest) {
+			dropped++
+		}
+	}
+
+	if s.config.MaxLengthM > 0 {
+		length := 0.0
+		for i := len(s.samples) - 1; i > dropped; i-- {
+			length += s.samples[i].position.Distance(s.samples[i-1].position)
+			if length > s.config.MaxLengthM*1000 {
+				dropped = i
+				break
+			}
+		}
+	}
+
+	s.samples = s.samples[dropped:]
+	return dropped > 0
+}
+
+// draw redraws the path and its heading arrows from the recorded samples. The path is ADDED once it has two points
+// and UPDATED afterwards, arrows keep their UUIDs by position along the path, and arrows past the end are REMOVED.
+// Must be called with trailMutex held.
+func (s *worldStateService) draw() error {
+	transforms := []*commonPB.Transform{}
+
+	points := make([]r3.Vector, 0, len(s.samples))
+	for _, recorded := range s.samples {
+		points = append(points, recorded.position)
+	}
+
+	tolerance := s.config.ToleranceMm
+	if tolerance <= 0 {
+		tolerance = DefaultToleranceMm
+	}
+
+	color := s.config.Color
+	if color == (lib.Color{}) {
+		color = lib.DefaultLineColor
+	}
+
+	if len(points) < 2 {
+		s.Remove(s.id.String())
+	} else {
+		line, err := lib.CreatePolyline(lib.DecimatePolyline(points, tolerance), s.config.LineWidthMm, s.frame, s.id.Bytes(), &color, s.config.ParentFrame)
+		if err != nil {
+			return err
+		}
+
+		transforms = append(transforms, line)
+	}
+
+	arrows := s.headingArrows()
+	for i, recorded := range arrows {
+		id := lib.DeriveUUID(s.id, fmt.Sprintf("arrow-%d", i))
+		pose := &commonPB.Pose{
+			X:  recorded.position.X,
+			Y:  recorded.position.Y,
+			Z:  recorded.position.Z,
+			OX: recorded.heading.X,
+			OY: recorded.heading.Y,
+			OZ: recorded.heading.Z,
+		}
+
+		arrow, err := lib.CreateArrow(pose, fmt.Sprintf("%s-arrow-%d", s.frame, i), id.Bytes(), &color, s.config.ParentFrame)
+		if err != nil {
+			return err
+		}
+
+		transforms = append(transforms, arrow)
+	}
+
+	if err := s.Put(transforms...); err != nil {
+		return err
+	}
+
+	for i := len(arrows); i < s.arrows; i++ {
+		id := lib.DeriveUUID(s.id, fmt.Sprintf("arrow-%d", i))
+		s.Remove(id.String())
+	}
+	s.arrows = len(arrows)
+
+	return nil
+}
+
+// headingArrows returns the samples heading arrows are drawn at, every arrow spacing along the path starting from the
+// oldest sample. Samples without a heading are skipped. Must be called with trailMutex held.
+func (s *worldStateService) headingArrows() []sample {
+	if s.config.ArrowSpacingM <= 0 {
+		return nil
+	}
+
+	spacing := s.config.ArrowSpacingM * 1000
+	arrows := []sample{}
+	travelled := spacing
+	for i, recorded := range s.samples {
+		if i > 0 {
+			travelled += recorded.position.Distance(s.samples[i-1].position)
+		}
+
+		if travelled >= spacing && recorded.heading != (r3.Vector{}) {
+			arrows = append(arrows, recorded)
+			travelled = 0
+		}
+	}
+
+	return arrows
+}
+
+// clear forgets the recorded path and removes its drawing. The local frame keeps its anchor, so a path recorded
+// afterwards lines up with the one cleared.
+func (s *worldStateService) clear() int {
+	s.trailMutex.Lock()
+	defer s.trailMutex.Unlock()
+
+	count := len(s.samples)
+	s.samples = nil
+	s.Clear()
+	s.arrows = 0
+
+	return count
+}
+
+// setRunning resumes or pauses recording. The path stays drawn while paused.
+func (s *worldStateService) setRunning(running bool) {
+	s.trailMutex.Lock()
+	defer s.trailMutex.Unlock()
+
+	// integrating velocity across a pause would add the whole pause at the last velocity
+	if running && !s.running {
+		s.integratedAt = time.Time{}
+	}
+	s.running = running
+}
+
+// commands describes the DoCommand keys of the movement trail service.
+var commands = &command.Set{
+	Model: WorldState,
+	Commands: []command.Command{
+		{
+			Name:        "resume",
+			Description: "Starts recording the path again",
+			Response:    map[string]any{"running": true},
+		},
+		{
+			Name:        "pause",
+			Description: "Stops recording, keeping the path drawn",
+			Response:    map[string]any{"running": true},
+		},
+		{
+			Name:        "clear",
+			Description: "Forgets the recorded path and removes its drawing",
+			Response:    map[string]any{"points_removed": 0},
+		},
+		command.HideCommand,
+		command.ShowCommand,
+		command.StatsCommand,
+	},
+}
+
+func (service *worldStateService) DoCommand(ctx context.Context, cmd map[string]any) (map[string]any, error) {
+	if result, ok, err := commands.Handle(cmd); ok {
+		return result, err
+	}
+
+	if result, ok, err := service.Visibility(cmd, service.SetVisible); ok {
+		return result, err
+	}
+
+	if _, ok := cmd["resume"]; ok {
+		service.setRunning(true)
+		return map[string]any{
+			"success": true,
+			"running": true,
+		}, nil
+	}
+
+	if _, ok := cmd["pause"]; ok {
+		service.setRunning(false)
+		return map[string]any{
+			"success": true,
+			"running": false,
+		}, nil
+	}
+
+	if _, ok := cmd["clear"]; ok {
+		count := service.clear()
+		return map[string]any{
+			"success":        true,
+			"points_removed": count,
+		}, nil
+	}
+
+	if _, ok := cmd["stats"]; ok {
+		return map[string]any{
+			"success": true,
+			"stats":   service.Stats().ToMap(),
+		}, nil
+	}
+
+	return nil, lib.Errorf(lib.ErrInvalidArgument, "Unknown command")
+}
@@ -0,0 +1,182 @@
+package drawtrail
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/geo/r3"
+	geo "github.com/kellydunn/golang-geo"
+	"github.com/viam-labs/draw-tools/lib/command/commandtest"
+	v1 "go.viam.com/api/service/worldstatestore/v1"
+	"go.viam.com/rdk/components/movementsensor"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	worldstatestore "go.viam.com/rdk/services/worldstatestore"
+	"go.viam.com/rdk/testutils/inject"
+	"go.viam.com/test"
+)
+
+// fakeGPS reports a fix that moves north by a thousandth of a degree, about 111 meters, every time it is stepped.
+type fakeGPS struct {
+	mu    sync.Mutex
+	steps int
+}
+
+func (f *fakeGPS) step() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.steps++
+}
+
+func (f *fakeGPS) sensor() *inject.MovementSensor {
+	sensor := inject.NewMovementSensor("gps")
+	sensor.PropertiesFunc = func(ctx context.Context, extra map[string]interface{}) (*movementsensor.Properties, error) {
+		return &movementsensor.Properties{PositionSupported: true}, nil
+	}
+	sensor.PositionFunc = func(ctx context.Context, extra map[string]interface{}) (*geo.Point, float64, error) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		return geo.NewPoint(40+0.001*float64(f.steps), -74), 0, nil
+	}
+
+	return sensor
+}
+
+func TestTrail(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	gps := &fakeGPS{}
+	deps := resource.Dependencies{movementsensor.Named("gps"): gps.sensor()}
+	conf := &Config{MovementSensor: "gps", RateHz: 50, ArrowSpacingM: 50}
+
+	service, err := NewWorldStateService(ctx, deps, worldstatestore.Named("trail"), conf, logging.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	defer service.Close(ctx)
+
+	stream, err := service.StreamTransformChanges(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+
+	// a single fix is not a path yet
+	time.Sleep(100 * time.Millisecond)
+	uuids, err := service.ListUUIDs(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, uuids, test.ShouldBeEmpty)
+
+	gps.step()
+
+	changes := map[string]worldstatestore.TransformChange{}
+	for len(changes) < 2 {
+		change, err := stream.Next()
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, change.ChangeType, test.ShouldEqual, v1.TransformChangeType_TRANSFORM_CHANGE_TYPE_ADDED)
+		changes[change.Transform.ReferenceFrame] = change
+	}
+
+	line := changes["gps-trail"]
+	test.That(t, line.Transform.Metadata.AsMap()["shape"], test.ShouldEqual, "line")
+	test.That(t, line.Transform.PoseInObserverFrame.ReferenceFrame, test.ShouldEqual, "world")
+
+	// the heading arrow faces north, the way the sensor moved
+	arrow := changes["gps-trail-arrow-0"].Transform.PoseInObserverFrame.Pose
+	test.That(t, arrow.Y, test.ShouldAlmostEqual, 111035.0, 100)
+	test.That(t, arrow.OY, test.ShouldAlmostEqual, 1.0, 1e-3)
+
+	gps.step()
+
+	updated, err := stream.Next()
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, updated.ChangeType, test.ShouldEqual, v1.TransformChangeType_TRANSFORM_CHANGE_TYPE_UPDATED)
+	test.That(t, updated.Transform.Uuid, test.ShouldResemble, line.Transform.Uuid)
+	test.That(t, updated.UpdatedFields, test.ShouldResemble, []string{"physicalObject"})
+
+	result, err := service.DoCommand(ctx, map[string]any{"pause": true})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, result["running"], test.ShouldEqual, false)
+
+	result, err = service.DoCommand(ctx, map[string]any{"clear": true})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, result["points_removed"], test.ShouldEqual, 3)
+
+	uuids, err = service.ListUUIDs(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, uuids, test.ShouldBeEmpty)
+}
+
+func TestTrailVelocity(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	odometry := inject.NewMovementSensor("odometry")
+	odometry.PropertiesFunc = func(ctx context.Context, extra map[string]interface{}) (*movementsensor.Properties, error) {
+		return &movementsensor.Properties{LinearVelocitySupported: true}, nil
+	}
+	odometry.LinearVelocityFunc = func(ctx context.Context, extra map[string]interface{}) (r3.Vector, error) {
+		return r3.Vector{X: 2}, nil
+	}
+
+	deps := resource.Dependencies{movementsensor.Named("odometry"): odometry}
+	conf := &Config{MovementSensor: "odometry", RateHz: 50, Paused: true}
+
+	service, err := NewWorldStateService(ctx, deps, worldstatestore.Named("trail"), conf, logging.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	defer service.Close(ctx)
+
+	// resume after subscribing so the stream sees the path being added
+	stream, err := service.StreamTransformChanges(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+
+	_, err = service.DoCommand(ctx, map[string]any{"resume": true})
+	test.That(t, err, test.ShouldBeNil)
+
+	added, err := stream.Next()
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, added.ChangeType, test.ShouldEqual, v1.TransformChangeType_TRANSFORM_CHANGE_TYPE_ADDED)
+	test.That(t, added.Transform.ReferenceFrame, test.ShouldEqual, "odometry-trail")
+}
+
+func TestTrim(t *testing.T) {
+	now := time.Now()
+	service := &worldStateService{config: &Config{MaxLengthM: 1.5, TimeWindowSec: 10}}
+	service.samples = []sample{
+		{at: now.Add(-time.Minute), position: r3.Vector{}},
+		{at: now, position: r3.Vector{X: 1000}},
+		{at: now, position: r3.Vector{X: 2000}},
+		{at: now, position: r3.Vector{X: 3000}},
+	}
+
+	test.That(t, service.trim(now), test.ShouldBeTrue)
+	test.That(t, service.samples, test.ShouldHaveLength, 2)
+	test.That(t, service.samples[0].position.X, test.ShouldEqual, 2000.0)
+
+	test.That(t, service.trim(now), test.ShouldBeFalse)
+}
+
+func TestValidate(t *testing.T) {
+	deps, _, err := (&Config{MovementSensor: "gps"}).Validate("services.0")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, deps, test.ShouldResemble, []string{"gps"})
+
+	_, _, err = (&Config{}).Validate("services.0")
+	test.That(t, err, test.ShouldNotBeNil)
+
+	_, _, err = (&Config{MovementSensor: "gps", Source: "lidar"}).Validate("services.0")
+	test.That(t, err, test.ShouldNotBeNil)
+
+	_, _, err = (&Config{MovementSensor: "gps", MaxLengthM: -1}).Validate("services.0")
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+func FuzzDoCommand(f *testing.F) {
+	gps := &fakeGPS{}
+	deps := resource.Dependencies{movementsensor.Named("gps"): gps.sensor()}
+	conf := &Config{MovementSensor: "gps", RateHz: 50, Paused: true}
+
+	service, err := NewWorldStateService(context.Background(), deps, worldstatestore.Named("trail"), conf, logging.NewTestLogger(f))
+	test.That(f, err, test.ShouldBeNil)
+	f.Cleanup(func() { service.Close(context.Background()) })
+
+	commandtest.Fuzz(f, commands, service.DoCommand)
+}
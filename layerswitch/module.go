@@ -0,0 +1,214 @@
+// Package layerswitch shows and hides layers of a draw-tools world state store service with a switch, one set of
+// visible layers per position.
+package layerswitch
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/viam-labs/draw-tools/client"
+	"github.com/viam-labs/draw-tools/lib"
+	"github.com/viam-labs/draw-tools/lib/command"
+
+	toggleswitch "go.viam.com/rdk/components/switch"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+)
+
+var (
+	LayerSwitch = resource.NewModel("viam-viz", "draw-tools", "layer-switch")
+)
+
+func init() {
+	resource.RegisterComponent(toggleswitch.API, LayerSwitch,
+		resource.Registration[toggleswitch.Switch, *Config]{
+			Constructor: newLayerSwitch,
+		},
+	)
+}
+
+// Position is a position of the switch and the layers it shows.
+type Position struct {
+	Label  string   `json:"label,omitempty"`  // Label of the position (defaults to none)
+	Layers []string `json:"layers,omitempty"` // Layers shown at this position; the other configured layers are hidden
+}
+
+type Config struct {
+	ServiceName string     `json:"service_name"`
+	Layers      []string   `json:"layers"`              // Layers the switch shows and hides (required)
+	Positions   []Position `json:"positions,omitempty"` // Positions of the switch (defaults to "visible", showing every layer, then "hidden")
+	Position    *uint32    `json:"position,omitempty"`  // Position applied when the switch starts (defaults to none, leaving the layers as they are)
+}
+
+func (cfg *Config) Validate(path string) ([]string, []string, error) {
+	if cfg.ServiceName == "" {
+		return nil, nil, resource.NewConfigValidationFieldRequiredError(path, "service_name")
+	}
+
+	if len(cfg.Layers) == 0 {
+		return nil, nil, resource.NewConfigValidationFieldRequiredError(path, "layers")
+	}
+
+	for i, layer := range cfg.Layers {
+		if layer == "" {
+			return nil, nil, resource.NewConfigValidationError(path, fmt.Errorf("layers[%d] must not be empty", i))
+		}
+	}
+
+	positions := cfg.positions()
+	for i, position := range positions {
+		for _, layer := range position.Layers {
+			if !slices.Contains(cfg.Layers, layer) {
+				return nil, nil, resource.NewConfigValidationError(path,
+					fmt.Errorf("positions[%d] shows layer %q, which is not one of the configured layers", i, layer))
+			}
+		}
+	}
+
+	if cfg.Position != nil && int(*cfg.Position) >= len(positions) {
+		return nil, nil, resource.NewConfigValidationError(path,
+			fmt.Errorf("position must be less than the %d positions, got %d", len(positions), *cfg.Position))
+	}
+
+	return []string{cfg.ServiceName}, nil, nil
+}
+
+// positions returns the configured positions, or "visible" and "hidden" if there are none.
+func (cfg *Config) positions() []Position {
+	if len(cfg.Positions) > 0 {
+		return cfg.Positions
+	}
+
+	return []Position{
+		{Label: "visible", Layers: cfg.Layers},
+		{Label: "hidden"},
+	}
+}
+
+type layerSwitch struct {
+	resource.AlwaysRebuild
+
+	name      resource.Name
+	logger    logging.Logger
+	config    *Config
+	positions []Position
+
+	client *client.Client
+
+	mu       sync.Mutex
+	position uint32
+}
+
+func newLayerSwitch(
+	ctx context.Context,
+	deps resource.Dependencies,
+	rawConf resource.Config,
+	logger logging.Logger,
+) (toggleswitch.Switch, error) {
+	conf, err := resource.NativeConfig[*Config](rawConf)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewLayerSwitch(ctx, deps, rawConf.ResourceName(), conf, logger)
+}
+
+func NewLayerSwitch(
+	ctx context.Context,
+	deps resource.Dependencies,
+	name resource.Name,
+	conf *Config,
+	logger logging.Logger,
+) (toggleswitch.Switch, error) {
+	drawClient, err := client.FromDependencies(deps, conf.ServiceName)
+	if err != nil {
+		return nil, err
+	}
+
+	component := &layerSwitch{
+		name:      name,
+		logger:    logger,
+		config:    conf,
+		positions: conf.positions(),
+		client:    drawClient,
+	}
+
+	if conf.Position != nil {
+		if err := component.SetPosition(ctx, *conf.Position, nil); err != nil {
+			return nil, fmt.Errorf("Failed to apply position %d: %w", *conf.Position, err)
+		}
+	}
+
+	return component, nil
+}
+
+func (s *layerSwitch) Name() resource.Name {
+	return s.name
+}
+
+// SetPosition shows the layers of a position and hides the other configured layers.
+func (s *layerSwitch) SetPosition(ctx context.Context, position uint32, extra map[string]interface{}) error {
+	if int(position) >= len(s.positions) {
+		return lib.Errorf(lib.ErrInvalidArgument, "Position must be less than %d, got %d", len(s.positions), position)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	shown := s.positions[position].Layers
+	hidden := []string{}
+	for _, layer := range s.config.Layers {
+		if !slices.Contains(shown, layer) {
+			hidden = append(hidden, layer)
+		}
+	}
+
+	if len(hidden) > 0 {
+		if _, err := s.client.Hide(ctx, lib.Selection{Layers: hidden}); err != nil {
+			return err
+		}
+	}
+
+	if len(shown) > 0 {
+		if _, err := s.client.Show(ctx, lib.Selection{Layers: shown}); err != nil {
+			return err
+		}
+	}
+
+	s.position = position
+	return nil
+}
+
+// GetPosition returns the position last set, or 0 if none was set yet.
+func (s *layerSwitch) GetPosition(ctx context.Context, extra map[string]interface{}) (uint32, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.position, nil
+}
+
+func (s *layerSwitch) GetNumberOfPositions(ctx context.Context, extra map[string]interface{}) (uint32, []string, error) {
+	labels := make([]string, 0, len(s.positions))
+	for _, position := range s.positions {
+		labels = append(labels, position.Label)
+	}
+
+	return uint32(len(s.positions)), labels, nil
+}
+
+// commands describes the switch, which has no commands of its own besides the built-in ones.
+var commands = &command.Set{Model: LayerSwitch}
+
+func (s *layerSwitch) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	if result, ok, err := commands.Handle(cmd); ok {
+		return result, err
+	}
+
+	return nil, fmt.Errorf("Not implemented, use service DoCommand instead")
+}
+
+func (s *layerSwitch) Close(context.Context) error {
+	return nil
+}
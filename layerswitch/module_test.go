@@ -0,0 +1,133 @@
+package layerswitch
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/viam-labs/draw-tools/drawshapes"
+	"github.com/viam-labs/draw-tools/lib"
+	"github.com/viam-labs/draw-tools/lib/command/commandtest"
+	toggleswitch "go.viam.com/rdk/components/switch"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	worldstatestore "go.viam.com/rdk/services/worldstatestore"
+	"go.viam.com/test"
+)
+
+func TestValidate(t *testing.T) {
+	one := uint32(1)
+	two := uint32(2)
+	for _, tc := range []struct {
+		name  string
+		conf  Config
+		valid bool
+	}{
+		{name: "layers", conf: Config{ServiceName: "shapes", Layers: []string{"fixtures"}}, valid: true},
+		{name: "default position", conf: Config{ServiceName: "shapes", Layers: []string{"fixtures"}, Position: &one}, valid: true},
+		{
+			name: "positions",
+			conf: Config{ServiceName: "shapes", Layers: []string{"fixtures", "robot"}, Positions: []Position{
+				{Label: "all", Layers: []string{"fixtures", "robot"}},
+				{Label: "robot", Layers: []string{"robot"}},
+				{Label: "none"},
+			}, Position: &two},
+			valid: true,
+		},
+		{name: "no service", conf: Config{Layers: []string{"fixtures"}}},
+		{name: "no layers", conf: Config{ServiceName: "shapes"}},
+		{name: "empty layer", conf: Config{ServiceName: "shapes", Layers: []string{""}}},
+		{name: "position out of range", conf: Config{ServiceName: "shapes", Layers: []string{"fixtures"}, Position: &two}},
+		{
+			name: "unknown layer",
+			conf: Config{ServiceName: "shapes", Layers: []string{"fixtures"}, Positions: []Position{{Layers: []string{"robot"}}}},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			deps, _, err := tc.conf.Validate("path")
+			if !tc.valid {
+				test.That(t, err, test.ShouldNotBeNil)
+				return
+			}
+
+			test.That(t, err, test.ShouldBeNil)
+			test.That(t, deps, test.ShouldResemble, []string{"shapes"})
+		})
+	}
+}
+
+func TestSetPosition(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	logger := logging.NewTestLogger(t)
+	service, err := drawshapes.NewWorldStateService(ctx, nil, worldstatestore.Named("shapes"), &drawshapes.Config{Shapes: []map[string]any{
+		{"type": "box", "dims_mm": map[string]any{"x": 10.0, "y": 10.0, "z": 10.0}, "name": "table", "layer": "fixtures"},
+		{"type": "axes", "pose": map[string]any{"o_z": 1.0}, "name": "tool", "layer": "robot"},
+		{"type": "sphere", "radius_mm": 10.0, "name": "ball"},
+	}}, logger)
+	test.That(t, err, test.ShouldBeNil)
+	defer service.Close(ctx)
+
+	shapes := service.(*drawshapes.Shapes)
+	visible := func(name string) bool {
+		return lib.TransformVisible(shapes.Named(name)[0])
+	}
+
+	deps := resource.Dependencies{worldstatestore.Named("shapes"): service}
+	conf := &Config{ServiceName: "shapes", Layers: []string{"fixtures", "robot"}, Positions: []Position{
+		{Label: "all", Layers: []string{"fixtures", "robot"}},
+		{Label: "robot", Layers: []string{"robot"}},
+		{Label: "none"},
+	}}
+
+	component, err := NewLayerSwitch(ctx, deps, toggleswitch.Named("layers"), conf, logger)
+	test.That(t, err, test.ShouldBeNil)
+	defer component.Close(ctx)
+
+	count, labels, err := component.GetNumberOfPositions(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, count, test.ShouldEqual, 3)
+	test.That(t, labels, test.ShouldResemble, []string{"all", "robot", "none"})
+
+	test.That(t, component.SetPosition(ctx, 1, nil), test.ShouldBeNil)
+	test.That(t, visible("table"), test.ShouldBeFalse)
+	test.That(t, visible("tool-x"), test.ShouldBeTrue)
+	test.That(t, visible("ball"), test.ShouldBeTrue)
+
+	test.That(t, component.SetPosition(ctx, 2, nil), test.ShouldBeNil)
+	test.That(t, visible("table"), test.ShouldBeFalse)
+	test.That(t, visible("tool-z"), test.ShouldBeFalse)
+	test.That(t, visible("ball"), test.ShouldBeTrue)
+
+	position, err := component.GetPosition(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, position, test.ShouldEqual, 2)
+
+	// a position out of range changes nothing
+	err = component.SetPosition(ctx, 3, nil)
+	test.That(t, errors.Is(err, lib.ErrInvalidArgument), test.ShouldBeTrue)
+	position, err = component.GetPosition(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, position, test.ShouldEqual, 2)
+
+	// the default positions show, then hide, every layer, and the configured position is applied at start
+	hidden := uint32(1)
+	component, err = NewLayerSwitch(ctx, deps, toggleswitch.Named("all"),
+		&Config{ServiceName: "shapes", Layers: []string{"fixtures", "robot"}, Position: &hidden}, logger)
+	test.That(t, err, test.ShouldBeNil)
+	defer component.Close(ctx)
+
+	_, labels, err = component.GetNumberOfPositions(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, labels, test.ShouldResemble, []string{"visible", "hidden"})
+
+	test.That(t, component.SetPosition(ctx, 0, nil), test.ShouldBeNil)
+	test.That(t, visible("table"), test.ShouldBeTrue)
+	test.That(t, visible("tool-y"), test.ShouldBeTrue)
+}
+
+func FuzzDoCommand(f *testing.F) {
+	commandtest.Fuzz(f, commands, (&layerSwitch{}).DoCommand)
+}
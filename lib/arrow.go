@@ -13,10 +13,12 @@ var defaultColor = Color{R: 255, G: 255, B: 0}
 // It contains all the necessary information to create a visual arrow in the world state.
 type ArrowJSON struct {
 	Pose        PoseJSON `json:"pose"`                   // Position and orientation (required)
-	Name        string   `json:"name"`                   // Name of the arrow frame (optional, defaults to "arrow-{uuid}")
+	Name        string   `json:"name,omitempty"`         // Name of the arrow frame (optional, defaults to "arrow-{uuid}")
 	UUID        string   `json:"uuid,omitempty"`         // UUID string (optional, generates new UUID if not provided)
-	Color       Color    `json:"color,omitempty"`        // RGB color (optional, defaults to yellow)
+	Color       *Color   `json:"color,omitempty"`        // RGB color (optional, defaults to yellow)
 	ParentFrame string   `json:"parent_frame,omitempty"` // Parent reference frame (optional, defaults to "world")
+	Layer       string   `json:"layer,omitempty"`        // Layer of the shape, kept in its "layer" metadata (optional, defaults to "default")
+	Axes        bool     `json:"axes,omitempty"`         // Draw an axes triad instead of a single arrow (optional, defaults to false)
 }
 
 // Arrow is a type alias for commonPB.Transform representing a visual arrow in the world state.
@@ -35,7 +37,7 @@ type Arrow = commonPB.Transform
 // Returns the created arrow transform or an error if creation fails.
 func CreateArrow(pose *commonPB.Pose, name string, uuid []byte, color *Color, parentFrame string) (*Arrow, error) {
 	if pose == nil {
-		return nil, fmt.Errorf("pose is required")
+		return nil, FieldErrorf("pose", "pose is required")
 	}
 
 	var id UUID
@@ -90,6 +92,7 @@ func CreateArrow(pose *commonPB.Pose, name string, uuid []byte, color *Color, pa
 
 // ParseArrows parses an array of arrows from JSON data.
 // It expects an array of arrow objects and returns a slice of parsed arrows.
+// Arrow objects with "axes": true are expanded into the three arrows of an axes triad.
 //
 // Parameters:
 //   - drawData: JSON array containing arrow objects
@@ -98,14 +101,23 @@ func CreateArrow(pose *commonPB.Pose, name string, uuid []byte, color *Color, pa
 func ParseArrows(drawData any) ([]*Arrow, error) {
 	arrowArray, ok := drawData.([]any)
 	if !ok {
-		return nil, fmt.Errorf("Expected array of arrows, got %T", drawData)
+		return nil, Errorf(ErrInvalidArgument, "Expected array of arrows, got %T", drawData)
 	}
 
 	arrows := make([]*Arrow, 0, len(arrowArray))
 	for i, item := range arrowArray {
+		if arrowMap, ok := item.(map[string]any); ok && arrowMap["axes"] == true {
+			axes, err := ParseAxes(item)
+			if err != nil {
+				return nil, AtIndex(i, fmt.Errorf("Failed to parse axes at index %d: %w", i, err))
+			}
+			arrows = append(arrows, axes...)
+			continue
+		}
+
 		arrowData, err := ParseArrow(item)
 		if err != nil {
-			return nil, fmt.Errorf("Failed to parse arrow at index %d: %w", i, err)
+			return nil, AtIndex(i, fmt.Errorf("Failed to parse arrow at index %d: %w", i, err))
 		}
 		arrows = append(arrows, arrowData)
 	}
@@ -122,71 +134,138 @@ func ParseArrows(drawData any) ([]*Arrow, error) {
 func ParseArrow(item any) (*Arrow, error) {
 	arrowMap, ok := item.(map[string]any)
 	if !ok {
-		return nil, fmt.Errorf("Expected arrow object, got %T", item)
+		return nil, Errorf(ErrInvalidArgument, "Expected arrow object, got %T", item)
 	}
 
-	poseData, ok := arrowMap["pose"]
-	if !ok {
-		return nil, fmt.Errorf("Missing required 'pose' field")
+	fields, err := parseArrowFields(arrowMap)
+	if err != nil {
+		return nil, err
 	}
 
-	pose, err := ParsePose(poseData)
+	name := fields.Name
+	if name == "" {
+		name = defaultName(fields.id)
+	}
+
+	bytes := fields.id.Bytes()
+	result, err := CreateArrow(fields.pose, name, bytes, fields.Color, fields.ParentFrame)
 	if err != nil {
-		return nil, fmt.Errorf("Failed to parse pose: %w", err)
+		return nil, fmt.Errorf("Failed to create arrow: %w", err)
 	}
 
-	var id UUID
-	idData, ok := arrowMap["uuid"].(string)
+	return result, nil
+}
+
+// ParseAxesList parses an array of axes triads from JSON data.
+//
+// Parameters:
+//   - drawData: JSON array containing axes objects
+//
+// Returns the arrows of every triad, three per triad, or an error if parsing fails.
+func ParseAxesList(drawData any) ([]*Arrow, error) {
+	axesArray, ok := drawData.([]any)
 	if !ok {
-		id = GenerateUUID()
-	} else {
-		parsed, err := UUIDFromString(idData)
+		return nil, Errorf(ErrInvalidArgument, "Expected array of axes, got %T", drawData)
+	}
+
+	arrows := make([]*Arrow, 0, 3*len(axesArray))
+	for i, item := range axesArray {
+		axes, err := ParseAxes(item)
 		if err != nil {
-			return nil, fmt.Errorf("Failed to parse UUID: %w", err)
+			return nil, AtIndex(i, fmt.Errorf("Failed to parse axes at index %d: %w", i, err))
 		}
-		id = *parsed
+		arrows = append(arrows, axes...)
 	}
+	return arrows, nil
+}
 
-	name, ok := arrowMap["name"].(string)
+// ParseAxes parses a single axes triad from JSON data.
+// It takes the same pose, name, uuid and parent_frame fields as an arrow. The triad has fixed axis colors.
+//
+// Parameters:
+//   - item: JSON object containing axes data
+//
+// Returns the x, y and z arrows of the triad or an error if parsing fails.
+func ParseAxes(item any) ([]*Arrow, error) {
+	axesMap, ok := item.(map[string]any)
 	if !ok {
-		if arrowMap["name"] == nil {
-			name = defaultName(id)
-		} else {
-			return nil, fmt.Errorf("Expected string for name, got %T", arrowMap["name"])
-		}
+		return nil, Errorf(ErrInvalidArgument, "Expected axes object, got %T", item)
 	}
-	if name == "" {
-		name = defaultName(id)
+
+	fields, err := parseArrowFields(axesMap)
+	if err != nil {
+		return nil, err
 	}
 
-	parentFrame := "world"
-	if frameData, ok := arrowMap["parent_frame"]; ok {
-		if frameStr, ok := frameData.(string); ok {
-			parentFrame = frameStr
-		} else {
-			return nil, fmt.Errorf("Expected string for parent frame, got %T", frameData)
-		}
+	result, err := CreateAxes(fields.pose, fields.Name, fields.id.Bytes(), fields.ParentFrame)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to create axes: %w", err)
 	}
 
-	var color *Color
-	if colorData, ok := arrowMap["color"]; ok {
-		parsed, err := ParseColor(colorData, defaultColor)
-		if err != nil {
-			return nil, fmt.Errorf("Failed to parse color: %w", err)
-		}
+	return result, nil
+}
 
-		color = &parsed
-	} else {
-		color = &defaultColor
+// ParseArrowSpec parses a single arrow or axes triad from JSON data into its configuration rather than a transform.
+// A UUID is generated when none is provided, so the returned configuration always identifies the arrow.
+//
+// Parameters:
+//   - item: JSON object containing arrow data
+//
+// Returns the parsed arrow configuration or an error if parsing fails.
+func ParseArrowSpec(item any) (*ArrowJSON, error) {
+	arrowMap, ok := item.(map[string]any)
+	if !ok {
+		return nil, Errorf(ErrInvalidArgument, "Expected arrow object, got %T", item)
 	}
 
-	bytes := id.Bytes()
-	result, err := CreateArrow(pose, name, bytes, color, parentFrame)
+	fields, err := parseArrowFields(arrowMap)
 	if err != nil {
-		return nil, fmt.Errorf("Failed to create arrow: %w", err)
+		return nil, err
 	}
 
-	return result, nil
+	spec := fields.ArrowJSON
+	spec.UUID = fields.id.String()
+
+	return &spec, nil
+}
+
+// arrowFields are the fields shared by arrows and axes triads.
+type arrowFields struct {
+	ArrowJSON
+	pose *commonPB.Pose
+	id   UUID
+}
+
+// parseArrowFields parses the pose, uuid, name, color and parent_frame fields of an arrow or axes object.
+// The name is left empty when missing so each shape can pick its own default.
+func parseArrowFields(arrowMap map[string]any) (*arrowFields, error) {
+	if arrowMap["pose"] == nil {
+		return nil, FieldErrorf("pose", "Missing required 'pose' field")
+	}
+
+	color := defaultColor
+	spec := ArrowJSON{
+		Color:       &color,
+		ParentFrame: "world",
+	}
+	if err := Decode(arrowMap, &spec); err != nil {
+		return nil, err
+	}
+
+	id := GenerateUUID()
+	if spec.UUID != "" {
+		parsed, err := UUIDFromString(spec.UUID)
+		if err != nil {
+			return nil, FieldErrorf("uuid", "Failed to parse UUID: %w", err)
+		}
+		id = *parsed
+	}
+
+	return &arrowFields{
+		ArrowJSON: spec,
+		pose:      PoseFromJSON(spec.Pose),
+		id:        id,
+	}, nil
 }
 
 func defaultName(uuid UUID) string {
@@ -163,6 +163,31 @@ func TestParseArrows(t *testing.T) {
 				test.That(t, arrows[1].PoseInObserverFrame.Pose.X, test.ShouldEqual, 50.0)
 			},
 		},
+		{
+			name: "axes expand into three arrows",
+			input: []any{
+				map[string]any{
+					"name": "arrow1",
+					"pose": map[string]any{"x": 100.0, "o_z": 1.0},
+				},
+				map[string]any{
+					"name":         "tool",
+					"pose":         map[string]any{"z": 500.0, "o_z": 1.0},
+					"parent_frame": "arm",
+					"axes":         true,
+				},
+			},
+			expected: func(t *testing.T, arrows []*Arrow, err error) {
+				test.That(t, err, test.ShouldBeNil)
+				test.That(t, len(arrows), test.ShouldEqual, 4)
+
+				test.That(t, arrows[0].ReferenceFrame, test.ShouldEqual, "arrow1")
+				test.That(t, arrows[1].ReferenceFrame, test.ShouldEqual, "tool-x")
+				test.That(t, arrows[2].ReferenceFrame, test.ShouldEqual, "tool-y")
+				test.That(t, arrows[3].ReferenceFrame, test.ShouldEqual, "tool-z")
+				test.That(t, arrows[3].PoseInObserverFrame.ReferenceFrame, test.ShouldEqual, "arm")
+			},
+		},
 		{
 			name:  "empty array",
 			input: []any{},
@@ -322,7 +347,7 @@ func TestParseArrow(t *testing.T) {
 			},
 			expected: func(t *testing.T, arrow *Arrow, err error) {
 				test.That(t, err, test.ShouldNotBeNil)
-				test.That(t, err.Error(), test.ShouldContainSubstring, "Expected string for name")
+				test.That(t, err.Error(), test.ShouldContainSubstring, "Failed to parse name")
 				test.That(t, arrow, test.ShouldBeNil)
 			},
 		},
@@ -335,7 +360,7 @@ func TestParseArrow(t *testing.T) {
 			},
 			expected: func(t *testing.T, arrow *Arrow, err error) {
 				test.That(t, err, test.ShouldNotBeNil)
-				test.That(t, err.Error(), test.ShouldContainSubstring, "Expected string for parent frame")
+				test.That(t, err.Error(), test.ShouldContainSubstring, "Failed to parse parent_frame")
 				test.That(t, arrow, test.ShouldBeNil)
 			},
 		},
@@ -374,3 +399,126 @@ func TestParseArrow(t *testing.T) {
 		})
 	}
 }
+
+func TestParseAxesList(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    any
+		expected func(*testing.T, []*Arrow, error)
+	}{
+		{
+			name: "valid axes",
+			input: []any{
+				map[string]any{
+					"pose": map[string]any{"x": 10.0, "o_z": 1.0},
+					"uuid": "550e8400-e29b-41d4-a716-446655440000",
+				},
+			},
+			expected: func(t *testing.T, arrows []*Arrow, err error) {
+				test.That(t, err, test.ShouldBeNil)
+				test.That(t, len(arrows), test.ShouldEqual, 3)
+				test.That(t, arrows[0].ReferenceFrame, test.ShouldEqual, "axes-550e8400-e29b-41d4-a716-446655440000-x")
+				test.That(t, arrows[0].PoseInObserverFrame.ReferenceFrame, test.ShouldEqual, "world")
+				test.That(t, arrows[0].PoseInObserverFrame.Pose.X, test.ShouldAlmostEqual, 10.0)
+			},
+		},
+		{
+			name:  "not an array",
+			input: map[string]any{},
+			expected: func(t *testing.T, arrows []*Arrow, err error) {
+				test.That(t, err, test.ShouldNotBeNil)
+				test.That(t, err.Error(), test.ShouldContainSubstring, "Expected array of axes")
+			},
+		},
+		{
+			name:  "missing pose",
+			input: []any{map[string]any{"name": "tool"}},
+			expected: func(t *testing.T, arrows []*Arrow, err error) {
+				test.That(t, err, test.ShouldNotBeNil)
+				test.That(t, err.Error(), test.ShouldContainSubstring, "Failed to parse axes at index 0")
+				test.That(t, err.Error(), test.ShouldContainSubstring, "Missing required 'pose' field")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ParseAxesList(tt.input)
+			tt.expected(t, result, err)
+		})
+	}
+}
+
+func TestParseArrowSpec(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    any
+		expected func(*testing.T, *ArrowJSON, error)
+	}{
+		{
+			name: "valid arrow",
+			input: map[string]any{
+				"name":         "target",
+				"pose":         map[string]any{"x": 100.0, "o_z": 1.0, "theta": 90.0},
+				"uuid":         "550e8400-e29b-41d4-a716-446655440000",
+				"color":        map[string]any{"r": 255, "g": 0, "b": 0},
+				"parent_frame": "robot",
+			},
+			expected: func(t *testing.T, arrow *ArrowJSON, err error) {
+				test.That(t, err, test.ShouldBeNil)
+				test.That(t, arrow.Name, test.ShouldEqual, "target")
+				test.That(t, arrow.UUID, test.ShouldEqual, "550e8400-e29b-41d4-a716-446655440000")
+				test.That(t, arrow.Pose, test.ShouldResemble, PoseJSON{X: 100, OZ: 1, Theta: 90})
+				test.That(t, arrow.Color, test.ShouldResemble, &Color{R: 255})
+				test.That(t, arrow.ParentFrame, test.ShouldEqual, "robot")
+				test.That(t, arrow.Axes, test.ShouldBeFalse)
+			},
+		},
+		{
+			name:  "defaults",
+			input: map[string]any{"pose": map[string]any{"o_z": 1.0}},
+			expected: func(t *testing.T, arrow *ArrowJSON, err error) {
+				test.That(t, err, test.ShouldBeNil)
+				test.That(t, arrow.Name, test.ShouldBeEmpty)
+				test.That(t, arrow.Color, test.ShouldResemble, &defaultColor)
+				test.That(t, arrow.ParentFrame, test.ShouldEqual, "world")
+
+				// a UUID is generated so the arrow can be found again
+				_, err = UUIDFromString(arrow.UUID)
+				test.That(t, err, test.ShouldBeNil)
+				test.That(t, arrow.UUID, test.ShouldNotBeEmpty)
+			},
+		},
+		{
+			name:  "axes",
+			input: map[string]any{"pose": map[string]any{"o_z": 1.0}, "axes": true},
+			expected: func(t *testing.T, arrow *ArrowJSON, err error) {
+				test.That(t, err, test.ShouldBeNil)
+				test.That(t, arrow.Axes, test.ShouldBeTrue)
+			},
+		},
+		{
+			name:  "missing pose",
+			input: map[string]any{"name": "target"},
+			expected: func(t *testing.T, arrow *ArrowJSON, err error) {
+				test.That(t, err, test.ShouldNotBeNil)
+				test.That(t, err.Error(), test.ShouldContainSubstring, "Missing required 'pose' field")
+			},
+		},
+		{
+			name:  "invalid color",
+			input: map[string]any{"pose": map[string]any{}, "color": "not a color"},
+			expected: func(t *testing.T, arrow *ArrowJSON, err error) {
+				test.That(t, err, test.ShouldNotBeNil)
+				test.That(t, err.Error(), test.ShouldContainSubstring, "Failed to parse color")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			arrow, err := ParseArrowSpec(tt.input)
+			tt.expected(t, arrow, err)
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package lib
+
+import (
+	"fmt"
+	"math"
+
+	commonPB "go.viam.com/api/common/v1"
+	"go.viam.com/rdk/spatialmath"
+)
+
+// Colors of the x, y and z arrows of an axes triad.
+var (
+	AxisXColor = Color{R: 255, G: 0, B: 0}
+	AxisYColor = Color{R: 0, G: 255, B: 0}
+	AxisZColor = Color{R: 0, G: 0, B: 255}
+)
+
+// axisRotations turn an arrow, which points along the local z axis of its pose, onto each axis of a frame.
+var axisRotations = []struct {
+	suffix   string
+	color    Color
+	rotation spatialmath.Orientation
+}{
+	{suffix: "x", color: AxisXColor, rotation: &spatialmath.R4AA{Theta: math.Pi / 2, RY: 1}},
+	{suffix: "y", color: AxisYColor, rotation: &spatialmath.R4AA{Theta: -math.Pi / 2, RX: 1}},
+	{suffix: "z", color: AxisZColor, rotation: &spatialmath.R4AA{Theta: 0, RZ: 1}},
+}
+
+// CreateAxes creates an axes triad as three arrows pointing along the x (red), y (green) and z (blue) axes of a pose.
+// The arrow UUIDs are derived from the triad UUID, so redrawing a triad with the same UUID updates the same arrows.
+//
+// Parameters:
+//   - pose: Position and orientation of the triad (required)
+//   - name: Name for the triad (empty string will generate "axes-{uuid}"); arrows are named "{name}-x", "{name}-y" and "{name}-z"
+//   - uuid: Optional UUID bytes of the triad (generates new UUID if nil)
+//   - parentFrame: Optional parent frame (defaults to "world" if empty)
+//
+// Returns the x, y and z arrows or an error if creation fails.
+func CreateAxes(pose *commonPB.Pose, name string, uuid []byte, parentFrame string) ([]*Arrow, error) {
+	if pose == nil {
+		return nil, FieldErrorf("pose", "pose is required")
+	}
+
+	var id UUID
+	if uuid == nil {
+		id = GenerateUUID()
+	} else {
+		parsed, err := UUIDFromBytes(uuid)
+		if err != nil {
+			return nil, err
+		}
+
+		id = *parsed
+	}
+
+	if name == "" {
+		name = fmt.Sprintf("axes-%s", id.String())
+	}
+
+	frame := spatialmath.NewPoseFromProtobuf(pose)
+	arrows := make([]*Arrow, 0, len(axisRotations))
+	for _, axis := range axisRotations {
+		axisPose := spatialmath.Compose(frame, spatialmath.NewPoseFromOrientation(axis.rotation))
+		axisID := DeriveUUID(id, axis.suffix)
+		color := axis.color
+
+		arrow, err := CreateArrow(spatialmath.PoseToProtobuf(axisPose), name+"-"+axis.suffix, axisID.Bytes(), &color, parentFrame)
+		if err != nil {
+			return nil, err
+		}
+
+		arrows = append(arrows, arrow)
+	}
+
+	return arrows, nil
+}
+
+// AxesUUIDs returns the UUIDs of the x, y and z arrows of the triad with the given UUID.
+func AxesUUIDs(uuid []byte) ([][]byte, error) {
+	parsed, err := UUIDFromBytes(uuid)
+	if err != nil {
+		return nil, err
+	}
+
+	uuids := make([][]byte, 0, len(axisRotations))
+	for _, axis := range axisRotations {
+		axisID := DeriveUUID(*parsed, axis.suffix)
+		uuids = append(uuids, axisID.Bytes())
+	}
+
+	return uuids, nil
+}
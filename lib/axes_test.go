@@ -0,0 +1,68 @@
+package lib
+
+import (
+	"testing"
+
+	"github.com/golang/geo/r3"
+	commonPB "go.viam.com/api/common/v1"
+	"go.viam.com/rdk/spatialmath"
+	"go.viam.com/test"
+)
+
+func TestCreateAxes(t *testing.T) {
+	t.Run("identity pose", func(t *testing.T) {
+		arrows, err := CreateAxes(&commonPB.Pose{X: 10, OZ: 1}, "gripper", testUUIDBytes, "arm")
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, arrows, test.ShouldHaveLength, 3)
+
+		directions := []r3.Vector{{X: 1}, {Y: 1}, {Z: 1}}
+		colors := []Color{AxisXColor, AxisYColor, AxisZColor}
+		for i, suffix := range []string{"x", "y", "z"} {
+			arrow := arrows[i]
+			test.That(t, arrow.ReferenceFrame, test.ShouldEqual, "gripper-"+suffix)
+			test.That(t, arrow.PoseInObserverFrame.ReferenceFrame, test.ShouldEqual, "arm")
+			test.That(t, arrow.PoseInObserverFrame.Pose.X, test.ShouldAlmostEqual, 10.0)
+
+			direction := spatialmath.NewPoseFromProtobuf(arrow.PoseInObserverFrame.Pose).Orientation().OrientationVectorRadians().Vector()
+			test.That(t, direction.Sub(directions[i]).Norm(), test.ShouldBeLessThan, 1e-6)
+
+			metadata := arrow.Metadata.AsMap()
+			test.That(t, metadata["shape"], test.ShouldEqual, "arrow")
+			test.That(t, metadata["color"], test.ShouldResemble, map[string]any{
+				"r": float64(colors[i].R), "g": float64(colors[i].G), "b": float64(colors[i].B),
+			})
+		}
+	})
+
+	t.Run("stable uuids", func(t *testing.T) {
+		first, err := CreateAxes(&commonPB.Pose{OZ: 1}, "", testUUIDBytes, "")
+		test.That(t, err, test.ShouldBeNil)
+		second, err := CreateAxes(&commonPB.Pose{X: 100, OZ: 1}, "", testUUIDBytes, "")
+		test.That(t, err, test.ShouldBeNil)
+
+		uuids, err := AxesUUIDs(testUUIDBytes)
+		test.That(t, err, test.ShouldBeNil)
+		for i := range first {
+			test.That(t, first[i].Uuid, test.ShouldResemble, second[i].Uuid)
+			test.That(t, first[i].Uuid, test.ShouldResemble, uuids[i])
+			test.That(t, first[i].Uuid, test.ShouldNotResemble, testUUIDBytes)
+		}
+
+		test.That(t, first[0].ReferenceFrame, test.ShouldEqual, "axes-"+testUUID.String()+"-x")
+		test.That(t, first[0].PoseInObserverFrame.ReferenceFrame, test.ShouldEqual, "world")
+	})
+
+	t.Run("rotated pose", func(t *testing.T) {
+		// rotating 90 degrees about z turns the x arrow onto the world y axis
+		arrows, err := CreateAxes(&commonPB.Pose{OZ: 1, Theta: 90}, "", nil, "")
+		test.That(t, err, test.ShouldBeNil)
+
+		direction := spatialmath.NewPoseFromProtobuf(arrows[0].PoseInObserverFrame.Pose).Orientation().OrientationVectorRadians().Vector()
+		test.That(t, direction.Sub(r3.Vector{Y: 1}).Norm(), test.ShouldBeLessThan, 1e-6)
+	})
+
+	t.Run("missing pose", func(t *testing.T) {
+		_, err := CreateAxes(nil, "", nil, "")
+		test.That(t, err, test.ShouldNotBeNil)
+	})
+}
@@ -1,7 +1,5 @@
 package lib
 
-import "fmt"
-
 // Color represents an RGB color value with red, green, and blue components.
 // Each component is an 8-bit unsigned integer (0-255).
 type Color struct {
@@ -21,7 +19,7 @@ type Color struct {
 func ParseColor(colorData any, defaultValue Color) (Color, error) {
 	colorMap, ok := colorData.(map[string]any)
 	if !ok {
-		return defaultValue, fmt.Errorf("expected color object, got %T", colorData)
+		return defaultValue, Errorf(ErrInvalidArgument, "expected color object, got %T", colorData)
 	}
 
 	if colorMap == nil {
@@ -55,3 +53,44 @@ func ParseColor(colorData any, defaultValue Color) (Color, error) {
 		B: uint8(b),
 	}, nil
 }
+
+// decode parses a color with ParseColor, keeping the current components as the defaults for missing ones.
+func (c *Color) decode(data any) error {
+	color, err := ParseColor(data, *c)
+	if err != nil {
+		return err
+	}
+
+	*c = color
+	return nil
+}
+
+// palette is a set of easily distinguished colors for drawing many objects at once.
+var palette = []Color{
+	{R: 31, G: 119, B: 180},
+	{R: 255, G: 127, B: 14},
+	{R: 44, G: 160, B: 44},
+	{R: 214, G: 39, B: 40},
+	{R: 148, G: 103, B: 189},
+	{R: 140, G: 86, B: 75},
+	{R: 227, G: 119, B: 194},
+	{R: 127, G: 127, B: 127},
+	{R: 188, G: 189, B: 34},
+	{R: 23, G: 190, B: 207},
+}
+
+// PaletteColor returns a color from a fixed palette of easily distinguished colors.
+// Indexes past the end of the palette wrap around, so every index maps to a color.
+//
+// Parameters:
+//   - index: Index of the color, typically the index of the object being drawn
+//
+// Returns the palette color for the index.
+func PaletteColor(index int) Color {
+	index %= len(palette)
+	if index < 0 {
+		index += len(palette)
+	}
+
+	return palette[index]
+}
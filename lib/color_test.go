@@ -102,3 +102,9 @@ func TestParseColor(t *testing.T) {
 		})
 	}
 }
+
+func TestPaletteColor(t *testing.T) {
+	test.That(t, PaletteColor(0), test.ShouldNotResemble, PaletteColor(1))
+	test.That(t, PaletteColor(len(palette)), test.ShouldResemble, PaletteColor(0))
+	test.That(t, PaletteColor(-1), test.ShouldResemble, PaletteColor(len(palette)-1))
+}
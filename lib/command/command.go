@@ -0,0 +1,210 @@
+// Package command describes the DoCommand keys of a model. Each model lists its commands once, and answers help,
+// describe and validate from that list, so the description cannot drift from what the model parses.
+package command
+
+import (
+	"sort"
+
+	"github.com/viam-labs/draw-tools/lib"
+
+	"go.viam.com/rdk/resource"
+)
+
+// Built-in commands answered by Set.Handle for every model.
+const (
+	Help     = "help"
+	Describe = "describe"
+	Validate = "validate"
+)
+
+// Command describes one DoCommand key of a model.
+type Command struct {
+	Name        string
+	Description string
+	Args        any                  // Example argument whose type is described, a Schema, or nil if the value is ignored
+	Response    map[string]any       // Example values, or Schemas, of the fields of a successful response besides success
+	Parse       func(args any) error // Checks the argument without changing state, or nil if the value is ignored
+}
+
+// Set is the commands of a model.
+type Set struct {
+	Model    resource.Model
+	Commands []Command
+}
+
+// Handle answers the help, describe and validate commands. It reports whether cmd was one of them; other commands are
+// left to the model.
+//
+// Parameters:
+//   - cmd: DoCommand payload
+//
+// Returns the response, whether the command was handled, and an error if it failed.
+func (set *Set) Handle(cmd map[string]any) (map[string]any, bool, error) {
+	for _, name := range []string{Help, Describe} {
+		if args, ok := cmd[name]; ok {
+			result, err := set.Describe(args)
+			if err != nil {
+				return lib.ErrorResponse(err), true, err
+			}
+
+			return result, true, nil
+		}
+	}
+
+	if payload, ok := cmd[Validate]; ok {
+		result, err := set.Validate(payload)
+		if err != nil {
+			return lib.ErrorResponse(err), true, err
+		}
+
+		return result, true, nil
+	}
+
+	return nil, false, nil
+}
+
+// Describe returns the model's commands with the JSON Schemas of their arguments and responses. A command name
+// describes only that command; any other value describes them all.
+func (set *Set) Describe(args any) (map[string]any, error) {
+	commands := set.all()
+	if name, ok := args.(string); ok && name != "" {
+		command, ok := set.find(name)
+		if !ok {
+			return nil, lib.Errorf(lib.ErrInvalidArgument, "Unknown command %q", name)
+		}
+		commands = []Command{command}
+	}
+
+	described := make([]any, 0, len(commands))
+	for _, command := range commands {
+		described = append(described, command.describe())
+	}
+
+	return map[string]any{
+		"success":  true,
+		"model":    set.Model.String(),
+		"commands": described,
+	}, nil
+}
+
+// Validate parses each command of a payload as the model would, without changing any state. Problems are reported in
+// the response rather than as an error, which is kept for a payload that is not an object.
+//
+// Parameters:
+//   - payload: Object mapping command names to their arguments, as they would be sent to DoCommand
+//
+// Returns a response with valid set to whether every command parsed and an errors array describing the others.
+func (set *Set) Validate(payload any) (map[string]any, error) {
+	cmd, ok := payload.(map[string]any)
+	if !ok {
+		return nil, lib.Errorf(lib.ErrInvalidArgument, "Expected object of commands to validate, got %T", payload)
+	}
+
+	errors := []any{}
+	for _, name := range sortedKeys(cmd) {
+		command, ok := set.find(name)
+		if !ok {
+			errors = append(errors, commandError(name, lib.Errorf(lib.ErrInvalidArgument, "Unknown command")))
+			continue
+		}
+
+		if command.Parse == nil {
+			continue
+		}
+
+		if err := command.Parse(cmd[name]); err != nil {
+			errors = append(errors, commandError(name, err))
+		}
+	}
+
+	return map[string]any{
+		"success": true,
+		"valid":   len(errors) == 0,
+		"errors":  errors,
+	}, nil
+}
+
+// commandError describes why a command of a validated payload would fail, with the code, path and index of err.
+func commandError(name string, err error) map[string]any {
+	fields := lib.ErrorFields(err)
+	fields["command"] = name
+
+	return fields
+}
+
+// all returns the model's commands followed by the built-in ones.
+func (set *Set) all() []Command {
+	commands := append([]Command{}, set.Commands...)
+	return append(commands, builtins...)
+}
+
+func (set *Set) find(name string) (Command, bool) {
+	for _, command := range set.all() {
+		if command.Name == name {
+			return command, true
+		}
+	}
+
+	return Command{}, false
+}
+
+func sortedKeys[V any](values map[string]V) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+func (command Command) describe() map[string]any {
+	properties := Schema{"success": Schema{"type": "boolean"}}
+	required := []any{"success"}
+	for _, name := range sortedKeys(command.Response) {
+		properties[name] = SchemaOf(command.Response[name])
+		required = append(required, name)
+	}
+
+	described := map[string]any{
+		"name":        command.Name,
+		"description": command.Description,
+		"response":    Schema{"type": "object", "properties": properties, "required": required},
+	}
+
+	if command.Args != nil {
+		described["args"] = SchemaOf(command.Args)
+	}
+
+	return described
+}
+
+var builtins = []Command{
+	{
+		Name:        Help,
+		Description: "Describes the commands of this model, or only the named command",
+		Args:        "draw",
+		Response:    map[string]any{"model": "", "commands": []map[string]any{}},
+	},
+	{
+		Name:        Describe,
+		Description: "Same as help",
+		Args:        "draw",
+		Response:    map[string]any{"model": "", "commands": []map[string]any{}},
+	},
+	{
+		Name:        Validate,
+		Description: "Parses an object of commands as they would be sent, without running them, and reports their errors",
+		Args:        map[string]any{"type": "object"},
+		Response: map[string]any{
+			"valid": true,
+			"errors": []struct {
+				Command string `json:"command"`
+				Error   string `json:"error"`
+				Code    string `json:"code,omitempty"`
+				Path    string `json:"path,omitempty"`
+				Index   int    `json:"index,omitempty"`
+			}{},
+		},
+	},
+}
@@ -0,0 +1,199 @@
+package command
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/viam-labs/draw-tools/lib"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/test"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func TestSchemaOf(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    any
+		expected Schema
+	}{
+		{
+			name:     "nil",
+			value:    nil,
+			expected: nil,
+		},
+		{
+			name:     "string",
+			value:    "",
+			expected: Schema{"type": "string"},
+		},
+		{
+			name:     "float slice",
+			value:    []float64{},
+			expected: Schema{"type": "array", "items": Schema{"type": "number"}},
+		},
+		{
+			name:     "schema",
+			value:    Schema{"type": "object"},
+			expected: Schema{"type": "object"},
+		},
+		{
+			name:  "color",
+			value: lib.Color{},
+			expected: Schema{
+				"type": "object",
+				"properties": Schema{
+					"r": Schema{"type": "integer", "minimum": 0, "maximum": 255},
+					"g": Schema{"type": "integer", "minimum": 0, "maximum": 255},
+					"b": Schema{"type": "integer", "minimum": 0, "maximum": 255},
+				},
+				"required": []any{"r", "g", "b"},
+			},
+		},
+		{
+			name: "struct with optional and ignored fields",
+			value: &struct {
+				Path    string            `json:"path"`
+				Scale   *float64          `json:"scale,omitempty"`
+				Labels  map[string]string `json:"labels,omitempty"`
+				Extra   map[string]any    `json:"extra,omitempty"`
+				Skipped bool              `json:"-"`
+				hidden  bool
+			}{},
+			expected: Schema{
+				"type": "object",
+				"properties": Schema{
+					"path":   Schema{"type": "string"},
+					"scale":  Schema{"type": "number"},
+					"labels": Schema{"type": "object", "additionalProperties": Schema{"type": "string"}},
+					"extra":  Schema{"type": "object"},
+				},
+				"required": []any{"path"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			test.That(t, SchemaOf(tt.value), test.ShouldResemble, tt.expected)
+		})
+	}
+}
+
+func TestTagged(t *testing.T) {
+	schema := Tagged(lib.ArrowJSON{}, lib.ShapeArrow, lib.ShapeAxes)
+	properties := schema["properties"].(Schema)
+	test.That(t, properties["type"], test.ShouldResemble, Schema{"type": "string", "enum": []any{"arrow", "axes"}})
+	test.That(t, properties["pose"].(Schema)["type"], test.ShouldEqual, "object")
+	test.That(t, schema["required"], test.ShouldResemble, []any{"type", "pose"})
+
+	// the primitive's own type field is replaced by the enum
+	schema = Tagged(lib.PrimitiveJSON{}, lib.PrimitiveBox)
+	test.That(t, schema["required"], test.ShouldResemble, []any{"type"})
+}
+
+func newSet() *Set {
+	return &Set{
+		Model: resource.NewModel("viam-viz", "draw-tools", "test-world-state"),
+		Commands: []Command{
+			{
+				Name:        "draw",
+				Description: "Draws arrows",
+				Args:        []lib.ArrowJSON{},
+				Response:    map[string]any{"arrows_added": 0},
+				Parse: func(args any) error {
+					if _, ok := args.([]any); !ok {
+						return errors.New("Expected array of arrows")
+					}
+					return nil
+				},
+			},
+			{
+				Name:        "clear",
+				Description: "Removes every arrow",
+				Response:    map[string]any{"arrows_removed": 0},
+			},
+		},
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	set := newSet()
+
+	result, handled, err := set.Handle(map[string]any{"help": true})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, handled, test.ShouldBeTrue)
+	test.That(t, result["model"], test.ShouldEqual, "viam-viz:draw-tools:test-world-state")
+
+	commands := result["commands"].([]any)
+	test.That(t, commands, test.ShouldHaveLength, 5)
+
+	draw := commands[0].(map[string]any)
+	test.That(t, draw["name"], test.ShouldEqual, "draw")
+	test.That(t, draw["args"].(Schema)["type"], test.ShouldEqual, "array")
+	test.That(t, draw["response"].(Schema)["required"], test.ShouldResemble, []any{"success", "arrows_added"})
+
+	// commands that ignore their value have no argument schema
+	_, ok := commands[1].(map[string]any)["args"]
+	test.That(t, ok, test.ShouldBeFalse)
+
+	// the description converts to a protobuf struct, as DoCommand responses must
+	_, err = structpb.NewStruct(result)
+	test.That(t, err, test.ShouldBeNil)
+
+	result, handled, err = set.Handle(map[string]any{"describe": "clear"})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, handled, test.ShouldBeTrue)
+	test.That(t, result["commands"], test.ShouldHaveLength, 1)
+
+	result, handled, err = set.Handle(map[string]any{"describe": "paint"})
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, handled, test.ShouldBeTrue)
+	test.That(t, result["success"], test.ShouldEqual, false)
+
+	_, handled, err = set.Handle(map[string]any{"draw": []any{}})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, handled, test.ShouldBeFalse)
+}
+
+func TestValidate(t *testing.T) {
+	set := newSet()
+
+	tests := []struct {
+		name    string
+		payload any
+		valid   bool
+		errors  []any
+	}{
+		{
+			name:    "valid",
+			payload: map[string]any{"draw": []any{}, "clear": true},
+			valid:   true,
+			errors:  []any{},
+		},
+		{
+			name:    "invalid argument",
+			payload: map[string]any{"draw": "arrow"},
+			errors:  []any{map[string]any{"command": "draw", "error": "Expected array of arrows"}},
+		},
+		{
+			name:    "unknown command",
+			payload: map[string]any{"paint": true, "help": true},
+			errors:  []any{map[string]any{"command": "paint", "error": "Unknown command", "code": "invalid_argument"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, handled, err := set.Handle(map[string]any{"validate": tt.payload})
+			test.That(t, err, test.ShouldBeNil)
+			test.That(t, handled, test.ShouldBeTrue)
+			test.That(t, result["valid"], test.ShouldEqual, tt.valid)
+			test.That(t, result["errors"], test.ShouldResemble, tt.errors)
+		})
+	}
+
+	result, _, err := set.Handle(map[string]any{"validate": []any{}})
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, errors.Is(err, lib.ErrInvalidArgument), test.ShouldBeTrue)
+	test.That(t, result["code"], test.ShouldEqual, "invalid_argument")
+}
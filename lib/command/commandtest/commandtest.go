@@ -0,0 +1,74 @@
+// Package commandtest fuzzes the DoCommand of a model described by a command.Set.
+package commandtest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/viam-labs/draw-tools/lib/command"
+)
+
+// malformed are arguments that no command should accept as is, from wrong types to out of range numbers and fields of
+// the wrong type inside otherwise valid objects.
+var malformed = []string{
+	`null`,
+	`true`,
+	`-1`,
+	`1e308`,
+	`"/"`,
+	`""`,
+	`[]`,
+	`[null]`,
+	`[1, "a", {}]`,
+	`{}`,
+	`{"uuids": [null], "names": "a"}`,
+	`{"uuid": 7, "name": ["a"]}`,
+	`{"pose": "up", "color": [255]}`,
+	`{"pose": {"x": "1", "o_z": null}}`,
+	`{"model_path": 1, "scale": -1}`,
+	`{"model_path": "/missing/*.ply", "uuid": "not-a-uuid"}`,
+	`{"type": "box", "dims_mm": {"x": 1e308, "y": -1, "z": "2"}}`,
+	`{"type": "cylinder", "radius_mm": 1, "length_mm": 1, "segments": 1.5}`,
+	`{"type": "line", "points": [{"x": 1}, null]}`,
+	`{"type": "label", "text": 3, "pose": {}}`,
+	`[{"type": "sphere", "radius_mm": 10, "opacity": 2}, {"type": "teapot"}]`,
+	`{"component": 1, "execution_id": "x"}`,
+}
+
+// Fuzz fuzzes a model's DoCommand with JSON objects. The corpus is seeded with every command of the set, and every
+// built-in command, given each malformed argument. Whatever the payload, DoCommand must not panic, and a response
+// reporting failure must come with an error.
+//
+// Parameters:
+//   - f: Fuzz test
+//   - set: Commands of the model
+//   - do: DoCommand of a model instance
+func Fuzz(f *testing.F, set *command.Set, do func(ctx context.Context, cmd map[string]any) (map[string]any, error)) {
+	names := []string{command.Help, command.Describe, command.Validate, "unknown"}
+	for _, cmd := range set.Commands {
+		names = append(names, cmd.Name)
+	}
+
+	for _, name := range names {
+		for _, args := range malformed {
+			f.Add(fmt.Sprintf(`{%q: %s}`, name, args))
+			if name != command.Validate {
+				f.Add(fmt.Sprintf(`{%q: {%q: %s}}`, command.Validate, name, args))
+			}
+		}
+	}
+
+	f.Fuzz(func(t *testing.T, payload string) {
+		var cmd map[string]any
+		if err := json.Unmarshal([]byte(payload), &cmd); err != nil || cmd == nil {
+			t.Skip()
+		}
+
+		result, err := do(context.Background(), cmd)
+		if err == nil && result["success"] == false {
+			t.Fatalf("%s reported failure without an error: %v", payload, result)
+		}
+	})
+}
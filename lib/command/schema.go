@@ -0,0 +1,131 @@
+package command
+
+import (
+	"reflect"
+	"strings"
+)
+
+// Schema is a JSON Schema. It is built from plain maps and slices so it can be returned from DoCommand as is.
+type Schema = map[string]any
+
+// SchemaOf returns the JSON Schema of the type of an example value. Struct fields are named by their json tags and are
+// required unless tagged omitempty. A Schema is returned unchanged, so hand written schemas can stand in for values.
+//
+// Parameters:
+//   - value: Example value whose type is described, or a Schema
+//
+// Returns the schema, or nil for a nil value.
+func SchemaOf(value any) Schema {
+	if value == nil {
+		return nil
+	}
+
+	if schema, ok := value.(Schema); ok {
+		return schema
+	}
+
+	return schemaOfType(reflect.TypeOf(value))
+}
+
+// OneOf returns a schema matched by any of the schemas of the given values.
+func OneOf(values ...any) Schema {
+	schemas := make([]any, 0, len(values))
+	for _, value := range values {
+		schemas = append(schemas, SchemaOf(value))
+	}
+
+	return Schema{"oneOf": schemas}
+}
+
+// ArrayOf returns the schema of an array whose items match the schema of value.
+func ArrayOf(value any) Schema {
+	return Schema{"type": "array", "items": SchemaOf(value)}
+}
+
+// Tagged returns the schema of an object with a required type field equal to one of the given types and the fields of
+// value. Shapes are described this way, with their type next to the fields of that type.
+func Tagged(value any, types ...string) Schema {
+	schema := SchemaOf(value)
+	properties := Schema{}
+	for name, property := range schema["properties"].(Schema) {
+		properties[name] = property
+	}
+
+	enum := make([]any, 0, len(types))
+	for _, shapeType := range types {
+		enum = append(enum, shapeType)
+	}
+	properties["type"] = Schema{"type": "string", "enum": enum}
+
+	required := []any{"type"}
+	if existing, ok := schema["required"].([]any); ok {
+		for _, name := range existing {
+			if name != "type" {
+				required = append(required, name)
+			}
+		}
+	}
+
+	return Schema{"type": "object", "properties": properties, "required": required}
+}
+
+func schemaOfType(t reflect.Type) Schema {
+	switch t.Kind() {
+	case reflect.Pointer:
+		return schemaOfType(t.Elem())
+	case reflect.Bool:
+		return Schema{"type": "boolean"}
+	case reflect.Uint8:
+		return Schema{"type": "integer", "minimum": 0, "maximum": 255}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return Schema{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return Schema{"type": "number"}
+	case reflect.String:
+		return Schema{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		return Schema{"type": "array", "items": schemaOfType(t.Elem())}
+	case reflect.Map:
+		if t.Elem().Kind() == reflect.Interface {
+			return Schema{"type": "object"}
+		}
+		return Schema{"type": "object", "additionalProperties": schemaOfType(t.Elem())}
+	case reflect.Struct:
+		return schemaOfStruct(t)
+	default:
+		// interfaces accept any value
+		return Schema{}
+	}
+}
+
+func schemaOfStruct(t reflect.Type) Schema {
+	properties := Schema{}
+	required := []any{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, options, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		properties[name] = schemaOfType(field.Type)
+		if !strings.Contains(options, "omitempty") {
+			required = append(required, name)
+		}
+	}
+
+	schema := Schema{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+
+	return schema
+}
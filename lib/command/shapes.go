@@ -0,0 +1,21 @@
+package command
+
+import "github.com/viam-labs/draw-tools/lib"
+
+// ShapeSchema describes a shape of any type: an object with a type field and the fields of that type.
+func ShapeSchema() Schema {
+	return OneOf(
+		Tagged(lib.ArrowJSON{}, lib.ShapeArrow, lib.ShapeAxes),
+		Tagged(lib.MeshJSON{}, lib.ShapeMesh),
+		Tagged(lib.PointCloudJSON{}, lib.ShapePointCloud),
+		Tagged(lib.PrimitiveJSON{}, lib.PrimitiveBox, lib.PrimitiveSphere, lib.PrimitiveCapsule, lib.PrimitiveCylinder),
+		Tagged(lib.LineJSON{}, lib.ShapeLine),
+		Tagged(lib.LabelJSON{}, lib.ShapeLabel),
+	)
+}
+
+// ParseIdentifiers checks a remove command's {"uuids", "names"} object.
+func ParseIdentifiers(args any) error {
+	_, err := lib.ParseIdentifiers(args)
+	return err
+}
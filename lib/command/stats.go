@@ -0,0 +1,10 @@
+package command
+
+import "github.com/viam-labs/draw-tools/lib/store"
+
+// StatsCommand describes the stats command of every world state store service.
+var StatsCommand = Command{
+	Name:        "stats",
+	Description: "Reports the drawn transforms by type and layer, their approximate bytes, the subscribers, the changes emitted and dropped, and the last error",
+	Response:    map[string]any{"stats": store.Stats{}},
+}
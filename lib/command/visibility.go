@@ -0,0 +1,32 @@
+package command
+
+import "github.com/viam-labs/draw-tools/lib"
+
+// SelectionSchema describes the objects selected by hide and show: "all", or an object of UUIDs, names and layers.
+func SelectionSchema() Schema {
+	return OneOf(Schema{"type": "string", "enum": []any{"all"}}, lib.Selection{})
+}
+
+// ParseSelection checks a hide or show command's "all" or {"uuids", "names", "layers", "all"} argument.
+func ParseSelection(args any) error {
+	_, err := lib.ParseSelection(args)
+	return err
+}
+
+// HideCommand and ShowCommand describe the visibility commands of every world state store service.
+var (
+	HideCommand = Command{
+		Name:        "hide",
+		Description: "Hides the objects with the given UUIDs, names or layers, or all of them, setting their visible metadata to false",
+		Args:        SelectionSchema(),
+		Response:    map[string]any{"changed": 0},
+		Parse:       ParseSelection,
+	}
+	ShowCommand = Command{
+		Name:        "show",
+		Description: "Shows the objects with the given UUIDs, names or layers, or all of them, setting their visible metadata to true",
+		Args:        SelectionSchema(),
+		Response:    map[string]any{"changed": 0},
+		Parse:       ParseSelection,
+	}
+)
@@ -0,0 +1,206 @@
+package lib
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+)
+
+// decodable is implemented by types that decode themselves, such as Color, which clamps its components.
+type decodable interface {
+	decode(data any) error
+}
+
+// Decode decodes JSON-like data, as DoCommand and configuration maps hold it, into a typed value.
+// Struct fields are matched by their json tags. Missing and null fields keep the value they already have, so defaults
+// can be set before decoding, but null array items are errors. Unknown fields are ignored. Any numeric type decodes
+// into a number field, and integer fields reject fractions and values out of range.
+//
+// Parameters:
+//   - data: Value to decode, typically a map[string]any
+//   - target: Non-nil pointer to the value to decode into
+//
+// Returns an invalid_argument *Error naming the path of the first field that does not match the target's type, or nil.
+func Decode(data any, target any) error {
+	value := reflect.ValueOf(target)
+	if value.Kind() != reflect.Pointer || value.IsNil() {
+		return fmt.Errorf("Expected non-nil pointer to decode into, got %T", target)
+	}
+
+	return decodeValue(data, value.Elem(), "")
+}
+
+func decodeValue(data any, value reflect.Value, path string) error {
+	if data == nil {
+		return nil
+	}
+
+	if value.CanAddr() {
+		if custom, ok := value.Addr().Interface().(decodable); ok {
+			if err := custom.decode(data); err != nil {
+				return fieldError(path, err)
+			}
+			return nil
+		}
+	}
+
+	switch value.Kind() {
+	case reflect.Pointer:
+		if value.IsNil() {
+			value.Set(reflect.New(value.Type().Elem()))
+		}
+		return decodeValue(data, value.Elem(), path)
+	case reflect.Interface:
+		if !reflect.TypeOf(data).AssignableTo(value.Type()) {
+			return mismatch(path, value.Type().String(), data)
+		}
+		value.Set(reflect.ValueOf(data))
+		return nil
+	case reflect.Bool:
+		b, ok := data.(bool)
+		if !ok {
+			return mismatch(path, "boolean", data)
+		}
+		value.SetBool(b)
+		return nil
+	case reflect.String:
+		str, ok := data.(string)
+		if !ok {
+			return mismatch(path, "string", data)
+		}
+		value.SetString(str)
+		return nil
+	case reflect.Float32, reflect.Float64:
+		number, ok := toFloat(data)
+		if !ok {
+			return mismatch(path, "number", data)
+		}
+		if math.IsNaN(number) || math.IsInf(number, 0) || value.OverflowFloat(number) {
+			return fieldError(path, fmt.Errorf("Expected finite number, got %v", number))
+		}
+		value.SetFloat(number)
+		return nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		number, ok := toFloat(data)
+		if !ok {
+			return mismatch(path, "integer", data)
+		}
+		if number != math.Trunc(number) || number < math.MinInt64 || number >= math.MaxInt64 ||
+			value.OverflowInt(int64(number)) {
+			return fieldError(path, fmt.Errorf("Expected integer in range, got %v", number))
+		}
+		value.SetInt(int64(number))
+		return nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		number, ok := toFloat(data)
+		if !ok {
+			return mismatch(path, "integer", data)
+		}
+		if number != math.Trunc(number) || number < 0 || number >= math.MaxUint64 || value.OverflowUint(uint64(number)) {
+			return fieldError(path, fmt.Errorf("Expected non-negative integer in range, got %v", number))
+		}
+		value.SetUint(uint64(number))
+		return nil
+	case reflect.Slice:
+		items := reflect.ValueOf(data)
+		if items.Kind() != reflect.Slice && items.Kind() != reflect.Array {
+			return mismatch(path, "array", data)
+		}
+		slice := reflect.MakeSlice(value.Type(), items.Len(), items.Len())
+		for i := 0; i < items.Len(); i++ {
+			itemPath := fmt.Sprintf("%s[%d]", path, i)
+			item := items.Index(i).Interface()
+			if item == nil {
+				return fieldError(itemPath, fmt.Errorf("Expected value, got null"))
+			}
+			if err := decodeValue(item, slice.Index(i), itemPath); err != nil {
+				return err
+			}
+		}
+		value.Set(slice)
+		return nil
+	case reflect.Map:
+		fields, ok := data.(map[string]any)
+		if !ok || value.Type().Key().Kind() != reflect.String {
+			return mismatch(path, "object", data)
+		}
+		if value.IsNil() {
+			value.Set(reflect.MakeMapWithSize(value.Type(), len(fields)))
+		}
+		for key, field := range fields {
+			item := reflect.New(value.Type().Elem()).Elem()
+			if err := decodeValue(field, item, join(path, key)); err != nil {
+				return err
+			}
+			value.SetMapIndex(reflect.ValueOf(key).Convert(value.Type().Key()), item)
+		}
+		return nil
+	case reflect.Struct:
+		fields, ok := data.(map[string]any)
+		if !ok {
+			return mismatch(path, "object", data)
+		}
+		return decodeStruct(fields, value, path)
+	default:
+		return fieldError(path, fmt.Errorf("Cannot decode into %s", value.Type()))
+	}
+}
+
+func decodeStruct(fields map[string]any, value reflect.Value, path string) error {
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		if err := decodeValue(fields[name], value.Field(i), join(path, name)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// toFloat converts any Go numeric type to a float64.
+func toFloat(data any) (float64, bool) {
+	value := reflect.ValueOf(data)
+	switch value.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return value.Float(), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(value.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(value.Uint()), true
+	default:
+		return 0, false
+	}
+}
+
+func join(path, name string) string {
+	if path == "" {
+		return name
+	}
+
+	return path + "." + name
+}
+
+func mismatch(path, expected string, data any) error {
+	return fieldError(path, fmt.Errorf("Expected %s, got %T", expected, data))
+}
+
+func fieldError(path string, err error) error {
+	if path == "" {
+		return &Error{Code: ErrInvalidArgument, Err: err}
+	}
+
+	return &Error{Code: ErrInvalidArgument, Path: path, Err: fmt.Errorf("Failed to parse %s: %w", path, err)}
+}
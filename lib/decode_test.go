@@ -0,0 +1,160 @@
+package lib
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"go.viam.com/test"
+)
+
+type decodeTarget struct {
+	Name    string            `json:"name"`
+	Count   int               `json:"count,omitempty"`
+	Level   uint8             `json:"level,omitempty"`
+	Scale   float64           `json:"scale,omitempty"`
+	Opacity *float64          `json:"opacity,omitempty"`
+	Enabled bool              `json:"enabled,omitempty"`
+	Color   Color             `json:"color,omitempty"`
+	Points  []Vector3JSON     `json:"points,omitempty"`
+	Labels  map[string]string `json:"labels,omitempty"`
+	Extra   any               `json:"extra,omitempty"`
+	Skipped string            `json:"-"`
+}
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    any
+		expected func(*testing.T, *decodeTarget, error)
+	}{
+		{
+			name: "valid",
+			input: map[string]any{
+				"name":    "crate",
+				"count":   3.0,
+				"level":   int64(200),
+				"scale":   2,
+				"opacity": 0.5,
+				"enabled": true,
+				"color":   map[string]any{"r": 300},
+				"points":  []any{map[string]any{"x": 1.0}, map[string]any{"z": float32(2)}},
+				"labels":  map[string]any{"side": "left"},
+				"extra":   []any{"anything"},
+				"Skipped": "ignored",
+				"unknown": "ignored",
+			},
+			expected: func(t *testing.T, target *decodeTarget, err error) {
+				test.That(t, err, test.ShouldBeNil)
+				test.That(t, target.Name, test.ShouldEqual, "crate")
+				test.That(t, target.Count, test.ShouldEqual, 3)
+				test.That(t, target.Level, test.ShouldEqual, uint8(200))
+				test.That(t, target.Scale, test.ShouldEqual, 2.0)
+				test.That(t, *target.Opacity, test.ShouldEqual, 0.5)
+				test.That(t, target.Enabled, test.ShouldBeTrue)
+				test.That(t, target.Color, test.ShouldResemble, Color{R: 255, G: 1, B: 2})
+				test.That(t, target.Points, test.ShouldResemble, []Vector3JSON{{X: 1}, {Z: 2}})
+				test.That(t, target.Labels, test.ShouldResemble, map[string]string{"side": "left"})
+				test.That(t, target.Extra, test.ShouldResemble, []any{"anything"})
+				test.That(t, target.Skipped, test.ShouldBeEmpty)
+			},
+		},
+		{
+			name:  "missing and null fields keep their defaults",
+			input: map[string]any{"name": nil, "scale": nil},
+			expected: func(t *testing.T, target *decodeTarget, err error) {
+				test.That(t, err, test.ShouldBeNil)
+				test.That(t, target.Name, test.ShouldEqual, "default")
+				test.That(t, target.Scale, test.ShouldEqual, 1.0)
+				test.That(t, target.Color, test.ShouldResemble, Color{R: 0, G: 1, B: 2})
+				test.That(t, target.Opacity, test.ShouldBeNil)
+			},
+		},
+		{
+			name:  "not an object",
+			input: "crate",
+			expected: func(t *testing.T, target *decodeTarget, err error) {
+				test.That(t, err, test.ShouldNotBeNil)
+				test.That(t, err.Error(), test.ShouldEqual, "Expected object, got string")
+			},
+		},
+		{
+			name:  "wrong type",
+			input: map[string]any{"name": 7},
+			expected: func(t *testing.T, target *decodeTarget, err error) {
+				test.That(t, err, test.ShouldNotBeNil)
+				test.That(t, err.Error(), test.ShouldEqual, "Failed to parse name: Expected string, got int")
+			},
+		},
+		{
+			name:  "nested path",
+			input: map[string]any{"points": []any{map[string]any{}, map[string]any{"y": "up"}}},
+			expected: func(t *testing.T, target *decodeTarget, err error) {
+				test.That(t, err, test.ShouldNotBeNil)
+				var fieldErr *Error
+				test.That(t, errors.As(err, &fieldErr), test.ShouldBeTrue)
+				test.That(t, fieldErr.Path, test.ShouldEqual, "points[1].y")
+				test.That(t, errors.Is(err, ErrInvalidArgument), test.ShouldBeTrue)
+			},
+		},
+		{
+			name:  "null array item",
+			input: map[string]any{"points": []any{nil}},
+			expected: func(t *testing.T, target *decodeTarget, err error) {
+				test.That(t, err, test.ShouldNotBeNil)
+				test.That(t, err.Error(), test.ShouldContainSubstring, "points[0]")
+			},
+		},
+		{
+			name:  "fractional integer",
+			input: map[string]any{"count": 1.5},
+			expected: func(t *testing.T, target *decodeTarget, err error) {
+				test.That(t, err, test.ShouldNotBeNil)
+				test.That(t, err.Error(), test.ShouldContainSubstring, "count")
+			},
+		},
+		{
+			name:  "integer out of range",
+			input: map[string]any{"level": 256},
+			expected: func(t *testing.T, target *decodeTarget, err error) {
+				test.That(t, err, test.ShouldNotBeNil)
+				test.That(t, err.Error(), test.ShouldContainSubstring, "level")
+			},
+		},
+		{
+			name:  "not a finite number",
+			input: map[string]any{"scale": math.Inf(1)},
+			expected: func(t *testing.T, target *decodeTarget, err error) {
+				test.That(t, err, test.ShouldNotBeNil)
+				test.That(t, err.Error(), test.ShouldContainSubstring, "scale")
+			},
+		},
+		{
+			name:  "color",
+			input: map[string]any{"color": "red"},
+			expected: func(t *testing.T, target *decodeTarget, err error) {
+				test.That(t, err, test.ShouldNotBeNil)
+				test.That(t, err.Error(), test.ShouldContainSubstring, "Failed to parse color")
+			},
+		},
+		{
+			name:  "map of the wrong type",
+			input: map[string]any{"labels": map[string]any{"side": 1}},
+			expected: func(t *testing.T, target *decodeTarget, err error) {
+				test.That(t, err, test.ShouldNotBeNil)
+				test.That(t, err.Error(), test.ShouldContainSubstring, "labels.side")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target := &decodeTarget{Name: "default", Scale: 1, Color: Color{R: 0, G: 1, B: 2}}
+			err := Decode(tt.input, target)
+			tt.expected(t, target, err)
+		})
+	}
+
+	err := Decode(map[string]any{}, decodeTarget{})
+	test.That(t, err, test.ShouldNotBeNil)
+}
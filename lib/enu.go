@@ -0,0 +1,56 @@
+package lib
+
+import (
+	"math"
+
+	"github.com/golang/geo/r3"
+	geo "github.com/kellydunn/golang-geo"
+)
+
+// WGS84 ellipsoid constants.
+const (
+	wgs84SemiMajorAxis  = 6378137.0
+	wgs84Flattening     = 1 / 298.257223563
+	wgs84Eccentricity2  = wgs84Flattening * (2 - wgs84Flattening)
+	millimetersPerMeter = 1000.0
+)
+
+// GeoToENU converts a GPS fix to a local east-north-up frame anchored at an origin fix, so readings can be drawn
+// alongside poses in millimeters.
+//
+// Parameters:
+//   - origin: Fix the local frame is anchored at
+//   - originAltitude: Altitude of the origin, in meters
+//   - point: Fix to convert
+//   - altitude: Altitude of the fix, in meters
+//
+// Returns the east (x), north (y) and up (z) offsets of the fix from the origin, in millimeters.
+func GeoToENU(origin *geo.Point, originAltitude float64, point *geo.Point, altitude float64) r3.Vector {
+	originECEF := geoToECEF(origin, originAltitude)
+	delta := geoToECEF(point, altitude).Sub(originECEF)
+
+	lat := origin.Lat() * math.Pi / 180
+	lng := origin.Lng() * math.Pi / 180
+	sinLat, cosLat := math.Sin(lat), math.Cos(lat)
+	sinLng, cosLng := math.Sin(lng), math.Cos(lng)
+
+	east := -sinLng*delta.X + cosLng*delta.Y
+	north := -sinLat*cosLng*delta.X - sinLat*sinLng*delta.Y + cosLat*delta.Z
+	up := cosLat*cosLng*delta.X + cosLat*sinLng*delta.Y + sinLat*delta.Z
+
+	return r3.Vector{X: east, Y: north, Z: up}.Mul(millimetersPerMeter)
+}
+
+// geoToECEF converts a fix to earth-centered earth-fixed coordinates, in meters.
+func geoToECEF(point *geo.Point, altitude float64) r3.Vector {
+	lat := point.Lat() * math.Pi / 180
+	lng := point.Lng() * math.Pi / 180
+	sinLat := math.Sin(lat)
+	radius := wgs84SemiMajorAxis / math.Sqrt(1-wgs84Eccentricity2*sinLat*sinLat)
+
+	return r3.Vector{
+		X: (radius + altitude) * math.Cos(lat) * math.Cos(lng),
+		Y: (radius + altitude) * math.Cos(lat) * math.Sin(lng),
+		Z: (radius*(1-wgs84Eccentricity2) + altitude) * sinLat,
+	}
+}
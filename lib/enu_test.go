@@ -0,0 +1,36 @@
+package lib
+
+import (
+	"testing"
+
+	geo "github.com/kellydunn/golang-geo"
+	"go.viam.com/test"
+)
+
+func TestGeoToENU(t *testing.T) {
+	origin := geo.NewPoint(40, -74)
+
+	t.Run("origin", func(t *testing.T) {
+		enu := GeoToENU(origin, 12, origin, 12)
+		test.That(t, enu.Norm(), test.ShouldAlmostEqual, 0.0, 1e-6)
+	})
+
+	t.Run("north", func(t *testing.T) {
+		// a thousandth of a degree of latitude is about 111 meters
+		enu := GeoToENU(origin, 0, geo.NewPoint(40.001, -74), 0)
+		test.That(t, enu.X, test.ShouldAlmostEqual, 0.0, 1)
+		test.That(t, enu.Y, test.ShouldAlmostEqual, 111035.0, 100)
+	})
+
+	t.Run("east", func(t *testing.T) {
+		// a thousandth of a degree of longitude shrinks with the cosine of the latitude
+		enu := GeoToENU(origin, 0, geo.NewPoint(40, -73.999), 0)
+		test.That(t, enu.X, test.ShouldAlmostEqual, 85394.0, 100)
+		test.That(t, enu.Y, test.ShouldAlmostEqual, 0.0, 10)
+	})
+
+	t.Run("up", func(t *testing.T) {
+		enu := GeoToENU(origin, 0, origin, 10)
+		test.That(t, enu.Z, test.ShouldAlmostEqual, 10000.0, 1e-3)
+	})
+}
@@ -0,0 +1,136 @@
+package lib
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+)
+
+// ErrorCode is a machine-readable kind of failure, returned as the "code" of a failed DoCommand response. Each code is
+// also an error, so errors.Is(err, ErrNotFound) matches any *Error with that code.
+type ErrorCode string
+
+const (
+	// ErrInvalidArgument is an argument or configuration field that is missing, of the wrong type or out of range.
+	ErrInvalidArgument ErrorCode = "invalid_argument"
+	// ErrNotFound is a drawn item or transform that does not exist.
+	ErrNotFound ErrorCode = "not_found"
+	// ErrFileNotFound is a model file that does not exist.
+	ErrFileNotFound ErrorCode = "file_not_found"
+	// ErrUnsupportedFormat is a model file that is not in a format that can be drawn.
+	ErrUnsupportedFormat ErrorCode = "unsupported_format"
+	// ErrLimitExceeded is a request past one of the module's limits, such as MaxCylinderSegments.
+	ErrLimitExceeded ErrorCode = "limit_exceeded"
+)
+
+func (code ErrorCode) Error() string {
+	return string(code)
+}
+
+// Error is a failure with a code, the path of the field that caused it and the index of the item that failed.
+// Its message is that of Err, so wrapping an error in an *Error does not change what users read.
+type Error struct {
+	Code  ErrorCode
+	Path  string // Path to the field within the failed item, such as "pose.x" or "points[2]" (optional)
+	Index *int   // Index of the failed item in the array of the command (optional)
+	Err   error  // Description of the failure
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is the code of the error.
+func (e *Error) Is(target error) bool {
+	code, ok := target.(ErrorCode)
+	return ok && code == e.Code
+}
+
+// Errorf returns an *Error with a code and a message formatted as by fmt.Errorf, so %w keeps wrapping the cause.
+func Errorf(code ErrorCode, format string, args ...any) error {
+	return &Error{Code: code, Err: fmt.Errorf(format, args...)}
+}
+
+// FieldErrorf returns an invalid_argument *Error for the field at path, with a message formatted as by fmt.Errorf.
+func FieldErrorf(path string, format string, args ...any) error {
+	return &Error{Code: ErrInvalidArgument, Path: path, Err: fmt.Errorf(format, args...)}
+}
+
+// AtIndex records that err is the failure of the item at index of an array. The code and path of the *Error that err
+// wraps, if any, are kept.
+//
+// Parameters:
+//   - index: Index of the failed item
+//   - err: Failure of the item, typically already naming the index in its message
+//
+// Returns an *Error wrapping err, or nil if err is nil.
+func AtIndex(index int, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	wrapped := &Error{Index: &index, Err: err}
+	var cause *Error
+	if errors.As(err, &cause) {
+		wrapped.Code = cause.Code
+		wrapped.Path = cause.Path
+	}
+
+	return wrapped
+}
+
+// fileError gives an error opening the file of a model_path the file_not_found code when the file does not exist.
+func fileError(err error) error {
+	if errors.Is(err, fs.ErrNotExist) {
+		return &Error{Code: ErrFileNotFound, Path: "model_path", Err: err}
+	}
+
+	return err
+}
+
+// formatError gives an error about the format of the file of a model_path the unsupported_format code.
+func formatError(format string, args ...any) error {
+	return &Error{Code: ErrUnsupportedFormat, Path: "model_path", Err: fmt.Errorf(format, args...)}
+}
+
+// ErrorFields describes a failure for a DoCommand response: its message as "error", and its "code", "path" and
+// "index" when known.
+//
+// Parameters:
+//   - err: Failure to describe
+//
+// Returns the fields describing the failure.
+func ErrorFields(err error) map[string]any {
+	fields := map[string]any{"error": err.Error()}
+
+	var coded *Error
+	if !errors.As(err, &coded) {
+		return fields
+	}
+
+	if coded.Code != "" {
+		fields["code"] = string(coded.Code)
+	}
+
+	if coded.Path != "" {
+		fields["path"] = coded.Path
+	}
+
+	if coded.Index != nil {
+		fields["index"] = *coded.Index
+	}
+
+	return fields
+}
+
+// ErrorResponse returns the response of a failed DoCommand: "success" set to false and the ErrorFields of err.
+func ErrorResponse(err error) map[string]any {
+	response := ErrorFields(err)
+	response["success"] = false
+
+	return response
+}
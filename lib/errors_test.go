@@ -0,0 +1,99 @@
+package lib
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go.viam.com/test"
+)
+
+func TestErrorFields(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		code     ErrorCode
+		expected map[string]any
+	}{
+		{
+			name:     "plain error",
+			err:      errors.New("connection lost"),
+			expected: map[string]any{"error": "connection lost"},
+		},
+		{
+			name:     "code",
+			err:      Errorf(ErrNotFound, "shape not found: %s", "crate"),
+			code:     ErrNotFound,
+			expected: map[string]any{"error": "shape not found: crate", "code": "not_found"},
+		},
+		{
+			name: "field path",
+			err:  parseError(ParseShape(map[string]any{"type": "sphere", "radius_mm": "big"})),
+			code: ErrInvalidArgument,
+			expected: map[string]any{
+				"error": "Failed to parse sphere: Failed to parse radius_mm: Expected number, got string",
+				"code":  "invalid_argument",
+				"path":  "radius_mm",
+			},
+		},
+		{
+			name: "index",
+			err: parseError(ParseShapes([]any{
+				map[string]any{"type": "sphere", "radius_mm": 10},
+				map[string]any{"type": "sphere", "radius_mm": 10, "pose": map[string]any{"x": "left"}},
+			})),
+			code: ErrInvalidArgument,
+			expected: map[string]any{
+				"error": "Failed to parse shape at index 1: Failed to parse sphere: Failed to parse pose.x: Expected number, got string",
+				"code":  "invalid_argument",
+				"path":  "pose.x",
+				"index": 1,
+			},
+		},
+		{
+			name: "limit",
+			err: parseError(ParsePrimitive(map[string]any{
+				"type": "cylinder", "radius_mm": 10, "length_mm": 10, "segments": 4096,
+			})),
+			code: ErrLimitExceeded,
+			expected: map[string]any{
+				"error": "cylinder segments must be at most 1024, got 4096",
+				"code":  "limit_exceeded",
+				"path":  "segments",
+			},
+		},
+		{
+			name: "missing file",
+			err:  ValidateMeshFile(filepath.Join(t.TempDir(), "missing.ply")),
+			code: ErrFileNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fields := ErrorFields(tt.err)
+			if tt.expected != nil {
+				test.That(t, fields, test.ShouldResemble, tt.expected)
+			}
+
+			if tt.code != "" {
+				test.That(t, errors.Is(tt.err, tt.code), test.ShouldBeTrue)
+				test.That(t, fields["code"], test.ShouldEqual, string(tt.code))
+			}
+
+			response := ErrorResponse(tt.err)
+			test.That(t, response["success"], test.ShouldEqual, false)
+			test.That(t, response["error"], test.ShouldEqual, tt.err.Error())
+		})
+	}
+
+	test.That(t, errors.Is(Errorf(ErrNotFound, "gone"), ErrInvalidArgument), test.ShouldBeFalse)
+	test.That(t, errors.Is(fileError(os.ErrNotExist), ErrFileNotFound), test.ShouldBeTrue)
+	test.That(t, AtIndex(0, nil), test.ShouldBeNil)
+}
+
+// parseError drops the parsed value of a parser's results.
+func parseError[T any](_ T, err error) error {
+	return err
+}
@@ -0,0 +1,92 @@
+package lib
+
+import "fmt"
+
+// Identifiers selects drawn objects by UUID or by frame name.
+type Identifiers struct {
+	UUIDs []string `json:"uuids,omitempty"` // UUID strings of the objects
+	Names []string `json:"names,omitempty"` // Frame names of the objects
+}
+
+// Empty reports whether no objects are selected.
+func (ids *Identifiers) Empty() bool {
+	return len(ids.UUIDs) == 0 && len(ids.Names) == 0
+}
+
+// ParseIdentifiers parses a selection of objects from JSON data.
+// It expects an object with optional "uuids" and "names" arrays of strings.
+//
+// Parameters:
+//   - data: JSON object containing the selection
+//
+// Returns the parsed identifiers or an error if parsing fails or nothing is selected.
+func ParseIdentifiers(data any) (*Identifiers, error) {
+	if _, ok := data.(map[string]any); !ok {
+		return nil, Errorf(ErrInvalidArgument, "Expected object with uuids or names, got %T", data)
+	}
+
+	ids := &Identifiers{}
+	if err := Decode(data, ids); err != nil {
+		return nil, err
+	}
+
+	for i, id := range ids.UUIDs {
+		if _, err := UUIDFromString(id); err != nil || id == "" {
+			return nil, FieldErrorf(fmt.Sprintf("uuids[%d]", i), "Failed to parse UUID at index %d: %q", i, id)
+		}
+	}
+
+	if ids.Empty() {
+		return nil, Errorf(ErrInvalidArgument, "Expected at least one of uuids or names")
+	}
+
+	return ids, nil
+}
+
+// Selection selects drawn objects by UUID, frame name or layer, or selects every object.
+type Selection struct {
+	UUIDs  []string `json:"uuids,omitempty"`  // UUID strings of the objects
+	Names  []string `json:"names,omitempty"`  // Frame names of the objects
+	Layers []string `json:"layers,omitempty"` // Layers of the objects
+	All    bool     `json:"all,omitempty"`    // Every object, whatever the other fields
+}
+
+// Empty reports whether no objects are selected.
+func (selection *Selection) Empty() bool {
+	return !selection.All && len(selection.UUIDs) == 0 && len(selection.Names) == 0 && len(selection.Layers) == 0
+}
+
+// ParseSelection parses a selection of objects from JSON data.
+// It expects the string "all", or an object with optional "uuids", "names" and "layers" arrays of strings and an
+// optional "all" flag.
+//
+// Parameters:
+//   - data: "all" or JSON object containing the selection
+//
+// Returns the parsed selection or an error if parsing fails or nothing is selected.
+func ParseSelection(data any) (*Selection, error) {
+	if data == "all" {
+		return &Selection{All: true}, nil
+	}
+
+	if _, ok := data.(map[string]any); !ok {
+		return nil, Errorf(ErrInvalidArgument, "Expected \"all\" or object with uuids, names or layers, got %T", data)
+	}
+
+	selection := &Selection{}
+	if err := Decode(data, selection); err != nil {
+		return nil, err
+	}
+
+	for i, id := range selection.UUIDs {
+		if _, err := UUIDFromString(id); err != nil || id == "" {
+			return nil, FieldErrorf(fmt.Sprintf("uuids[%d]", i), "Failed to parse UUID at index %d: %q", i, id)
+		}
+	}
+
+	if selection.Empty() {
+		return nil, Errorf(ErrInvalidArgument, "Expected at least one of uuids, names, layers or all")
+	}
+
+	return selection, nil
+}
@@ -0,0 +1,109 @@
+package lib
+
+import (
+	"testing"
+
+	"go.viam.com/test"
+)
+
+func TestParseIdentifiers(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    any
+		expected func(*testing.T, *Identifiers, error)
+	}{
+		{
+			name: "uuids and names",
+			input: map[string]any{
+				"uuids": []any{"550e8400-e29b-41d4-a716-446655440000"},
+				"names": []any{"fixture"},
+			},
+			expected: func(t *testing.T, ids *Identifiers, err error) {
+				test.That(t, err, test.ShouldBeNil)
+				test.That(t, ids.UUIDs, test.ShouldResemble, []string{"550e8400-e29b-41d4-a716-446655440000"})
+				test.That(t, ids.Names, test.ShouldResemble, []string{"fixture"})
+				test.That(t, ids.Empty(), test.ShouldBeFalse)
+			},
+		},
+		{
+			name:  "names only",
+			input: map[string]any{"names": []any{"a", "b"}},
+			expected: func(t *testing.T, ids *Identifiers, err error) {
+				test.That(t, err, test.ShouldBeNil)
+				test.That(t, ids.UUIDs, test.ShouldBeEmpty)
+				test.That(t, ids.Names, test.ShouldResemble, []string{"a", "b"})
+			},
+		},
+		{
+			name:  "empty selection",
+			input: map[string]any{},
+			expected: func(t *testing.T, ids *Identifiers, err error) {
+				test.That(t, err, test.ShouldNotBeNil)
+				test.That(t, err.Error(), test.ShouldContainSubstring, "at least one")
+			},
+		},
+		{
+			name:  "invalid uuid",
+			input: map[string]any{"uuids": []any{"not-a-uuid"}},
+			expected: func(t *testing.T, ids *Identifiers, err error) {
+				test.That(t, err, test.ShouldNotBeNil)
+				test.That(t, err.Error(), test.ShouldContainSubstring, "index 0")
+			},
+		},
+		{
+			name:  "non-string name",
+			input: map[string]any{"names": []any{"a", 1}},
+			expected: func(t *testing.T, ids *Identifiers, err error) {
+				test.That(t, err, test.ShouldNotBeNil)
+				test.That(t, err.Error(), test.ShouldContainSubstring, "names[1]")
+			},
+		},
+		{
+			name:  "not an object",
+			input: []any{"fixture"},
+			expected: func(t *testing.T, ids *Identifiers, err error) {
+				test.That(t, err, test.ShouldNotBeNil)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ParseIdentifiers(tt.input)
+			tt.expected(t, result, err)
+		})
+	}
+}
+
+func TestParseSelection(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		input    any
+		expected *Selection
+		err      string
+	}{
+		{name: "all", input: "all", expected: &Selection{All: true}},
+		{name: "all flag", input: map[string]any{"all": true}, expected: &Selection{All: true}},
+		{
+			name:     "layers and names",
+			input:    map[string]any{"layers": []any{"fixtures"}, "names": []any{"tool"}},
+			expected: &Selection{Layers: []string{"fixtures"}, Names: []string{"tool"}},
+		},
+		{name: "empty selection", input: map[string]any{"all": false}, err: "at least one"},
+		{name: "other string", input: "none", err: "Expected \"all\""},
+		{name: "invalid uuid", input: map[string]any{"uuids": []any{"not-a-uuid"}}, err: "index 0"},
+		{name: "non-string layer", input: map[string]any{"layers": []any{1}}, err: "layers[0]"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			selection, err := ParseSelection(tc.input)
+			if tc.err != "" {
+				test.That(t, err, test.ShouldNotBeNil)
+				test.That(t, err.Error(), test.ShouldContainSubstring, tc.err)
+				return
+			}
+
+			test.That(t, err, test.ShouldBeNil)
+			test.That(t, selection, test.ShouldResemble, tc.expected)
+		})
+	}
+}
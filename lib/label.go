@@ -0,0 +1,130 @@
+package lib
+
+import (
+	"fmt"
+
+	commonPB "go.viam.com/api/common/v1"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// DefaultLabelColor is the color used for labels drawn without an explicit color (white).
+var DefaultLabelColor = Color{R: 255, G: 255, B: 255}
+
+// CreateLabel creates a transform drawing a text label at a pose. Labels have no geometry; the viewer renders the
+// text from the metadata.
+//
+// Parameters:
+//   - pose: Position of the label (required)
+//   - text: Text of the label (required)
+//   - name: Name for the label frame (empty string will generate "label-{uuid}")
+//   - uuid: Optional UUID bytes (generates new UUID if nil)
+//   - color: Optional color (defaults to white if nil)
+//   - parentFrame: Optional parent frame (defaults to "world" if empty)
+//
+// Returns the created label transform or an error if creation fails.
+func CreateLabel(pose *commonPB.Pose, text string, name string, uuid []byte, color *Color, parentFrame string) (*commonPB.Transform, error) {
+	if pose == nil {
+		return nil, FieldErrorf("pose", "pose is required")
+	}
+
+	if text == "" {
+		return nil, FieldErrorf("text", "text is required")
+	}
+
+	var id UUID
+	if uuid == nil {
+		id = GenerateUUID()
+	} else {
+		parsed, err := UUIDFromBytes(uuid)
+		if err != nil {
+			return nil, err
+		}
+
+		id = *parsed
+	}
+
+	if name == "" {
+		name = fmt.Sprintf("label-%s", id.String())
+	}
+
+	if color == nil {
+		color = &DefaultLabelColor
+	}
+
+	metadata, err := structpb.NewStruct(map[string]any{
+		"shape": "label",
+		"text":  text,
+		"color": map[string]any{
+			"r": int(color.R),
+			"g": int(color.G),
+			"b": int(color.B),
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	parent := "world"
+	if parentFrame != "" {
+		parent = parentFrame
+	}
+
+	return &commonPB.Transform{
+		ReferenceFrame: name,
+		PoseInObserverFrame: &commonPB.PoseInFrame{
+			ReferenceFrame: parent,
+			Pose:           pose,
+		},
+		Uuid:     id.Bytes(),
+		Metadata: metadata,
+	}, nil
+}
+
+// LabelJSON represents a text label configuration in JSON format.
+type LabelJSON struct {
+	Pose        PoseJSON `json:"pose"`                   // Position of the label (required)
+	Text        string   `json:"text"`                   // Text of the label (required)
+	Name        string   `json:"name,omitempty"`         // Name of the label frame (optional, defaults to "label-{uuid}")
+	UUID        string   `json:"uuid,omitempty"`         // UUID string (optional, generates new UUID if not provided)
+	Color       *Color   `json:"color,omitempty"`        // RGB color (optional, defaults to white)
+	ParentFrame string   `json:"parent_frame,omitempty"` // Parent reference frame (optional, defaults to "world")
+	Layer       string   `json:"layer,omitempty"`        // Layer of the shape, kept in its "layer" metadata (optional, defaults to "default")
+}
+
+// ParseLabel parses a single text label from JSON data.
+// It expects a label object with required pose and text fields and optional fields.
+//
+// Parameters:
+//   - item: JSON object containing label data
+//
+// Returns the parsed label configuration or an error if parsing fails.
+func ParseLabel(item any) (*LabelJSON, error) {
+	labelMap, ok := item.(map[string]any)
+	if !ok {
+		return nil, Errorf(ErrInvalidArgument, "Expected label object, got %T", item)
+	}
+
+	if labelMap["pose"] == nil {
+		return nil, FieldErrorf("pose", "Missing required 'pose' field")
+	}
+
+	color := DefaultLabelColor
+	label := &LabelJSON{
+		Color: &color,
+	}
+	if err := Decode(item, label); err != nil {
+		return nil, err
+	}
+
+	if label.Text == "" {
+		return nil, FieldErrorf("text", "Missing required 'text' field")
+	}
+
+	if label.UUID != "" {
+		if _, err := UUIDFromString(label.UUID); err != nil {
+			return nil, FieldErrorf("uuid", "Failed to parse UUID: %w", err)
+		}
+	}
+
+	return label, nil
+}
@@ -0,0 +1,103 @@
+package lib
+
+import (
+	"testing"
+
+	commonPB "go.viam.com/api/common/v1"
+	"go.viam.com/test"
+)
+
+func TestCreateLabel(t *testing.T) {
+	t.Run("valid label", func(t *testing.T) {
+		label, err := CreateLabel(&commonPB.Pose{Z: 100, OZ: 1}, "gripper", "gripper-label", testUUIDBytes, nil, "arm")
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, label.ReferenceFrame, test.ShouldEqual, "gripper-label")
+		test.That(t, label.PoseInObserverFrame.ReferenceFrame, test.ShouldEqual, "arm")
+		test.That(t, label.PoseInObserverFrame.Pose.Z, test.ShouldEqual, 100.0)
+		test.That(t, label.PhysicalObject, test.ShouldBeNil)
+
+		metadata := label.Metadata.AsMap()
+		test.That(t, metadata["shape"], test.ShouldEqual, "label")
+		test.That(t, metadata["text"], test.ShouldEqual, "gripper")
+		test.That(t, metadata["color"], test.ShouldResemble, map[string]any{"r": 255.0, "g": 255.0, "b": 255.0})
+	})
+
+	t.Run("defaults", func(t *testing.T) {
+		label, err := CreateLabel(&commonPB.Pose{OZ: 1}, "text", "", testUUIDBytes, nil, "")
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, label.ReferenceFrame, test.ShouldEqual, "label-"+testUUID.String())
+		test.That(t, label.PoseInObserverFrame.ReferenceFrame, test.ShouldEqual, "world")
+	})
+
+	t.Run("missing text", func(t *testing.T) {
+		_, err := CreateLabel(&commonPB.Pose{OZ: 1}, "", "", nil, nil, "")
+		test.That(t, err, test.ShouldNotBeNil)
+	})
+}
+
+func TestParseLabel(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    any
+		expected func(*testing.T, *LabelJSON, error)
+	}{
+		{
+			name: "valid label",
+			input: map[string]any{
+				"pose":         map[string]any{"x": 10.0, "z": 100.0},
+				"text":         "gripper",
+				"name":         "gripper-label",
+				"color":        map[string]any{"r": 0, "g": 255, "b": 0},
+				"parent_frame": "arm",
+			},
+			expected: func(t *testing.T, label *LabelJSON, err error) {
+				test.That(t, err, test.ShouldBeNil)
+				test.That(t, label.Text, test.ShouldEqual, "gripper")
+				test.That(t, label.Name, test.ShouldEqual, "gripper-label")
+				test.That(t, label.Pose.X, test.ShouldEqual, 10.0)
+				test.That(t, label.Pose.Z, test.ShouldEqual, 100.0)
+				test.That(t, label.Color, test.ShouldResemble, &Color{G: 255})
+				test.That(t, label.ParentFrame, test.ShouldEqual, "arm")
+			},
+		},
+		{
+			name:  "defaults",
+			input: map[string]any{"pose": map[string]any{}, "text": "origin"},
+			expected: func(t *testing.T, label *LabelJSON, err error) {
+				test.That(t, err, test.ShouldBeNil)
+				test.That(t, label.Color, test.ShouldResemble, &DefaultLabelColor)
+				test.That(t, label.Name, test.ShouldBeEmpty)
+			},
+		},
+		{
+			name:  "missing text",
+			input: map[string]any{"pose": map[string]any{}},
+			expected: func(t *testing.T, label *LabelJSON, err error) {
+				test.That(t, err, test.ShouldNotBeNil)
+				test.That(t, err.Error(), test.ShouldContainSubstring, "Missing required 'text' field")
+			},
+		},
+		{
+			name:  "missing pose",
+			input: map[string]any{"text": "origin"},
+			expected: func(t *testing.T, label *LabelJSON, err error) {
+				test.That(t, err, test.ShouldNotBeNil)
+				test.That(t, err.Error(), test.ShouldContainSubstring, "Missing required 'pose' field")
+			},
+		},
+		{
+			name:  "name not a string",
+			input: map[string]any{"pose": map[string]any{}, "text": "origin", "name": 1.0},
+			expected: func(t *testing.T, label *LabelJSON, err error) {
+				test.That(t, err, test.ShouldNotBeNil)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			label, err := ParseLabel(tt.input)
+			tt.expected(t, label, err)
+		})
+	}
+}
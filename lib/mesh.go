@@ -0,0 +1,215 @@
+package lib
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	commonPB "go.viam.com/api/common/v1"
+	"go.viam.com/rdk/spatialmath"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// DefaultMeshColor is the color used for meshes drawn without an explicit color (blue).
+var DefaultMeshColor = Color{R: 0, G: 0, B: 255}
+
+// MeshJSON represents a mesh configuration in JSON format.
+// It contains all the necessary information to load a PLY file and place it in the world state.
+type MeshJSON struct {
+	ModelPath   string   `json:"model_path"`             // Path to the PLY file (required)
+	Pose        PoseJSON `json:"pose,omitempty"`         // Position and orientation (optional, defaults to the origin)
+	Name        string   `json:"name,omitempty"`         // Name of the mesh frame (optional, defaults to "mesh-{uuid}")
+	UUID        string   `json:"uuid,omitempty"`         // UUID string (optional, generates new UUID if not provided)
+	Color       *Color   `json:"color,omitempty"`        // RGB color (optional, defaults to blue)
+	ParentFrame string   `json:"parent_frame,omitempty"` // Parent reference frame (optional, defaults to "world")
+	Layer       string   `json:"layer,omitempty"`        // Layer of the shape, kept in its "layer" metadata (optional, defaults to "default")
+	Scale       float64  `json:"scale,omitempty"`        // Uniform scale applied to the vertices (optional, defaults to 1)
+	Label       string   `json:"label,omitempty"`        // Geometry label (optional, defaults to the model path)
+	Watch       bool     `json:"watch,omitempty"`        // Reload the mesh when the file changes (optional, defaults to false)
+
+	IgnoreFileColors bool `json:"ignore_file_colors,omitempty"` // Draw in the single color even if the file has vertex or face colors (optional, defaults to false)
+}
+
+// ValidateMeshFile checks that a mesh file exists and is in a supported format.
+// Only PLY files are supported; the extension and the header magic are both checked.
+//
+// Parameters:
+//   - path: Path to the mesh file
+//
+// Returns an error describing why the file cannot be drawn, or nil.
+func ValidateMeshFile(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileError(err)
+	}
+
+	if info.IsDir() {
+		return FieldErrorf("model_path", "%s is a directory", path)
+	}
+
+	if ext := strings.ToLower(filepath.Ext(path)); ext != ".ply" {
+		return formatError("unsupported mesh format %q, expected .ply", ext)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return fileError(err)
+	}
+	defer file.Close()
+
+	magic, err := bufio.NewReader(file).ReadString('\n')
+	if err != nil || strings.TrimSpace(magic) != "ply" {
+		return formatError("%s is not a PLY file", path)
+	}
+
+	return nil
+}
+
+// ParseMesh parses a single mesh from JSON data.
+// It expects a mesh object with a required model_path and optional fields.
+//
+// Parameters:
+//   - item: JSON object containing mesh data
+//
+// Returns the parsed mesh configuration or an error if parsing fails.
+func ParseMesh(item any) (*MeshJSON, error) {
+	if _, ok := item.(map[string]any); !ok {
+		return nil, Errorf(ErrInvalidArgument, "Expected mesh object, got %T", item)
+	}
+
+	color := DefaultMeshColor
+	mesh := &MeshJSON{
+		Color: &color,
+		Scale: 1,
+	}
+	if err := Decode(item, mesh); err != nil {
+		return nil, err
+	}
+
+	if mesh.ModelPath == "" {
+		return nil, FieldErrorf("model_path", "Missing required 'model_path' field")
+	}
+
+	if mesh.UUID != "" {
+		if _, err := UUIDFromString(mesh.UUID); err != nil {
+			return nil, FieldErrorf("uuid", "Failed to parse UUID: %w", err)
+		}
+	}
+
+	if mesh.Scale <= 0 {
+		return nil, FieldErrorf("scale", "Expected positive number for scale, got %v", mesh.Scale)
+	}
+
+	return mesh, nil
+}
+
+// ScaleMesh returns a copy of a mesh with every vertex uniformly scaled about the mesh origin.
+// The underlying PLY data is rewritten in place, so vertex order and per-vertex properties such as
+// colors still line up with the scaled mesh. Meshes whose data is not an ASCII PLY file are rebuilt
+// from their scaled triangles instead.
+//
+// Parameters:
+//   - mesh: Mesh to scale
+//   - scale: Scale factor (1 returns the mesh unchanged)
+//
+// Returns the scaled mesh or an error if the mesh data cannot be read.
+func ScaleMesh(mesh *spatialmath.Mesh, scale float64) (*spatialmath.Mesh, error) {
+	if scale == 1 {
+		return mesh, nil
+	}
+
+	scaled, err := ScalePLY(mesh.ToProtobuf().GetMesh().GetMesh(), scale)
+	if errors.Is(err, ErrUnsupportedFormat) {
+		triangles := make([]*spatialmath.Triangle, 0, len(mesh.Triangles()))
+		for _, triangle := range mesh.Triangles() {
+			points := triangle.Points()
+			triangles = append(triangles, spatialmath.NewTriangle(points[0].Mul(scale), points[1].Mul(scale),
+				points[2].Mul(scale)))
+		}
+
+		return spatialmath.NewMesh(mesh.Pose(), triangles, mesh.Label()), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return spatialmath.NewMeshFromProto(mesh.Pose(), &commonPB.Mesh{ContentType: "ply", Mesh: scaled}, mesh.Label())
+}
+
+// CreateMesh creates a new mesh transform from a mesh geometry and individual components.
+// It generates a UUID if none is provided and uses default values for optional parameters.
+//
+// Parameters:
+//   - geometry: Protobuf geometry of the mesh (required)
+//   - pose: Position and orientation of the mesh (defaults to the origin if nil)
+//   - name: Name for the mesh frame (empty string will generate "mesh-{uuid}")
+//   - uuid: Optional UUID bytes (generates new UUID if nil)
+//   - color: Optional color (defaults to blue if nil)
+//   - parentFrame: Optional parent frame (defaults to "world" if empty)
+//   - colors: Optional per-vertex and per-face colors, sent alongside the single color (nil for none)
+//
+// Returns the created mesh transform or an error if creation fails.
+func CreateMesh(geometry *commonPB.Geometry, pose *commonPB.Pose, name string, uuid []byte, color *Color, parentFrame string, colors *MeshColors) (*commonPB.Transform, error) {
+	if geometry == nil {
+		return nil, Errorf(ErrInvalidArgument, "geometry is required")
+	}
+
+	var id UUID
+	if uuid == nil {
+		id = GenerateUUID()
+	} else {
+		parsed, err := UUIDFromBytes(uuid)
+		if err != nil {
+			return nil, err
+		}
+
+		id = *parsed
+	}
+
+	if name == "" {
+		name = fmt.Sprintf("mesh-%s", id.String())
+	}
+
+	if pose == nil {
+		pose = &commonPB.Pose{}
+	}
+
+	if pose.OX == 0 && pose.OY == 0 && pose.OZ == 0 {
+		pose = &commonPB.Pose{X: pose.X, Y: pose.Y, Z: pose.Z, OZ: 1, Theta: pose.Theta}
+	}
+
+	if color == nil {
+		color = &DefaultMeshColor
+	}
+
+	fields := colors.Metadata()
+	fields["color"] = map[string]any{
+		"r": int(color.R),
+		"g": int(color.G),
+		"b": int(color.B),
+	}
+
+	metadata, err := structpb.NewStruct(fields)
+	if err != nil {
+		return nil, err
+	}
+
+	parent := "world"
+	if parentFrame != "" {
+		parent = parentFrame
+	}
+
+	return &commonPB.Transform{
+		ReferenceFrame: name,
+		PoseInObserverFrame: &commonPB.PoseInFrame{
+			ReferenceFrame: parent,
+			Pose:           pose,
+		},
+		Uuid:           id.Bytes(),
+		PhysicalObject: geometry,
+		Metadata:       metadata,
+	}, nil
+}
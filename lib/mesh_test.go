@@ -0,0 +1,184 @@
+package lib
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	commonPB "go.viam.com/api/common/v1"
+	"go.viam.com/test"
+)
+
+func TestParseMesh(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    any
+		expected func(*testing.T, *MeshJSON, error)
+	}{
+		{
+			name: "valid mesh",
+			input: map[string]any{
+				"model_path":   "/meshes/fixture.ply",
+				"name":         "fixture",
+				"uuid":         "550e8400-e29b-41d4-a716-446655440000",
+				"parent_frame": "table",
+				"label":        "fixture-label",
+				"scale":        0.001,
+				"watch":        true,
+				"color":        map[string]any{"r": 255, "g": 0, "b": 0},
+				"pose": map[string]any{
+					"x":   100.0,
+					"o_z": 1.0,
+				},
+			},
+			expected: func(t *testing.T, mesh *MeshJSON, err error) {
+				test.That(t, err, test.ShouldBeNil)
+				test.That(t, mesh.ModelPath, test.ShouldEqual, "/meshes/fixture.ply")
+				test.That(t, mesh.Name, test.ShouldEqual, "fixture")
+				test.That(t, mesh.UUID, test.ShouldEqual, "550e8400-e29b-41d4-a716-446655440000")
+				test.That(t, mesh.ParentFrame, test.ShouldEqual, "table")
+				test.That(t, mesh.Label, test.ShouldEqual, "fixture-label")
+				test.That(t, mesh.Scale, test.ShouldEqual, 0.001)
+				test.That(t, mesh.Watch, test.ShouldBeTrue)
+				test.That(t, mesh.Color, test.ShouldResemble, &Color{R: 255, G: 0, B: 0})
+				test.That(t, mesh.Pose.X, test.ShouldEqual, 100.0)
+				test.That(t, mesh.Pose.OZ, test.ShouldEqual, 1.0)
+			},
+		},
+		{
+			name:  "defaults",
+			input: map[string]any{"model_path": "/meshes/fixture.ply"},
+			expected: func(t *testing.T, mesh *MeshJSON, err error) {
+				test.That(t, err, test.ShouldBeNil)
+				test.That(t, mesh.Color, test.ShouldResemble, &DefaultMeshColor)
+				test.That(t, mesh.Scale, test.ShouldEqual, 1.0)
+				test.That(t, mesh.Name, test.ShouldBeEmpty)
+			},
+		},
+		{
+			name:  "explicit black",
+			input: map[string]any{"model_path": "/meshes/fixture.ply", "color": map[string]any{"r": 0, "g": 0, "b": 0}},
+			expected: func(t *testing.T, mesh *MeshJSON, err error) {
+				test.That(t, err, test.ShouldBeNil)
+				test.That(t, mesh.Color, test.ShouldResemble, &Color{})
+			},
+		},
+		{
+			name:  "missing model path",
+			input: map[string]any{"name": "fixture"},
+			expected: func(t *testing.T, mesh *MeshJSON, err error) {
+				test.That(t, err, test.ShouldNotBeNil)
+				test.That(t, err.Error(), test.ShouldContainSubstring, "model_path")
+			},
+		},
+		{
+			name:  "not an object",
+			input: "/meshes/fixture.ply",
+			expected: func(t *testing.T, mesh *MeshJSON, err error) {
+				test.That(t, err, test.ShouldNotBeNil)
+				test.That(t, err.Error(), test.ShouldContainSubstring, "Expected mesh object")
+			},
+		},
+		{
+			name:  "invalid uuid",
+			input: map[string]any{"model_path": "/meshes/fixture.ply", "uuid": "not-a-uuid"},
+			expected: func(t *testing.T, mesh *MeshJSON, err error) {
+				test.That(t, err, test.ShouldNotBeNil)
+				test.That(t, err.Error(), test.ShouldContainSubstring, "Failed to parse UUID")
+			},
+		},
+		{
+			name:  "invalid watch",
+			input: map[string]any{"model_path": "/meshes/fixture.ply", "watch": "yes"},
+			expected: func(t *testing.T, mesh *MeshJSON, err error) {
+				test.That(t, err, test.ShouldNotBeNil)
+				test.That(t, err.Error(), test.ShouldContainSubstring, "watch")
+			},
+		},
+		{
+			name:  "invalid scale",
+			input: map[string]any{"model_path": "/meshes/fixture.ply", "scale": -1},
+			expected: func(t *testing.T, mesh *MeshJSON, err error) {
+				test.That(t, err, test.ShouldNotBeNil)
+				test.That(t, err.Error(), test.ShouldContainSubstring, "scale")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ParseMesh(tt.input)
+			tt.expected(t, result, err)
+		})
+	}
+}
+
+func TestValidateMeshFile(t *testing.T) {
+	dir := t.TempDir()
+	valid := writeTestFile(t, dir, "mesh.ply", testPLY)
+	wrongExtension := writeTestFile(t, dir, "mesh.obj", testPLY)
+	wrongMagic := writeTestFile(t, dir, "fake.ply", "solid cube\n")
+
+	test.That(t, ValidateMeshFile(valid), test.ShouldBeNil)
+	test.That(t, errors.Is(ValidateMeshFile(wrongExtension), ErrUnsupportedFormat), test.ShouldBeTrue)
+	test.That(t, errors.Is(ValidateMeshFile(wrongMagic), ErrUnsupportedFormat), test.ShouldBeTrue)
+	test.That(t, errors.Is(ValidateMeshFile(dir), ErrInvalidArgument), test.ShouldBeTrue)
+	test.That(t, errors.Is(ValidateMeshFile(filepath.Join(dir, "missing.ply")), ErrFileNotFound), test.ShouldBeTrue)
+}
+
+func TestScaleMesh(t *testing.T) {
+	path := writeTestFile(t, t.TempDir(), "mesh.ply", testPLY)
+	cached, err := NewMeshCache(0, 0).Load(path)
+	test.That(t, err, test.ShouldBeNil)
+
+	unscaled, err := ScaleMesh(cached.Mesh, 1)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, unscaled, test.ShouldEqual, cached.Mesh)
+
+	scaled, err := ScaleMesh(cached.Mesh, 2)
+	test.That(t, err, test.ShouldBeNil)
+	original := cached.Mesh.Triangles()[0].Points()
+	points := scaled.Triangles()[0].Points()
+	for i := range points {
+		test.That(t, points[i].X, test.ShouldAlmostEqual, original[i].X*2)
+		test.That(t, points[i].Y, test.ShouldAlmostEqual, original[i].Y*2)
+		test.That(t, points[i].Z, test.ShouldAlmostEqual, original[i].Z*2)
+	}
+}
+
+func TestCreateMesh(t *testing.T) {
+	geometry := &commonPB.Geometry{GeometryType: &commonPB.Geometry_Mesh{Mesh: &commonPB.Mesh{ContentType: "ply"}}}
+
+	t.Run("defaults", func(t *testing.T) {
+		mesh, err := CreateMesh(geometry, nil, "", nil, nil, "", nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, mesh.ReferenceFrame, test.ShouldStartWith, "mesh-")
+		test.That(t, mesh.PoseInObserverFrame.ReferenceFrame, test.ShouldEqual, "world")
+		test.That(t, mesh.PoseInObserverFrame.Pose.OZ, test.ShouldEqual, 1.0)
+		test.That(t, len(mesh.Uuid), test.ShouldEqual, 16)
+		test.That(t, mesh.PhysicalObject, test.ShouldEqual, geometry)
+
+		color := mesh.Metadata.Fields["color"].GetStructValue()
+		test.That(t, color.Fields["b"].GetNumberValue(), test.ShouldEqual, DefaultMeshColor.B)
+		_, ok := mesh.Metadata.Fields["vertex_colors"]
+		test.That(t, ok, test.ShouldBeFalse)
+	})
+
+	t.Run("explicit values", func(t *testing.T) {
+		pose := &commonPB.Pose{X: 10, OX: 1}
+		colors := &MeshColors{Face: []byte{1, 2, 3, 4}}
+		mesh, err := CreateMesh(geometry, pose, "fixture", testUUIDBytes, &Color{R: 1, G: 2, B: 3}, "table", colors)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, mesh.Metadata.Fields["face_colors"].GetStringValue(), test.ShouldEqual, "AQIDBA==")
+		test.That(t, mesh.Metadata.Fields["color_encoding"].GetStringValue(), test.ShouldEqual, ColorEncoding)
+		test.That(t, mesh.ReferenceFrame, test.ShouldEqual, "fixture")
+		test.That(t, mesh.PoseInObserverFrame.ReferenceFrame, test.ShouldEqual, "table")
+		test.That(t, mesh.PoseInObserverFrame.Pose, test.ShouldEqual, pose)
+		test.That(t, mesh.Uuid, test.ShouldResemble, testUUIDBytes)
+	})
+
+	t.Run("nil geometry returns error", func(t *testing.T) {
+		_, err := CreateMesh(nil, nil, "", nil, nil, "", nil)
+		test.That(t, err, test.ShouldNotBeNil)
+	})
+}
@@ -0,0 +1,262 @@
+package lib
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"sync"
+	"time"
+
+	commonPB "go.viam.com/api/common/v1"
+	"go.viam.com/rdk/spatialmath"
+)
+
+const (
+	// DefaultMeshCacheMaxBytes is the default approximate memory budget of a MeshCache (256 MiB).
+	DefaultMeshCacheMaxBytes int64 = 256 * 1024 * 1024
+	// DefaultMeshCacheMaxEntries is the default number of parsed meshes held by a MeshCache.
+	DefaultMeshCacheMaxEntries = 64
+
+	// approximate in-memory size of a parsed triangle (three points, a normal and bookkeeping)
+	triangleSizeBytes = 128
+)
+
+// CachedMesh is a parsed mesh held by a MeshCache along with its protobuf geometry.
+// Cached values are shared between callers and must be treated as read-only.
+type CachedMesh struct {
+	Path     string             // Path the mesh was first loaded from
+	Hash     string             // Hex encoded SHA-256 of the file contents
+	ModTime  time.Time          // Modification time of the file when it was loaded
+	Mesh     *spatialmath.Mesh  // Parsed mesh
+	Geometry *commonPB.Geometry // Protobuf geometry of the mesh
+	Colors   *MeshColors        // Per-vertex and per-face colors of the file
+	Size     int64              // Approximate memory used by the entry in bytes
+}
+
+// MeshCacheStats reports the usage of a MeshCache.
+type MeshCacheStats struct {
+	Hits       uint64 `json:"hits"`
+	Misses     uint64 `json:"misses"`
+	Evictions  uint64 `json:"evictions"`
+	Entries    int    `json:"entries"`
+	Bytes      int64  `json:"bytes"`
+	MaxEntries int    `json:"max_entries"`
+	MaxBytes   int64  `json:"max_bytes"`
+}
+
+// ToMap converts the stats to a map suitable for a DoCommand response.
+func (s MeshCacheStats) ToMap() map[string]any {
+	return map[string]any{
+		"hits":        int64(s.Hits),
+		"misses":      int64(s.Misses),
+		"evictions":   int64(s.Evictions),
+		"entries":     s.Entries,
+		"bytes":       s.Bytes,
+		"max_entries": s.MaxEntries,
+		"max_bytes":   s.MaxBytes,
+	}
+}
+
+type meshFileKey struct {
+	modTime time.Time
+	size    int64
+	hash    string
+}
+
+// MeshCache is a bounded, thread-safe LRU cache of parsed PLY meshes.
+// Entries are keyed by content hash, and each path remembers the modification time and
+// size it was last seen with so unchanged files are served without being read again.
+type MeshCache struct {
+	mu sync.Mutex
+
+	maxBytes   int64
+	maxEntries int
+
+	lru     *list.List
+	entries map[string]*list.Element
+	files   map[string]meshFileKey
+	bytes   int64
+
+	hits      uint64
+	misses    uint64
+	evictions uint64
+}
+
+// NewMeshCache creates a mesh cache bounded by an approximate memory budget and an entry count.
+// Non-positive limits fall back to DefaultMeshCacheMaxBytes and DefaultMeshCacheMaxEntries.
+//
+// Parameters:
+//   - maxBytes: Approximate memory budget in bytes
+//   - maxEntries: Maximum number of cached meshes
+//
+// Returns the new cache.
+func NewMeshCache(maxBytes int64, maxEntries int) *MeshCache {
+	if maxBytes <= 0 {
+		maxBytes = DefaultMeshCacheMaxBytes
+	}
+
+	if maxEntries <= 0 {
+		maxEntries = DefaultMeshCacheMaxEntries
+	}
+
+	return &MeshCache{
+		maxBytes:   maxBytes,
+		maxEntries: maxEntries,
+		lru:        list.New(),
+		entries:    make(map[string]*list.Element),
+		files:      make(map[string]meshFileKey),
+	}
+}
+
+// Load returns the parsed mesh for the PLY file at path.
+// A file whose modification time and size are unchanged is served from the cache without
+// being read. Otherwise the file is read and hashed, and only parsed if no entry with the
+// same contents is cached. Binary PLY files are parsed like ASCII ones, keeping their vertex
+// and face colors.
+//
+// Parameters:
+//   - path: Path to the PLY file
+//
+// Returns the cached mesh or an error if the file cannot be read or parsed.
+func (c *MeshCache) Load(path string) (*CachedMesh, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fileError(err)
+	}
+
+	if info.IsDir() {
+		return nil, FieldErrorf("model_path", "%s is a directory", path)
+	}
+
+	c.mu.Lock()
+	if key, ok := c.files[path]; ok && key.modTime.Equal(info.ModTime()) && key.size == info.Size() {
+		if entry, ok := c.lookup(key.hash); ok {
+			c.mu.Unlock()
+			return entry, nil
+		}
+	}
+	c.mu.Unlock()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fileError(err)
+	}
+
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+	key := meshFileKey{modTime: info.ModTime(), size: info.Size(), hash: hash}
+
+	c.mu.Lock()
+	if entry, ok := c.lookup(hash); ok {
+		c.files[path] = key
+		c.mu.Unlock()
+		return entry, nil
+	}
+	c.misses++
+	c.mu.Unlock()
+
+	header, err := readPLYHeader(data)
+	if err != nil {
+		return nil, err
+	}
+
+	colors, err := plyColors(data, header)
+	if err != nil {
+		return nil, err
+	}
+
+	// the RDK only parses ASCII PLY files
+	ascii, err := asciiPLY(data, header)
+	if err != nil {
+		return nil, err
+	}
+
+	mesh, err := spatialmath.NewMeshFromProto(
+		spatialmath.NewZeroPose(),
+		&commonPB.Mesh{ContentType: "ply", Mesh: ascii},
+		path,
+	)
+	if err != nil {
+		return nil, formatError("%w", err)
+	}
+
+	entry := &CachedMesh{
+		Path:     path,
+		Hash:     hash,
+		ModTime:  info.ModTime(),
+		Mesh:     mesh,
+		Geometry: mesh.ToProtobuf(),
+		Colors:   colors,
+		Size:     int64(len(data)) + int64(len(mesh.Triangles()))*triangleSizeBytes + int64(len(colors.Vertex)+len(colors.Face)),
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if existing, ok := c.entries[hash]; ok {
+		// another caller parsed the same contents while we were
+		c.lru.MoveToFront(existing)
+		c.files[path] = key
+		return existing.Value.(*CachedMesh), nil
+	}
+
+	if entry.Size > c.maxBytes {
+		// too large to ever fit, hand it back without caching it
+		return entry, nil
+	}
+
+	c.entries[hash] = c.lru.PushFront(entry)
+	c.files[path] = key
+	c.bytes += entry.Size
+	c.evict()
+
+	return entry, nil
+}
+
+// Stats returns a snapshot of the cache usage.
+func (c *MeshCache) Stats() MeshCacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return MeshCacheStats{
+		Hits:       c.hits,
+		Misses:     c.misses,
+		Evictions:  c.evictions,
+		Entries:    c.lru.Len(),
+		Bytes:      c.bytes,
+		MaxEntries: c.maxEntries,
+		MaxBytes:   c.maxBytes,
+	}
+}
+
+// lookup returns the entry for hash and marks it as recently used. Must be called with mu held.
+func (c *MeshCache) lookup(hash string) (*CachedMesh, bool) {
+	element, ok := c.entries[hash]
+	if !ok {
+		return nil, false
+	}
+
+	c.hits++
+	c.lru.MoveToFront(element)
+	return element.Value.(*CachedMesh), true
+}
+
+// evict drops least recently used entries until the cache is within its limits. Must be called with mu held.
+func (c *MeshCache) evict() {
+	for c.lru.Len() > 0 && (c.bytes > c.maxBytes || c.lru.Len() > c.maxEntries) {
+		oldest := c.lru.Back()
+		entry := oldest.Value.(*CachedMesh)
+
+		c.lru.Remove(oldest)
+		delete(c.entries, entry.Hash)
+		for path, key := range c.files {
+			if key.hash == entry.Hash {
+				delete(c.files, path)
+			}
+		}
+
+		c.bytes -= entry.Size
+		c.evictions++
+	}
+}
@@ -0,0 +1,172 @@
+package lib
+
+import (
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"go.viam.com/test"
+)
+
+const testPLY = `ply
+format ascii 1.0
+element vertex 3
+property float x
+property float y
+property float z
+element face 1
+property list uchar int vertex_indices
+end_header
+0 0 0
+1 0 0
+0 1 0
+3 0 1 2
+`
+
+const otherTestPLY = `ply
+format ascii 1.0
+element vertex 3
+property float x
+property float y
+property float z
+element face 1
+property list uchar int vertex_indices
+end_header
+0 0 0
+2 0 0
+0 2 0
+3 0 1 2
+`
+
+func writeTestFile(t *testing.T, dir, name, contents string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	err := os.WriteFile(path, []byte(contents), 0o644)
+	test.That(t, err, test.ShouldBeNil)
+	return path
+}
+
+func TestMeshCache(t *testing.T) {
+	t.Run("hit on unchanged file", func(t *testing.T) {
+		path := writeTestFile(t, t.TempDir(), "mesh.ply", testPLY)
+		cache := NewMeshCache(0, 0)
+
+		first, err := cache.Load(path)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, len(first.Mesh.Triangles()), test.ShouldEqual, 1)
+		test.That(t, first.Geometry.GetMesh(), test.ShouldNotBeNil)
+
+		second, err := cache.Load(path)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, second, test.ShouldEqual, first)
+
+		stats := cache.Stats()
+		test.That(t, stats.Hits, test.ShouldEqual, 1)
+		test.That(t, stats.Misses, test.ShouldEqual, 1)
+		test.That(t, stats.Entries, test.ShouldEqual, 1)
+		test.That(t, stats.Bytes, test.ShouldEqual, first.Size)
+	})
+
+	t.Run("miss on changed contents", func(t *testing.T) {
+		path := writeTestFile(t, t.TempDir(), "mesh.ply", testPLY)
+		cache := NewMeshCache(0, 0)
+
+		first, err := cache.Load(path)
+		test.That(t, err, test.ShouldBeNil)
+
+		writeTestFile(t, filepath.Dir(path), "mesh.ply", otherTestPLY)
+		later := time.Now().Add(time.Minute)
+		test.That(t, os.Chtimes(path, later, later), test.ShouldBeNil)
+
+		second, err := cache.Load(path)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, second.Hash, test.ShouldNotEqual, first.Hash)
+		test.That(t, cache.Stats().Misses, test.ShouldEqual, 2)
+	})
+
+	t.Run("hit on identical contents", func(t *testing.T) {
+		dir := t.TempDir()
+		first := writeTestFile(t, dir, "a.ply", testPLY)
+		second := writeTestFile(t, dir, "b.ply", testPLY)
+		cache := NewMeshCache(0, 0)
+
+		_, err := cache.Load(first)
+		test.That(t, err, test.ShouldBeNil)
+		_, err = cache.Load(second)
+		test.That(t, err, test.ShouldBeNil)
+
+		stats := cache.Stats()
+		test.That(t, stats.Hits, test.ShouldEqual, 1)
+		test.That(t, stats.Misses, test.ShouldEqual, 1)
+		test.That(t, stats.Entries, test.ShouldEqual, 1)
+	})
+
+	t.Run("evicts least recently used", func(t *testing.T) {
+		dir := t.TempDir()
+		first := writeTestFile(t, dir, "a.ply", testPLY)
+		second := writeTestFile(t, dir, "b.ply", otherTestPLY)
+		cache := NewMeshCache(0, 1)
+
+		_, err := cache.Load(first)
+		test.That(t, err, test.ShouldBeNil)
+		_, err = cache.Load(second)
+		test.That(t, err, test.ShouldBeNil)
+		_, err = cache.Load(first)
+		test.That(t, err, test.ShouldBeNil)
+
+		stats := cache.Stats()
+		test.That(t, stats.Misses, test.ShouldEqual, 3)
+		test.That(t, stats.Evictions, test.ShouldEqual, 2)
+		test.That(t, stats.Entries, test.ShouldEqual, 1)
+	})
+
+	t.Run("does not cache entries over the byte limit", func(t *testing.T) {
+		path := writeTestFile(t, t.TempDir(), "mesh.ply", testPLY)
+		cache := NewMeshCache(1, 0)
+
+		entry, err := cache.Load(path)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, entry, test.ShouldNotBeNil)
+		test.That(t, cache.Stats().Entries, test.ShouldEqual, 0)
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		cache := NewMeshCache(0, 0)
+		_, err := cache.Load(filepath.Join(t.TempDir(), "missing.ply"))
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, os.IsNotExist(err), test.ShouldBeTrue)
+	})
+
+	t.Run("malformed file", func(t *testing.T) {
+		cache := NewMeshCache(0, 0)
+
+		// the face points past the last vertex
+		_, err := cache.Load(writeTestFile(t, t.TempDir(), "mesh.ply", strings.Replace(testPLY, "3 0 1 2", "3 0 1 7", 1)))
+		test.That(t, errors.Is(err, ErrUnsupportedFormat), test.ShouldBeTrue)
+
+		_, err = cache.Load(writeTestFile(t, t.TempDir(), "mesh.ply", "ply\nformat ascii 1.0\nelement vertex x\nend_header\n"))
+		test.That(t, errors.Is(err, ErrUnsupportedFormat), test.ShouldBeTrue)
+	})
+
+	t.Run("binary file", func(t *testing.T) {
+		data := binaryColoredTestPLY("binary_little_endian", binary.LittleEndian)
+		path := writeTestFile(t, t.TempDir(), "mesh.ply", string(data))
+
+		entry, err := NewMeshCache(0, 0).Load(path)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, len(entry.Mesh.Triangles()), test.ShouldEqual, 1)
+		test.That(t, entry.Mesh.Triangles()[0].Points()[1].X, test.ShouldAlmostEqual, 1000)
+
+		colors, err := ParsePLYColors([]byte(coloredTestPLY))
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, entry.Colors, test.ShouldResemble, colors)
+
+		scaled, err := ScaleMesh(entry.Mesh, 2)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, scaled.Triangles()[0].Points()[1].X, test.ShouldAlmostEqual, 2000)
+	})
+}
@@ -0,0 +1,601 @@
+package lib
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ColorEncoding identifies how per-vertex and per-face colors are packed in transform metadata:
+// four bytes (red, green, blue, alpha) per vertex or face, base64 encoded.
+const ColorEncoding = "rgba8"
+
+// MeshColors holds the per-vertex and per-face colors of a mesh file.
+// Colors are packed as four bytes (red, green, blue, alpha) per vertex or face, in file order.
+type MeshColors struct {
+	Vertex []byte // Packed vertex colors, nil if the file has none
+	Face   []byte // Packed face colors, nil if the file has none
+}
+
+// Empty reports whether the mesh has neither vertex nor face colors.
+func (c *MeshColors) Empty() bool {
+	return c == nil || (len(c.Vertex) == 0 && len(c.Face) == 0)
+}
+
+// Metadata returns the colors as transform metadata fields: "vertex_colors" and "face_colors"
+// hold the base64 encoded packed colors, and "color_encoding" names the packing.
+func (c *MeshColors) Metadata() map[string]any {
+	metadata := map[string]any{}
+	if c.Empty() {
+		return metadata
+	}
+
+	metadata["color_encoding"] = ColorEncoding
+	if len(c.Vertex) > 0 {
+		metadata["vertex_colors"] = base64.StdEncoding.EncodeToString(c.Vertex)
+	}
+
+	if len(c.Face) > 0 {
+		metadata["face_colors"] = base64.StdEncoding.EncodeToString(c.Face)
+	}
+
+	return metadata
+}
+
+// PLY body formats.
+const (
+	plyASCII              = "ascii"
+	plyBinaryLittleEndian = "binary_little_endian"
+	plyBinaryBigEndian    = "binary_big_endian"
+)
+
+type plyProperty struct {
+	name      string
+	dataType  string // Type of a scalar, or of the items of a list
+	countType string // Type of the length of a list, empty for scalars
+	list      bool
+}
+
+type plyElement struct {
+	name       string
+	count      int
+	properties []plyProperty
+}
+
+// plyHeader describes a PLY file: the format of its body, its elements in file order and where its body starts.
+type plyHeader struct {
+	format   string
+	elements []*plyElement
+	size     int // Length of the header in bytes, up to and including the end_header line
+}
+
+// readPLYHeader reads the header of an ASCII or binary PLY file.
+func readPLYHeader(data []byte) (*plyHeader, error) {
+	header := &plyHeader{}
+	offset := 0
+	for offset < len(data) {
+		end := bytes.IndexByte(data[offset:], '\n')
+		if end < 0 {
+			end = len(data) - offset
+		}
+		line := strings.TrimSuffix(string(data[offset:offset+end]), "\r")
+		offset = min(offset+end+1, len(data))
+
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		switch fields[0] {
+		case "ply", "comment", "obj_info":
+		case "format":
+			if len(fields) < 2 {
+				return nil, formatError("invalid PLY format line %q", line)
+			}
+
+			switch fields[1] {
+			case plyASCII, plyBinaryLittleEndian, plyBinaryBigEndian:
+				header.format = fields[1]
+			default:
+				return nil, formatError("unsupported PLY format %q", fields[1])
+			}
+		case "element":
+			if len(fields) < 3 {
+				return nil, formatError("invalid element line %q", line)
+			}
+
+			count, err := strconv.Atoi(fields[2])
+			if err != nil || count < 0 {
+				return nil, formatError("invalid element count in %q", line)
+			}
+
+			header.elements = append(header.elements, &plyElement{name: fields[1], count: count})
+		case "property":
+			if len(header.elements) == 0 {
+				return nil, formatError("property before element in %q", line)
+			}
+
+			var property plyProperty
+			if len(fields) >= 5 && fields[1] == "list" {
+				property = plyProperty{name: fields[4], dataType: fields[3], countType: fields[2], list: true}
+			} else if len(fields) >= 3 {
+				property = plyProperty{name: fields[2], dataType: fields[1]}
+			} else {
+				return nil, formatError("invalid property line %q", line)
+			}
+
+			if plyTypeSize(property.dataType) == 0 || (property.list && plyTypeSize(property.countType) == 0) {
+				return nil, formatError("unsupported property type in %q", line)
+			}
+
+			element := header.elements[len(header.elements)-1]
+			element.properties = append(element.properties, property)
+		case "end_header":
+			if header.format == "" {
+				return nil, formatError("PLY header is missing its format line")
+			}
+
+			header.size = offset
+			if err := header.checkCounts(len(data) - offset); err != nil {
+				return nil, err
+			}
+
+			return header, nil
+		default:
+			return nil, formatError("invalid PLY header line %q", line)
+		}
+	}
+
+	return nil, formatError("PLY header is missing end_header")
+}
+
+// checkCounts rejects element counts that a body of the given size could not hold, so that readers may size their
+// results by the counts without allocating more than the file warrants.
+func (h *plyHeader) checkCounts(bodySize int) error {
+	remaining := bodySize
+	if h.format == plyASCII {
+		// the last line may lack its newline
+		remaining++
+	}
+
+	for _, element := range h.elements {
+		size := max(1, h.minRowSize(element))
+		if element.count > remaining/size {
+			return formatError("PLY file is too short for %d %s rows", element.count, element.name)
+		}
+
+		remaining -= element.count * size
+	}
+
+	return nil
+}
+
+// minRowSize returns the fewest bytes a row of element takes in the body: a value and its separator per property for
+// ASCII files, and the size of each scalar and list length for binary files.
+func (h *plyHeader) minRowSize(element *plyElement) int {
+	size := 0
+	for _, property := range element.properties {
+		switch {
+		case h.format == plyASCII:
+			size += 2
+		case property.list:
+			size += plyTypeSize(property.countType)
+		default:
+			size += plyTypeSize(property.dataType)
+		}
+	}
+
+	return size
+}
+
+// plyRow holds a row of a PLY element. Values, lists and offsets are indexed like the properties of the element: values
+// of scalar properties, items of list properties, and the index of the token of each scalar for ASCII files, whose row
+// is kept in tokens.
+type plyRow struct {
+	values  []float64
+	lists   [][]float64
+	offsets []int
+	tokens  []string
+}
+
+// plyReader reads the rows of the body of a PLY file, element by element in the order of the header.
+type plyReader struct {
+	data   []byte
+	offset int
+	order  binary.ByteOrder // Byte order of binary files, nil for ASCII files
+}
+
+func newPLYReader(data []byte, header *plyHeader) *plyReader {
+	reader := &plyReader{data: data, offset: header.size}
+	switch header.format {
+	case plyBinaryLittleEndian:
+		reader.order = binary.LittleEndian
+	case plyBinaryBigEndian:
+		reader.order = binary.BigEndian
+	}
+
+	return reader
+}
+
+// next reads row index of element into row.
+func (r *plyReader) next(element *plyElement, index int, row *plyRow) error {
+	n := len(element.properties)
+	row.values = slices.Grow(row.values[:0], n)[:n]
+	row.lists = slices.Grow(row.lists[:0], n)[:n]
+	row.offsets = slices.Grow(row.offsets[:0], n)[:n]
+	clear(row.values)
+	clear(row.offsets)
+	for i := range row.lists {
+		row.lists[i] = row.lists[i][:0]
+	}
+
+	if r.order == nil {
+		return r.nextLine(element, index, row)
+	}
+
+	for i, property := range element.properties {
+		if property.list {
+			count, ok := r.read(property.countType)
+			if !ok {
+				return formatError("PLY file ended before %s %d", element.name, index)
+			}
+			if count < 0 {
+				return formatError("invalid %s list length %v in %s %d", property.name, count, element.name, index)
+			}
+
+			for range int(count) {
+				item, ok := r.read(property.dataType)
+				if !ok {
+					return formatError("PLY file ended before %s %d", element.name, index)
+				}
+
+				row.lists[i] = append(row.lists[i], item)
+			}
+			continue
+		}
+
+		value, ok := r.read(property.dataType)
+		if !ok {
+			return formatError("PLY file ended before %s %d", element.name, index)
+		}
+
+		row.values[i] = value
+	}
+
+	return nil
+}
+
+// nextLine reads a row of an ASCII file, which is a line of values with each list preceded by its length.
+func (r *plyReader) nextLine(element *plyElement, index int, row *plyRow) error {
+	if r.offset >= len(r.data) {
+		return formatError("PLY file ended before %s %d", element.name, index)
+	}
+
+	end := bytes.IndexByte(r.data[r.offset:], '\n')
+	if end < 0 {
+		end = len(r.data) - r.offset
+	}
+	row.tokens = strings.Fields(string(r.data[r.offset : r.offset+end]))
+	r.offset = min(r.offset+end+1, len(r.data))
+
+	cursor := 0
+	for i, property := range element.properties {
+		if cursor >= len(row.tokens) {
+			return formatError("%s %d has %d values, expected more", element.name, index, len(row.tokens))
+		}
+
+		if property.list {
+			count, err := strconv.Atoi(row.tokens[cursor])
+			if err != nil || count < 0 || cursor+count >= len(row.tokens) {
+				return formatError("invalid %s list length %q in %s %d", property.name, row.tokens[cursor], element.name,
+					index)
+			}
+
+			for _, token := range row.tokens[cursor+1 : cursor+1+count] {
+				item, err := strconv.ParseFloat(token, 64)
+				if err != nil {
+					return formatError("invalid %s item %q in %s %d", property.name, token, element.name, index)
+				}
+
+				row.lists[i] = append(row.lists[i], item)
+			}
+
+			cursor += 1 + count
+			continue
+		}
+
+		value, err := strconv.ParseFloat(row.tokens[cursor], 64)
+		if err != nil {
+			return formatError("invalid %s %q in %s %d", property.name, row.tokens[cursor], element.name, index)
+		}
+
+		row.values[i] = value
+		row.offsets[i] = cursor
+		cursor++
+	}
+
+	return nil
+}
+
+// read reads a binary value, reporting false if the file ends before it.
+func (r *plyReader) read(dataType string) (float64, bool) {
+	size := plyTypeSize(dataType)
+	if size > len(r.data)-r.offset {
+		return 0, false
+	}
+
+	value := plyDecode(r.order, dataType, r.data[r.offset:r.offset+size])
+	r.offset += size
+	return value, true
+}
+
+// plyTypeSize returns the size in bytes of a binary value of a PLY type, or 0 for unknown types.
+func plyTypeSize(dataType string) int {
+	switch dataType {
+	case "char", "int8", "uchar", "uint8":
+		return 1
+	case "short", "int16", "ushort", "uint16":
+		return 2
+	case "int", "int32", "uint", "uint32", "float", "float32":
+		return 4
+	case "double", "float64":
+		return 8
+	default:
+		return 0
+	}
+}
+
+// plyFloat reports whether a PLY type is a floating point type.
+func plyFloat(dataType string) bool {
+	switch dataType {
+	case "float", "float32", "double", "float64":
+		return true
+	default:
+		return false
+	}
+}
+
+func plyDecode(order binary.ByteOrder, dataType string, b []byte) float64 {
+	switch dataType {
+	case "char", "int8":
+		return float64(int8(b[0]))
+	case "uchar", "uint8":
+		return float64(b[0])
+	case "short", "int16":
+		return float64(int16(order.Uint16(b)))
+	case "ushort", "uint16":
+		return float64(order.Uint16(b))
+	case "int", "int32":
+		return float64(int32(order.Uint32(b)))
+	case "uint", "uint32":
+		return float64(order.Uint32(b))
+	case "float", "float32":
+		return float64(math.Float32frombits(order.Uint32(b)))
+	default:
+		return math.Float64frombits(order.Uint64(b))
+	}
+}
+
+// ParsePLYColors reads the per-vertex and per-face colors of an ASCII or binary PLY file.
+// The red, green, blue and optional alpha properties (or their diffuse_ prefixed variants) are read as
+// 0-255 integers, or as 0-1 values for floating point properties. Missing alpha is treated as opaque.
+// The body of a file without color properties is not read.
+//
+// Parameters:
+//   - data: Contents of the PLY file
+//
+// Returns the colors, which are empty if the file has none, or an error if the file cannot be read.
+func ParsePLYColors(data []byte) (*MeshColors, error) {
+	header, err := readPLYHeader(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return plyColors(data, header)
+}
+
+func plyColors(data []byte, header *plyHeader) (*MeshColors, error) {
+	colors := &MeshColors{}
+	if !slices.ContainsFunc(header.elements, hasColorProperties) {
+		return colors, nil
+	}
+
+	reader := newPLYReader(data, header)
+	row := &plyRow{}
+	for _, element := range header.elements {
+		var target *[]byte
+		switch element.name {
+		case "vertex":
+			target = &colors.Vertex
+		case "face":
+			target = &colors.Face
+		}
+
+		if target != nil && !hasColorProperties(element) {
+			target = nil
+		}
+
+		if target != nil {
+			*target = make([]byte, 0, element.count*4)
+		}
+
+		for i := 0; i < element.count; i++ {
+			if err := reader.next(element, i, row); err != nil {
+				return nil, err
+			}
+
+			if target == nil {
+				continue
+			}
+
+			rgba := [4]byte{0, 0, 0, 255}
+			for j, property := range element.properties {
+				if channel := colorChannel(property.name); channel >= 0 && !property.list {
+					rgba[channel] = colorComponent(property.dataType, row.values[j])
+				}
+			}
+
+			*target = append(*target, rgba[:]...)
+		}
+	}
+
+	return colors, nil
+}
+
+// ScalePLY uniformly scales the x, y and z vertex properties of an ASCII PLY file.
+// Every other line is copied unchanged, so vertex order and properties such as colors are preserved.
+//
+// Parameters:
+//   - data: Contents of the PLY file
+//   - scale: Scale factor
+//
+// Returns the contents of the scaled PLY file or an error if the file cannot be read or is not ASCII.
+func ScalePLY(data []byte, scale float64) ([]byte, error) {
+	header, err := readPLYHeader(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if header.format != plyASCII {
+		return nil, formatError("only ASCII PLY files can be scaled, got %s", header.format)
+	}
+
+	var out bytes.Buffer
+	out.Grow(len(data))
+	out.Write(data[:header.size])
+
+	reader := newPLYReader(data, header)
+	row := &plyRow{}
+	for _, element := range header.elements {
+		for i := 0; i < element.count; i++ {
+			start := reader.offset
+			if err := reader.next(element, i, row); err != nil {
+				return nil, err
+			}
+
+			if element.name != "vertex" {
+				out.Write(data[start:reader.offset])
+				continue
+			}
+
+			for j, property := range element.properties {
+				if isCoordinate(property) {
+					row.tokens[row.offsets[j]] = strconv.FormatFloat(row.values[j]*scale, 'g', -1, 64)
+				}
+			}
+
+			out.WriteString(strings.Join(row.tokens, " "))
+			out.WriteByte('\n')
+		}
+	}
+
+	return out.Bytes(), nil
+}
+
+// asciiPLY rewrites a binary PLY file as an ASCII one with the same elements, properties and values, in the same order,
+// so vertex and face colors still line up with the rewritten mesh. ASCII files are returned unchanged.
+func asciiPLY(data []byte, header *plyHeader) ([]byte, error) {
+	if header.format == plyASCII {
+		return data, nil
+	}
+
+	var out bytes.Buffer
+	out.Grow(len(data) * 2)
+	out.WriteString("ply\nformat ascii 1.0\n")
+	for _, element := range header.elements {
+		fmt.Fprintf(&out, "element %s %d\n", element.name, element.count)
+		for _, property := range element.properties {
+			if property.list {
+				fmt.Fprintf(&out, "property list %s %s %s\n", property.countType, property.dataType, property.name)
+			} else {
+				fmt.Fprintf(&out, "property %s %s\n", property.dataType, property.name)
+			}
+		}
+	}
+	out.WriteString("end_header\n")
+
+	reader := newPLYReader(data, header)
+	row := &plyRow{}
+	for _, element := range header.elements {
+		for i := 0; i < element.count; i++ {
+			if err := reader.next(element, i, row); err != nil {
+				return nil, err
+			}
+
+			for j, property := range element.properties {
+				if j > 0 {
+					out.WriteByte(' ')
+				}
+
+				if !property.list {
+					out.WriteString(formatPLYValue(property.dataType, row.values[j]))
+					continue
+				}
+
+				out.WriteString(strconv.Itoa(len(row.lists[j])))
+				for _, item := range row.lists[j] {
+					out.WriteByte(' ')
+					out.WriteString(formatPLYValue(property.dataType, item))
+				}
+			}
+			out.WriteByte('\n')
+		}
+	}
+
+	return out.Bytes(), nil
+}
+
+// formatPLYValue formats a value of a PLY type as an ASCII PLY token.
+func formatPLYValue(dataType string, value float64) string {
+	switch dataType {
+	case "float", "float32":
+		return strconv.FormatFloat(value, 'g', -1, 32)
+	case "double", "float64":
+		return strconv.FormatFloat(value, 'g', -1, 64)
+	default:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	}
+}
+
+func isCoordinate(property plyProperty) bool {
+	return !property.list && (property.name == "x" || property.name == "y" || property.name == "z")
+}
+
+func hasColorProperties(element *plyElement) bool {
+	for _, property := range element.properties {
+		if !property.list && colorChannel(property.name) >= 0 && colorChannel(property.name) < 3 {
+			return true
+		}
+	}
+
+	return false
+}
+
+func colorChannel(name string) int {
+	switch strings.TrimPrefix(name, "diffuse_") {
+	case "red":
+		return 0
+	case "green":
+		return 1
+	case "blue":
+		return 2
+	case "alpha":
+		return 3
+	default:
+		return -1
+	}
+}
+
+// colorComponent converts a color value to a byte, scaling 0-1 floating point values to 0-255.
+func colorComponent(dataType string, value float64) byte {
+	if plyFloat(dataType) {
+		value *= 255
+	}
+
+	return byte(math.Max(0, math.Min(255, math.Round(value))))
+}
@@ -0,0 +1,179 @@
+package lib
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"strings"
+	"testing"
+
+	"go.viam.com/test"
+)
+
+const coloredTestPLY = `ply
+format ascii 1.0
+comment vertex colors as bytes, face colors as floats
+element vertex 3
+property float x
+property float y
+property float z
+property uchar red
+property uchar green
+property uchar blue
+element face 1
+property list uchar int vertex_indices
+property float red
+property float green
+property float blue
+property float alpha
+end_header
+0 0 0 255 0 0
+1 0 0 0 255 0
+0 1 0 0 0 255
+3 0 1 2 1.0 0.5 0 0.5
+`
+
+// binaryColoredTestPLY encodes coloredTestPLY as a binary PLY file of the given format.
+func binaryColoredTestPLY(format string, order binary.ByteOrder) []byte {
+	header := coloredTestPLY[:strings.Index(coloredTestPLY, "end_header\n")+len("end_header\n")]
+
+	var data bytes.Buffer
+	data.WriteString(strings.Replace(header, "format ascii 1.0", "format "+format+" 1.0", 1))
+	vertices := []struct {
+		position [3]float32
+		color    [3]byte
+	}{
+		{[3]float32{0, 0, 0}, [3]byte{255, 0, 0}},
+		{[3]float32{1, 0, 0}, [3]byte{0, 255, 0}},
+		{[3]float32{0, 1, 0}, [3]byte{0, 0, 255}},
+	}
+	for _, vertex := range vertices {
+		binary.Write(&data, order, vertex.position)
+		data.Write(vertex.color[:])
+	}
+
+	data.WriteByte(3)
+	binary.Write(&data, order, []int32{0, 1, 2})
+	binary.Write(&data, order, []float32{1, 0.5, 0, 0.5})
+	return data.Bytes()
+}
+
+func TestParsePLYColors(t *testing.T) {
+	t.Run("vertex and face colors", func(t *testing.T) {
+		colors, err := ParsePLYColors([]byte(coloredTestPLY))
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, colors.Empty(), test.ShouldBeFalse)
+		test.That(t, colors.Vertex, test.ShouldResemble, []byte{
+			255, 0, 0, 255,
+			0, 255, 0, 255,
+			0, 0, 255, 255,
+		})
+		test.That(t, colors.Face, test.ShouldResemble, []byte{255, 128, 0, 128})
+
+		metadata := colors.Metadata()
+		test.That(t, metadata["color_encoding"], test.ShouldEqual, ColorEncoding)
+		test.That(t, metadata["vertex_colors"], test.ShouldEqual, "/wAA/wD/AP8AAP//")
+		test.That(t, metadata["face_colors"], test.ShouldEqual, "/4AAgA==")
+	})
+
+	t.Run("no colors", func(t *testing.T) {
+		colors, err := ParsePLYColors([]byte(testPLY))
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, colors.Empty(), test.ShouldBeTrue)
+		test.That(t, colors.Metadata(), test.ShouldBeEmpty)
+	})
+
+	t.Run("binary files", func(t *testing.T) {
+		expected, err := ParsePLYColors([]byte(coloredTestPLY))
+		test.That(t, err, test.ShouldBeNil)
+
+		for format, order := range map[string]binary.ByteOrder{
+			"binary_little_endian": binary.LittleEndian,
+			"binary_big_endian":    binary.BigEndian,
+		} {
+			colors, err := ParsePLYColors(binaryColoredTestPLY(format, order))
+			test.That(t, err, test.ShouldBeNil)
+			test.That(t, colors, test.ShouldResemble, expected)
+		}
+
+		data := binaryColoredTestPLY("binary_little_endian", binary.LittleEndian)
+		_, err = ParsePLYColors(data[:len(data)-1])
+		test.That(t, errors.Is(err, ErrUnsupportedFormat), test.ShouldBeTrue)
+	})
+
+	t.Run("counts past the end of the file", func(t *testing.T) {
+		for _, data := range []string{
+			"ply\nformat binary_little_endian 1.0\nelement vertex 9223372036854775807\nproperty uchar red\nend_header\n",
+			"ply\nformat ascii 1.0\nelement vertex 1000000000000\nproperty uchar red\nend_header\n0\n",
+			"ply\nformat binary_big_endian 1.0\nelement vertex 0\nelement face 4611686018427387904\nend_header\n",
+		} {
+			_, err := ParsePLYColors([]byte(data))
+			test.That(t, errors.Is(err, ErrUnsupportedFormat), test.ShouldBeTrue)
+			test.That(t, err.Error(), test.ShouldStartWith, "PLY file is too short for ")
+		}
+	})
+
+	t.Run("unknown formats are rejected", func(t *testing.T) {
+		unknown := strings.Replace(testPLY, "format ascii 1.0", "format binary_middle_endian 1.0", 1)
+		_, err := ParsePLYColors([]byte(unknown))
+		test.That(t, errors.Is(err, ErrUnsupportedFormat), test.ShouldBeTrue)
+	})
+
+	t.Run("truncated body", func(t *testing.T) {
+		truncated := strings.TrimSuffix(coloredTestPLY, "3 0 1 2 1.0 0.5 0 0.5\n")
+		_, err := ParsePLYColors([]byte(truncated))
+		test.That(t, errors.Is(err, ErrUnsupportedFormat), test.ShouldBeTrue)
+	})
+}
+
+func TestScalePLY(t *testing.T) {
+	scaled, err := ScalePLY([]byte(coloredTestPLY), 2)
+	test.That(t, err, test.ShouldBeNil)
+
+	lines := strings.Split(strings.TrimSpace(string(scaled)), "\n")
+	body := lines[len(lines)-4:]
+	test.That(t, body, test.ShouldResemble, []string{
+		"0 0 0 255 0 0",
+		"2 0 0 0 255 0",
+		"0 2 0 0 0 255",
+		"3 0 1 2 1.0 0.5 0 0.5",
+	})
+
+	// colors still line up with the scaled vertices
+	colors, err := ParsePLYColors(scaled)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, colors.Vertex[4:8], test.ShouldResemble, []byte{0, 255, 0, 255})
+
+	_, err = ScalePLY(binaryColoredTestPLY("binary_little_endian", binary.LittleEndian), 2)
+	test.That(t, errors.Is(err, ErrUnsupportedFormat), test.ShouldBeTrue)
+}
+
+func TestASCIIPLY(t *testing.T) {
+	data := []byte(coloredTestPLY)
+	header, err := readPLYHeader(data)
+	test.That(t, err, test.ShouldBeNil)
+	unchanged, err := asciiPLY(data, header)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, unchanged, test.ShouldResemble, data)
+
+	for format, order := range map[string]binary.ByteOrder{
+		"binary_little_endian": binary.LittleEndian,
+		"binary_big_endian":    binary.BigEndian,
+	} {
+		data := binaryColoredTestPLY(format, order)
+		header, err := readPLYHeader(data)
+		test.That(t, err, test.ShouldBeNil)
+
+		ascii, err := asciiPLY(data, header)
+		test.That(t, err, test.ShouldBeNil)
+
+		lines := strings.Split(strings.TrimSpace(string(ascii)), "\n")
+		test.That(t, lines[1], test.ShouldEqual, "format ascii 1.0")
+		test.That(t, lines[len(lines)-4:], test.ShouldResemble, []string{
+			"0 0 0 255 0 0",
+			"1 0 0 0 255 0",
+			"0 1 0 0 0 255",
+			"3 0 1 2 1 0.5 0 0.5",
+		})
+	}
+}
@@ -0,0 +1,556 @@
+package lib
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/golang/geo/r3"
+	commonPB "go.viam.com/api/common/v1"
+	"go.viam.com/rdk/pointcloud"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+const (
+	// ColorByHeight colors each point by its height (z) using a blue to red colormap.
+	ColorByHeight = "height"
+	// ColorByIntensity colors each point by its intensity using a blue to red colormap.
+	ColorByIntensity = "intensity"
+)
+
+// DefaultPointCloudColor is the color used for points that have no color of their own (gray).
+var DefaultPointCloudColor = Color{R: 128, G: 128, B: 128}
+
+// PointCloudJSON represents a point cloud configuration in JSON format.
+// It contains all the necessary information to load a PCD or PLY file and place it in the world state.
+type PointCloudJSON struct {
+	ModelPath   string   `json:"model_path"`              // Path to the PCD or PLY file (required)
+	Pose        PoseJSON `json:"pose,omitempty"`          // Position and orientation (optional, defaults to the origin)
+	Name        string   `json:"name,omitempty"`          // Name of the point cloud frame (optional, defaults to "pointcloud-{uuid}")
+	UUID        string   `json:"uuid,omitempty"`          // UUID string (optional, generates new UUID if not provided)
+	Color       *Color   `json:"color,omitempty"`         // RGB color of points without their own color (optional, defaults to gray)
+	ParentFrame string   `json:"parent_frame,omitempty"`  // Parent reference frame (optional, defaults to "world")
+	Layer       string   `json:"layer,omitempty"`         // Layer of the shape, kept in its "layer" metadata (optional, defaults to "default")
+	Label       string   `json:"label,omitempty"`         // Geometry label (optional, defaults to the model path)
+	VoxelSizeMm float64  `json:"voxel_size_mm,omitempty"` // Edge length of the voxels used to downsample the cloud (optional, 0 keeps every point)
+	ColorBy     string   `json:"color_by,omitempty"`      // Recolor the points by "height" or "intensity" (optional, defaults to the file colors)
+	PointSize   float64  `json:"point_size,omitempty"`    // Rendered size of each point (optional, defaults to the viewer's size)
+}
+
+// ValidatePointCloudFile checks that a point cloud file exists and is in a supported format.
+// PCD and PLY files, ASCII or binary, are supported.
+//
+// Parameters:
+//   - path: Path to the point cloud file
+//
+// Returns an error describing why the file cannot be drawn, or nil.
+func ValidatePointCloudFile(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileError(err)
+	}
+
+	if info.IsDir() {
+		return FieldErrorf("model_path", "%s is a directory", path)
+	}
+
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".pcd":
+		return nil
+	case ".ply":
+		return ValidateMeshFile(path)
+	default:
+		return formatError("unsupported point cloud format %q, expected .pcd or .ply", ext)
+	}
+}
+
+// ParsePointCloud parses a single point cloud from JSON data.
+// It expects a point cloud object with a required model_path and optional fields.
+//
+// Parameters:
+//   - item: JSON object containing point cloud data
+//
+// Returns the parsed point cloud configuration or an error if parsing fails.
+func ParsePointCloud(item any) (*PointCloudJSON, error) {
+	if _, ok := item.(map[string]any); !ok {
+		return nil, Errorf(ErrInvalidArgument, "Expected point cloud object, got %T", item)
+	}
+
+	color := DefaultPointCloudColor
+	cloud := &PointCloudJSON{
+		Color: &color,
+	}
+	if err := Decode(item, cloud); err != nil {
+		return nil, err
+	}
+
+	if cloud.ModelPath == "" {
+		return nil, FieldErrorf("model_path", "Missing required 'model_path' field")
+	}
+
+	if cloud.UUID != "" {
+		if _, err := UUIDFromString(cloud.UUID); err != nil {
+			return nil, FieldErrorf("uuid", "Failed to parse UUID: %w", err)
+		}
+	}
+
+	if err := ValidateColorBy(cloud.ColorBy); err != nil {
+		return nil, err
+	}
+
+	for key, value := range map[string]float64{
+		"voxel_size_mm": cloud.VoxelSizeMm,
+		"point_size":    cloud.PointSize,
+	} {
+		if value < 0 {
+			return nil, FieldErrorf(key, "Expected non-negative number for %s, got %v", key, value)
+		}
+	}
+
+	return cloud, nil
+}
+
+// ValidateColorBy checks that a color_by mode is empty, "height" or "intensity".
+func ValidateColorBy(colorBy string) error {
+	switch colorBy {
+	case "", ColorByHeight, ColorByIntensity:
+		return nil
+	default:
+		return FieldErrorf("color_by", "Unsupported color_by %q, expected %q or %q", colorBy, ColorByHeight,
+			ColorByIntensity)
+	}
+}
+
+// LoadPointCloud reads a point cloud from a PCD or PLY file.
+// PCD files are read by the RDK. PLY files are read by ReadPLYPoints. Both may be ASCII or binary.
+//
+// Parameters:
+//   - path: Path to the point cloud file
+//
+// Returns the point cloud, in millimeters, or an error if the file cannot be read. A file that cannot be parsed fails
+// with the unsupported_format code.
+func LoadPointCloud(path string) (pointcloud.PointCloud, error) {
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".pcd":
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, fileError(err)
+		}
+		defer file.Close()
+
+		cloud, err := pointcloud.ReadPCD(file, pointcloud.BasicType)
+		if err != nil {
+			return nil, formatError("Failed to parse %s: %w", path, err)
+		}
+
+		return cloud, nil
+	case ".ply":
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fileError(err)
+		}
+
+		return ReadPLYPoints(data)
+	default:
+		return nil, formatError("unsupported point cloud format %q, expected .pcd or .ply", ext)
+	}
+}
+
+// ReadPLYPoints reads the vertices of an ASCII or binary PLY file as a point cloud; faces of a mesh file are ignored.
+// Coordinates are read in meters and converted to millimeters, matching the RDK's PCD reader.
+// Vertex colors (red, green, blue) and an intensity property are kept when present.
+//
+// Parameters:
+//   - data: Contents of the PLY file
+//
+// Returns the point cloud or an error if the file cannot be read.
+func ReadPLYPoints(data []byte) (pointcloud.PointCloud, error) {
+	header, err := readPLYHeader(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var cloud pointcloud.PointCloud
+	reader := newPLYReader(data, header)
+	row := &plyRow{}
+	for _, element := range header.elements {
+		if element.name == "vertex" {
+			// readPLYHeader only accepts counts the file can hold
+			cloud = pointcloud.NewBasicPointCloud(element.count)
+		}
+
+		colored := element.name == "vertex" && hasColorProperties(element)
+		for i := 0; i < element.count; i++ {
+			if err := reader.next(element, i, row); err != nil {
+				return nil, err
+			}
+
+			if element.name != "vertex" {
+				continue
+			}
+
+			var position [3]float64
+			var intensity float64
+			var intensityType string
+			rgba := [4]byte{0, 0, 0, 255}
+			for j, property := range element.properties {
+				if property.list {
+					continue
+				}
+
+				switch property.name {
+				case "x", "y", "z":
+					position[property.name[0]-'x'] = row.values[j] * 1000
+				case "intensity", "scalar_intensity":
+					intensity = row.values[j]
+					intensityType = property.dataType
+				default:
+					if channel := colorChannel(property.name); channel >= 0 {
+						rgba[channel] = colorComponent(property.dataType, row.values[j])
+					}
+				}
+			}
+
+			point := pointcloud.NewBasicData()
+			if colored {
+				point = pointcloud.NewColoredData(color.NRGBA{R: rgba[0], G: rgba[1], B: rgba[2], A: rgba[3]})
+			}
+
+			if intensityType != "" {
+				point.SetIntensity(plyIntensity(intensityType, intensity))
+			}
+
+			if err := cloud.Set(r3.Vector{X: position[0], Y: position[1], Z: position[2]}, point); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if cloud == nil {
+		return nil, formatError("PLY file has no vertex element")
+	}
+
+	return cloud, nil
+}
+
+// VoxelDownsample reduces a point cloud to one point per occupied voxel.
+// Each remaining point is the centroid of the points in its voxel, with their average color and intensity.
+//
+// Parameters:
+//   - cloud: Point cloud to downsample
+//   - voxelSizeMm: Edge length of the voxels in millimeters (0 returns the cloud unchanged)
+//
+// Returns the downsampled point cloud or an error if it cannot be built.
+func VoxelDownsample(cloud pointcloud.PointCloud, voxelSizeMm float64) (pointcloud.PointCloud, error) {
+	if voxelSizeMm <= 0 {
+		return cloud, nil
+	}
+
+	type voxel struct {
+		sum       r3.Vector
+		rgb       [3]float64
+		intensity float64
+		count     float64
+		colored   float64
+	}
+
+	voxels := make(map[[3]int64]*voxel)
+	cloud.Iterate(0, 0, func(p r3.Vector, d pointcloud.Data) bool {
+		key := [3]int64{
+			int64(math.Floor(p.X / voxelSizeMm)),
+			int64(math.Floor(p.Y / voxelSizeMm)),
+			int64(math.Floor(p.Z / voxelSizeMm)),
+		}
+
+		v, ok := voxels[key]
+		if !ok {
+			v = &voxel{}
+			voxels[key] = v
+		}
+
+		v.sum = v.sum.Add(p)
+		v.count++
+		if d == nil {
+			return true
+		}
+
+		v.intensity += float64(d.Intensity())
+		if d.HasColor() {
+			r, g, b := d.RGB255()
+			v.rgb[0] += float64(r)
+			v.rgb[1] += float64(g)
+			v.rgb[2] += float64(b)
+			v.colored++
+		}
+
+		return true
+	})
+
+	downsampled := pointcloud.NewBasicPointCloud(len(voxels))
+	for _, v := range voxels {
+		point := pointcloud.NewBasicData()
+		if v.colored > 0 {
+			point = pointcloud.NewColoredData(color.NRGBA{
+				R: uint8(math.Round(v.rgb[0] / v.colored)),
+				G: uint8(math.Round(v.rgb[1] / v.colored)),
+				B: uint8(math.Round(v.rgb[2] / v.colored)),
+				A: 255,
+			})
+		}
+
+		point.SetIntensity(uint16(math.Round(v.intensity / v.count)))
+		if err := downsampled.Set(v.sum.Mul(1/v.count), point); err != nil {
+			return nil, err
+		}
+	}
+
+	return downsampled, nil
+}
+
+// LimitPoints reduces a point cloud to at most maxPoints points by keeping every n-th point.
+//
+// Parameters:
+//   - cloud: Point cloud to reduce
+//   - maxPoints: Maximum number of points to keep (0 returns the cloud unchanged)
+//
+// Returns the reduced point cloud or an error if it cannot be built.
+func LimitPoints(cloud pointcloud.PointCloud, maxPoints int) (pointcloud.PointCloud, error) {
+	if maxPoints <= 0 || cloud.Size() <= maxPoints {
+		return cloud, nil
+	}
+
+	stride := (cloud.Size() + maxPoints - 1) / maxPoints
+	limited := pointcloud.NewBasicPointCloud(maxPoints)
+	index := 0
+	var setErr error
+	cloud.Iterate(0, 0, func(p r3.Vector, d pointcloud.Data) bool {
+		if index%stride == 0 {
+			setErr = limited.Set(p, d)
+		}
+
+		index++
+		return setErr == nil
+	})
+	if setErr != nil {
+		return nil, setErr
+	}
+
+	return limited, nil
+}
+
+// ColorPointCloud recolors every point of a cloud by its height or intensity.
+// Values are normalized between the minimum and maximum of the cloud and mapped from blue (low) to red (high).
+//
+// Parameters:
+//   - cloud: Point cloud to recolor
+//   - colorBy: ColorByHeight, ColorByIntensity, or empty to return the cloud unchanged
+//
+// Returns the recolored point cloud or an error if the mode is unknown.
+func ColorPointCloud(cloud pointcloud.PointCloud, colorBy string) (pointcloud.PointCloud, error) {
+	if err := ValidateColorBy(colorBy); err != nil {
+		return nil, err
+	}
+
+	if colorBy == "" {
+		return cloud, nil
+	}
+
+	value := func(p r3.Vector, d pointcloud.Data) float64 {
+		if colorBy == ColorByHeight {
+			return p.Z
+		}
+
+		if d == nil {
+			return 0
+		}
+
+		return float64(d.Intensity())
+	}
+
+	low, high := math.Inf(1), math.Inf(-1)
+	cloud.Iterate(0, 0, func(p r3.Vector, d pointcloud.Data) bool {
+		v := value(p, d)
+		low = math.Min(low, v)
+		high = math.Max(high, v)
+		return true
+	})
+
+	recolored := pointcloud.NewBasicPointCloud(cloud.Size())
+	var setErr error
+	cloud.Iterate(0, 0, func(p r3.Vector, d pointcloud.Data) bool {
+		t := 0.0
+		if high > low {
+			t = (value(p, d) - low) / (high - low)
+		}
+
+		point := pointcloud.NewColoredData(Colormap(t))
+		if d != nil {
+			point.SetIntensity(d.Intensity())
+		}
+
+		setErr = recolored.Set(p, point)
+		return setErr == nil
+	})
+	if setErr != nil {
+		return nil, setErr
+	}
+
+	return recolored, nil
+}
+
+// PaintPointCloud returns a copy of a point cloud with every point set to a single color.
+//
+// Parameters:
+//   - cloud: Point cloud to paint
+//   - c: Color of every point
+//
+// Returns the painted point cloud or an error if it cannot be built.
+func PaintPointCloud(cloud pointcloud.PointCloud, c Color) (pointcloud.PointCloud, error) {
+	painted := pointcloud.NewBasicPointCloud(cloud.Size())
+	var setErr error
+	cloud.Iterate(0, 0, func(p r3.Vector, d pointcloud.Data) bool {
+		point := pointcloud.NewColoredData(color.NRGBA{R: c.R, G: c.G, B: c.B, A: 255})
+		if d != nil {
+			point.SetIntensity(d.Intensity())
+		}
+
+		setErr = painted.Set(p, point)
+		return setErr == nil
+	})
+	if setErr != nil {
+		return nil, setErr
+	}
+
+	return painted, nil
+}
+
+// colormapStops are the colors of the blue to red colormap at evenly spaced positions.
+var colormapStops = []color.NRGBA{
+	{R: 0, G: 0, B: 255, A: 255},
+	{R: 0, G: 255, B: 255, A: 255},
+	{R: 0, G: 255, B: 0, A: 255},
+	{R: 255, G: 255, B: 0, A: 255},
+	{R: 255, G: 0, B: 0, A: 255},
+}
+
+// Colormap maps a value between 0 and 1 to a color from blue (0) through cyan, green and yellow to red (1).
+// Values outside the range are clamped.
+func Colormap(t float64) color.NRGBA {
+	t = math.Max(0, math.Min(1, t))
+	position := t * float64(len(colormapStops)-1)
+	index := int(math.Floor(position))
+	if index >= len(colormapStops)-1 {
+		return colormapStops[len(colormapStops)-1]
+	}
+
+	from, to := colormapStops[index], colormapStops[index+1]
+	fraction := position - float64(index)
+	lerp := func(a, b uint8) uint8 {
+		return uint8(math.Round(float64(a) + (float64(b)-float64(a))*fraction))
+	}
+
+	return color.NRGBA{R: lerp(from.R, to.R), G: lerp(from.G, to.G), B: lerp(from.B, to.B), A: 255}
+}
+
+// CreatePointCloud creates a new point cloud transform from a point cloud and individual components.
+// It generates a UUID if none is provided and uses default values for optional parameters.
+//
+// Parameters:
+//   - cloud: Point cloud to draw, in millimeters (required)
+//   - pose: Position and orientation of the point cloud (defaults to the origin if nil)
+//   - name: Name for the point cloud frame (empty string will generate "pointcloud-{uuid}")
+//   - uuid: Optional UUID bytes (generates new UUID if nil)
+//   - color: Optional color of points without their own color (defaults to gray if nil)
+//   - parentFrame: Optional parent frame (defaults to "world" if empty)
+//   - pointSize: Rendered size of each point (0 leaves it to the viewer)
+//   - label: Geometry label
+//
+// Returns the created point cloud transform or an error if creation fails.
+func CreatePointCloud(cloud pointcloud.PointCloud, pose *commonPB.Pose, name string, uuid []byte, color *Color, parentFrame string, pointSize float64, label string) (*commonPB.Transform, error) {
+	if cloud == nil {
+		return nil, Errorf(ErrInvalidArgument, "point cloud is required")
+	}
+
+	data, err := pointcloud.ToBytes(cloud)
+	if err != nil {
+		return nil, err
+	}
+
+	var id UUID
+	if uuid == nil {
+		id = GenerateUUID()
+	} else {
+		parsed, err := UUIDFromBytes(uuid)
+		if err != nil {
+			return nil, err
+		}
+
+		id = *parsed
+	}
+
+	if name == "" {
+		name = fmt.Sprintf("pointcloud-%s", id.String())
+	}
+
+	if pose == nil {
+		pose = &commonPB.Pose{}
+	}
+
+	if pose.OX == 0 && pose.OY == 0 && pose.OZ == 0 {
+		pose = &commonPB.Pose{X: pose.X, Y: pose.Y, Z: pose.Z, OZ: 1, Theta: pose.Theta}
+	}
+
+	if color == nil {
+		color = &DefaultPointCloudColor
+	}
+
+	fields := map[string]any{
+		"color": map[string]any{
+			"r": int(color.R),
+			"g": int(color.G),
+			"b": int(color.B),
+		},
+	}
+
+	if pointSize > 0 {
+		fields["point_size"] = pointSize
+	}
+
+	metadata, err := structpb.NewStruct(fields)
+	if err != nil {
+		return nil, err
+	}
+
+	parent := "world"
+	if parentFrame != "" {
+		parent = parentFrame
+	}
+
+	return &commonPB.Transform{
+		ReferenceFrame: name,
+		PoseInObserverFrame: &commonPB.PoseInFrame{
+			ReferenceFrame: parent,
+			Pose:           pose,
+		},
+		Uuid: id.Bytes(),
+		PhysicalObject: &commonPB.Geometry{
+			Center: &commonPB.Pose{OZ: 1},
+			GeometryType: &commonPB.Geometry_Pointcloud{
+				Pointcloud: &commonPB.PointCloud{PointCloud: data},
+			},
+			Label: label,
+		},
+		Metadata: metadata,
+	}, nil
+}
+
+func plyIntensity(dataType string, value float64) uint16 {
+	if plyFloat(dataType) {
+		value *= math.MaxUint16
+	}
+
+	return uint16(math.Max(0, math.Min(math.MaxUint16, math.Round(value))))
+}
@@ -0,0 +1,325 @@
+package lib
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image/color"
+	"testing"
+
+	"github.com/golang/geo/r3"
+	commonPB "go.viam.com/api/common/v1"
+	"go.viam.com/rdk/pointcloud"
+	"go.viam.com/test"
+)
+
+const pointsPLY = `ply
+format ascii 1.0
+element vertex 3
+property float x
+property float y
+property float z
+property uchar red
+property uchar green
+property uchar blue
+property float intensity
+end_header
+0 0 0 255 0 0 0.0
+0.5 0 0 0 255 0 0.5
+0 0 1 0 0 255 1.0
+`
+
+const asciiPCD = `VERSION .7
+FIELDS x y z
+SIZE 4 4 4
+TYPE F F F
+COUNT 1 1 1
+WIDTH 2
+HEIGHT 1
+VIEWPOINT 0 0 0 1 0 0 0
+POINTS 2
+DATA ascii
+0 0 0
+0.1 0.2 0.3
+`
+
+func TestParsePointCloud(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    any
+		expected func(*testing.T, *PointCloudJSON, error)
+	}{
+		{
+			name: "valid point cloud",
+			input: map[string]any{
+				"model_path":    "/clouds/scan.pcd",
+				"name":          "scan",
+				"parent_frame":  "camera",
+				"voxel_size_mm": 5,
+				"color_by":      "height",
+				"point_size":    2.5,
+				"color":         map[string]any{"r": 255, "g": 0, "b": 0},
+				"pose":          map[string]any{"z": 100.0},
+			},
+			expected: func(t *testing.T, cloud *PointCloudJSON, err error) {
+				test.That(t, err, test.ShouldBeNil)
+				test.That(t, cloud.ModelPath, test.ShouldEqual, "/clouds/scan.pcd")
+				test.That(t, cloud.Name, test.ShouldEqual, "scan")
+				test.That(t, cloud.ParentFrame, test.ShouldEqual, "camera")
+				test.That(t, cloud.VoxelSizeMm, test.ShouldEqual, 5.0)
+				test.That(t, cloud.ColorBy, test.ShouldEqual, ColorByHeight)
+				test.That(t, cloud.PointSize, test.ShouldEqual, 2.5)
+				test.That(t, cloud.Color, test.ShouldResemble, &Color{R: 255, G: 0, B: 0})
+				test.That(t, cloud.Pose.Z, test.ShouldEqual, 100.0)
+			},
+		},
+		{
+			name:  "defaults",
+			input: map[string]any{"model_path": "/clouds/scan.pcd"},
+			expected: func(t *testing.T, cloud *PointCloudJSON, err error) {
+				test.That(t, err, test.ShouldBeNil)
+				test.That(t, cloud.Color, test.ShouldResemble, &DefaultPointCloudColor)
+				test.That(t, cloud.VoxelSizeMm, test.ShouldEqual, 0.0)
+				test.That(t, cloud.ColorBy, test.ShouldBeEmpty)
+			},
+		},
+		{
+			name:  "missing model path",
+			input: map[string]any{"name": "scan"},
+			expected: func(t *testing.T, cloud *PointCloudJSON, err error) {
+				test.That(t, err, test.ShouldNotBeNil)
+				test.That(t, err.Error(), test.ShouldContainSubstring, "model_path")
+			},
+		},
+		{
+			name:  "unknown color_by",
+			input: map[string]any{"model_path": "/clouds/scan.pcd", "color_by": "depth"},
+			expected: func(t *testing.T, cloud *PointCloudJSON, err error) {
+				test.That(t, err, test.ShouldNotBeNil)
+				test.That(t, err.Error(), test.ShouldContainSubstring, "color_by")
+			},
+		},
+		{
+			name:  "negative voxel size",
+			input: map[string]any{"model_path": "/clouds/scan.pcd", "voxel_size_mm": -1},
+			expected: func(t *testing.T, cloud *PointCloudJSON, err error) {
+				test.That(t, err, test.ShouldNotBeNil)
+				test.That(t, err.Error(), test.ShouldContainSubstring, "voxel_size_mm")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cloud, err := ParsePointCloud(tt.input)
+			tt.expected(t, cloud, err)
+		})
+	}
+}
+
+func TestLoadPointCloud(t *testing.T) {
+	dir := t.TempDir()
+
+	t.Run("ascii pcd", func(t *testing.T) {
+		cloud, err := LoadPointCloud(writeTestFile(t, dir, "scan.pcd", asciiPCD))
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, cloud.Size(), test.ShouldEqual, 2)
+		test.That(t, cloud.MetaData().MaxZ, test.ShouldAlmostEqual, 300.0, 0.01)
+	})
+
+	t.Run("binary pcd", func(t *testing.T) {
+		source := pointcloud.NewBasicPointCloud(2)
+		test.That(t, source.Set(r3.Vector{X: 10}, pointcloud.NewBasicData()), test.ShouldBeNil)
+		test.That(t, source.Set(r3.Vector{Z: 20}, pointcloud.NewBasicData()), test.ShouldBeNil)
+
+		var buf bytes.Buffer
+		test.That(t, pointcloud.ToPCD(source, &buf, pointcloud.PCDBinary), test.ShouldBeNil)
+
+		cloud, err := LoadPointCloud(writeTestFile(t, dir, "binary.pcd", buf.String()))
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, cloud.Size(), test.ShouldEqual, 2)
+	})
+
+	t.Run("malformed pcd", func(t *testing.T) {
+		_, err := LoadPointCloud(writeTestFile(t, dir, "malformed.pcd", "not a point cloud\n"))
+		test.That(t, errors.Is(err, ErrUnsupportedFormat), test.ShouldBeTrue)
+	})
+
+	t.Run("vertex-only ply", func(t *testing.T) {
+		cloud, err := LoadPointCloud(writeTestFile(t, dir, "points.ply", pointsPLY))
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, cloud.Size(), test.ShouldEqual, 3)
+		test.That(t, cloud.MetaData().HasColor, test.ShouldBeTrue)
+
+		// meters are converted to millimeters
+		data, ok := cloud.At(500, 0, 0)
+		test.That(t, ok, test.ShouldBeTrue)
+		r, g, b := data.RGB255()
+		test.That(t, []uint8{r, g, b}, test.ShouldResemble, []uint8{0, 255, 0})
+		test.That(t, data.Intensity(), test.ShouldEqual, uint16(32768))
+	})
+
+	t.Run("mesh ply keeps its vertices", func(t *testing.T) {
+		cloud, err := LoadPointCloud(writeTestFile(t, dir, "mesh.ply", testPLY))
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, cloud.Size(), test.ShouldEqual, 3)
+	})
+
+	t.Run("binary ply", func(t *testing.T) {
+		var buf bytes.Buffer
+		buf.WriteString("ply\nformat binary_big_endian 1.0\nelement vertex 2\nproperty double x\nproperty double y\n" +
+			"property double z\nproperty uchar red\nproperty uchar green\nproperty uchar blue\nproperty ushort intensity\n" +
+			"end_header\n")
+		for _, point := range []struct {
+			position  [3]float64
+			color     [3]byte
+			intensity uint16
+		}{
+			{[3]float64{0, 0, 0}, [3]byte{255, 0, 0}, 0},
+			{[3]float64{0.5, 0, 0}, [3]byte{0, 255, 0}, 32768},
+		} {
+			binary.Write(&buf, binary.BigEndian, point.position)
+			buf.Write(point.color[:])
+			binary.Write(&buf, binary.BigEndian, point.intensity)
+		}
+
+		cloud, err := LoadPointCloud(writeTestFile(t, dir, "binary.ply", buf.String()))
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, cloud.Size(), test.ShouldEqual, 2)
+
+		data, ok := cloud.At(500, 0, 0)
+		test.That(t, ok, test.ShouldBeTrue)
+		r, g, b := data.RGB255()
+		test.That(t, []uint8{r, g, b}, test.ShouldResemble, []uint8{0, 255, 0})
+		test.That(t, data.Intensity(), test.ShouldEqual, uint16(32768))
+
+		// a truncated file is a malformed one
+		_, err = LoadPointCloud(writeTestFile(t, dir, "truncated.ply", buf.String()[:buf.Len()-1]))
+		test.That(t, errors.Is(err, ErrUnsupportedFormat), test.ShouldBeTrue)
+	})
+
+	t.Run("binary mesh ply", func(t *testing.T) {
+		data := binaryColoredTestPLY("binary_little_endian", binary.LittleEndian)
+		cloud, err := LoadPointCloud(writeTestFile(t, dir, "binary-mesh.ply", string(data)))
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, cloud.Size(), test.ShouldEqual, 3)
+		test.That(t, cloud.MetaData().HasColor, test.ShouldBeTrue)
+	})
+
+	t.Run("ply with more vertices than it holds", func(t *testing.T) {
+		header := "ply\nformat binary_little_endian 1.0\nelement vertex 9223372036854775807\nproperty float x\nend_header\n"
+		_, err := LoadPointCloud(writeTestFile(t, dir, "huge.ply", header))
+		test.That(t, errors.Is(err, ErrUnsupportedFormat), test.ShouldBeTrue)
+
+		_, err = ReadPLYPoints([]byte("ply\nformat ascii 1.0\nelement vertex 1000000000000\nproperty float x\nend_header\n0\n"))
+		test.That(t, errors.Is(err, ErrUnsupportedFormat), test.ShouldBeTrue)
+	})
+
+	t.Run("ply without vertices", func(t *testing.T) {
+		faces := "ply\nformat ascii 1.0\nelement face 1\nproperty list uchar int vertex_indices\nend_header\n3 0 1 2\n"
+		_, err := LoadPointCloud(writeTestFile(t, dir, "faces.ply", faces))
+		test.That(t, errors.Is(err, ErrUnsupportedFormat), test.ShouldBeTrue)
+	})
+
+	t.Run("unsupported format", func(t *testing.T) {
+		_, err := LoadPointCloud(writeTestFile(t, dir, "scan.xyz", "0 0 0"))
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, ValidatePointCloudFile(writeTestFile(t, dir, "scan.las", "")), test.ShouldNotBeNil)
+	})
+}
+
+func TestVoxelDownsample(t *testing.T) {
+	cloud := pointcloud.NewBasicPointCloud(3)
+	test.That(t, cloud.Set(r3.Vector{X: 1}, pointcloud.NewColoredData(color.NRGBA{R: 100, A: 255})), test.ShouldBeNil)
+	test.That(t, cloud.Set(r3.Vector{X: 3}, pointcloud.NewColoredData(color.NRGBA{R: 200, A: 255})), test.ShouldBeNil)
+	test.That(t, cloud.Set(r3.Vector{X: 50}, pointcloud.NewColoredData(color.NRGBA{B: 255, A: 255})), test.ShouldBeNil)
+
+	unchanged, err := VoxelDownsample(cloud, 0)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, unchanged.Size(), test.ShouldEqual, 3)
+
+	downsampled, err := VoxelDownsample(cloud, 10)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, downsampled.Size(), test.ShouldEqual, 2)
+
+	data, ok := downsampled.At(2, 0, 0)
+	test.That(t, ok, test.ShouldBeTrue)
+	r, _, _ := data.RGB255()
+	test.That(t, r, test.ShouldEqual, uint8(150))
+}
+
+func TestLimitPoints(t *testing.T) {
+	cloud := pointcloud.NewBasicPointCloud(10)
+	for i := 0; i < 10; i++ {
+		test.That(t, cloud.Set(r3.Vector{X: float64(i)}, pointcloud.NewBasicData()), test.ShouldBeNil)
+	}
+
+	unchanged, err := LimitPoints(cloud, 0)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, unchanged.Size(), test.ShouldEqual, 10)
+
+	limited, err := LimitPoints(cloud, 4)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, limited.Size(), test.ShouldBeLessThanOrEqualTo, 4)
+	test.That(t, limited.Size(), test.ShouldBeGreaterThan, 0)
+}
+
+func TestColorPointCloud(t *testing.T) {
+	cloud, err := ReadPLYPoints([]byte(pointsPLY))
+	test.That(t, err, test.ShouldBeNil)
+
+	byHeight, err := ColorPointCloud(cloud, ColorByHeight)
+	test.That(t, err, test.ShouldBeNil)
+	low, _ := byHeight.At(0, 0, 0)
+	high, _ := byHeight.At(0, 0, 1000)
+	test.That(t, low.Color(), test.ShouldResemble, Colormap(0))
+	test.That(t, high.Color(), test.ShouldResemble, Colormap(1))
+
+	byIntensity, err := ColorPointCloud(cloud, ColorByIntensity)
+	test.That(t, err, test.ShouldBeNil)
+	middle, _ := byIntensity.At(500, 0, 0)
+	test.That(t, middle.Color(), test.ShouldResemble, Colormap(0.5))
+
+	_, err = ColorPointCloud(cloud, "depth")
+	test.That(t, err, test.ShouldNotBeNil)
+
+	test.That(t, Colormap(-1), test.ShouldResemble, color.NRGBA{B: 255, A: 255})
+	test.That(t, Colormap(2), test.ShouldResemble, color.NRGBA{R: 255, A: 255})
+}
+
+func TestPaintPointCloud(t *testing.T) {
+	cloud, err := ReadPLYPoints([]byte(pointsPLY))
+	test.That(t, err, test.ShouldBeNil)
+
+	painted, err := PaintPointCloud(cloud, Color{R: 10, G: 20, B: 30})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, painted.Size(), test.ShouldEqual, 3)
+
+	data, ok := painted.At(0, 0, 1000)
+	test.That(t, ok, test.ShouldBeTrue)
+	r, g, b := data.RGB255()
+	test.That(t, []uint8{r, g, b}, test.ShouldResemble, []uint8{10, 20, 30})
+	test.That(t, data.Intensity(), test.ShouldEqual, uint16(65535))
+}
+
+func TestCreatePointCloud(t *testing.T) {
+	cloud, err := ReadPLYPoints([]byte(pointsPLY))
+	test.That(t, err, test.ShouldBeNil)
+
+	transform, err := CreatePointCloud(cloud, nil, "", testUUIDBytes, nil, "", 3, "scan")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, transform.ReferenceFrame, test.ShouldEqual, "pointcloud-"+testUUID.String())
+	test.That(t, transform.PoseInObserverFrame.ReferenceFrame, test.ShouldEqual, "world")
+	test.That(t, transform.PoseInObserverFrame.Pose.OZ, test.ShouldEqual, 1.0)
+	test.That(t, transform.PhysicalObject.Label, test.ShouldEqual, "scan")
+	test.That(t, transform.Metadata.AsMap()["point_size"], test.ShouldEqual, 3.0)
+
+	geometry, ok := transform.PhysicalObject.GeometryType.(*commonPB.Geometry_Pointcloud)
+	test.That(t, ok, test.ShouldBeTrue)
+	decoded, err := pointcloud.ReadPCD(bytes.NewReader(geometry.Pointcloud.PointCloud), pointcloud.BasicType)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, decoded.Size(), test.ShouldEqual, 3)
+
+	_, err = CreatePointCloud(nil, nil, "", nil, nil, "", 0, "")
+	test.That(t, err, test.ShouldNotBeNil)
+}
@@ -0,0 +1,264 @@
+package lib
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/golang/geo/r3"
+	commonPB "go.viam.com/api/common/v1"
+	"go.viam.com/rdk/spatialmath"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// DefaultLineWidthMm is the default width of the ribbon a polyline is drawn as.
+const DefaultLineWidthMm = 20.0
+
+// DefaultLineColor is the color used for polylines drawn without an explicit color (cyan).
+var DefaultLineColor = Color{R: 0, G: 200, B: 255}
+
+// DecimatePolyline simplifies a polyline with the Ramer-Douglas-Peucker algorithm, dropping points that lie within
+// toleranceMm of the line through their neighbours. The first and last points are always kept.
+//
+// Parameters:
+//   - points: Vertices of the polyline, in millimeters
+//   - toleranceMm: Largest distance a dropped point may be from the simplified line (0 keeps every point)
+//
+// Returns the vertices of the simplified polyline.
+func DecimatePolyline(points []r3.Vector, toleranceMm float64) []r3.Vector {
+	if toleranceMm <= 0 || len(points) < 3 {
+		return append([]r3.Vector{}, points...)
+	}
+
+	keep := make([]bool, len(points))
+	keep[0] = true
+	keep[len(points)-1] = true
+	decimateRange(points, 0, len(points)-1, toleranceMm, keep)
+
+	decimated := make([]r3.Vector, 0, len(points))
+	for i, point := range points {
+		if keep[i] {
+			decimated = append(decimated, point)
+		}
+	}
+
+	return decimated
+}
+
+// decimateRange marks the point between first and last farthest from their chord when it exceeds the tolerance, and
+// recurses on both halves.
+func decimateRange(points []r3.Vector, first, last int, toleranceMm float64, keep []bool) {
+	farthest := -1
+	farthestDistance := toleranceMm
+	for i := first + 1; i < last; i++ {
+		distance := segmentDistance(points[i], points[first], points[last])
+		if distance > farthestDistance {
+			farthest = i
+			farthestDistance = distance
+		}
+	}
+
+	if farthest < 0 {
+		return
+	}
+
+	keep[farthest] = true
+	decimateRange(points, first, farthest, toleranceMm, keep)
+	decimateRange(points, farthest, last, toleranceMm, keep)
+}
+
+// segmentDistance returns the distance from a point to the segment between a and b.
+func segmentDistance(point, a, b r3.Vector) float64 {
+	segment := b.Sub(a)
+	lengthSquared := segment.Norm2()
+	if lengthSquared == 0 {
+		return point.Distance(a)
+	}
+
+	t := math.Max(0, math.Min(1, point.Sub(a).Dot(segment)/lengthSquared))
+	return point.Distance(a.Add(segment.Mul(t)))
+}
+
+// PolylineLength returns the total length of a polyline, in millimeters.
+func PolylineLength(points []r3.Vector) float64 {
+	length := 0.0
+	for i := 1; i < len(points); i++ {
+		length += points[i].Distance(points[i-1])
+	}
+
+	return length
+}
+
+// NewPolylineMesh builds a flat ribbon mesh following a polyline. Each segment is a quad of the given width, lying
+// horizontally unless the segment is vertical.
+//
+// Parameters:
+//   - points: Vertices of the polyline, in millimeters (at least two)
+//   - widthMm: Width of the ribbon, in millimeters
+//   - label: Label of the mesh geometry
+//
+// Returns the ribbon mesh or an error if the polyline cannot be drawn.
+func NewPolylineMesh(points []r3.Vector, widthMm float64, label string) (spatialmath.Geometry, error) {
+	if widthMm <= 0 {
+		return nil, Errorf(ErrInvalidArgument, "width must be positive, got %v", widthMm)
+	}
+
+	triangles := make([]*spatialmath.Triangle, 0, 2*len(points))
+	for i := 1; i < len(points); i++ {
+		start, end := points[i-1], points[i]
+		direction := end.Sub(start)
+		if direction.Norm() == 0 {
+			continue
+		}
+
+		side := direction.Cross(r3.Vector{Z: 1})
+		if side.Norm() < 1e-9*direction.Norm() {
+			side = direction.Cross(r3.Vector{X: 1})
+		}
+		side = side.Normalize().Mul(widthMm / 2)
+
+		triangles = append(triangles,
+			spatialmath.NewTriangle(start.Add(side), start.Sub(side), end.Add(side)),
+			spatialmath.NewTriangle(start.Sub(side), end.Sub(side), end.Add(side)),
+		)
+	}
+
+	if len(triangles) == 0 {
+		return nil, Errorf(ErrInvalidArgument, "polyline needs at least two distinct points")
+	}
+
+	return spatialmath.NewMesh(spatialmath.NewZeroPose(), triangles, label), nil
+}
+
+// CreatePolyline creates a transform drawing a polyline as a ribbon mesh.
+//
+// Parameters:
+//   - points: Vertices of the polyline in the parent frame, in millimeters (at least two)
+//   - widthMm: Width of the ribbon (defaults to 20 if not positive)
+//   - name: Name for the polyline frame (empty string will generate "line-{uuid}")
+//   - uuid: Optional UUID bytes (generates new UUID if nil)
+//   - color: Optional color (defaults to cyan if nil)
+//   - parentFrame: Optional parent frame (defaults to "world" if empty)
+//
+// Returns the created polyline transform or an error if creation fails.
+func CreatePolyline(points []r3.Vector, widthMm float64, name string, uuid []byte, color *Color, parentFrame string) (*commonPB.Transform, error) {
+	if widthMm <= 0 {
+		widthMm = DefaultLineWidthMm
+	}
+
+	var id UUID
+	if uuid == nil {
+		id = GenerateUUID()
+	} else {
+		parsed, err := UUIDFromBytes(uuid)
+		if err != nil {
+			return nil, err
+		}
+
+		id = *parsed
+	}
+
+	if name == "" {
+		name = fmt.Sprintf("line-%s", id.String())
+	}
+
+	geometry, err := NewPolylineMesh(points, widthMm, name)
+	if err != nil {
+		return nil, err
+	}
+
+	if color == nil {
+		color = &DefaultLineColor
+	}
+
+	metadata, err := structpb.NewStruct(map[string]any{
+		"shape": "line",
+		"color": map[string]any{
+			"r": int(color.R),
+			"g": int(color.G),
+			"b": int(color.B),
+		},
+		"width_mm": widthMm,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	parent := "world"
+	if parentFrame != "" {
+		parent = parentFrame
+	}
+
+	return &commonPB.Transform{
+		ReferenceFrame: name,
+		PoseInObserverFrame: &commonPB.PoseInFrame{
+			ReferenceFrame: parent,
+			Pose:           &commonPB.Pose{OZ: 1},
+		},
+		Uuid:           id.Bytes(),
+		PhysicalObject: geometry.ToProtobuf(),
+		Metadata:       metadata,
+	}, nil
+}
+
+// LineJSON represents a polyline configuration in JSON format.
+type LineJSON struct {
+	Points      []Vector3JSON `json:"points"`                 // Vertices in the parent frame, in millimeters (required, at least two)
+	WidthMm     float64       `json:"width_mm,omitempty"`     // Width of the ribbon (optional, defaults to 20)
+	Name        string        `json:"name,omitempty"`         // Name of the line frame (optional, defaults to "line-{uuid}")
+	UUID        string        `json:"uuid,omitempty"`         // UUID string (optional, generates new UUID if not provided)
+	Color       *Color        `json:"color,omitempty"`        // RGB color (optional, defaults to cyan)
+	ParentFrame string        `json:"parent_frame,omitempty"` // Parent reference frame (optional, defaults to "world")
+	Layer       string        `json:"layer,omitempty"`        // Layer of the shape, kept in its "layer" metadata (optional, defaults to "default")
+}
+
+// ParseLine parses a single polyline from JSON data.
+// It expects a line object with a required points array of {x, y, z} objects and optional fields.
+//
+// Parameters:
+//   - item: JSON object containing line data
+//
+// Returns the parsed line configuration or an error if parsing fails.
+func ParseLine(item any) (*LineJSON, error) {
+	lineMap, ok := item.(map[string]any)
+	if !ok {
+		return nil, Errorf(ErrInvalidArgument, "Expected line object, got %T", item)
+	}
+
+	if _, ok := lineMap["points"].([]any); !ok {
+		return nil, FieldErrorf("points", "Missing required 'points' array")
+	}
+
+	color := DefaultLineColor
+	line := &LineJSON{
+		Color: &color,
+	}
+	if err := Decode(item, line); err != nil {
+		return nil, err
+	}
+
+	if len(line.Points) < 2 {
+		return nil, FieldErrorf("points", "Expected at least two points, got %d", len(line.Points))
+	}
+
+	if lineMap["width_mm"] != nil && line.WidthMm <= 0 {
+		return nil, FieldErrorf("width_mm", "Expected positive number for width_mm, got %v", line.WidthMm)
+	}
+
+	if line.UUID != "" {
+		if _, err := UUIDFromString(line.UUID); err != nil {
+			return nil, FieldErrorf("uuid", "Failed to parse UUID: %w", err)
+		}
+	}
+
+	return line, nil
+}
+
+// LinePoints converts the vertices of a line configuration to vectors.
+func LinePoints(line *LineJSON) []r3.Vector {
+	points := make([]r3.Vector, len(line.Points))
+	for i, point := range line.Points {
+		points[i] = r3.Vector{X: point.X, Y: point.Y, Z: point.Z}
+	}
+
+	return points
+}
@@ -0,0 +1,168 @@
+package lib
+
+import (
+	"testing"
+
+	"github.com/golang/geo/r3"
+	"go.viam.com/rdk/spatialmath"
+	"go.viam.com/test"
+)
+
+func TestDecimatePolyline(t *testing.T) {
+	tests := []struct {
+		name      string
+		points    []r3.Vector
+		tolerance float64
+		expected  []r3.Vector
+	}{
+		{
+			name:      "collinear points collapse to the ends",
+			points:    []r3.Vector{{}, {X: 100}, {X: 200}, {X: 300}},
+			tolerance: 1,
+			expected:  []r3.Vector{{}, {X: 300}},
+		},
+		{
+			name:      "corners are kept",
+			points:    []r3.Vector{{}, {X: 100}, {X: 200}, {X: 200, Y: 100}, {X: 200, Y: 200}},
+			tolerance: 1,
+			expected:  []r3.Vector{{}, {X: 200}, {X: 200, Y: 200}},
+		},
+		{
+			name:      "small wiggles within the tolerance are dropped",
+			points:    []r3.Vector{{}, {X: 100, Y: 4}, {X: 200, Y: -4}, {X: 300}},
+			tolerance: 5,
+			expected:  []r3.Vector{{}, {X: 300}},
+		},
+		{
+			name:      "zero tolerance keeps every point",
+			points:    []r3.Vector{{}, {X: 100}, {X: 200}},
+			tolerance: 0,
+			expected:  []r3.Vector{{}, {X: 100}, {X: 200}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			test.That(t, DecimatePolyline(tt.points, tt.tolerance), test.ShouldResemble, tt.expected)
+		})
+	}
+}
+
+func TestPolylineLength(t *testing.T) {
+	test.That(t, PolylineLength(nil), test.ShouldEqual, 0.0)
+	test.That(t, PolylineLength([]r3.Vector{{}, {X: 300}, {X: 300, Y: 400}}), test.ShouldEqual, 700.0)
+}
+
+func TestCreatePolyline(t *testing.T) {
+	t.Run("ribbon", func(t *testing.T) {
+		points := []r3.Vector{{}, {X: 1000}, {X: 1000, Y: 1000}}
+		transform, err := CreatePolyline(points, 0, "path", testUUIDBytes, nil, "odom")
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, transform.ReferenceFrame, test.ShouldEqual, "path")
+		test.That(t, transform.PoseInObserverFrame.ReferenceFrame, test.ShouldEqual, "odom")
+		test.That(t, transform.Uuid, test.ShouldResemble, testUUIDBytes)
+
+		metadata := transform.Metadata.AsMap()
+		test.That(t, metadata["shape"], test.ShouldEqual, "line")
+		test.That(t, metadata["width_mm"], test.ShouldEqual, DefaultLineWidthMm)
+		test.That(t, metadata["color"], test.ShouldResemble, map[string]any{"r": 0.0, "g": 200.0, "b": 255.0})
+
+		mesh, err := spatialmath.NewMeshFromProto(spatialmath.NewZeroPose(), transform.PhysicalObject.GetMesh(), "")
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, len(mesh.Triangles()), test.ShouldEqual, 4)
+	})
+
+	t.Run("vertical segment", func(t *testing.T) {
+		_, err := CreatePolyline([]r3.Vector{{}, {Z: 100}}, 10, "", nil, nil, "")
+		test.That(t, err, test.ShouldBeNil)
+	})
+
+	t.Run("single point", func(t *testing.T) {
+		_, err := CreatePolyline([]r3.Vector{{X: 5}, {X: 5}}, 10, "", nil, nil, "")
+		test.That(t, err, test.ShouldNotBeNil)
+	})
+}
+
+func TestParseLine(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    any
+		expected func(*testing.T, *LineJSON, error)
+	}{
+		{
+			name: "valid line",
+			input: map[string]any{
+				"points":       []any{map[string]any{"x": 0.0}, map[string]any{"x": 100.0, "y": 50.0, "z": 10.0}},
+				"width_mm":     5.0,
+				"name":         "path",
+				"uuid":         testUUID.String(),
+				"color":        map[string]any{"r": 255, "g": 0, "b": 0},
+				"parent_frame": "odom",
+			},
+			expected: func(t *testing.T, line *LineJSON, err error) {
+				test.That(t, err, test.ShouldBeNil)
+				test.That(t, line.Name, test.ShouldEqual, "path")
+				test.That(t, line.UUID, test.ShouldEqual, testUUID.String())
+				test.That(t, line.WidthMm, test.ShouldEqual, 5.0)
+				test.That(t, line.ParentFrame, test.ShouldEqual, "odom")
+				test.That(t, line.Color, test.ShouldResemble, &Color{R: 255})
+				test.That(t, LinePoints(line), test.ShouldResemble, []r3.Vector{{}, {X: 100, Y: 50, Z: 10}})
+			},
+		},
+		{
+			name:  "defaults",
+			input: map[string]any{"points": []any{map[string]any{}, map[string]any{"z": 100.0}}},
+			expected: func(t *testing.T, line *LineJSON, err error) {
+				test.That(t, err, test.ShouldBeNil)
+				test.That(t, line.Color, test.ShouldResemble, &DefaultLineColor)
+				test.That(t, line.WidthMm, test.ShouldEqual, 0.0)
+				test.That(t, line.UUID, test.ShouldBeEmpty)
+			},
+		},
+		{
+			name:  "missing points",
+			input: map[string]any{"name": "path"},
+			expected: func(t *testing.T, line *LineJSON, err error) {
+				test.That(t, err, test.ShouldNotBeNil)
+				test.That(t, err.Error(), test.ShouldContainSubstring, "Missing required 'points' array")
+			},
+		},
+		{
+			name:  "single point",
+			input: map[string]any{"points": []any{map[string]any{}}},
+			expected: func(t *testing.T, line *LineJSON, err error) {
+				test.That(t, err, test.ShouldNotBeNil)
+				test.That(t, err.Error(), test.ShouldContainSubstring, "at least two points")
+			},
+		},
+		{
+			name:  "point not an object",
+			input: map[string]any{"points": []any{map[string]any{}, 1.0}},
+			expected: func(t *testing.T, line *LineJSON, err error) {
+				test.That(t, err, test.ShouldNotBeNil)
+				test.That(t, err.Error(), test.ShouldContainSubstring, "points[1]")
+			},
+		},
+		{
+			name:  "negative width",
+			input: map[string]any{"points": []any{map[string]any{}, map[string]any{"x": 1.0}}, "width_mm": -1.0},
+			expected: func(t *testing.T, line *LineJSON, err error) {
+				test.That(t, err, test.ShouldNotBeNil)
+			},
+		},
+		{
+			name:  "invalid uuid",
+			input: map[string]any{"points": []any{map[string]any{}, map[string]any{"x": 1.0}}, "uuid": "not-a-uuid"},
+			expected: func(t *testing.T, line *LineJSON, err error) {
+				test.That(t, err, test.ShouldNotBeNil)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			line, err := ParseLine(tt.input)
+			tt.expected(t, line, err)
+		})
+	}
+}
@@ -1,8 +1,6 @@
 package lib
 
 import (
-	"fmt"
-
 	commonPB "go.viam.com/api/common/v1"
 )
 
@@ -31,30 +29,18 @@ type PoseJSON struct {
 // Parameters:
 //   - poseData: JSON object containing pose data
 //
-// Returns the parsed pose or an error if parsing fails.
+// Returns the parsed pose or an error if parsing fails, naming the component that is not a number.
 func ParsePose(data any) (*commonPB.Pose, error) {
-	pose, ok := data.(map[string]any)
-	if !ok {
-		return nil, fmt.Errorf("expected pose object, got %T", data)
+	if _, ok := data.(map[string]any); !ok {
+		return nil, Errorf(ErrInvalidArgument, "expected pose object, got %T", data)
 	}
 
-	x := parseFloat(pose["x"], 0.0)
-	y := parseFloat(pose["y"], 0.0)
-	z := parseFloat(pose["z"], 0.0)
-	oX := parseFloat(pose["o_x"], 0.0)
-	oY := parseFloat(pose["o_y"], 0.0)
-	oZ := parseFloat(pose["o_z"], 0.0)
-	theta := parseFloat(pose["theta"], 0.0)
+	var pose PoseJSON
+	if err := Decode(data, &pose); err != nil {
+		return nil, err
+	}
 
-	return &commonPB.Pose{
-		X:     x,
-		Y:     y,
-		Z:     z,
-		OX:    oX,
-		OY:    oY,
-		OZ:    oZ,
-		Theta: theta,
-	}, nil
+	return PoseFromJSON(pose), nil
 }
 
 // PoseFromJSON converts a PoseJSON object to a commonPB.Pose.
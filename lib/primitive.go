@@ -0,0 +1,288 @@
+package lib
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/golang/geo/r3"
+	commonPB "go.viam.com/api/common/v1"
+	"go.viam.com/rdk/spatialmath"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+const (
+	// PrimitiveBox is a rectangular prism sized by dims_mm.
+	PrimitiveBox = "box"
+	// PrimitiveSphere is a sphere sized by radius_mm.
+	PrimitiveSphere = "sphere"
+	// PrimitiveCapsule is a capsule along the z axis sized by radius_mm and length_mm, including its end caps.
+	PrimitiveCapsule = "capsule"
+	// PrimitiveCylinder is a cylinder along the z axis sized by radius_mm and length_mm, approximated by a triangle mesh.
+	PrimitiveCylinder = "cylinder"
+
+	// DefaultCylinderSegments is the default number of sides of the prism approximating a cylinder.
+	DefaultCylinderSegments = 32
+	// MaxCylinderSegments is the largest number of sides of the prism approximating a cylinder.
+	MaxCylinderSegments = 1024
+)
+
+// DefaultPrimitiveColor is the color used for primitives drawn without an explicit color (red).
+var DefaultPrimitiveColor = Color{R: 255, G: 0, B: 0}
+
+// Vector3JSON represents a vector in JSON format, in millimeters.
+type Vector3JSON struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+	Z float64 `json:"z"`
+}
+
+// PrimitiveJSON represents a primitive geometry configuration in JSON format.
+// It contains all the necessary information to build a box, sphere, capsule or cylinder and place it in the world state.
+type PrimitiveJSON struct {
+	Type        string      `json:"type"`                   // One of "box", "sphere", "capsule" or "cylinder" (required)
+	Pose        PoseJSON    `json:"pose,omitempty"`         // Position and orientation of the center (optional, defaults to the origin)
+	Name        string      `json:"name,omitempty"`         // Name of the primitive frame (optional, defaults to "{type}-{uuid}")
+	UUID        string      `json:"uuid,omitempty"`         // UUID string (optional, generates new UUID if not provided)
+	Color       *Color      `json:"color,omitempty"`        // RGB color (optional, defaults to red)
+	ParentFrame string      `json:"parent_frame,omitempty"` // Parent reference frame (optional, defaults to "world")
+	Layer       string      `json:"layer,omitempty"`        // Layer of the shape, kept in its "layer" metadata (optional, defaults to "default")
+	Label       string      `json:"label,omitempty"`        // Geometry label (optional, defaults to the name)
+	DimsMm      Vector3JSON `json:"dims_mm,omitempty"`      // Box edge lengths (required for boxes)
+	RadiusMm    float64     `json:"radius_mm,omitempty"`    // Radius (required for spheres, capsules and cylinders)
+	LengthMm    float64     `json:"length_mm,omitempty"`    // Length along z (required for capsules and cylinders)
+	Opacity     *float64    `json:"opacity,omitempty"`      // Opacity from 0 (invisible) to 1 (opaque) (optional, defaults to 1)
+	Wireframe   bool        `json:"wireframe,omitempty"`    // Draw only the edges (optional, defaults to false)
+	Segments    int         `json:"segments,omitempty"`     // Sides of the prism approximating a cylinder (optional, defaults to 32)
+}
+
+// ParsePrimitive parses a single primitive from JSON data.
+// It expects a primitive object with a required type, the dimensions of that type, and optional fields.
+//
+// Parameters:
+//   - item: JSON object containing primitive data
+//
+// Returns the parsed primitive configuration or an error if parsing or validation fails.
+func ParsePrimitive(item any) (*PrimitiveJSON, error) {
+	if _, ok := item.(map[string]any); !ok {
+		return nil, Errorf(ErrInvalidArgument, "Expected primitive object, got %T", item)
+	}
+
+	color := DefaultPrimitiveColor
+	primitive := &PrimitiveJSON{
+		Color: &color,
+	}
+	if err := Decode(item, primitive); err != nil {
+		return nil, err
+	}
+
+	if primitive.Type == "" {
+		return nil, FieldErrorf("type", "Missing required 'type' field")
+	}
+
+	if primitive.UUID != "" {
+		if _, err := UUIDFromString(primitive.UUID); err != nil {
+			return nil, FieldErrorf("uuid", "Failed to parse UUID: %w", err)
+		}
+	}
+
+	if err := ValidatePrimitive(primitive); err != nil {
+		return nil, err
+	}
+
+	return primitive, nil
+}
+
+// ValidatePrimitive checks that a primitive has a known type, the dimensions that type needs, and a valid opacity.
+//
+// Parameters:
+//   - primitive: Primitive to check
+//
+// Returns an error describing the first problem found, or nil.
+func ValidatePrimitive(primitive *PrimitiveJSON) error {
+	switch primitive.Type {
+	case PrimitiveBox:
+		if primitive.DimsMm.X <= 0 || primitive.DimsMm.Y <= 0 || primitive.DimsMm.Z <= 0 {
+			return FieldErrorf("dims_mm", "box dims_mm x, y and z must be positive")
+		}
+	case PrimitiveSphere:
+		if primitive.RadiusMm <= 0 {
+			return FieldErrorf("radius_mm", "sphere radius_mm must be positive")
+		}
+	case PrimitiveCapsule:
+		if primitive.RadiusMm <= 0 {
+			return FieldErrorf("radius_mm", "capsule radius_mm must be positive")
+		}
+
+		if primitive.LengthMm <= 0 {
+			return FieldErrorf("length_mm", "capsule length_mm must be positive")
+		}
+
+		if primitive.LengthMm < 2*primitive.RadiusMm {
+			return FieldErrorf("length_mm", "capsule length_mm must be at least twice its radius_mm")
+		}
+	case PrimitiveCylinder:
+		if primitive.RadiusMm <= 0 {
+			return FieldErrorf("radius_mm", "cylinder radius_mm must be positive")
+		}
+
+		if primitive.LengthMm <= 0 {
+			return FieldErrorf("length_mm", "cylinder length_mm must be positive")
+		}
+
+		if primitive.Segments < 0 || (primitive.Segments > 0 && primitive.Segments < 3) {
+			return FieldErrorf("segments", "cylinder segments must be at least 3")
+		}
+
+		if primitive.Segments > MaxCylinderSegments {
+			err := fmt.Errorf("cylinder segments must be at most %d, got %d", MaxCylinderSegments, primitive.Segments)
+			return &Error{Code: ErrLimitExceeded, Path: "segments", Err: err}
+		}
+	default:
+		return FieldErrorf("type", "Unsupported primitive type %q, expected %q, %q, %q or %q",
+			primitive.Type, PrimitiveBox, PrimitiveSphere, PrimitiveCapsule, PrimitiveCylinder)
+	}
+
+	if primitive.Opacity != nil && (*primitive.Opacity < 0 || *primitive.Opacity > 1) {
+		return FieldErrorf("opacity", "opacity must be between 0 and 1")
+	}
+
+	return nil
+}
+
+// NewPrimitiveGeometry builds the spatialmath geometry of a primitive, centered on its own origin.
+// Boxes, spheres and capsules map to the matching spatialmath geometries; cylinders are approximated
+// by a closed triangle mesh with the configured number of sides.
+//
+// Parameters:
+//   - primitive: Primitive to build
+//   - label: Geometry label
+//
+// Returns the geometry or an error if the primitive is invalid.
+func NewPrimitiveGeometry(primitive *PrimitiveJSON, label string) (spatialmath.Geometry, error) {
+	if err := ValidatePrimitive(primitive); err != nil {
+		return nil, err
+	}
+
+	origin := spatialmath.NewZeroPose()
+	switch primitive.Type {
+	case PrimitiveBox:
+		dims := r3.Vector{X: primitive.DimsMm.X, Y: primitive.DimsMm.Y, Z: primitive.DimsMm.Z}
+		return spatialmath.NewBox(origin, dims, label)
+	case PrimitiveSphere:
+		return spatialmath.NewSphere(origin, primitive.RadiusMm, label)
+	case PrimitiveCapsule:
+		return spatialmath.NewCapsule(origin, primitive.RadiusMm, primitive.LengthMm, label)
+	default:
+		segments := primitive.Segments
+		if segments == 0 {
+			segments = DefaultCylinderSegments
+		}
+
+		return spatialmath.NewMesh(origin, cylinderTriangles(primitive.RadiusMm, primitive.LengthMm, segments), label), nil
+	}
+}
+
+// cylinderTriangles tessellates a closed cylinder along the z axis, centered on the origin.
+func cylinderTriangles(radius, length float64, segments int) []*spatialmath.Triangle {
+	top := r3.Vector{Z: length / 2}
+	bottom := r3.Vector{Z: -length / 2}
+	rim := func(i int, z float64) r3.Vector {
+		angle := 2 * math.Pi * float64(i%segments) / float64(segments)
+		return r3.Vector{X: radius * math.Cos(angle), Y: radius * math.Sin(angle), Z: z}
+	}
+
+	triangles := make([]*spatialmath.Triangle, 0, 4*segments)
+	for i := 0; i < segments; i++ {
+		topA, topB := rim(i, top.Z), rim(i+1, top.Z)
+		bottomA, bottomB := rim(i, bottom.Z), rim(i+1, bottom.Z)
+
+		triangles = append(triangles,
+			spatialmath.NewTriangle(top, topA, topB),
+			spatialmath.NewTriangle(bottom, bottomB, bottomA),
+			spatialmath.NewTriangle(bottomA, bottomB, topB),
+			spatialmath.NewTriangle(bottomA, topB, topA),
+		)
+	}
+
+	return triangles
+}
+
+// CreatePrimitive creates a new primitive transform from a geometry and individual components.
+// It generates a UUID if none is provided and uses default values for optional parameters.
+// The color, opacity and wireframe flag are sent in the transform metadata.
+//
+// Parameters:
+//   - primitiveType: Type of the primitive, used for the default name and the "shape" metadata
+//   - geometry: Geometry of the primitive (required)
+//   - pose: Position and orientation of the primitive (defaults to the origin if nil)
+//   - name: Name for the primitive frame (empty string will generate "{type}-{uuid}")
+//   - uuid: Optional UUID bytes (generates new UUID if nil)
+//   - color: Optional color (defaults to red if nil)
+//   - parentFrame: Optional parent frame (defaults to "world" if empty)
+//   - opacity: Opacity from 0 to 1
+//   - wireframe: Whether to draw only the edges
+//
+// Returns the created primitive transform or an error if creation fails.
+func CreatePrimitive(primitiveType string, geometry spatialmath.Geometry, pose *commonPB.Pose, name string, uuid []byte, color *Color, parentFrame string, opacity float64, wireframe bool) (*commonPB.Transform, error) {
+	if geometry == nil {
+		return nil, Errorf(ErrInvalidArgument, "geometry is required")
+	}
+
+	var id UUID
+	if uuid == nil {
+		id = GenerateUUID()
+	} else {
+		parsed, err := UUIDFromBytes(uuid)
+		if err != nil {
+			return nil, err
+		}
+
+		id = *parsed
+	}
+
+	if name == "" {
+		name = fmt.Sprintf("%s-%s", primitiveType, id.String())
+	}
+
+	if pose == nil {
+		pose = &commonPB.Pose{}
+	}
+
+	if pose.OX == 0 && pose.OY == 0 && pose.OZ == 0 {
+		pose = &commonPB.Pose{X: pose.X, Y: pose.Y, Z: pose.Z, OZ: 1, Theta: pose.Theta}
+	}
+
+	if color == nil {
+		color = &DefaultPrimitiveColor
+	}
+
+	metadata, err := structpb.NewStruct(map[string]any{
+		"shape": primitiveType,
+		"color": map[string]any{
+			"r": int(color.R),
+			"g": int(color.G),
+			"b": int(color.B),
+		},
+		"opacity":   opacity,
+		"wireframe": wireframe,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	parent := "world"
+	if parentFrame != "" {
+		parent = parentFrame
+	}
+
+	return &commonPB.Transform{
+		ReferenceFrame: name,
+		PoseInObserverFrame: &commonPB.PoseInFrame{
+			ReferenceFrame: parent,
+			Pose:           pose,
+		},
+		Uuid:           id.Bytes(),
+		PhysicalObject: geometry.ToProtobuf(),
+		Metadata:       metadata,
+	}, nil
+}
@@ -0,0 +1,183 @@
+package lib
+
+import (
+	"errors"
+	"testing"
+
+	commonPB "go.viam.com/api/common/v1"
+	"go.viam.com/rdk/spatialmath"
+	"go.viam.com/test"
+)
+
+func TestParsePrimitive(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    any
+		expected func(*testing.T, *PrimitiveJSON, error)
+	}{
+		{
+			name: "valid box",
+			input: map[string]any{
+				"type":         "box",
+				"name":         "keep-out",
+				"parent_frame": "table",
+				"dims_mm":      map[string]any{"x": 100, "y": 200, "z": 300.0},
+				"opacity":      0.4,
+				"wireframe":    true,
+				"color":        map[string]any{"r": 0, "g": 255, "b": 0},
+				"pose":         map[string]any{"z": 150.0},
+			},
+			expected: func(t *testing.T, primitive *PrimitiveJSON, err error) {
+				test.That(t, err, test.ShouldBeNil)
+				test.That(t, primitive.Type, test.ShouldEqual, PrimitiveBox)
+				test.That(t, primitive.Name, test.ShouldEqual, "keep-out")
+				test.That(t, primitive.ParentFrame, test.ShouldEqual, "table")
+				test.That(t, primitive.DimsMm, test.ShouldResemble, Vector3JSON{X: 100, Y: 200, Z: 300})
+				test.That(t, *primitive.Opacity, test.ShouldEqual, 0.4)
+				test.That(t, primitive.Wireframe, test.ShouldBeTrue)
+				test.That(t, primitive.Color, test.ShouldResemble, &Color{R: 0, G: 255, B: 0})
+				test.That(t, primitive.Pose.Z, test.ShouldEqual, 150.0)
+			},
+		},
+		{
+			name:  "defaults",
+			input: map[string]any{"type": "sphere", "radius_mm": 50},
+			expected: func(t *testing.T, primitive *PrimitiveJSON, err error) {
+				test.That(t, err, test.ShouldBeNil)
+				test.That(t, primitive.Color, test.ShouldResemble, &DefaultPrimitiveColor)
+				test.That(t, primitive.Opacity, test.ShouldBeNil)
+				test.That(t, primitive.Wireframe, test.ShouldBeFalse)
+			},
+		},
+		{
+			name:  "missing type",
+			input: map[string]any{"radius_mm": 50},
+			expected: func(t *testing.T, primitive *PrimitiveJSON, err error) {
+				test.That(t, err, test.ShouldNotBeNil)
+				test.That(t, err.Error(), test.ShouldContainSubstring, "type")
+			},
+		},
+		{
+			name:  "unknown type",
+			input: map[string]any{"type": "cone", "radius_mm": 50},
+			expected: func(t *testing.T, primitive *PrimitiveJSON, err error) {
+				test.That(t, err, test.ShouldNotBeNil)
+				test.That(t, err.Error(), test.ShouldContainSubstring, "cone")
+			},
+		},
+		{
+			name:  "box without dims",
+			input: map[string]any{"type": "box"},
+			expected: func(t *testing.T, primitive *PrimitiveJSON, err error) {
+				test.That(t, err, test.ShouldNotBeNil)
+				test.That(t, err.Error(), test.ShouldContainSubstring, "dims_mm")
+			},
+		},
+		{
+			name:  "capsule without radius",
+			input: map[string]any{"type": "capsule", "length_mm": 60},
+			expected: func(t *testing.T, primitive *PrimitiveJSON, err error) {
+				test.That(t, errors.Is(err, ErrInvalidArgument), test.ShouldBeTrue)
+				test.That(t, ErrorFields(err)["path"], test.ShouldEqual, "radius_mm")
+			},
+		},
+		{
+			name:  "cylinder without length",
+			input: map[string]any{"type": "cylinder", "radius_mm": 50},
+			expected: func(t *testing.T, primitive *PrimitiveJSON, err error) {
+				test.That(t, errors.Is(err, ErrInvalidArgument), test.ShouldBeTrue)
+				test.That(t, ErrorFields(err)["path"], test.ShouldEqual, "length_mm")
+			},
+		},
+		{
+			name:  "capsule shorter than its caps",
+			input: map[string]any{"type": "capsule", "radius_mm": 50, "length_mm": 60},
+			expected: func(t *testing.T, primitive *PrimitiveJSON, err error) {
+				test.That(t, err, test.ShouldNotBeNil)
+				test.That(t, err.Error(), test.ShouldContainSubstring, "length_mm")
+			},
+		},
+		{
+			name:  "too many cylinder segments",
+			input: map[string]any{"type": "cylinder", "radius_mm": 50, "length_mm": 100, "segments": MaxCylinderSegments + 1},
+			expected: func(t *testing.T, primitive *PrimitiveJSON, err error) {
+				test.That(t, errors.Is(err, ErrLimitExceeded), test.ShouldBeTrue)
+				test.That(t, ErrorFields(err)["path"], test.ShouldEqual, "segments")
+			},
+		},
+		{
+			name:  "opacity out of range",
+			input: map[string]any{"type": "sphere", "radius_mm": 50, "opacity": 1.5},
+			expected: func(t *testing.T, primitive *PrimitiveJSON, err error) {
+				test.That(t, err, test.ShouldNotBeNil)
+				test.That(t, err.Error(), test.ShouldContainSubstring, "opacity")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			primitive, err := ParsePrimitive(tt.input)
+			tt.expected(t, primitive, err)
+		})
+	}
+}
+
+func TestNewPrimitiveGeometry(t *testing.T) {
+	t.Run("box", func(t *testing.T) {
+		geometry, err := NewPrimitiveGeometry(&PrimitiveJSON{Type: PrimitiveBox, DimsMm: Vector3JSON{X: 1, Y: 2, Z: 3}}, "box")
+		test.That(t, err, test.ShouldBeNil)
+		box := geometry.ToProtobuf().GetBox()
+		test.That(t, box, test.ShouldNotBeNil)
+		test.That(t, box.DimsMm.Z, test.ShouldEqual, 3.0)
+	})
+
+	t.Run("sphere", func(t *testing.T) {
+		geometry, err := NewPrimitiveGeometry(&PrimitiveJSON{Type: PrimitiveSphere, RadiusMm: 10}, "sphere")
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, geometry.ToProtobuf().GetSphere().RadiusMm, test.ShouldEqual, 10.0)
+	})
+
+	t.Run("capsule", func(t *testing.T) {
+		geometry, err := NewPrimitiveGeometry(&PrimitiveJSON{Type: PrimitiveCapsule, RadiusMm: 10, LengthMm: 50}, "capsule")
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, geometry.ToProtobuf().GetCapsule().LengthMm, test.ShouldEqual, 50.0)
+	})
+
+	t.Run("cylinder", func(t *testing.T) {
+		geometry, err := NewPrimitiveGeometry(&PrimitiveJSON{Type: PrimitiveCylinder, RadiusMm: 10, LengthMm: 50, Segments: 8}, "cylinder")
+		test.That(t, err, test.ShouldBeNil)
+
+		mesh, ok := geometry.(*spatialmath.Mesh)
+		test.That(t, ok, test.ShouldBeTrue)
+		test.That(t, len(mesh.Triangles()), test.ShouldEqual, 32)
+		test.That(t, geometry.ToProtobuf().GetMesh().ContentType, test.ShouldEqual, "ply")
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := NewPrimitiveGeometry(&PrimitiveJSON{Type: PrimitiveCylinder, RadiusMm: 10}, "cylinder")
+		test.That(t, err, test.ShouldNotBeNil)
+	})
+}
+
+func TestCreatePrimitive(t *testing.T) {
+	geometry, err := NewPrimitiveGeometry(&PrimitiveJSON{Type: PrimitiveSphere, RadiusMm: 10}, "")
+	test.That(t, err, test.ShouldBeNil)
+
+	transform, err := CreatePrimitive(PrimitiveSphere, geometry, &commonPB.Pose{X: 5}, "", testUUIDBytes, nil, "", 0.5, true)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, transform.ReferenceFrame, test.ShouldEqual, "sphere-"+testUUID.String())
+	test.That(t, transform.PoseInObserverFrame.ReferenceFrame, test.ShouldEqual, "world")
+	test.That(t, transform.PoseInObserverFrame.Pose.X, test.ShouldEqual, 5.0)
+	test.That(t, transform.PoseInObserverFrame.Pose.OZ, test.ShouldEqual, 1.0)
+	test.That(t, transform.PhysicalObject.GetSphere(), test.ShouldNotBeNil)
+
+	metadata := transform.Metadata.AsMap()
+	test.That(t, metadata["shape"], test.ShouldEqual, "sphere")
+	test.That(t, metadata["opacity"], test.ShouldEqual, 0.5)
+	test.That(t, metadata["wireframe"], test.ShouldEqual, true)
+	test.That(t, metadata["color"], test.ShouldResemble, map[string]any{"r": 255.0, "g": 0.0, "b": 0.0})
+
+	_, err = CreatePrimitive(PrimitiveSphere, nil, nil, "", nil, nil, "", 1, false)
+	test.That(t, err, test.ShouldNotBeNil)
+}
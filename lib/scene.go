@@ -0,0 +1,210 @@
+package lib
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// SceneSidecarName is the name of the optional placement file read from directories of meshes.
+// It maps mesh file names to the fields that place them, for example:
+//
+//	{"base.ply": {"pose": {"z": 100}, "color": {"r": 128, "g": 128, "b": 128}}}
+const SceneSidecarName = "scene.json"
+
+// IsMeshPattern reports whether a model path refers to a directory or a glob pattern
+// rather than a single mesh file. An existing file is never a pattern, so a file named
+// like "part[1].ply" is drawn as itself.
+//
+// Parameters:
+//   - path: Model path to check
+//
+// Returns true if the path is an existing directory, or names no existing file and contains
+// glob metacharacters.
+func IsMeshPattern(path string) bool {
+	info, err := os.Stat(path)
+	if err == nil {
+		return info.IsDir()
+	}
+
+	return strings.ContainsAny(path, "*?[")
+}
+
+// ExpandMeshes expands a mesh whose model path is a directory or glob pattern into one mesh per PLY file.
+// Each mesh is named after its file, without the extension, and inherits the remaining fields of spec.
+// Fields set for the file in a scene.json sidecar next to it take precedence.
+//
+// Parameters:
+//   - spec: Mesh whose ModelPath is a directory or glob pattern
+//
+// Returns the meshes sorted by path, or an error if nothing matches or a sidecar cannot be read.
+func ExpandMeshes(spec MeshJSON) ([]MeshJSON, error) {
+	if spec.UUID != "" {
+		return nil, FieldErrorf("uuid", "uuid cannot be set when drawing a directory or glob pattern")
+	}
+
+	pattern := spec.ModelPath
+	if info, err := os.Stat(pattern); err == nil && info.IsDir() {
+		pattern = filepath.Join(pattern, "*")
+	}
+
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, FieldErrorf("model_path", "%w", err)
+	}
+
+	paths := make([]string, 0, len(matches))
+	for _, match := range matches {
+		if strings.ToLower(filepath.Ext(match)) != ".ply" {
+			continue
+		}
+
+		if info, err := os.Stat(match); err != nil || info.IsDir() {
+			continue
+		}
+
+		paths = append(paths, match)
+	}
+
+	if len(paths) == 0 {
+		err := fmt.Errorf("no PLY files match %s", spec.ModelPath)
+		return nil, &Error{Code: ErrFileNotFound, Path: "model_path", Err: err}
+	}
+
+	sort.Strings(paths)
+
+	sidecars := make(map[string]map[string]MeshJSON)
+	meshes := make([]MeshJSON, 0, len(paths))
+	for _, path := range paths {
+		dir := filepath.Dir(path)
+		sidecar, ok := sidecars[dir]
+		if !ok {
+			sidecar, err = LoadSceneSidecar(dir)
+			if err != nil {
+				return nil, err
+			}
+			sidecars[dir] = sidecar
+		}
+
+		mesh := spec
+		mesh.ModelPath = path
+		mesh.Name = MeshNameFromPath(path)
+		if override, ok := sidecar[filepath.Base(path)]; ok {
+			mesh = applyMeshOverride(mesh, override)
+		}
+
+		meshes = append(meshes, mesh)
+	}
+
+	return meshes, nil
+}
+
+// LoadSceneSidecar reads the scene.json sidecar of a directory.
+//
+// Parameters:
+//   - dir: Directory containing the meshes
+//
+// Returns the placement of each file by file name, or an empty map if the directory has no sidecar. A sidecar that is
+// not valid JSON fails with the unsupported_format code, and a placement field that ParseMesh would reject fails with
+// the invalid_argument code and a path under the file name, such as "base.ply.scale".
+func LoadSceneSidecar(dir string) (map[string]MeshJSON, error) {
+	path := filepath.Join(dir, SceneSidecarName)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return map[string]MeshJSON{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var entries map[string]any
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, formatError("Failed to parse %s: %w", path, err)
+	}
+
+	sidecar := make(map[string]MeshJSON, len(entries))
+	if err := Decode(entries, &sidecar); err != nil {
+		return nil, fmt.Errorf("Failed to parse %s: %w", path, err)
+	}
+
+	names := make([]string, 0, len(sidecar))
+	for name := range sidecar {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		fields, _ := entries[name].(map[string]any)
+		if err := validateMeshOverride(name, sidecar[name], fields); err != nil {
+			return nil, fmt.Errorf("Failed to parse %s: %w", path, err)
+		}
+	}
+
+	return sidecar, nil
+}
+
+// validateMeshOverride checks the placement of a file as ParseMesh checks a mesh.
+func validateMeshOverride(name string, override MeshJSON, fields map[string]any) error {
+	if override.UUID != "" {
+		if _, err := UUIDFromString(override.UUID); err != nil {
+			return FieldErrorf(join(name, "uuid"), "Failed to parse UUID: %w", err)
+		}
+	}
+
+	if fields["scale"] != nil && override.Scale <= 0 {
+		return FieldErrorf(join(name, "scale"), "Expected positive number for scale, got %v", override.Scale)
+	}
+
+	return nil
+}
+
+// MeshNameFromPath derives a mesh name from its file name by dropping the directory and extension.
+//
+// Parameters:
+//   - path: Path to the mesh file
+//
+// Returns the derived name (e.g. "base" for "/fixtures/base.ply").
+func MeshNameFromPath(path string) string {
+	base := filepath.Base(path)
+	return strings.TrimSuffix(base, filepath.Ext(base))
+}
+
+func applyMeshOverride(mesh, override MeshJSON) MeshJSON {
+	if override.Pose != (PoseJSON{}) {
+		mesh.Pose = override.Pose
+	}
+
+	if override.Name != "" {
+		mesh.Name = override.Name
+	}
+
+	if override.UUID != "" {
+		mesh.UUID = override.UUID
+	}
+
+	if override.Color != nil {
+		mesh.Color = override.Color
+	}
+
+	if override.ParentFrame != "" {
+		mesh.ParentFrame = override.ParentFrame
+	}
+
+	if override.Scale != 0 {
+		mesh.Scale = override.Scale
+	}
+
+	if override.Label != "" {
+		mesh.Label = override.Label
+	}
+
+	if override.Watch {
+		mesh.Watch = true
+	}
+
+	return mesh
+}
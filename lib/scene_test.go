@@ -0,0 +1,133 @@
+package lib
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go.viam.com/test"
+)
+
+func TestExpandMeshes(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, dir, "base.ply", testPLY)
+	writeTestFile(t, dir, "clamp.ply", otherTestPLY)
+	writeTestFile(t, dir, "notes.txt", "not a mesh")
+	test.That(t, os.Mkdir(filepath.Join(dir, "nested.ply"), 0o755), test.ShouldBeNil)
+
+	base := MeshJSON{Color: &Color{R: 1, G: 2, B: 3}, ParentFrame: "table", Scale: 1}
+
+	t.Run("directory", func(t *testing.T) {
+		spec := base
+		spec.ModelPath = dir
+		test.That(t, IsMeshPattern(spec.ModelPath), test.ShouldBeTrue)
+
+		meshes, err := ExpandMeshes(spec)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, len(meshes), test.ShouldEqual, 2)
+		test.That(t, meshes[0].ModelPath, test.ShouldEqual, filepath.Join(dir, "base.ply"))
+		test.That(t, meshes[0].Name, test.ShouldEqual, "base")
+		test.That(t, meshes[0].ParentFrame, test.ShouldEqual, "table")
+		test.That(t, meshes[1].Name, test.ShouldEqual, "clamp")
+		test.That(t, meshes[1].Color, test.ShouldResemble, &Color{R: 1, G: 2, B: 3})
+	})
+
+	t.Run("glob", func(t *testing.T) {
+		spec := base
+		spec.ModelPath = filepath.Join(dir, "c*.ply")
+		test.That(t, IsMeshPattern(spec.ModelPath), test.ShouldBeTrue)
+
+		meshes, err := ExpandMeshes(spec)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, len(meshes), test.ShouldEqual, 1)
+		test.That(t, meshes[0].Name, test.ShouldEqual, "clamp")
+	})
+
+	t.Run("sidecar placement", func(t *testing.T) {
+		writeTestFile(t, dir, SceneSidecarName, `{
+			"base.ply": {"pose": {"z": 100, "o_z": 1}, "color": {"r": 200}, "name": "workcell-base"}
+		}`)
+		defer os.Remove(filepath.Join(dir, SceneSidecarName))
+
+		spec := base
+		spec.ModelPath = dir
+		meshes, err := ExpandMeshes(spec)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, meshes[0].Name, test.ShouldEqual, "workcell-base")
+		test.That(t, meshes[0].Pose.Z, test.ShouldEqual, 100.0)
+		test.That(t, meshes[0].Color, test.ShouldResemble, &Color{R: 200})
+		test.That(t, meshes[0].ParentFrame, test.ShouldEqual, "table")
+		test.That(t, meshes[1].Name, test.ShouldEqual, "clamp")
+		test.That(t, meshes[1].Pose, test.ShouldResemble, PoseJSON{})
+	})
+
+	t.Run("invalid sidecar", func(t *testing.T) {
+		writeTestFile(t, dir, SceneSidecarName, `not json`)
+		defer os.Remove(filepath.Join(dir, SceneSidecarName))
+
+		spec := base
+		spec.ModelPath = dir
+		_, err := ExpandMeshes(spec)
+		test.That(t, errors.Is(err, ErrUnsupportedFormat), test.ShouldBeTrue)
+	})
+
+	t.Run("invalid sidecar placement", func(t *testing.T) {
+		defer os.Remove(filepath.Join(dir, SceneSidecarName))
+
+		for _, tc := range []struct {
+			placement string
+			path      string
+		}{
+			{`{"scale": -1}`, "base.ply.scale"},
+			{`{"scale": 0}`, "base.ply.scale"},
+			{`{"uuid": "not-a-uuid"}`, "base.ply.uuid"},
+			{`{"color": "red"}`, "base.ply.color"},
+			{`"base"`, "base.ply"},
+		} {
+			writeTestFile(t, dir, SceneSidecarName, `{"base.ply": `+tc.placement+`}`)
+
+			spec := base
+			spec.ModelPath = dir
+			_, err := ExpandMeshes(spec)
+			test.That(t, errors.Is(err, ErrInvalidArgument), test.ShouldBeTrue)
+
+			var coded *Error
+			test.That(t, errors.As(err, &coded), test.ShouldBeTrue)
+			test.That(t, coded.Path, test.ShouldEqual, tc.path)
+		}
+	})
+
+	t.Run("no matches", func(t *testing.T) {
+		spec := base
+		spec.ModelPath = filepath.Join(dir, "*.obj")
+		_, err := ExpandMeshes(spec)
+		test.That(t, errors.Is(err, ErrFileNotFound), test.ShouldBeTrue)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "no PLY files")
+	})
+
+	t.Run("single file is not a pattern", func(t *testing.T) {
+		test.That(t, IsMeshPattern(filepath.Join(dir, "base.ply")), test.ShouldBeFalse)
+	})
+
+	t.Run("existing file with glob characters is not a pattern", func(t *testing.T) {
+		path := filepath.Join(dir, "part[1].ply")
+		test.That(t, IsMeshPattern(path), test.ShouldBeTrue)
+		test.That(t, os.WriteFile(path, []byte("ply"), 0o644), test.ShouldBeNil)
+		defer os.Remove(path)
+		test.That(t, IsMeshPattern(path), test.ShouldBeFalse)
+	})
+
+	t.Run("uuid not allowed", func(t *testing.T) {
+		spec := base
+		spec.ModelPath = dir
+		spec.UUID = "550e8400-e29b-41d4-a716-446655440000"
+		_, err := ExpandMeshes(spec)
+		test.That(t, err, test.ShouldNotBeNil)
+	})
+}
+
+func TestMeshNameFromPath(t *testing.T) {
+	test.That(t, MeshNameFromPath("/fixtures/base.ply"), test.ShouldEqual, "base")
+	test.That(t, MeshNameFromPath("clamp.v2.PLY"), test.ShouldEqual, "clamp.v2")
+}
@@ -0,0 +1,128 @@
+package lib
+
+import "fmt"
+
+// Shape types accepted in the "type" field of a shape, besides the primitive types "box", "sphere", "capsule" and
+// "cylinder".
+const (
+	// ShapeArrow is a single arrow.
+	ShapeArrow = "arrow"
+	// ShapeAxes is an axes triad of three arrows.
+	ShapeAxes = "axes"
+	// ShapeMesh is a mesh loaded from a PLY file.
+	ShapeMesh = "mesh"
+	// ShapePointCloud is a point cloud loaded from a PCD or PLY file.
+	ShapePointCloud = "pointcloud"
+	// ShapeLine is a polyline.
+	ShapeLine = "line"
+	// ShapeLabel is a text label.
+	ShapeLabel = "label"
+)
+
+// ShapeTypes lists every shape type, in the order they are documented.
+var ShapeTypes = []string{
+	ShapeArrow,
+	ShapeAxes,
+	ShapeMesh,
+	ShapePointCloud,
+	PrimitiveBox,
+	PrimitiveSphere,
+	PrimitiveCapsule,
+	PrimitiveCylinder,
+	ShapeLine,
+	ShapeLabel,
+}
+
+// ShapeJSON is a drawable item of any type. Type says which one of the configurations is set: Arrow for arrows and
+// axes triads, Primitive for boxes, spheres, capsules and cylinders, and the matching field for the other types.
+type ShapeJSON struct {
+	Type       string
+	Arrow      *ArrowJSON
+	Mesh       *MeshJSON
+	PointCloud *PointCloudJSON
+	Primitive  *PrimitiveJSON
+	Line       *LineJSON
+	Label      *LabelJSON
+}
+
+// IsPrimitiveType reports whether a shape type is one of the primitive types.
+func IsPrimitiveType(shapeType string) bool {
+	switch shapeType {
+	case PrimitiveBox, PrimitiveSphere, PrimitiveCapsule, PrimitiveCylinder:
+		return true
+	default:
+		return false
+	}
+}
+
+// ParseShape parses a single shape of any type from JSON data.
+// It expects an object with a required "type" field; the remaining fields are those of that type.
+//
+// Parameters:
+//   - item: JSON object containing shape data
+//
+// Returns the parsed shape or an error if parsing fails.
+func ParseShape(item any) (*ShapeJSON, error) {
+	shapeMap, ok := item.(map[string]any)
+	if !ok {
+		return nil, Errorf(ErrInvalidArgument, "Expected shape object, got %T", item)
+	}
+
+	shapeType, ok := shapeMap["type"].(string)
+	if !ok || shapeType == "" {
+		return nil, FieldErrorf("type", "Missing required 'type' field")
+	}
+
+	shape := &ShapeJSON{Type: shapeType}
+	var err error
+	switch {
+	case shapeType == ShapeArrow || shapeType == ShapeAxes:
+		shape.Arrow, err = ParseArrowSpec(shapeMap)
+		if err == nil {
+			shape.Arrow.Axes = shapeType == ShapeAxes
+		}
+	case shapeType == ShapeMesh:
+		shape.Mesh, err = ParseMesh(shapeMap)
+	case shapeType == ShapePointCloud:
+		shape.PointCloud, err = ParsePointCloud(shapeMap)
+	case IsPrimitiveType(shapeType):
+		shape.Primitive, err = ParsePrimitive(shapeMap)
+	case shapeType == ShapeLine:
+		shape.Line, err = ParseLine(shapeMap)
+	case shapeType == ShapeLabel:
+		shape.Label, err = ParseLabel(shapeMap)
+	default:
+		return nil, FieldErrorf("type", "Unknown shape type %q, expected one of %v", shapeType, ShapeTypes)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse %s: %w", shapeType, err)
+	}
+
+	return shape, nil
+}
+
+// ParseShapes parses a single shape object or an array of shapes from JSON data.
+//
+// Parameters:
+//   - data: JSON object or array containing shape data
+//
+// Returns the parsed shapes or an error naming the index of the first shape that fails to parse.
+func ParseShapes(data any) ([]*ShapeJSON, error) {
+	items, ok := data.([]any)
+	if !ok {
+		items = []any{data}
+	}
+
+	shapes := make([]*ShapeJSON, 0, len(items))
+	for i, item := range items {
+		shape, err := ParseShape(item)
+		if err != nil {
+			return nil, AtIndex(i, fmt.Errorf("Failed to parse shape at index %d: %w", i, err))
+		}
+
+		shapes = append(shapes, shape)
+	}
+
+	return shapes, nil
+}
@@ -0,0 +1,145 @@
+package lib
+
+import (
+	"testing"
+
+	"go.viam.com/test"
+)
+
+func TestParseShape(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    any
+		expected func(*testing.T, *ShapeJSON, error)
+	}{
+		{
+			name:  "arrow",
+			input: map[string]any{"type": "arrow", "pose": map[string]any{"o_z": 1.0}, "name": "target"},
+			expected: func(t *testing.T, shape *ShapeJSON, err error) {
+				test.That(t, err, test.ShouldBeNil)
+				test.That(t, shape.Type, test.ShouldEqual, ShapeArrow)
+				test.That(t, shape.Arrow.Name, test.ShouldEqual, "target")
+				test.That(t, shape.Arrow.Axes, test.ShouldBeFalse)
+			},
+		},
+		{
+			name:  "axes",
+			input: map[string]any{"type": "axes", "pose": map[string]any{"o_z": 1.0}},
+			expected: func(t *testing.T, shape *ShapeJSON, err error) {
+				test.That(t, err, test.ShouldBeNil)
+				test.That(t, shape.Type, test.ShouldEqual, ShapeAxes)
+				test.That(t, shape.Arrow.Axes, test.ShouldBeTrue)
+			},
+		},
+		{
+			name:  "mesh",
+			input: map[string]any{"type": "mesh", "model_path": "/models/part.ply"},
+			expected: func(t *testing.T, shape *ShapeJSON, err error) {
+				test.That(t, err, test.ShouldBeNil)
+				test.That(t, shape.Mesh.ModelPath, test.ShouldEqual, "/models/part.ply")
+				test.That(t, shape.Arrow, test.ShouldBeNil)
+			},
+		},
+		{
+			name:  "pointcloud",
+			input: map[string]any{"type": "pointcloud", "model_path": "/scans/room.pcd"},
+			expected: func(t *testing.T, shape *ShapeJSON, err error) {
+				test.That(t, err, test.ShouldBeNil)
+				test.That(t, shape.PointCloud.ModelPath, test.ShouldEqual, "/scans/room.pcd")
+			},
+		},
+		{
+			name:  "primitive",
+			input: map[string]any{"type": "sphere", "radius_mm": 50.0},
+			expected: func(t *testing.T, shape *ShapeJSON, err error) {
+				test.That(t, err, test.ShouldBeNil)
+				test.That(t, shape.Type, test.ShouldEqual, PrimitiveSphere)
+				test.That(t, shape.Primitive.Type, test.ShouldEqual, PrimitiveSphere)
+				test.That(t, shape.Primitive.RadiusMm, test.ShouldEqual, 50.0)
+			},
+		},
+		{
+			name:  "line",
+			input: map[string]any{"type": "line", "points": []any{map[string]any{}, map[string]any{"x": 100.0}}},
+			expected: func(t *testing.T, shape *ShapeJSON, err error) {
+				test.That(t, err, test.ShouldBeNil)
+				test.That(t, shape.Line.Points, test.ShouldHaveLength, 2)
+			},
+		},
+		{
+			name:  "label",
+			input: map[string]any{"type": "label", "pose": map[string]any{}, "text": "dock"},
+			expected: func(t *testing.T, shape *ShapeJSON, err error) {
+				test.That(t, err, test.ShouldBeNil)
+				test.That(t, shape.Label.Text, test.ShouldEqual, "dock")
+			},
+		},
+		{
+			name:  "missing type",
+			input: map[string]any{"pose": map[string]any{}},
+			expected: func(t *testing.T, shape *ShapeJSON, err error) {
+				test.That(t, err, test.ShouldNotBeNil)
+				test.That(t, err.Error(), test.ShouldContainSubstring, "Missing required 'type' field")
+			},
+		},
+		{
+			name:  "unknown type",
+			input: map[string]any{"type": "teapot"},
+			expected: func(t *testing.T, shape *ShapeJSON, err error) {
+				test.That(t, err, test.ShouldNotBeNil)
+				test.That(t, err.Error(), test.ShouldContainSubstring, `Unknown shape type "teapot"`)
+			},
+		},
+		{
+			name:  "invalid fields",
+			input: map[string]any{"type": "label", "pose": map[string]any{}},
+			expected: func(t *testing.T, shape *ShapeJSON, err error) {
+				test.That(t, err, test.ShouldNotBeNil)
+				test.That(t, err.Error(), test.ShouldContainSubstring, "Failed to parse label")
+			},
+		},
+		{
+			name:  "not an object",
+			input: []any{},
+			expected: func(t *testing.T, shape *ShapeJSON, err error) {
+				test.That(t, err, test.ShouldNotBeNil)
+				test.That(t, err.Error(), test.ShouldContainSubstring, "Expected shape object")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shape, err := ParseShape(tt.input)
+			tt.expected(t, shape, err)
+		})
+	}
+}
+
+func TestParseShapes(t *testing.T) {
+	t.Run("array", func(t *testing.T) {
+		shapes, err := ParseShapes([]any{
+			map[string]any{"type": "box", "dims_mm": map[string]any{"x": 10.0, "y": 10.0, "z": 10.0}},
+			map[string]any{"type": "label", "pose": map[string]any{}, "text": "box"},
+		})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, shapes, test.ShouldHaveLength, 2)
+		test.That(t, shapes[0].Type, test.ShouldEqual, PrimitiveBox)
+		test.That(t, shapes[1].Type, test.ShouldEqual, ShapeLabel)
+	})
+
+	t.Run("single object", func(t *testing.T) {
+		shapes, err := ParseShapes(map[string]any{"type": "axes", "pose": map[string]any{}})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, shapes, test.ShouldHaveLength, 1)
+	})
+
+	t.Run("invalid shape", func(t *testing.T) {
+		_, err := ParseShapes([]any{
+			map[string]any{"type": "axes", "pose": map[string]any{}},
+			map[string]any{"type": "teapot"},
+		})
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "Failed to parse shape at index 1")
+	})
+}
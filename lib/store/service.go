@@ -0,0 +1,160 @@
+package store
+
+import (
+	"context"
+	"sync"
+
+	"github.com/viam-labs/draw-tools/lib"
+
+	"github.com/google/uuid"
+	commonPB "go.viam.com/api/common/v1"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	worldstatestore "go.viam.com/rdk/services/worldstatestore"
+)
+
+// Service implements the world state store API on top of a Store. Models embed it, add their DoCommand, and run their
+// background work with Go so Close can stop it before ending the subscriptions.
+type Service struct {
+	resource.AlwaysRebuild
+	*Store
+
+	name   resource.Name
+	logger logging.Logger
+
+	cancelCtx    context.Context
+	cancelFunc   func()
+	workers      sync.WaitGroup
+	workersMutex sync.Mutex
+	closed       bool
+	closeOnce    sync.Once
+}
+
+// NewService creates a service with an empty store.
+func NewService(name resource.Name, logger logging.Logger) *Service {
+	cancelCtx, cancelFunc := context.WithCancel(context.Background())
+	return &Service{
+		Store:      New(logger),
+		name:       name,
+		logger:     logger,
+		cancelCtx:  cancelCtx,
+		cancelFunc: cancelFunc,
+	}
+}
+
+func (service *Service) Name() resource.Name {
+	return service.name
+}
+
+// Context returns a context that is canceled when the service starts closing.
+func (service *Service) Context() context.Context {
+	return service.cancelCtx
+}
+
+// Go runs fn in the background with the service's context. Close cancels the context and waits for fn to return.
+// Once the service is closing, fn is not run.
+func (service *Service) Go(fn func(ctx context.Context)) {
+	service.workersMutex.Lock()
+	defer service.workersMutex.Unlock()
+
+	// Close waits for the workers started before it, so none may be added after
+	if service.closed {
+		return
+	}
+
+	service.workers.Add(1)
+	go func() {
+		defer service.workers.Done()
+		fn(service.cancelCtx)
+	}()
+}
+
+// Fail records err as the last error of the store and returns the failed DoCommand response for it.
+func (service *Service) Fail(err error) (map[string]any, error) {
+	service.RecordError(err)
+	return lib.ErrorResponse(err), err
+}
+
+// Visibility answers the hide and show commands of a model. set shows or hides the selected transforms and returns
+// how many changed; models pass their SetVisible, so one that selects by its own names keeps doing so.
+//
+// Parameters:
+//   - cmd: DoCommand payload
+//   - set: Shows or hides a selection, such as Store.SetVisible
+//
+// Returns the response, whether cmd was hide or show, and an error if it failed.
+func (service *Service) Visibility(
+	cmd map[string]any,
+	set func(selection *lib.Selection, visible bool) (int, error),
+) (map[string]any, bool, error) {
+	for _, name := range []string{"hide", "show"} {
+		args, ok := cmd[name]
+		if !ok {
+			continue
+		}
+
+		selection, err := lib.ParseSelection(args)
+		if err != nil {
+			result, err := service.Fail(err)
+			return result, true, err
+		}
+
+		count, err := set(selection, name == "show")
+		if err != nil {
+			result, err := service.Fail(err)
+			return result, true, err
+		}
+
+		return map[string]any{
+			"success": true,
+			"changed": count,
+		}, true, nil
+	}
+
+	return nil, false, nil
+}
+
+func (service *Service) ListUUIDs(ctx context.Context, extra map[string]any) ([][]byte, error) {
+	ids := service.IDs()
+	uuids := make([][]byte, 0, len(ids))
+	for _, id := range ids {
+		parsedId := uuid.MustParse(id)
+		uuids = append(uuids, parsedId[:])
+	}
+
+	return uuids, nil
+}
+
+func (service *Service) GetTransform(ctx context.Context, id []byte, extra map[string]any) (*commonPB.Transform, error) {
+	uuidString, err := uuid.FromBytes(id)
+	if err != nil {
+		service.logger.Errorw("Failed to parse UUID", "error", err.Error())
+		return nil, err
+	}
+
+	transform, ok := service.Get(uuidString.String())
+	if !ok {
+		return nil, lib.Errorf(lib.ErrNotFound, "transform not found for UUID: %x", uuidString)
+	}
+
+	return transform, nil
+}
+
+func (service *Service) StreamTransformChanges(ctx context.Context, extra map[string]any) (*worldstatestore.TransformChangeStream, error) {
+	return worldstatestore.NewTransformChangeStreamFromChannel(ctx, service.Subscribe(ctx)), nil
+}
+
+// Close stops the background work started with Go, then ends every subscription. It is safe to call more than once.
+func (service *Service) Close(context.Context) error {
+	service.closeOnce.Do(func() {
+		service.workersMutex.Lock()
+		service.closed = true
+		service.workersMutex.Unlock()
+
+		service.cancelFunc()
+		service.workers.Wait()
+		service.Store.Close()
+	})
+
+	return nil
+}
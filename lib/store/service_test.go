@@ -0,0 +1,121 @@
+package store
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	v1 "go.viam.com/api/service/worldstatestore/v1"
+	"go.viam.com/rdk/logging"
+	worldstatestore "go.viam.com/rdk/services/worldstatestore"
+	"go.viam.com/test"
+)
+
+func TestService(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	service := NewService(worldstatestore.Named("store"), logging.NewTestLogger(t))
+	defer service.Close(ctx)
+
+	test.That(t, service.Name(), test.ShouldResemble, worldstatestore.Named("store"))
+
+	stream, err := service.StreamTransformChanges(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+
+	arrow := newTransform("arrow", 0)
+	test.That(t, service.Put(arrow), test.ShouldBeNil)
+
+	change, err := stream.Next()
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, change.ChangeType, test.ShouldEqual, v1.TransformChangeType_TRANSFORM_CHANGE_TYPE_ADDED)
+
+	uuids, err := service.ListUUIDs(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, uuids, test.ShouldResemble, [][]byte{arrow.Uuid})
+
+	transform, err := service.GetTransform(ctx, arrow.Uuid, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, transform, test.ShouldPointTo, arrow)
+
+	missing := uuid.New()
+	_, err = service.GetTransform(ctx, missing[:], nil)
+	test.That(t, err, test.ShouldNotBeNil)
+
+	_, err = service.GetTransform(ctx, []byte{1, 2, 3}, nil)
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+func TestServiceStreamCanceled(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	service := NewService(worldstatestore.Named("store"), logging.NewTestLogger(t))
+	defer service.Close(ctx)
+
+	streamCtx, cancelStream := context.WithCancel(ctx)
+	stream, err := service.StreamTransformChanges(streamCtx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, service.Subscribers(), test.ShouldEqual, 1)
+
+	cancelStream()
+	_, err = stream.Next()
+	test.That(t, err, test.ShouldNotBeNil)
+
+	// the subscription is dropped once its stream is canceled
+	for service.Subscribers() != 0 {
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestServiceClose(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	service := NewService(worldstatestore.Named("store"), logging.NewTestLogger(t))
+
+	stream, err := service.StreamTransformChanges(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+
+	// a worker keeps drawing until the service closes
+	stopped := make(chan struct{})
+	arrow := newTransform("arrow", 0)
+	service.Go(func(ctx context.Context) {
+		defer close(stopped)
+		for x := 0.0; ctx.Err() == nil; x++ {
+			if err := service.Put(withPose(arrow, x)); err != nil {
+				t.Error(err)
+			}
+			time.Sleep(time.Millisecond)
+		}
+	})
+
+	_, err = stream.Next()
+	test.That(t, err, test.ShouldBeNil)
+
+	test.That(t, service.Close(ctx), test.ShouldBeNil)
+	test.That(t, service.Close(ctx), test.ShouldBeNil)
+
+	// Close waits for the worker before ending the stream
+	select {
+	case <-stopped:
+	default:
+		t.Fatal("worker still running after Close")
+	}
+	test.That(t, service.Context().Err(), test.ShouldNotBeNil)
+
+	// work started once the service is closing never runs
+	ran := false
+	service.Go(func(context.Context) { ran = true })
+	test.That(t, service.Close(ctx), test.ShouldBeNil)
+	test.That(t, ran, test.ShouldBeFalse)
+
+	for {
+		if _, err := stream.Next(); err != nil {
+			test.That(t, err, test.ShouldEqual, io.EOF)
+			break
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package store
+
+import (
+	"github.com/viam-labs/draw-tools/lib"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// Stats reports what a Store holds and how its changes were delivered.
+type Stats struct {
+	Transforms    int            `json:"transforms"`
+	ByType        map[string]int `json:"by_type"`        // Transforms by lib.TransformType
+	ByLayer       map[string]int `json:"by_layer"`       // Transforms by lib.TransformLayer
+	Bytes         int            `json:"bytes"`          // Approximate memory held, as the encoded size of the transforms
+	Subscribers   int            `json:"subscribers"`    // Current subscribers to the change stream
+	EventsEmitted uint64         `json:"events_emitted"` // Changes made, each counted once whatever the number of subscribers
+	EventsDropped uint64         `json:"events_dropped"` // Changes missed by subscribers that were too far behind
+	LastError     string         `json:"last_error"`     // Most recent error recorded with RecordError, or empty
+}
+
+// ToMap converts the stats to a map suitable for a DoCommand response or sensor readings.
+func (s Stats) ToMap() map[string]any {
+	return map[string]any{
+		"transforms":     s.Transforms,
+		"by_type":        counts(s.ByType),
+		"by_layer":       counts(s.ByLayer),
+		"bytes":          s.Bytes,
+		"subscribers":    s.Subscribers,
+		"events_emitted": int64(s.EventsEmitted),
+		"events_dropped": int64(s.EventsDropped),
+		"last_error":     s.LastError,
+	}
+}
+
+// Merge adds the counts of other to s. The last error of other replaces that of s unless it is empty.
+func (s *Stats) Merge(other Stats) {
+	s.Transforms += other.Transforms
+	s.ByType = mergeCounts(s.ByType, other.ByType)
+	s.ByLayer = mergeCounts(s.ByLayer, other.ByLayer)
+	s.Bytes += other.Bytes
+	s.Subscribers += other.Subscribers
+	s.EventsEmitted += other.EventsEmitted
+	s.EventsDropped += other.EventsDropped
+	if other.LastError != "" {
+		s.LastError = other.LastError
+	}
+}
+
+// Stats counts the transforms of the store by type and layer, and reports its subscribers, emitted and dropped
+// changes, and last error.
+func (s *Store) Stats() Stats {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	stats := Stats{
+		Transforms:    len(s.transforms),
+		ByType:        make(map[string]int),
+		ByLayer:       make(map[string]int),
+		Subscribers:   len(s.subscribers),
+		EventsEmitted: s.emitted,
+		EventsDropped: s.dropped,
+		LastError:     s.lastError,
+	}
+
+	for _, transform := range s.transforms {
+		stats.ByType[lib.TransformType(transform)]++
+		stats.ByLayer[lib.TransformLayer(transform)]++
+		stats.Bytes += proto.Size(transform)
+	}
+
+	return stats
+}
+
+// RecordError remembers err as the last error of the store, reported by Stats. A nil error is ignored.
+func (s *Store) RecordError(err error) {
+	if err == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastError = err.Error()
+}
+
+func counts(values map[string]int) map[string]any {
+	converted := make(map[string]any, len(values))
+	for key, value := range values {
+		converted[key] = value
+	}
+
+	return converted
+}
+
+func mergeCounts(total, values map[string]int) map[string]int {
+	if total == nil {
+		total = make(map[string]int, len(values))
+	}
+
+	for key, value := range values {
+		total[key] += value
+	}
+
+	return total
+}
@@ -0,0 +1,431 @@
+// Package store holds the transforms drawn by a world state store service and streams their changes to subscribers.
+package store
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/viam-labs/draw-tools/lib"
+
+	"github.com/google/uuid"
+	commonPB "go.viam.com/api/common/v1"
+	v1 "go.viam.com/api/service/worldstatestore/v1"
+	"go.viam.com/rdk/logging"
+	worldstatestore "go.viam.com/rdk/services/worldstatestore"
+)
+
+// DefaultSubscriberBuffer is the number of changes buffered for each subscriber before further changes are dropped.
+const DefaultSubscriberBuffer = 10000
+
+// Store is a thread-safe set of transforms indexed by UUID and by frame name. Every change to the set is emitted to
+// each subscriber as an ADDED, UPDATED or REMOVED change, in the order the changes were made.
+//
+// A subscriber that falls more than its buffer behind misses changes instead of blocking the store; the missed
+// changes are counted and logged.
+//
+// Every stored transform carries a "visible" field in its metadata, set by SetVisible and kept when the transform is
+// replaced.
+type Store struct {
+	logger logging.Logger
+	buffer int
+
+	mu          sync.RWMutex
+	transforms  map[string]*commonPB.Transform
+	names       map[string]map[string]struct{}
+	subscribers map[*subscriber]struct{}
+	closed      bool
+	done        chan struct{}
+
+	hiddenLayers map[string]struct{}
+	visibility   map[string]bool // Visibility set for single transforms, overriding that of their layer
+
+	emitted   uint64
+	dropped   uint64
+	lastError string
+}
+
+type subscriber struct {
+	changes chan worldstatestore.TransformChange
+	dropped uint64
+}
+
+// New creates an empty store buffering DefaultSubscriberBuffer changes for each subscriber.
+func New(logger logging.Logger) *Store {
+	return NewWithBuffer(logger, DefaultSubscriberBuffer)
+}
+
+// NewWithBuffer creates an empty store buffering the given number of changes for each subscriber.
+func NewWithBuffer(logger logging.Logger, buffer int) *Store {
+	if buffer <= 0 {
+		buffer = DefaultSubscriberBuffer
+	}
+
+	return &Store{
+		logger:       logger,
+		buffer:       buffer,
+		transforms:   make(map[string]*commonPB.Transform),
+		names:        make(map[string]map[string]struct{}),
+		subscribers:  make(map[*subscriber]struct{}),
+		done:         make(chan struct{}),
+		hiddenLayers: make(map[string]struct{}),
+		visibility:   make(map[string]bool),
+	}
+}
+
+// Get returns the transform with the given UUID.
+func (s *Store) Get(id string) (*commonPB.Transform, bool) {
+	key, err := normalize(id)
+	if err != nil {
+		return nil, false
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	transform, ok := s.transforms[key]
+	return transform, ok
+}
+
+// Named returns the transforms with the given frame name, sorted by UUID.
+func (s *Store) Named(name string) []*commonPB.Transform {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ids := make([]string, 0, len(s.names[name]))
+	for id := range s.names[name] {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	transforms := make([]*commonPB.Transform, 0, len(ids))
+	for _, id := range ids {
+		transforms = append(transforms, s.transforms[id])
+	}
+
+	return transforms
+}
+
+// IDs returns the UUIDs of every transform, sorted.
+func (s *Store) IDs() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ids := make([]string, 0, len(s.transforms))
+	for id := range s.transforms {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	return ids
+}
+
+// List returns every transform, sorted by UUID.
+func (s *Store) List() []*commonPB.Transform {
+	ids := s.IDs()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	transforms := make([]*commonPB.Transform, 0, len(ids))
+	for _, id := range ids {
+		if transform, ok := s.transforms[id]; ok {
+			transforms = append(transforms, transform)
+		}
+	}
+
+	return transforms
+}
+
+// Len returns the number of transforms.
+func (s *Store) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return len(s.transforms)
+}
+
+// Add adds transforms and emits an ADDED change for each. Nothing is added if any transform has an invalid UUID or
+// one that is already stored.
+func (s *Store) Add(transforms ...*commonPB.Transform) error {
+	keys, err := keys(transforms)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	seen := make(map[string]struct{}, len(keys))
+	for _, key := range keys {
+		if _, ok := s.transforms[key]; ok {
+			return lib.FieldErrorf("uuid", "transform with UUID %s already exists", key)
+		}
+
+		if _, ok := seen[key]; ok {
+			return lib.FieldErrorf("uuid", "transform with UUID %s is added twice", key)
+		}
+		seen[key] = struct{}{}
+	}
+
+	for i, transform := range transforms {
+		s.put(keys[i], transform)
+	}
+
+	return nil
+}
+
+// Put adds transforms, or replaces the stored transforms with the same UUIDs. A new transform is ADDED, a changed one
+// is UPDATED with the fields that changed, and one that did not change emits nothing. Nothing is stored if any
+// transform has an invalid UUID.
+func (s *Store) Put(transforms ...*commonPB.Transform) error {
+	keys, err := keys(transforms)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, transform := range transforms {
+		s.put(keys[i], transform)
+	}
+
+	return nil
+}
+
+// Remove removes the transforms with the given UUIDs and emits a REMOVED change for each. UUIDs that are not stored
+// are ignored. Returns the number of transforms removed.
+func (s *Store) Remove(ids ...string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	count := 0
+	for _, id := range ids {
+		key, err := normalize(id)
+		if err != nil {
+			continue
+		}
+
+		if s.remove(key) {
+			count++
+		}
+	}
+
+	return count
+}
+
+// Reconcile makes the store hold exactly the given transforms: transforms it does not hold yet are ADDED, changed ones
+// are UPDATED, and stored transforms missing from the given ones are REMOVED. Nothing changes if any transform has an
+// invalid UUID.
+func (s *Store) Reconcile(transforms ...*commonPB.Transform) error {
+	keys, err := keys(transforms)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	next := make(map[string]struct{}, len(keys))
+	for i, transform := range transforms {
+		next[keys[i]] = struct{}{}
+		s.put(keys[i], transform)
+	}
+
+	for key := range s.transforms {
+		if _, ok := next[key]; !ok {
+			s.remove(key)
+		}
+	}
+
+	return nil
+}
+
+// Clear removes every transform and emits a REMOVED change for each. Returns the number of transforms removed.
+func (s *Store) Clear() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	count := 0
+	for key := range s.transforms {
+		if s.remove(key) {
+			count++
+		}
+	}
+
+	return count
+}
+
+// Subscribe returns a channel receiving every change made after the call, until ctx is done or the store is closed,
+// at which point the channel is closed.
+func (s *Store) Subscribe(ctx context.Context) <-chan worldstatestore.TransformChange {
+	sub := &subscriber{changes: make(chan worldstatestore.TransformChange, s.buffer)}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		close(sub.changes)
+		return sub.changes
+	}
+
+	s.subscribers[sub] = struct{}{}
+	go func() {
+		select {
+		case <-ctx.Done():
+			s.unsubscribe(sub)
+		case <-s.done:
+		}
+	}()
+
+	return sub.changes
+}
+
+// Subscribers returns the number of current subscribers.
+func (s *Store) Subscribers() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return len(s.subscribers)
+}
+
+// Close ends every subscription. The transforms stay readable, but later changes are no longer emitted.
+func (s *Store) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return
+	}
+
+	s.closed = true
+	close(s.done)
+	for sub := range s.subscribers {
+		delete(s.subscribers, sub)
+		close(sub.changes)
+	}
+}
+
+func (s *Store) unsubscribe(sub *subscriber) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.subscribers[sub]; !ok {
+		return
+	}
+
+	delete(s.subscribers, sub)
+	close(sub.changes)
+}
+
+// put stores a transform with its visibility and emits its change. Must be called with mu held.
+func (s *Store) put(key string, transform *commonPB.Transform) {
+	transform = lib.WithVisible(transform, s.visible(key, transform))
+	previous, ok := s.transforms[key]
+	s.transforms[key] = transform
+
+	if !ok {
+		s.index(key, transform.ReferenceFrame)
+		s.emit(worldstatestore.TransformChange{
+			ChangeType: v1.TransformChangeType_TRANSFORM_CHANGE_TYPE_ADDED,
+			Transform:  transform,
+		})
+		return
+	}
+
+	if previous.ReferenceFrame != transform.ReferenceFrame {
+		s.unindex(key, previous.ReferenceFrame)
+		s.index(key, transform.ReferenceFrame)
+	}
+
+	if fields := lib.UpdatedFields(previous, transform); len(fields) > 0 {
+		s.emit(worldstatestore.TransformChange{
+			ChangeType:    v1.TransformChangeType_TRANSFORM_CHANGE_TYPE_UPDATED,
+			Transform:     transform,
+			UpdatedFields: fields,
+		})
+	}
+}
+
+// remove deletes a transform and emits its removal. Must be called with mu held.
+func (s *Store) remove(key string) bool {
+	transform, ok := s.transforms[key]
+	if !ok {
+		return false
+	}
+
+	delete(s.transforms, key)
+	delete(s.visibility, key)
+	s.unindex(key, transform.ReferenceFrame)
+
+	id := uuid.MustParse(key)
+	s.emit(worldstatestore.TransformChange{
+		ChangeType: v1.TransformChangeType_TRANSFORM_CHANGE_TYPE_REMOVED,
+		Transform: &commonPB.Transform{
+			Uuid: id[:],
+		},
+	})
+
+	return true
+}
+
+func (s *Store) index(key, name string) {
+	if s.names[name] == nil {
+		s.names[name] = make(map[string]struct{})
+	}
+	s.names[name][key] = struct{}{}
+}
+
+func (s *Store) unindex(key, name string) {
+	delete(s.names[name], key)
+	if len(s.names[name]) == 0 {
+		delete(s.names, name)
+	}
+}
+
+// emit sends a change to every subscriber without blocking. Must be called with mu held.
+func (s *Store) emit(change worldstatestore.TransformChange) {
+	if s.closed {
+		return
+	}
+
+	s.emitted++
+	for sub := range s.subscribers {
+		select {
+		case sub.changes <- change:
+		default:
+			s.dropped++
+			sub.dropped++
+			// log the first drop and then every thousandth, a stalled subscriber would otherwise flood the log
+			if sub.dropped%1000 == 1 {
+				s.logger.Warnw("Subscriber is too far behind, dropping changes", "dropped", sub.dropped)
+			}
+		}
+	}
+}
+
+// keys returns the normalized UUID strings of transforms.
+func keys(transforms []*commonPB.Transform) ([]string, error) {
+	keys := make([]string, 0, len(transforms))
+	for _, transform := range transforms {
+		id, err := uuid.FromBytes(transform.Uuid)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to parse UUID of transform %q: %w", transform.ReferenceFrame, err)
+		}
+
+		keys = append(keys, id.String())
+	}
+
+	return keys, nil
+}
+
+func normalize(id string) (string, error) {
+	parsed, err := uuid.Parse(id)
+	if err != nil {
+		return "", err
+	}
+
+	return parsed.String(), nil
+}
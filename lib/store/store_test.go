@@ -0,0 +1,437 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/viam-labs/draw-tools/lib"
+
+	"github.com/google/uuid"
+	commonPB "go.viam.com/api/common/v1"
+	v1 "go.viam.com/api/service/worldstatestore/v1"
+	"go.viam.com/rdk/logging"
+	worldstatestore "go.viam.com/rdk/services/worldstatestore"
+	"go.viam.com/test"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func newTransform(name string, x float64) *commonPB.Transform {
+	id := uuid.New()
+	return withPose(&commonPB.Transform{ReferenceFrame: name, Uuid: id[:]}, x)
+}
+
+// withPose returns a copy of transform placed at x in the world frame. It is already marked visible, so the store
+// keeps it as is.
+func withPose(transform *commonPB.Transform, x float64) *commonPB.Transform {
+	return &commonPB.Transform{
+		ReferenceFrame: transform.ReferenceFrame,
+		Uuid:           transform.Uuid,
+		PoseInObserverFrame: &commonPB.PoseInFrame{
+			ReferenceFrame: "world",
+			Pose:           &commonPB.Pose{X: x, OZ: 1},
+		},
+		Metadata: &structpb.Struct{Fields: map[string]*structpb.Value{"visible": structpb.NewBoolValue(true)}},
+	}
+}
+
+func idOf(transform *commonPB.Transform) string {
+	return uuid.UUID(transform.Uuid).String()
+}
+
+// next reads a change from a subscription, failing the test if none arrives.
+func next(t *testing.T, changes <-chan worldstatestore.TransformChange) worldstatestore.TransformChange {
+	t.Helper()
+	select {
+	case change, ok := <-changes:
+		test.That(t, ok, test.ShouldBeTrue)
+		return change
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for a change")
+		return worldstatestore.TransformChange{}
+	}
+}
+
+// expectNone checks that no change is waiting on a subscription.
+func expectNone(t *testing.T, changes <-chan worldstatestore.TransformChange) {
+	t.Helper()
+	select {
+	case change := <-changes:
+		t.Fatalf("unexpected %v change", change.ChangeType)
+	default:
+	}
+}
+
+func TestPut(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s := New(logging.NewTestLogger(t))
+	changes := s.Subscribe(ctx)
+
+	arrow := newTransform("arrow", 0)
+	test.That(t, s.Put(arrow), test.ShouldBeNil)
+
+	change := next(t, changes)
+	test.That(t, change.ChangeType, test.ShouldEqual, v1.TransformChangeType_TRANSFORM_CHANGE_TYPE_ADDED)
+	test.That(t, change.Transform, test.ShouldPointTo, arrow)
+
+	// putting an identical transform emits nothing
+	test.That(t, s.Put(withPose(arrow, 0)), test.ShouldBeNil)
+	expectNone(t, changes)
+
+	moved := withPose(arrow, 100)
+	test.That(t, s.Put(moved), test.ShouldBeNil)
+
+	change = next(t, changes)
+	test.That(t, change.ChangeType, test.ShouldEqual, v1.TransformChangeType_TRANSFORM_CHANGE_TYPE_UPDATED)
+	test.That(t, change.UpdatedFields, test.ShouldResemble, []string{"poseInObserverFrame"})
+
+	stored, ok := s.Get(idOf(arrow))
+	test.That(t, ok, test.ShouldBeTrue)
+	test.That(t, stored, test.ShouldPointTo, moved)
+	test.That(t, s.Len(), test.ShouldEqual, 1)
+
+	// nothing is stored when any transform of a batch is invalid
+	err := s.Put(newTransform("other", 0), &commonPB.Transform{ReferenceFrame: "broken", Uuid: []byte{1, 2}})
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "broken")
+	test.That(t, s.Len(), test.ShouldEqual, 1)
+	expectNone(t, changes)
+}
+
+func TestAdd(t *testing.T) {
+	s := New(logging.NewTestLogger(t))
+
+	first := newTransform("first", 0)
+	test.That(t, s.Add(first), test.ShouldBeNil)
+
+	err := s.Add(newTransform("second", 0), withPose(first, 10))
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "already exists")
+	test.That(t, s.Len(), test.ShouldEqual, 1)
+
+	second := newTransform("second", 0)
+	err = s.Add(second, withPose(second, 10))
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "added twice")
+	test.That(t, s.Len(), test.ShouldEqual, 1)
+}
+
+func TestRemoveAndClear(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s := New(logging.NewTestLogger(t))
+	first, second, third := newTransform("first", 0), newTransform("second", 0), newTransform("third", 0)
+	test.That(t, s.Put(first, second, third), test.ShouldBeNil)
+
+	changes := s.Subscribe(ctx)
+
+	// unknown and invalid UUIDs are ignored
+	test.That(t, s.Remove(idOf(first), uuid.NewString(), "not-a-uuid"), test.ShouldEqual, 1)
+
+	change := next(t, changes)
+	test.That(t, change.ChangeType, test.ShouldEqual, v1.TransformChangeType_TRANSFORM_CHANGE_TYPE_REMOVED)
+	test.That(t, change.Transform.Uuid, test.ShouldResemble, first.Uuid)
+
+	_, ok := s.Get(idOf(first))
+	test.That(t, ok, test.ShouldBeFalse)
+	test.That(t, s.Named("first"), test.ShouldBeEmpty)
+
+	test.That(t, s.Clear(), test.ShouldEqual, 2)
+	removed := map[string]bool{}
+	for range 2 {
+		change := next(t, changes)
+		test.That(t, change.ChangeType, test.ShouldEqual, v1.TransformChangeType_TRANSFORM_CHANGE_TYPE_REMOVED)
+		removed[idOf(change.Transform)] = true
+	}
+	test.That(t, removed, test.ShouldResemble, map[string]bool{idOf(second): true, idOf(third): true})
+	test.That(t, s.Len(), test.ShouldEqual, 0)
+	test.That(t, s.Clear(), test.ShouldEqual, 0)
+}
+
+func TestReconcile(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s := New(logging.NewTestLogger(t))
+	kept, moved, dropped := newTransform("kept", 0), newTransform("moved", 0), newTransform("dropped", 0)
+	test.That(t, s.Put(kept, moved, dropped), test.ShouldBeNil)
+
+	changes := s.Subscribe(ctx)
+
+	added := newTransform("added", 0)
+	test.That(t, s.Reconcile(withPose(kept, 0), withPose(moved, 50), added), test.ShouldBeNil)
+
+	byType := map[v1.TransformChangeType][]string{}
+	for range 3 {
+		change := next(t, changes)
+		byType[change.ChangeType] = append(byType[change.ChangeType], idOf(change.Transform))
+	}
+	expectNone(t, changes)
+
+	test.That(t, byType[v1.TransformChangeType_TRANSFORM_CHANGE_TYPE_ADDED], test.ShouldResemble, []string{idOf(added)})
+	test.That(t, byType[v1.TransformChangeType_TRANSFORM_CHANGE_TYPE_UPDATED], test.ShouldResemble, []string{idOf(moved)})
+	test.That(t, byType[v1.TransformChangeType_TRANSFORM_CHANGE_TYPE_REMOVED], test.ShouldResemble, []string{idOf(dropped)})
+	test.That(t, s.Len(), test.ShouldEqual, 3)
+}
+
+func TestIndexes(t *testing.T) {
+	s := New(logging.NewTestLogger(t))
+	first, second, other := newTransform("arrow", 0), newTransform("arrow", 10), newTransform("other", 0)
+	test.That(t, s.Put(first, second, other), test.ShouldBeNil)
+
+	named := s.Named("arrow")
+	test.That(t, named, test.ShouldHaveLength, 2)
+	test.That(t, s.Named("missing"), test.ShouldBeEmpty)
+
+	ids := s.IDs()
+	test.That(t, ids, test.ShouldHaveLength, 3)
+	test.That(t, ids[0] < ids[1] && ids[1] < ids[2], test.ShouldBeTrue)
+	test.That(t, s.List(), test.ShouldHaveLength, 3)
+
+	// renaming a transform moves it in the name index
+	renamed := withPose(second, 10)
+	renamed.ReferenceFrame = "renamed"
+	test.That(t, s.Put(renamed), test.ShouldBeNil)
+	test.That(t, s.Named("arrow"), test.ShouldResemble, []*commonPB.Transform{first})
+	test.That(t, s.Named("renamed"), test.ShouldResemble, []*commonPB.Transform{renamed})
+
+	// lookups accept any UUID format uuid.Parse does
+	_, ok := s.Get(idOf(first))
+	test.That(t, ok, test.ShouldBeTrue)
+	_, ok = s.Get(uuid.UUID(first.Uuid).URN())
+	test.That(t, ok, test.ShouldBeTrue)
+	_, ok = s.Get("not-a-uuid")
+	test.That(t, ok, test.ShouldBeFalse)
+}
+
+func TestSubscribers(t *testing.T) {
+	s := New(logging.NewTestLogger(t))
+
+	firstCtx, cancelFirst := context.WithCancel(context.Background())
+	secondCtx, cancelSecond := context.WithCancel(context.Background())
+	defer cancelSecond()
+
+	first := s.Subscribe(firstCtx)
+	second := s.Subscribe(secondCtx)
+	test.That(t, s.Subscribers(), test.ShouldEqual, 2)
+
+	// every subscriber receives every change
+	arrow := newTransform("arrow", 0)
+	test.That(t, s.Put(arrow), test.ShouldBeNil)
+	test.That(t, next(t, first).Transform, test.ShouldPointTo, arrow)
+	test.That(t, next(t, second).Transform, test.ShouldPointTo, arrow)
+
+	// a canceled subscription is closed and no longer receives changes
+	cancelFirst()
+	_, ok := <-first
+	test.That(t, ok, test.ShouldBeFalse)
+
+	for s.Subscribers() != 1 {
+		time.Sleep(time.Millisecond)
+	}
+
+	test.That(t, s.Put(newTransform("other", 0)), test.ShouldBeNil)
+	test.That(t, next(t, second).Transform.ReferenceFrame, test.ShouldEqual, "other")
+}
+
+func TestSlowSubscriber(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s := NewWithBuffer(logging.NewTestLogger(t), 2)
+	slow := s.Subscribe(ctx)
+
+	// changes past the buffer are dropped for the slow subscriber instead of blocking the store
+	transforms := []*commonPB.Transform{newTransform("a", 0), newTransform("b", 0), newTransform("c", 0)}
+	test.That(t, s.Put(transforms...), test.ShouldBeNil)
+	test.That(t, s.Len(), test.ShouldEqual, 3)
+
+	test.That(t, next(t, slow).Transform.ReferenceFrame, test.ShouldEqual, "a")
+	test.That(t, next(t, slow).Transform.ReferenceFrame, test.ShouldEqual, "b")
+	expectNone(t, slow)
+
+	// the subscriber receives changes again once it catches up
+	test.That(t, s.Remove(idOf(transforms[0])), test.ShouldEqual, 1)
+	test.That(t, next(t, slow).ChangeType, test.ShouldEqual, v1.TransformChangeType_TRANSFORM_CHANGE_TYPE_REMOVED)
+}
+
+func TestStats(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s := NewWithBuffer(logging.NewTestLogger(t), 2)
+	stats := s.Stats()
+	test.That(t, stats.Transforms, test.ShouldEqual, 0)
+	test.That(t, stats.Bytes, test.ShouldEqual, 0)
+	test.That(t, stats.LastError, test.ShouldBeEmpty)
+
+	slow := s.Subscribe(ctx)
+	box := newTransform("box", 0)
+	box.PhysicalObject = &commonPB.Geometry{GeometryType: &commonPB.Geometry_Box{Box: &commonPB.RectangularPrism{}}}
+	box.Metadata = &structpb.Struct{Fields: map[string]*structpb.Value{"layer": structpb.NewStringValue("fixtures")}}
+	transforms := []*commonPB.Transform{box, newTransform("a", 0), newTransform("b", 0)}
+	test.That(t, s.Put(transforms...), test.ShouldBeNil)
+
+	// the third change overflows the buffer of the subscriber
+	stats = s.Stats()
+	test.That(t, stats.Transforms, test.ShouldEqual, 3)
+	test.That(t, stats.ByType, test.ShouldResemble, map[string]int{"box": 1, "frame": 2})
+	test.That(t, stats.ByLayer, test.ShouldResemble, map[string]int{"fixtures": 1, "default": 2})
+	test.That(t, stats.Bytes, test.ShouldBeGreaterThan, 0)
+	test.That(t, stats.Subscribers, test.ShouldEqual, 1)
+	test.That(t, stats.EventsEmitted, test.ShouldEqual, uint64(3))
+	test.That(t, stats.EventsDropped, test.ShouldEqual, uint64(1))
+	next(t, slow)
+
+	s.RecordError(nil)
+	test.That(t, s.Stats().LastError, test.ShouldBeEmpty)
+	s.RecordError(errors.New("camera unavailable"))
+	test.That(t, s.Stats().LastError, test.ShouldEqual, "camera unavailable")
+
+	fields := s.Stats().ToMap()
+	test.That(t, fields["by_type"], test.ShouldResemble, map[string]any{"box": 1, "frame": 2})
+	test.That(t, fields["events_emitted"], test.ShouldEqual, int64(3))
+	test.That(t, fields["last_error"], test.ShouldEqual, "camera unavailable")
+}
+
+func TestMergeStats(t *testing.T) {
+	total := Stats{}
+	total.Merge(Stats{Transforms: 2, ByType: map[string]int{"mesh": 2}, Bytes: 10, EventsEmitted: 4, LastError: "first"})
+	total.Merge(Stats{Transforms: 1, ByType: map[string]int{"mesh": 1}, ByLayer: map[string]int{"default": 1}, Subscribers: 1})
+
+	test.That(t, total.Transforms, test.ShouldEqual, 3)
+	test.That(t, total.ByType, test.ShouldResemble, map[string]int{"mesh": 3})
+	test.That(t, total.ByLayer, test.ShouldResemble, map[string]int{"default": 1})
+	test.That(t, total.Bytes, test.ShouldEqual, 10)
+	test.That(t, total.Subscribers, test.ShouldEqual, 1)
+	test.That(t, total.EventsEmitted, test.ShouldEqual, uint64(4))
+	test.That(t, total.LastError, test.ShouldEqual, "first")
+}
+
+func TestVisibility(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s := New(logging.NewTestLogger(t))
+	changes := s.Subscribe(ctx)
+
+	// a transform without the flag is stored visible, leaving the given one unchanged
+	tool := &commonPB.Transform{ReferenceFrame: "tool", Uuid: newTransform("tool", 0).Uuid}
+	part := newTransform("part", 0)
+	part.Metadata.Fields["layer"] = structpb.NewStringValue("fixtures")
+	test.That(t, s.Put(tool, part), test.ShouldBeNil)
+	test.That(t, tool.Metadata, test.ShouldBeNil)
+	next(t, changes)
+	next(t, changes)
+
+	stored, _ := s.Get(idOf(tool))
+	test.That(t, stored.Metadata.Fields["visible"].GetBoolValue(), test.ShouldBeTrue)
+
+	count, err := s.SetVisible(&lib.Selection{Names: []string{"tool"}}, false)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, count, test.ShouldEqual, 1)
+
+	change := next(t, changes)
+	test.That(t, change.ChangeType, test.ShouldEqual, v1.TransformChangeType_TRANSFORM_CHANGE_TYPE_UPDATED)
+	test.That(t, change.UpdatedFields, test.ShouldResemble, []string{"metadata"})
+	test.That(t, lib.TransformVisible(change.Transform), test.ShouldBeFalse)
+
+	// hiding it again changes nothing, and replacing it keeps it hidden
+	count, err = s.SetVisible(&lib.Selection{UUIDs: []string{idOf(tool)}}, false)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, count, test.ShouldEqual, 0)
+	test.That(t, s.Put(withPose(tool, 100)), test.ShouldBeNil)
+	change = next(t, changes)
+	test.That(t, change.UpdatedFields, test.ShouldResemble, []string{"poseInObserverFrame"})
+	test.That(t, lib.TransformVisible(change.Transform), test.ShouldBeFalse)
+
+	// a hidden layer hides the transforms added to it later
+	count, err = s.SetVisible(&lib.Selection{Layers: []string{"fixtures"}}, false)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, count, test.ShouldEqual, 1)
+	next(t, changes)
+
+	bolt := newTransform("bolt", 0)
+	bolt.Metadata.Fields["layer"] = structpb.NewStringValue("fixtures")
+	test.That(t, s.Put(bolt), test.ShouldBeNil)
+	test.That(t, lib.TransformVisible(next(t, changes).Transform), test.ShouldBeFalse)
+
+	// showing all of them overrides the layer for the transforms stored now
+	count, err = s.SetVisible(&lib.Selection{All: true}, true)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, count, test.ShouldEqual, 3)
+	for range 3 {
+		test.That(t, lib.TransformVisible(next(t, changes).Transform), test.ShouldBeTrue)
+	}
+
+	// nothing changes when a name or UUID matches no transform
+	_, err = s.SetVisible(&lib.Selection{Names: []string{"tool", "missing"}}, false)
+	test.That(t, errors.Is(err, lib.ErrNotFound), test.ShouldBeTrue)
+	_, err = s.SetVisible(&lib.Selection{UUIDs: []string{uuid.NewString()}}, false)
+	test.That(t, errors.Is(err, lib.ErrNotFound), test.ShouldBeTrue)
+	expectNone(t, changes)
+}
+
+func TestClose(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s := New(logging.NewTestLogger(t))
+	changes := s.Subscribe(ctx)
+
+	s.Close()
+	s.Close()
+
+	_, ok := <-changes
+	test.That(t, ok, test.ShouldBeFalse)
+	test.That(t, s.Subscribers(), test.ShouldEqual, 0)
+
+	// the store stays usable, but nothing is emitted and new subscriptions end immediately
+	test.That(t, s.Put(newTransform("arrow", 0)), test.ShouldBeNil)
+	test.That(t, s.Len(), test.ShouldEqual, 1)
+
+	_, ok = <-s.Subscribe(ctx)
+	test.That(t, ok, test.ShouldBeFalse)
+}
+
+func TestConcurrentUse(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s := New(logging.NewTestLogger(t))
+	changes := s.Subscribe(ctx)
+
+	const writers, puts = 8, 100
+	var wg sync.WaitGroup
+	for range writers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			arrow := newTransform("arrow", 0)
+			for i := range puts {
+				if err := s.Put(withPose(arrow, float64(i))); err != nil {
+					t.Error(err)
+				}
+				s.Named("arrow")
+				s.List()
+			}
+			s.Remove(idOf(arrow))
+		}()
+	}
+	wg.Wait()
+
+	// each writer added its transform, moved it puts-1 times and removed it
+	counts := map[v1.TransformChangeType]int{}
+	for range writers * (puts + 1) {
+		counts[next(t, changes).ChangeType]++
+	}
+	test.That(t, counts[v1.TransformChangeType_TRANSFORM_CHANGE_TYPE_ADDED], test.ShouldEqual, writers)
+	test.That(t, counts[v1.TransformChangeType_TRANSFORM_CHANGE_TYPE_UPDATED], test.ShouldEqual, writers*(puts-1))
+	test.That(t, counts[v1.TransformChangeType_TRANSFORM_CHANGE_TYPE_REMOVED], test.ShouldEqual, writers)
+	test.That(t, s.Len(), test.ShouldEqual, 0)
+}
@@ -0,0 +1,105 @@
+package store
+
+import (
+	"sort"
+
+	"github.com/viam-labs/draw-tools/lib"
+
+	commonPB "go.viam.com/api/common/v1"
+)
+
+// SetVisible shows or hides the selected transforms, emitting an UPDATED change with the "metadata" field for each
+// transform whose visibility changed.
+//
+// Selecting layers also shows or hides the transforms later added to them, and makes the transforms already in them
+// follow the layer again. Selecting UUIDs, names or all sets the visibility of the transforms stored now, whatever
+// their layer. Nothing changes if a UUID or name matches no transform.
+//
+// Parameters:
+//   - selection: Transforms to show or hide
+//   - visible: Whether to show the transforms
+//
+// Returns the number of transforms whose visibility changed, or a not_found error.
+func (s *Store) SetVisible(selection *lib.Selection, visible bool) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	keys := make(map[string]struct{})
+	for _, id := range selection.UUIDs {
+		key, err := normalize(id)
+		if err != nil {
+			return 0, lib.FieldErrorf("uuids", "Failed to parse UUID %q: %w", id, err)
+		}
+
+		if _, ok := s.transforms[key]; !ok {
+			return 0, lib.Errorf(lib.ErrNotFound, "transform not found for UUID: %s", key)
+		}
+		keys[key] = struct{}{}
+	}
+
+	for _, name := range selection.Names {
+		if len(s.names[name]) == 0 {
+			return 0, lib.Errorf(lib.ErrNotFound, "transform not found for name: %s", name)
+		}
+
+		for key := range s.names[name] {
+			keys[key] = struct{}{}
+		}
+	}
+
+	if selection.All {
+		for key := range s.transforms {
+			keys[key] = struct{}{}
+		}
+	}
+
+	changed := map[string]struct{}{}
+	for _, layer := range selection.Layers {
+		if visible {
+			delete(s.hiddenLayers, layer)
+		} else {
+			s.hiddenLayers[layer] = struct{}{}
+		}
+
+		for key, transform := range s.transforms {
+			if lib.TransformLayer(transform) == layer {
+				delete(s.visibility, key)
+				changed[key] = struct{}{}
+			}
+		}
+	}
+
+	for key := range keys {
+		s.visibility[key] = visible
+		changed[key] = struct{}{}
+	}
+
+	// emit the changes in UUID order, as the selection has none of its own
+	ordered := make([]string, 0, len(changed))
+	for key := range changed {
+		ordered = append(ordered, key)
+	}
+	sort.Strings(ordered)
+
+	count := 0
+	for _, key := range ordered {
+		transform := s.transforms[key]
+		if lib.TransformVisible(transform) != s.visible(key, transform) {
+			s.put(key, transform)
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+// visible returns whether a transform is shown: the visibility set for it, or else that of its layer. Must be called
+// with mu held.
+func (s *Store) visible(key string, transform *commonPB.Transform) bool {
+	if visible, ok := s.visibility[key]; ok {
+		return visible
+	}
+
+	_, hidden := s.hiddenLayers[lib.TransformLayer(transform)]
+	return !hidden
+}
@@ -0,0 +1,104 @@
+package lib
+
+import (
+	commonPB "go.viam.com/api/common/v1"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// UpdatedFields compares two versions of a transform and returns the names of the fields that changed, as listed in
+// the UpdatedFields of an UPDATED change. It returns no fields when the transforms are the same.
+func UpdatedFields(previous, next *commonPB.Transform) []string {
+	fields := []string{}
+	if previous.ReferenceFrame != next.ReferenceFrame {
+		fields = append(fields, "referenceFrame")
+	}
+
+	if !proto.Equal(previous.PoseInObserverFrame, next.PoseInObserverFrame) {
+		fields = append(fields, "poseInObserverFrame")
+	}
+
+	if !proto.Equal(previous.PhysicalObject, next.PhysicalObject) {
+		fields = append(fields, "physicalObject")
+	}
+
+	if !proto.Equal(previous.Metadata, next.Metadata) {
+		fields = append(fields, "metadata")
+	}
+
+	return fields
+}
+
+// DefaultLayer is the layer of transforms whose metadata has no "layer" field.
+const DefaultLayer = "default"
+
+// TransformType returns the kind of item a transform draws: the "type" field of its metadata, set by the shapes
+// services, or else its "shape" field, or else the type of its geometry. A transform with none of them is a "frame".
+func TransformType(transform *commonPB.Transform) string {
+	for _, key := range []string{"type", "shape"} {
+		if value := metadataString(transform, key); value != "" {
+			return value
+		}
+	}
+
+	switch transform.GetPhysicalObject().GetGeometryType().(type) {
+	case *commonPB.Geometry_Box:
+		return PrimitiveBox
+	case *commonPB.Geometry_Sphere:
+		return PrimitiveSphere
+	case *commonPB.Geometry_Capsule:
+		return PrimitiveCapsule
+	case *commonPB.Geometry_Mesh:
+		return ShapeMesh
+	case *commonPB.Geometry_Pointcloud:
+		return ShapePointCloud
+	default:
+		return "frame"
+	}
+}
+
+// TransformLayer returns the "layer" field of a transform's metadata, or DefaultLayer if it has none.
+func TransformLayer(transform *commonPB.Transform) string {
+	if layer := metadataString(transform, "layer"); layer != "" {
+		return layer
+	}
+
+	return DefaultLayer
+}
+
+// TransformVisible returns the "visible" field of a transform's metadata, or true if it has none.
+func TransformVisible(transform *commonPB.Transform) bool {
+	value, ok := transform.GetMetadata().GetFields()["visible"]
+	if !ok {
+		return true
+	}
+
+	return value.GetBoolValue()
+}
+
+// WithVisible returns a transform with the "visible" field of its metadata set. The transform is returned as is if the
+// field already has that value, otherwise a shallow copy with copied metadata is returned so the original is unchanged.
+func WithVisible(transform *commonPB.Transform, visible bool) *commonPB.Transform {
+	current, ok := transform.GetMetadata().GetFields()["visible"].GetKind().(*structpb.Value_BoolValue)
+	if ok && current.BoolValue == visible {
+		return transform
+	}
+
+	fields := make(map[string]*structpb.Value, len(transform.GetMetadata().GetFields())+1)
+	for key, value := range transform.GetMetadata().GetFields() {
+		fields[key] = value
+	}
+	fields["visible"] = structpb.NewBoolValue(visible)
+
+	return &commonPB.Transform{
+		ReferenceFrame:      transform.ReferenceFrame,
+		PoseInObserverFrame: transform.PoseInObserverFrame,
+		PhysicalObject:      transform.PhysicalObject,
+		Uuid:                transform.Uuid,
+		Metadata:            &structpb.Struct{Fields: fields},
+	}
+}
+
+func metadataString(transform *commonPB.Transform, key string) string {
+	return transform.GetMetadata().GetFields()[key].GetStringValue()
+}
@@ -0,0 +1,60 @@
+package lib
+
+import (
+	"testing"
+
+	commonPB "go.viam.com/api/common/v1"
+	"go.viam.com/test"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func TestUpdatedFields(t *testing.T) {
+	arrow, err := CreateArrow(&commonPB.Pose{OZ: 1}, "arrow", testUUIDBytes, nil, "")
+	test.That(t, err, test.ShouldBeNil)
+
+	same, err := CreateArrow(&commonPB.Pose{OZ: 1}, "arrow", testUUIDBytes, nil, "")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, UpdatedFields(arrow, same), test.ShouldBeEmpty)
+
+	moved, err := CreateArrow(&commonPB.Pose{X: 10, OZ: 1}, "arrow", testUUIDBytes, nil, "")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, UpdatedFields(arrow, moved), test.ShouldResemble, []string{"poseInObserverFrame"})
+
+	recolored, err := CreateArrow(&commonPB.Pose{OZ: 1}, "renamed", testUUIDBytes, &Color{R: 1}, "table")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, UpdatedFields(arrow, recolored), test.ShouldResemble, []string{"referenceFrame", "poseInObserverFrame", "metadata"})
+}
+
+func TestTransformTypeAndLayer(t *testing.T) {
+	arrow, err := CreateArrow(&commonPB.Pose{OZ: 1}, "arrow", testUUIDBytes, nil, "")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, TransformType(arrow), test.ShouldEqual, ShapeArrow)
+	test.That(t, TransformLayer(arrow), test.ShouldEqual, DefaultLayer)
+
+	arrow.Metadata.Fields["type"] = structpb.NewStringValue(ShapeAxes)
+	arrow.Metadata.Fields["layer"] = structpb.NewStringValue("targets")
+	test.That(t, TransformType(arrow), test.ShouldEqual, ShapeAxes)
+	test.That(t, TransformLayer(arrow), test.ShouldEqual, "targets")
+
+	cloud := &commonPB.Transform{PhysicalObject: &commonPB.Geometry{GeometryType: &commonPB.Geometry_Pointcloud{}}}
+	test.That(t, TransformType(cloud), test.ShouldEqual, ShapePointCloud)
+	test.That(t, TransformType(&commonPB.Transform{}), test.ShouldEqual, "frame")
+}
+
+func TestWithVisible(t *testing.T) {
+	arrow, err := CreateArrow(&commonPB.Pose{OZ: 1}, "arrow", testUUIDBytes, nil, "")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, TransformVisible(arrow), test.ShouldBeTrue)
+
+	// hiding copies the transform, keeping the other metadata
+	hidden := WithVisible(arrow, false)
+	test.That(t, TransformVisible(hidden), test.ShouldBeFalse)
+	test.That(t, TransformVisible(arrow), test.ShouldBeTrue)
+	test.That(t, hidden.Metadata.Fields["shape"].GetStringValue(), test.ShouldEqual, ShapeArrow)
+	test.That(t, UpdatedFields(arrow, hidden), test.ShouldResemble, []string{"metadata"})
+
+	// a transform that already has the value is returned as is
+	test.That(t, WithVisible(hidden, false), test.ShouldPointTo, hidden)
+	test.That(t, TransformVisible(WithVisible(hidden, true)), test.ShouldBeTrue)
+	test.That(t, TransformVisible(WithVisible(&commonPB.Transform{}, true)), test.ShouldBeTrue)
+}
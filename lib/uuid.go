@@ -74,3 +74,17 @@ func UUIDFromString(id string) (*UUID, error) {
 		data: parsed,
 	}, nil
 }
+
+// DeriveUUID creates a UUID derived from a base UUID and a name.
+// The same base and name always produce the same UUID, so related transforms keep stable ids across redraws.
+//
+// Parameters:
+//   - base: UUID to derive from
+//   - name: Name distinguishing the derived UUID
+//
+// Returns the derived UUID.
+func DeriveUUID(base UUID, name string) UUID {
+	return UUID{
+		data: uuid.NewSHA1(base.data, []byte(name)),
+	}
+}
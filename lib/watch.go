@@ -0,0 +1,67 @@
+package lib
+
+import (
+	"context"
+	"os"
+	"time"
+)
+
+const (
+	// DefaultWatchInterval is the default period between checks of a watched file.
+	DefaultWatchInterval = time.Second
+	// DefaultWatchDebounce is the default time a watched file must stay unchanged before a change is reported.
+	DefaultWatchDebounce = 500 * time.Millisecond
+)
+
+// WatchFile polls a file for changes to its modification time or size.
+// A change is reported once the file has stayed unchanged for the debounce period, so a file
+// that is still being written triggers a single callback. The file may be missing while it is
+// watched; it counts as changed when it reappears. WatchFile blocks until ctx is done.
+//
+// Parameters:
+//   - ctx: Context that stops the watcher when done
+//   - path: Path of the file to watch
+//   - interval: Period between checks (defaults to DefaultWatchInterval if not positive)
+//   - debounce: Quiet period before reporting a change (defaults to DefaultWatchDebounce if negative)
+//   - onChange: Callback run on the watcher's goroutine for every settled change
+func WatchFile(ctx context.Context, path string, interval, debounce time.Duration, onChange func()) {
+	if interval <= 0 {
+		interval = DefaultWatchInterval
+	}
+
+	if debounce < 0 {
+		debounce = DefaultWatchDebounce
+	}
+
+	last, _ := os.Stat(path)
+	var changedAt time.Time
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		info, err := os.Stat(path)
+		if err != nil {
+			// missing or mid-rewrite, report it once it is back
+			last = nil
+			continue
+		}
+
+		if last == nil || !info.ModTime().Equal(last.ModTime()) || info.Size() != last.Size() {
+			last = info
+			changedAt = time.Now()
+			continue
+		}
+
+		if !changedAt.IsZero() && time.Since(changedAt) >= debounce {
+			changedAt = time.Time{}
+			onChange()
+		}
+	}
+}
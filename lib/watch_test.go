@@ -0,0 +1,55 @@
+package lib
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"go.viam.com/test"
+)
+
+func TestWatchFile(t *testing.T) {
+	path := writeTestFile(t, t.TempDir(), "mesh.ply", testPLY)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	changes := make(chan struct{}, 10)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		WatchFile(ctx, path, 5*time.Millisecond, 20*time.Millisecond, func() {
+			changes <- struct{}{}
+		})
+	}()
+
+	// unchanged files are not reported
+	select {
+	case <-changes:
+		t.Fatal("unexpected change for an unmodified file")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	test.That(t, os.WriteFile(path, []byte(otherTestPLY), 0o644), test.ShouldBeNil)
+	later := time.Now().Add(time.Minute)
+	test.That(t, os.Chtimes(path, later, later), test.ShouldBeNil)
+
+	select {
+	case <-changes:
+	case <-time.After(2 * time.Second):
+		t.Fatal("change was not reported")
+	}
+
+	// a settled change is only reported once
+	select {
+	case <-changes:
+		t.Fatal("change was reported twice")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("watcher did not stop")
+	}
+}
@@ -0,0 +1,475 @@
+package posetracker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/viam-labs/draw-tools/lib"
+	"github.com/viam-labs/draw-tools/lib/command"
+	"github.com/viam-labs/draw-tools/lib/store"
+
+	commonPB "go.viam.com/api/common/v1"
+	"go.viam.com/rdk/components/arm"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/robot/framesystem"
+	worldstatestore "go.viam.com/rdk/services/worldstatestore"
+	"go.viam.com/rdk/spatialmath"
+)
+
+var (
+	WorldState = resource.NewModel("viam-viz", "draw-tools", "pose-tracker")
+)
+
+const (
+	// DefaultRateHz is the default number of times per second each component's pose is requested.
+	DefaultRateHz = 5.0
+	// DefaultReferenceFrame is the default frame component poses are reported in.
+	DefaultReferenceFrame = "world"
+	// DefaultTrailRadiusMm is the default radius of the breadcrumbs left along a component's path.
+	DefaultTrailRadiusMm = 5.0
+	// DefaultTrailOpacity is the default opacity of the breadcrumbs left along a component's path.
+	DefaultTrailOpacity = 0.5
+
+	// DisplayArrow draws each component as a single arrow along the z axis of its pose.
+	DisplayArrow = "arrow"
+	// DisplayAxes draws each component as an axes triad.
+	DisplayAxes = "axes"
+)
+
+func init() {
+	resource.RegisterService(worldstatestore.API, WorldState,
+		resource.Registration[worldstatestore.Service, *Config]{
+			Constructor: newWorldStateService,
+		},
+	)
+}
+
+type Config struct {
+	Components     []string `json:"components"`
+	ReferenceFrame string   `json:"reference_frame,omitempty"`  // Frame the poses are requested in (defaults to "world")
+	RateHz         float64  `json:"rate_hz,omitempty"`          // Poses requested per second (defaults to 5)
+	Display        string   `json:"display,omitempty"`          // Draw each component as an "arrow" or "axes" (defaults to "arrow")
+	UseEndPosition bool     `json:"use_end_position,omitempty"` // Read arms through arm.EndPosition instead of the frame system (defaults to false)
+	ThresholdMm    float64  `json:"threshold_mm,omitempty"`     // Ignore moves of at most this distance (defaults to 0)
+	ThresholdDeg   float64  `json:"threshold_deg,omitempty"`    // Ignore rotations of at most this angle (defaults to 0)
+	TrailLength    int      `json:"trail_length,omitempty"`     // Number of breadcrumbs kept along each component's path (defaults to 0, no trail)
+	TrailRadiusMm  float64  `json:"trail_radius_mm,omitempty"`  // Radius of each breadcrumb (defaults to 5)
+	Paused         bool     `json:"paused,omitempty"`           // Wait for a start command before requesting poses (defaults to false)
+}
+
+func (cfg *Config) Validate(path string) ([]string, []string, error) {
+	if len(cfg.Components) == 0 {
+		return nil, nil, resource.NewConfigValidationFieldRequiredError(path, "components")
+	}
+
+	seen := make(map[string]bool, len(cfg.Components))
+	for i, name := range cfg.Components {
+		if name == "" {
+			return nil, nil, resource.NewConfigValidationError(path, fmt.Errorf("components[%d] must not be empty", i))
+		}
+
+		if seen[name] {
+			return nil, nil, resource.NewConfigValidationError(path, fmt.Errorf("component %q is listed more than once", name))
+		}
+		seen[name] = true
+	}
+
+	if cfg.RateHz < 0 {
+		return nil, nil, resource.NewConfigValidationError(path, errors.New("rate_hz must not be negative"))
+	}
+
+	if cfg.Display != "" && cfg.Display != DisplayArrow && cfg.Display != DisplayAxes {
+		return nil, nil, resource.NewConfigValidationError(path, fmt.Errorf("display must be %q or %q, got %q", DisplayArrow, DisplayAxes, cfg.Display))
+	}
+
+	if cfg.ThresholdMm < 0 {
+		return nil, nil, resource.NewConfigValidationError(path, errors.New("threshold_mm must not be negative"))
+	}
+
+	if cfg.ThresholdDeg < 0 {
+		return nil, nil, resource.NewConfigValidationError(path, errors.New("threshold_deg must not be negative"))
+	}
+
+	if cfg.TrailLength < 0 {
+		return nil, nil, resource.NewConfigValidationError(path, errors.New("trail_length must not be negative"))
+	}
+
+	if cfg.TrailRadiusMm < 0 {
+		return nil, nil, resource.NewConfigValidationError(path, errors.New("trail_radius_mm must not be negative"))
+	}
+
+	// the frame system is always available to modules, so components are only dependencies when arms are read directly
+	if cfg.UseEndPosition {
+		return append([]string{}, cfg.Components...), nil, nil
+	}
+
+	return nil, nil, nil
+}
+
+// trackedComponent is a component drawn at its latest pose, with the breadcrumbs of the poses it left behind.
+type trackedComponent struct {
+	name   string
+	id     lib.UUID         // UUID of the arrow, or of the triad the axes arrows derive theirs from
+	color  lib.Color        // Color of the arrow and breadcrumbs
+	arm    arm.Arm          // Arm read through EndPosition, nil when read through the frame system
+	pose   spatialmath.Pose // Last drawn pose, nil until the component is first drawn
+	parent string           // Frame the last drawn pose is in
+	trail  []string         // UUIDs of the breadcrumbs, oldest first
+}
+
+type worldStateService struct {
+	*store.Service
+
+	logger logging.Logger
+	config *Config
+
+	frameSystem    framesystem.Service
+	referenceFrame string
+	display        string
+	period         time.Duration
+	trailGeometry  spatialmath.Geometry
+
+	components      []*trackedComponent
+	breadcrumbs     int
+	running         bool
+	componentsMutex sync.RWMutex
+}
+
+func newWorldStateService(
+	ctx context.Context,
+	deps resource.Dependencies,
+	rawConf resource.Config,
+	logger logging.Logger,
+) (worldstatestore.Service, error) {
+	conf, err := resource.NativeConfig[*Config](rawConf)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewWorldStateService(ctx, deps, rawConf.ResourceName(), conf, logger)
+}
+
+func NewWorldStateService(
+	ctx context.Context,
+	deps resource.Dependencies,
+	name resource.Name,
+	conf *Config,
+	logger logging.Logger,
+) (worldstatestore.Service, error) {
+	components := make([]*trackedComponent, 0, len(conf.Components))
+	needsFrameSystem := false
+	for i, componentName := range conf.Components {
+		component := &trackedComponent{
+			name:  componentName,
+			id:    lib.GenerateUUID(),
+			color: lib.PaletteColor(i),
+		}
+
+		if conf.UseEndPosition {
+			// components that are not arms fall back to the frame system
+			if a, err := arm.FromDependencies(deps, componentName); err == nil {
+				component.arm = a
+			}
+		}
+
+		if component.arm == nil {
+			needsFrameSystem = true
+		}
+
+		components = append(components, component)
+	}
+
+	var frameSystem framesystem.Service
+	if needsFrameSystem {
+		fs, err := framesystem.FromDependencies(deps)
+		if err != nil {
+			return nil, fmt.Errorf("Unable to get frame system: %w", err)
+		}
+		frameSystem = fs
+	}
+
+	referenceFrame := conf.ReferenceFrame
+	if referenceFrame == "" {
+		referenceFrame = DefaultReferenceFrame
+	}
+
+	display := conf.Display
+	if display == "" {
+		display = DisplayArrow
+	}
+
+	rate := conf.RateHz
+	if rate <= 0 {
+		rate = DefaultRateHz
+	}
+
+	trailRadius := conf.TrailRadiusMm
+	if trailRadius <= 0 {
+		trailRadius = DefaultTrailRadiusMm
+	}
+
+	trailGeometry, err := lib.NewPrimitiveGeometry(&lib.PrimitiveJSON{Type: lib.PrimitiveSphere, RadiusMm: trailRadius}, "breadcrumb")
+	if err != nil {
+		return nil, err
+	}
+
+	service := &worldStateService{
+		Service:        store.NewService(name, logger),
+		logger:         logger,
+		config:         conf,
+		frameSystem:    frameSystem,
+		referenceFrame: referenceFrame,
+		display:        display,
+		period:         time.Duration(float64(time.Second) / rate),
+		trailGeometry:  trailGeometry,
+		components:     components,
+		running:        !conf.Paused,
+	}
+
+	service.Go(service.poll)
+
+	return service, nil
+}
+
+// poll requests the pose of every component each period while the service is running.
+func (s *worldStateService) poll(ctx context.Context) {
+	ticker := time.NewTicker(s.period)
+	defer ticker.Stop()
+
+	for {
+		s.componentsMutex.RLock()
+		running := s.running
+		s.componentsMutex.RUnlock()
+
+		if running {
+			s.update(ctx)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// update requests the pose of every component and moves the ones that changed. A component whose pose cannot be
+// read keeps its last drawn pose.
+func (s *worldStateService) update(ctx context.Context) {
+	for _, component := range s.components {
+		pose, parent, err := s.pose(ctx, component)
+		if err != nil {
+			if ctx.Err() == nil {
+				s.RecordError(err)
+				s.logger.Warnw("Failed to get component pose", "component", component.name, "error", err.Error())
+			}
+			continue
+		}
+
+		if err := s.move(component, pose, parent); err != nil {
+			s.RecordError(err)
+			s.logger.Warnw("Failed to draw component pose", "component", component.name, "error", err.Error())
+		}
+	}
+}
+
+// pose returns the current pose of a component and the frame it is in.
+func (s *worldStateService) pose(ctx context.Context, component *trackedComponent) (spatialmath.Pose, string, error) {
+	if component.arm != nil {
+		pose, err := component.arm.EndPosition(ctx, nil)
+		if err != nil {
+			return nil, "", err
+		}
+
+		// the end position is relative to the base of the arm, which the frame system names "{arm}_origin"
+		return pose, component.name + "_origin", nil
+	}
+
+	poseInFrame, err := s.frameSystem.GetPose(ctx, component.name, s.referenceFrame, nil, nil)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return poseInFrame.Pose(), poseInFrame.Parent(), nil
+}
+
+// moved reports whether a pose differs from the last drawn one by more than the configured thresholds.
+func (s *worldStateService) moved(component *trackedComponent, pose spatialmath.Pose, parent string) bool {
+	if component.pose == nil || component.parent != parent {
+		return true
+	}
+
+	distance := component.pose.Point().Distance(pose.Point())
+	angle := math.Abs(spatialmath.OrientationBetween(component.pose.Orientation(), pose.Orientation()).AxisAngles().Theta * 180 / math.Pi)
+	if angle > 180 {
+		angle = 360 - angle
+	}
+
+	return distance > s.config.ThresholdMm || angle > s.config.ThresholdDeg
+}
+
+// move draws a component at a new pose, ADDING it the first time and UPDATING it afterwards. When a trail is kept,
+// a breadcrumb is left at the previous pose and the oldest breadcrumbs past the trail length are removed.
+func (s *worldStateService) move(component *trackedComponent, pose spatialmath.Pose, parent string) error {
+	s.componentsMutex.Lock()
+	defer s.componentsMutex.Unlock()
+
+	// the service may have been stopped while the pose was being read
+	if !s.running || !s.moved(component, pose, parent) {
+		return nil
+	}
+
+	transforms, err := s.marker(component, pose, parent)
+	if err != nil {
+		return err
+	}
+
+	if component.pose != nil && s.config.TrailLength > 0 {
+		if err := s.dropBreadcrumb(component); err != nil {
+			return err
+		}
+	}
+
+	if err := s.Put(transforms...); err != nil {
+		return err
+	}
+
+	component.pose = pose
+	component.parent = parent
+	return nil
+}
+
+// marker builds the arrow or axes arrows drawing a component at a pose.
+func (s *worldStateService) marker(component *trackedComponent, pose spatialmath.Pose, parent string) ([]*commonPB.Transform, error) {
+	if s.display == DisplayAxes {
+		return lib.CreateAxes(spatialmath.PoseToProtobuf(pose), component.name, component.id.Bytes(), parent)
+	}
+
+	arrow, err := lib.CreateArrow(spatialmath.PoseToProtobuf(pose), component.name, component.id.Bytes(), &component.color, parent)
+	if err != nil {
+		return nil, err
+	}
+
+	return []*commonPB.Transform{arrow}, nil
+}
+
+// dropBreadcrumb leaves a breadcrumb at the last drawn pose of a component. Must be called with componentsMutex held.
+func (s *worldStateService) dropBreadcrumb(component *trackedComponent) error {
+	id := lib.GenerateUUID()
+	name := fmt.Sprintf("%s-trail-%d", component.name, s.breadcrumbs)
+	opacity := DefaultTrailOpacity
+
+	pose := spatialmath.NewPoseFromPoint(component.pose.Point())
+	transform, err := lib.CreatePrimitive(lib.PrimitiveSphere, s.trailGeometry, spatialmath.PoseToProtobuf(pose), name, id.Bytes(), &component.color, component.parent, opacity, false)
+	if err != nil {
+		return err
+	}
+
+	if err := s.Add(transform); err != nil {
+		return err
+	}
+
+	s.breadcrumbs++
+	component.trail = append(component.trail, id.String())
+	for len(component.trail) > s.config.TrailLength {
+		s.Remove(component.trail[0])
+		component.trail = component.trail[1:]
+	}
+
+	return nil
+}
+
+// clearTrails removes every breadcrumb. Components keep leaving breadcrumbs as they move.
+func (s *worldStateService) clearTrails() int {
+	s.componentsMutex.Lock()
+	defer s.componentsMutex.Unlock()
+
+	count := 0
+	for _, component := range s.components {
+		count += s.Remove(component.trail...)
+		component.trail = nil
+	}
+
+	return count
+}
+
+// setRunning starts or stops requesting poses. Components stay drawn at their last pose while stopped.
+func (s *worldStateService) setRunning(running bool) {
+	s.componentsMutex.Lock()
+	defer s.componentsMutex.Unlock()
+
+	s.running = running
+}
+
+// commands describes the DoCommand keys of the pose tracker service.
+var commands = &command.Set{
+	Model: WorldState,
+	Commands: []command.Command{
+		{
+			Name:        "start",
+			Description: "Starts requesting component poses",
+			Response:    map[string]any{"running": true},
+		},
+		{
+			Name:        "stop",
+			Description: "Stops requesting component poses, keeping the markers at their last pose",
+			Response:    map[string]any{"running": true},
+		},
+		{
+			Name:        "clear_trail",
+			Description: "Removes every breadcrumb",
+			Response:    map[string]any{"breadcrumbs_removed": 0},
+		},
+		command.HideCommand,
+		command.ShowCommand,
+		command.StatsCommand,
+	},
+}
+
+func (service *worldStateService) DoCommand(ctx context.Context, cmd map[string]any) (map[string]any, error) {
+	if result, ok, err := commands.Handle(cmd); ok {
+		return result, err
+	}
+
+	if result, ok, err := service.Visibility(cmd, service.SetVisible); ok {
+		return result, err
+	}
+
+	if _, ok := cmd["start"]; ok {
+		service.setRunning(true)
+		return map[string]any{
+			"success": true,
+			"running": true,
+		}, nil
+	}
+
+	if _, ok := cmd["stop"]; ok {
+		service.setRunning(false)
+		return map[string]any{
+			"success": true,
+			"running": false,
+		}, nil
+	}
+
+	if _, ok := cmd["clear_trail"]; ok {
+		count := service.clearTrails()
+		return map[string]any{
+			"success":             true,
+			"breadcrumbs_removed": count,
+		}, nil
+	}
+
+	if _, ok := cmd["stats"]; ok {
+		return map[string]any{
+			"success": true,
+			"stats":   service.Stats().ToMap(),
+		}, nil
+	}
+
+	return nil, lib.Errorf(lib.ErrInvalidArgument, "Unknown command")
+}
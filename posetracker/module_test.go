@@ -0,0 +1,211 @@
+package posetracker
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/geo/r3"
+	"github.com/viam-labs/draw-tools/lib/command/commandtest"
+	v1 "go.viam.com/api/service/worldstatestore/v1"
+	"go.viam.com/rdk/components/arm"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/robot/framesystem"
+	worldstatestore "go.viam.com/rdk/services/worldstatestore"
+	"go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/testutils/inject"
+	"go.viam.com/test"
+)
+
+// fakeFrameSystem reports every component at the x position most recently set.
+type fakeFrameSystem struct {
+	mu sync.Mutex
+	x  float64
+}
+
+func (f *fakeFrameSystem) set(x float64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.x = x
+}
+
+func (f *fakeFrameSystem) service() *inject.FrameSystemService {
+	fs := inject.NewFrameSystemService("$framesystem")
+	fs.GetPoseFunc = func(
+		ctx context.Context,
+		componentName, destinationFrame string,
+		supplementalTransforms []*referenceframe.LinkInFrame,
+		extra map[string]interface{},
+	) (*referenceframe.PoseInFrame, error) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		return referenceframe.NewPoseInFrame(destinationFrame, spatialmath.NewPoseFromPoint(r3.Vector{X: f.x})), nil
+	}
+
+	return fs
+}
+
+func TestPoseTracker(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	fake := &fakeFrameSystem{}
+	deps := resource.Dependencies{framesystem.PublicServiceName: fake.service()}
+	conf := &Config{Components: []string{"gripper"}, RateHz: 50, ThresholdMm: 5, TrailLength: 1, Paused: true}
+
+	service, err := NewWorldStateService(ctx, deps, worldstatestore.Named("tracker"), conf, logging.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	defer service.Close(ctx)
+
+	// start after subscribing so the stream sees the component being added
+	stream, err := service.StreamTransformChanges(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+
+	_, err = service.DoCommand(ctx, map[string]any{"start": true})
+	test.That(t, err, test.ShouldBeNil)
+
+	added, err := stream.Next()
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, added.ChangeType, test.ShouldEqual, v1.TransformChangeType_TRANSFORM_CHANGE_TYPE_ADDED)
+	test.That(t, added.Transform.ReferenceFrame, test.ShouldEqual, "gripper")
+	test.That(t, added.Transform.PoseInObserverFrame.ReferenceFrame, test.ShouldEqual, "world")
+	test.That(t, added.Transform.Metadata.AsMap()["shape"], test.ShouldEqual, "arrow")
+
+	// moves within the threshold are ignored
+	fake.set(3)
+	time.Sleep(100 * time.Millisecond)
+
+	fake.set(100)
+
+	// the first move leaves a breadcrumb at the previous pose
+	breadcrumb, err := stream.Next()
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, breadcrumb.ChangeType, test.ShouldEqual, v1.TransformChangeType_TRANSFORM_CHANGE_TYPE_ADDED)
+	test.That(t, breadcrumb.Transform.ReferenceFrame, test.ShouldEqual, "gripper-trail-0")
+	test.That(t, breadcrumb.Transform.PoseInObserverFrame.Pose.X, test.ShouldEqual, 0.0)
+
+	updated, err := stream.Next()
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, updated.ChangeType, test.ShouldEqual, v1.TransformChangeType_TRANSFORM_CHANGE_TYPE_UPDATED)
+	test.That(t, updated.Transform.Uuid, test.ShouldResemble, added.Transform.Uuid)
+	test.That(t, updated.Transform.PoseInObserverFrame.Pose.X, test.ShouldEqual, 100.0)
+	test.That(t, updated.UpdatedFields, test.ShouldResemble, []string{"poseInObserverFrame"})
+
+	// the next move drops the oldest breadcrumb past the trail length
+	fake.set(200)
+
+	next, err := stream.Next()
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, next.ChangeType, test.ShouldEqual, v1.TransformChangeType_TRANSFORM_CHANGE_TYPE_ADDED)
+	test.That(t, next.Transform.ReferenceFrame, test.ShouldEqual, "gripper-trail-1")
+
+	removed, err := stream.Next()
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, removed.ChangeType, test.ShouldEqual, v1.TransformChangeType_TRANSFORM_CHANGE_TYPE_REMOVED)
+	test.That(t, removed.Transform.Uuid, test.ShouldResemble, breadcrumb.Transform.Uuid)
+
+	result, err := service.DoCommand(ctx, map[string]any{"stop": true})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, result["running"], test.ShouldEqual, false)
+
+	result, err = service.DoCommand(ctx, map[string]any{"clear_trail": true})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, result["breadcrumbs_removed"], test.ShouldEqual, 1)
+
+	uuids, err := service.ListUUIDs(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, uuids, test.ShouldResemble, [][]byte{added.Transform.Uuid})
+}
+
+func TestPoseTrackerAxes(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	fake := &fakeFrameSystem{}
+	deps := resource.Dependencies{framesystem.PublicServiceName: fake.service()}
+	conf := &Config{Components: []string{"camera"}, RateHz: 50, Display: DisplayAxes, ReferenceFrame: "table", Paused: true}
+
+	service, err := NewWorldStateService(ctx, deps, worldstatestore.Named("tracker"), conf, logging.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	defer service.Close(ctx)
+
+	stream, err := service.StreamTransformChanges(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+
+	_, err = service.DoCommand(ctx, map[string]any{"start": true})
+	test.That(t, err, test.ShouldBeNil)
+
+	frames := map[string]bool{}
+	for len(frames) < 3 {
+		change, err := stream.Next()
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, change.ChangeType, test.ShouldEqual, v1.TransformChangeType_TRANSFORM_CHANGE_TYPE_ADDED)
+		test.That(t, change.Transform.PoseInObserverFrame.ReferenceFrame, test.ShouldEqual, "table")
+		frames[change.Transform.ReferenceFrame] = true
+	}
+
+	test.That(t, frames, test.ShouldResemble, map[string]bool{"camera-x": true, "camera-y": true, "camera-z": true})
+}
+
+func TestPoseTrackerEndPosition(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	a := inject.NewArm("arm")
+	a.EndPositionFunc = func(ctx context.Context, extra map[string]interface{}) (spatialmath.Pose, error) {
+		return spatialmath.NewPoseFromPoint(r3.Vector{Z: 400}), nil
+	}
+
+	// no frame system is needed when every component is an arm read directly
+	deps := resource.Dependencies{arm.Named("arm"): a}
+	conf := &Config{Components: []string{"arm"}, RateHz: 50, UseEndPosition: true, Paused: true}
+
+	service, err := NewWorldStateService(ctx, deps, worldstatestore.Named("tracker"), conf, logging.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	defer service.Close(ctx)
+
+	stream, err := service.StreamTransformChanges(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+
+	_, err = service.DoCommand(ctx, map[string]any{"start": true})
+	test.That(t, err, test.ShouldBeNil)
+
+	added, err := stream.Next()
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, added.Transform.PoseInObserverFrame.ReferenceFrame, test.ShouldEqual, "arm_origin")
+	test.That(t, added.Transform.PoseInObserverFrame.Pose.Z, test.ShouldEqual, 400.0)
+}
+
+func TestValidate(t *testing.T) {
+	deps, _, err := (&Config{Components: []string{"arm", "gripper"}}).Validate("services.0")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, deps, test.ShouldBeEmpty)
+
+	deps, _, err = (&Config{Components: []string{"arm"}, UseEndPosition: true}).Validate("services.0")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, deps, test.ShouldResemble, []string{"arm"})
+
+	_, _, err = (&Config{}).Validate("services.0")
+	test.That(t, err, test.ShouldNotBeNil)
+
+	_, _, err = (&Config{Components: []string{"arm", "arm"}}).Validate("services.0")
+	test.That(t, err, test.ShouldNotBeNil)
+
+	_, _, err = (&Config{Components: []string{"arm"}, Display: "cone"}).Validate("services.0")
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+func FuzzDoCommand(f *testing.F) {
+	fake := &fakeFrameSystem{}
+	deps := resource.Dependencies{framesystem.PublicServiceName: fake.service()}
+	conf := &Config{Components: []string{"gripper"}, RateHz: 50, Paused: true}
+
+	service, err := NewWorldStateService(context.Background(), deps, worldstatestore.Named("tracker"), conf, logging.NewTestLogger(f))
+	test.That(f, err, test.ShouldBeNil)
+	f.Cleanup(func() { service.Close(context.Background()) })
+
+	commandtest.Fuzz(f, commands, service.DoCommand)
+}
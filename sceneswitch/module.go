@@ -0,0 +1,164 @@
+// Package sceneswitch chooses the scene drawn by a draw-shapes world state store service with a switch, one scene
+// per position.
+package sceneswitch
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/viam-labs/draw-tools/client"
+	"github.com/viam-labs/draw-tools/lib"
+	"github.com/viam-labs/draw-tools/lib/command"
+
+	toggleswitch "go.viam.com/rdk/components/switch"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+)
+
+var (
+	SceneSwitch = resource.NewModel("viam-viz", "draw-tools", "scene-switch")
+)
+
+func init() {
+	resource.RegisterComponent(toggleswitch.API, SceneSwitch,
+		resource.Registration[toggleswitch.Switch, *Config]{
+			Constructor: newSceneSwitch,
+		},
+	)
+}
+
+type Config struct {
+	ServiceName string   `json:"service_name"`
+	Scenes      []string `json:"scenes"`             // Scenes of the service drawn at each position, which they label (required)
+	Position    *uint32  `json:"position,omitempty"` // Position applied when the switch starts (defaults to none, leaving the scene as it is)
+}
+
+func (cfg *Config) Validate(path string) ([]string, []string, error) {
+	if cfg.ServiceName == "" {
+		return nil, nil, resource.NewConfigValidationFieldRequiredError(path, "service_name")
+	}
+
+	if len(cfg.Scenes) == 0 {
+		return nil, nil, resource.NewConfigValidationFieldRequiredError(path, "scenes")
+	}
+
+	for i, scene := range cfg.Scenes {
+		if scene == "" {
+			return nil, nil, resource.NewConfigValidationError(path, fmt.Errorf("scenes[%d] must not be empty", i))
+		}
+	}
+
+	if cfg.Position != nil && int(*cfg.Position) >= len(cfg.Scenes) {
+		return nil, nil, resource.NewConfigValidationError(path,
+			fmt.Errorf("position must be less than the %d scenes, got %d", len(cfg.Scenes), *cfg.Position))
+	}
+
+	return []string{cfg.ServiceName}, nil, nil
+}
+
+type sceneSwitch struct {
+	resource.AlwaysRebuild
+
+	name   resource.Name
+	logger logging.Logger
+	config *Config
+
+	client *client.Client
+
+	mu       sync.Mutex
+	position uint32
+}
+
+func newSceneSwitch(
+	ctx context.Context,
+	deps resource.Dependencies,
+	rawConf resource.Config,
+	logger logging.Logger,
+) (toggleswitch.Switch, error) {
+	conf, err := resource.NativeConfig[*Config](rawConf)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewSceneSwitch(ctx, deps, rawConf.ResourceName(), conf, logger)
+}
+
+func NewSceneSwitch(
+	ctx context.Context,
+	deps resource.Dependencies,
+	name resource.Name,
+	conf *Config,
+	logger logging.Logger,
+) (toggleswitch.Switch, error) {
+	drawClient, err := client.FromDependencies(deps, conf.ServiceName)
+	if err != nil {
+		return nil, err
+	}
+
+	component := &sceneSwitch{
+		name:   name,
+		logger: logger,
+		config: conf,
+		client: drawClient,
+	}
+
+	if conf.Position != nil {
+		if err := component.SetPosition(ctx, *conf.Position, nil); err != nil {
+			return nil, fmt.Errorf("Failed to apply position %d: %w", *conf.Position, err)
+		}
+	}
+
+	return component, nil
+}
+
+func (s *sceneSwitch) Name() resource.Name {
+	return s.name
+}
+
+// SetPosition draws the scene of a position. The service only emits the changes between the previous scene and it.
+func (s *sceneSwitch) SetPosition(ctx context.Context, position uint32, extra map[string]interface{}) error {
+	if int(position) >= len(s.config.Scenes) {
+		return lib.Errorf(lib.ErrInvalidArgument, "Position must be less than %d, got %d", len(s.config.Scenes), position)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result, err := s.client.SetScene(ctx, s.config.Scenes[position])
+	if err != nil {
+		return err
+	}
+
+	s.logger.Debugw("Set scene", "scene", result.Scene, "added", result.Added, "updated", result.Updated, "removed", result.Removed)
+	s.position = position
+	return nil
+}
+
+// GetPosition returns the position last set, or 0 if none was set yet.
+func (s *sceneSwitch) GetPosition(ctx context.Context, extra map[string]interface{}) (uint32, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.position, nil
+}
+
+// GetNumberOfPositions returns one position per scene, labeled with its name.
+func (s *sceneSwitch) GetNumberOfPositions(ctx context.Context, extra map[string]interface{}) (uint32, []string, error) {
+	return uint32(len(s.config.Scenes)), append([]string{}, s.config.Scenes...), nil
+}
+
+// commands describes the switch, which has no commands of its own besides the built-in ones.
+var commands = &command.Set{Model: SceneSwitch}
+
+func (s *sceneSwitch) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	if result, ok, err := commands.Handle(cmd); ok {
+		return result, err
+	}
+
+	return nil, fmt.Errorf("Not implemented, use service DoCommand instead")
+}
+
+func (s *sceneSwitch) Close(context.Context) error {
+	return nil
+}
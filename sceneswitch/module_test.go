@@ -0,0 +1,112 @@
+package sceneswitch
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/viam-labs/draw-tools/drawshapes"
+	"github.com/viam-labs/draw-tools/lib"
+	"github.com/viam-labs/draw-tools/lib/command/commandtest"
+	toggleswitch "go.viam.com/rdk/components/switch"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	worldstatestore "go.viam.com/rdk/services/worldstatestore"
+	"go.viam.com/test"
+)
+
+func TestValidate(t *testing.T) {
+	one := uint32(1)
+	two := uint32(2)
+	for _, tc := range []struct {
+		name  string
+		conf  Config
+		valid bool
+	}{
+		{name: "scenes", conf: Config{ServiceName: "shapes", Scenes: []string{"day", "night"}}, valid: true},
+		{name: "position", conf: Config{ServiceName: "shapes", Scenes: []string{"day", "night"}, Position: &one}, valid: true},
+		{name: "no service", conf: Config{Scenes: []string{"day"}}},
+		{name: "no scenes", conf: Config{ServiceName: "shapes"}},
+		{name: "empty scene", conf: Config{ServiceName: "shapes", Scenes: []string{"day", ""}}},
+		{name: "position out of range", conf: Config{ServiceName: "shapes", Scenes: []string{"day", "night"}, Position: &two}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			deps, _, err := tc.conf.Validate("path")
+			if !tc.valid {
+				test.That(t, err, test.ShouldNotBeNil)
+				return
+			}
+
+			test.That(t, err, test.ShouldBeNil)
+			test.That(t, deps, test.ShouldResemble, []string{"shapes"})
+		})
+	}
+}
+
+func TestSetPosition(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	logger := logging.NewTestLogger(t)
+	service, err := drawshapes.NewWorldStateService(ctx, nil, worldstatestore.Named("shapes"), &drawshapes.Config{
+		Scenes: map[string]drawshapes.Scene{
+			"day": {Primitives: []map[string]any{
+				{"type": "box", "dims_mm": map[string]any{"x": 10.0, "y": 10.0, "z": 10.0}, "name": "table"},
+			}},
+			"night": {Primitives: []map[string]any{
+				{"type": "box", "dims_mm": map[string]any{"x": 10.0, "y": 10.0, "z": 10.0}, "name": "table"},
+				{"type": "sphere", "radius_mm": 10.0, "name": "lamp"},
+			}},
+		},
+	}, logger)
+	test.That(t, err, test.ShouldBeNil)
+	defer service.Close(ctx)
+
+	shapes := service.(*drawshapes.Shapes)
+	deps := resource.Dependencies{worldstatestore.Named("shapes"): service}
+
+	// the configured position is drawn at start
+	night := uint32(1)
+	component, err := NewSceneSwitch(ctx, deps, toggleswitch.Named("scenes"),
+		&Config{ServiceName: "shapes", Scenes: []string{"day", "night"}, Position: &night}, logger)
+	test.That(t, err, test.ShouldBeNil)
+	defer component.Close(ctx)
+	test.That(t, shapes.Scene(), test.ShouldEqual, "night")
+	test.That(t, shapes.Named("lamp"), test.ShouldHaveLength, 1)
+
+	count, labels, err := component.GetNumberOfPositions(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, count, test.ShouldEqual, 2)
+	test.That(t, labels, test.ShouldResemble, []string{"day", "night"})
+
+	test.That(t, component.SetPosition(ctx, 0, nil), test.ShouldBeNil)
+	test.That(t, shapes.Scene(), test.ShouldEqual, "day")
+	test.That(t, shapes.Named("table"), test.ShouldHaveLength, 1)
+	test.That(t, shapes.Named("lamp"), test.ShouldBeEmpty)
+
+	position, err := component.GetPosition(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, position, test.ShouldEqual, 0)
+
+	// a position out of range changes nothing
+	err = component.SetPosition(ctx, 2, nil)
+	test.That(t, errors.Is(err, lib.ErrInvalidArgument), test.ShouldBeTrue)
+	test.That(t, shapes.Scene(), test.ShouldEqual, "day")
+
+	// a scene the service does not have fails, keeping the position
+	component, err = NewSceneSwitch(ctx, deps, toggleswitch.Named("missing"),
+		&Config{ServiceName: "shapes", Scenes: []string{"day", "dusk"}}, logger)
+	test.That(t, err, test.ShouldBeNil)
+	defer component.Close(ctx)
+
+	err = component.SetPosition(ctx, 1, nil)
+	test.That(t, errors.Is(err, lib.ErrNotFound), test.ShouldBeTrue)
+	position, err = component.GetPosition(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, position, test.ShouldEqual, 0)
+}
+
+func FuzzDoCommand(f *testing.F) {
+	commandtest.Fuzz(f, commands, (&sceneSwitch{}).DoCommand)
+}